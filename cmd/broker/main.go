@@ -2,55 +2,114 @@ package main
 
 import (
 	"flag"
-	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	_ "net/http/pprof" //nolint:gosec
 	"time"
 
+	"github.com/decentraland/webrtc-broker/internal/config"
 	"github.com/decentraland/webrtc-broker/internal/logging"
-	"github.com/decentraland/webrtc-broker/pkg/authentication"
 	"github.com/decentraland/webrtc-broker/pkg/broker"
-	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
-	pion "github.com/pion/webrtc/v2"
-
-	"github.com/rs/zerolog"
-)
-
-const (
-	profilerPort = 9082
+	"github.com/decentraland/webrtc-broker/pkg/server"
 )
 
 func main() {
-	log := logging.New().Level(zerolog.DebugLevel)
+	cfg := config.DefaultBroker()
+
+	configPath := flag.String("config", "", "config file (.yaml, .yml or .toml)")
+	coordinatorURL := flag.String("coordinatorURL", cfg.CoordinatorURL, "")
+	coordinatorCAFile := flag.String("coordinatorCAFile", "", "CA file used to verify a wss coordinator")
+	fallbackURL := flag.String("fallbackURL", "", "public websocket fallback url, announced to clients")
+	fallbackAddr := flag.String("fallbackAddr", "", "websocket fallback listen address, disabled if empty")
+	recordPath := flag.String("recordPath", "", "traffic recording base path, disabled if empty")
+	recordMaxFileSize := flag.Int64("recordMaxFileSize", 64*1024*1024, "traffic recording file rotation size")
+	flag.Parse()
+
+	log := logging.New()
+
+	if err := config.Load(*configPath, config.BrokerEnvPrefix, &cfg); err != nil {
+		log.Fatal().Err(err).Msg("cannot load config")
+	}
+
+	// NOTE: flags explicitly set take precedence over the config file and the environment
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "coordinatorURL":
+			cfg.CoordinatorURL = *coordinatorURL
+		case "coordinatorCAFile":
+			if cfg.CoordinatorTLS == nil {
+				cfg.CoordinatorTLS = &config.ClientTLS{}
+			}
+
+			cfg.CoordinatorTLS.CAFile = *coordinatorCAFile
+		case "fallbackURL":
+			cfg.FallbackURL = *fallbackURL
+		case "fallbackAddr":
+			cfg.FallbackAddr = *fallbackAddr
+		case "recordPath":
+			cfg.Recorder = &config.Recorder{Path: *recordPath, MaxFileSize: *recordMaxFileSize}
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid config")
+	}
+
+	log = cfg.Logger()
 	defer logging.LogPanic(log)
 
-	auth := authentication.NoopAuthenticator{}
-	config := broker.Config{
-		Auth: &auth,
-		Log:  &log,
-		ICEServers: []pion.ICEServer{
-			{
-				URLs: []string{"stun:stun.l.google.com:19302"},
-			},
-		},
-		Role: protocol.Role_COMMUNICATION_SERVER,
+	brokerConfig, err := cfg.BrokerConfig(&log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create broker config")
 	}
 
-	flag.StringVar(&config.CoordinatorURL, "coordinatorURL", "ws://localhost:9090", "")
-	flag.Parse()
+	// NOTE: the DTLS certificate is also reloaded when the files change, SIGHUP forces it
+	if provider, ok := brokerConfig.CertificateProvider.(*server.FileCertificateProvider); ok {
+		go func() {
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+
+			for range hup {
+				if err := provider.Reload(); err != nil {
+					log.Error().Err(err).Msg("cannot reload DTLS certificate")
+				}
+			}
+		}()
+	}
 
-	go func() {
-		addr := fmt.Sprintf("0.0.0.0:%d", profilerPort)
-		log.Info().Msgf("Starting profiler at %s", addr)
-		log.Debug().Err(http.ListenAndServe(addr, nil))
-	}()
+	if cfg.ProfilerAddr != "" {
+		go func() {
+			log.Info().Msgf("Starting profiler at %s", cfg.ProfilerAddr)
+			log.Debug().Err(http.ListenAndServe(cfg.ProfilerAddr, nil))
+		}()
+	}
 
-	b, err := broker.NewBroker(&config)
+	b, err := broker.NewBroker(brokerConfig)
 	if err != nil {
 		log.Fatal().Err(err).Msg("error creating new broker")
 	}
 
+	if cfg.FallbackAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+
+			// NOTE: without tickets only the http signaling is served, see broker.RegisterFallback
+			if err := b.RegisterFallback(mux); err != nil {
+				log.Warn().Err(err).Msg("websocket fallback disabled")
+			}
+
+			if cfg.HTTPSignaling {
+				b.RegisterHTTPSignaling(mux)
+			}
+
+			log.Info().Msgf("Starting fallback server at %s", cfg.FallbackAddr)
+			log.Fatal().Err(http.ListenAndServe(cfg.FallbackAddr, mux)).Msg("fallback server failure")
+		}()
+	}
+
 	log.Info().Msg("starting communication server node")
 
 	if err := b.Connect(); err != nil {
@@ -63,8 +122,9 @@ func main() {
 
 	go b.ProcessControlMessages()
 
-	seconds := uint64(10)
-	reportTicker := time.NewTicker(time.Duration(seconds) * time.Second)
+	period := time.Duration(cfg.StatsReportPeriod)
+	seconds := uint64(period.Seconds())
+	reportTicker := time.NewTicker(period)
 	summaryGenerator := broker.NewStatsSummaryGenerator()
 
 	for {
@@ -94,6 +154,21 @@ func main() {
 			Uint64("bytes received per second [SCTP]", sctpBytesReceived).
 			Int("peer_count", len(stats.Peers)).
 			Int("topic_count", stats.TopicCount).
+			Str("coordinator_state", stats.CoordinatorState.String()).
+			Uint32("coordinator_reconnects", stats.CoordinatorReconnects).
+			Uint32("reconnects", stats.Reconnects).
+			Uint32("failed_reconnects", stats.FailedReconnects).
+			Uint32("ice_timeouts", stats.ICETimeouts).
+			Uint32("dtls_timeouts", stats.DTLSTimeouts).
+			Uint32("data_channel_timeouts", stats.DataChannelTimeouts).
+			Uint32("auth_timeouts", stats.AuthTimeouts).
+			Uint32("low_quality_disconnects", stats.LowQualityDisconnects).
+			Dur("rtt_avg", summary.RTTAvg).
+			Dur("rtt_p95", summary.RTTP95).
+			Float64("unreliable_loss", summary.UnreliableLoss).
+			Dur("server_rtt_avg", summary.ServerRTTAvg).
+			Dur("server_rtt_p95", summary.ServerRTTP95).
+			Float64("server_unreliable_loss", summary.ServerUnreliableLoss).
 			Msg("")
 	}
 }
@@ -4,32 +4,110 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/decentraland/webrtc-broker/internal/config"
 	"github.com/decentraland/webrtc-broker/internal/logging"
-	"github.com/decentraland/webrtc-broker/pkg/authentication"
 	"github.com/decentraland/webrtc-broker/pkg/coordinator"
 )
 
 func main() {
-	host := flag.String("host", "localhost", "")
-	port := flag.Int("port", 9090, "")
+	cfg := config.DefaultCoordinator()
+
+	configPath := flag.String("config", "", "config file (.yaml, .yml or .toml)")
+	host := flag.String("host", cfg.Host, "")
+	port := flag.Int("port", cfg.Port, "")
+	adminAddr := flag.String("adminAddr", "", "admin api address, disabled if empty, requires adminToken")
+	certFile := flag.String("certFile", "", "TLS certificate file, TLS is enabled if set")
+	keyFile := flag.String("keyFile", "", "TLS key file")
 	flag.Parse()
 
 	log := logging.New()
+
+	if err := config.Load(*configPath, config.CoordinatorEnvPrefix, &cfg); err != nil {
+		log.Fatal().Err(err).Msg("cannot load config")
+	}
+
+	// NOTE: flags explicitly set take precedence over the config file and the environment
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "host":
+			cfg.Host = *host
+		case "port":
+			cfg.Port = *port
+		case "adminAddr":
+			cfg.AdminAddr = *adminAddr
+		case "certFile", "keyFile":
+			if cfg.TLS == nil {
+				cfg.TLS = &config.ServerTLS{}
+			}
+
+			cfg.TLS.CertFile = *certFile
+			cfg.TLS.KeyFile = *keyFile
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid config")
+	}
+
+	log = cfg.Logger()
 	defer logging.LogPanic(log)
 
-	config := coordinator.Config{
-		Auth: &authentication.NoopAuthenticator{},
-		Log:  &log,
+	coordinatorConfig, err := cfg.CoordinatorConfig(&log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create coordinator config")
 	}
-	state := coordinator.MakeState(&config)
+
+	state := coordinator.MakeState(coordinatorConfig)
 
 	go coordinator.Start(state)
 
 	mux := http.NewServeMux()
 	coordinator.Register(state, mux)
 
-	addr := fmt.Sprintf("%s:%d", *host, *port)
-	log.Info().Msgf("starting coordinator at %s", addr)
-	log.Fatal().Err(http.ListenAndServe(addr, mux))
+	if cfg.AdminAddr != "" {
+		adminMux := http.NewServeMux()
+		coordinator.RegisterAdmin(state, adminMux, cfg.AdminAuthenticator())
+
+		go func() {
+			log.Info().Msgf("starting coordinator admin api at %s", cfg.AdminAddr)
+			log.Fatal().Err(http.ListenAndServe(cfg.AdminAddr, adminMux)).Msg("admin listener failed")
+		}()
+	}
+
+	tlsConfig, reloader, err := cfg.TLSConfig(log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load TLS config")
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+
+	if tlsConfig == nil {
+		log.Info().Msgf("starting coordinator at %s", addr)
+		log.Fatal().Err(http.ListenAndServe(addr, mux)).Msg("coordinator listener failed")
+
+		return
+	}
+
+	// NOTE: certificates are also reloaded when the files change, SIGHUP forces it
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+
+		for range hup {
+			if err := reloader.Reload(); err != nil {
+				log.Error().Err(err).Msg("cannot reload certificate")
+			} else {
+				log.Info().Msg("certificate reloaded")
+			}
+		}
+	}()
+
+	srv := &http.Server{Addr: addr, Handler: mux, TLSConfig: tlsConfig}
+
+	log.Info().Msgf("starting coordinator at %s (TLS)", addr)
+	log.Fatal().Err(srv.ListenAndServeTLS("", "")).Msg("coordinator listener failed")
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/internal/config"
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	"github.com/decentraland/webrtc-broker/pkg/broker"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/simulation"
+)
+
+func forEachRecord(files []string, fn func(record *broker.Record)) error {
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		reader, err := broker.NewRecordReader(file)
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		for {
+			record, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				file.Close()
+				return err
+			}
+
+			fn(record)
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func main() {
+	addr := flag.String("coordinatorURL", "ws://localhost:9090", "Coordinator URL")
+	recording := flag.String("recording", "", "recording base path, alternatively pass the recording files as arguments")
+	speed := flag.Float64("speed", 1, "replay pace, 2 replays twice as fast as recorded")
+	configPath := flag.String("config", "", "broker config file (.yaml, .yml or .toml), its iceServers are used "+
+		"if the coordinator doesn't hand out any")
+	flag.Parse()
+
+	// NOTE: the ICE servers are read as the broker reads them, from the config file and the environment
+	cfg := config.Broker{}
+	if err := config.Load(*configPath, config.BrokerEnvPrefix, &cfg); err != nil {
+		log.Fatal("cannot load config ", err)
+	}
+
+	if *speed <= 0 {
+		log.Fatal("speed has to be greater than zero")
+	}
+
+	files := flag.Args()
+
+	if *recording != "" {
+		var err error
+
+		files, err = broker.RecordingFiles(*recording)
+		if err != nil {
+			log.Fatal("cannot list recording files ", err)
+		}
+	}
+
+	if len(files) == 0 {
+		log.Fatal("no recording files")
+	}
+
+	// NOTE: only client traffic is replayed, server to server traffic is regenerated by the cluster itself
+	clients := make(map[uint64]*simulation.Client)
+	count := 0
+
+	err := forEachRecord(files, func(record *broker.Record) {
+		if record.Role == protocol.Role_CLIENT {
+			clients[record.Alias] = nil
+			count++
+		}
+	})
+	if err != nil {
+		log.Fatal("cannot read recording ", err)
+	}
+
+	log.Printf("replaying %d messages from %d clients", count, len(clients))
+
+	auth := authentication.NoopAuthenticator{}
+	iceServers := config.PionICEServers(cfg.ICEServers)
+
+	for alias := range clients {
+		config := simulation.Config{
+			Auth:           &auth,
+			CoordinatorURL: *addr,
+			Log:            logging.New(),
+			ICEServers:     iceServers,
+		}
+
+		clients[alias] = simulation.Start(&config)
+	}
+
+	var base time.Time
+
+	start := time.Now()
+
+	err = forEachRecord(files, func(record *broker.Record) {
+		client := clients[record.Alias]
+		if client == nil {
+			return
+		}
+
+		if base.IsZero() {
+			base = record.Time
+		}
+
+		offset := time.Duration(float64(record.Time.Sub(base)) / *speed)
+		time.Sleep(time.Until(start.Add(offset)))
+
+		if record.Reliable {
+			client.SendReliable <- record.Message
+		} else {
+			client.SendUnreliable <- record.Message
+		}
+	})
+	if err != nil {
+		log.Fatal("cannot read recording ", err)
+	}
+
+	log.Printf("replay finished in %s", time.Since(start))
+}
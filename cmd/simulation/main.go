@@ -6,19 +6,27 @@ import (
 	"log"
 	"time"
 
+	"github.com/decentraland/webrtc-broker/internal/ws"
 	"github.com/decentraland/webrtc-broker/pkg/authentication"
 	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
 	"github.com/decentraland/webrtc-broker/pkg/simulation"
 	"github.com/golang/protobuf/proto"
-	pion "github.com/pion/webrtc/v2"
 )
 
 func main() {
 	addr := flag.String("coordinatorURL", "ws://localhost:9090", "Coordinator URL")
 	nBots := flag.Int("n", 1, "number of bots")
 	trackStats := flag.Bool("trackStats", false, "")
+	forceFallback := flag.Bool("fallback", false, "connect through the server websocket fallback")
+	caFile := flag.String("caFile", "", "CA file used to verify wss urls")
+	serverName := flag.String("serverName", "", "TLS server name (SNI) override")
 	flag.Parse()
 
+	tlsConfig, err := ws.ClientTLSConfig(*caFile, "", "", *serverName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	log.Println("running random simulation")
 
 	auth := authentication.NoopAuthenticator{}
@@ -28,11 +36,8 @@ func main() {
 			config := simulation.Config{
 				Auth:           &auth,
 				CoordinatorURL: *addr,
-				ICEServers: []pion.ICEServer{
-					{
-						URLs: []string{"stun:stun.l.google.com:19302"},
-					},
-				},
+				ForceFallback:  *forceFallback,
+				TLSConfig:      tlsConfig,
 			}
 
 			if *trackStats {
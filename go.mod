@@ -1,6 +1,7 @@
 module github.com/decentraland/webrtc-broker
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/golang/protobuf v1.3.0
 	github.com/gorilla/websocket v1.4.0
 	github.com/pion/datachannel v1.4.13
@@ -9,6 +10,7 @@ require (
 	github.com/rs/zerolog v1.14.3
 	github.com/stretchr/objx v0.1.1 // indirect
 	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.4
 )
 
 go 1.13
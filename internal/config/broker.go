@@ -0,0 +1,590 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/internal/ws"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	"github.com/decentraland/webrtc-broker/pkg/broker"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+	pion "github.com/pion/webrtc/v2"
+)
+
+// Writer controller types
+const (
+	WriterControllerBuffered   = "buffered"
+	WriterControllerFixedQueue = "fixedQueue"
+	WriterControllerDiscard    = "discard"
+	WriterControllerUnbounded  = "unbounded"
+)
+
+// Auth types
+const (
+	AuthNoop = "noop"
+	AuthJWT  = "jwt"
+	AuthHMAC = "hmac"
+)
+
+const defaultMaxPeerBufferSize uint64 = 1024 * 1024 // 1 MB
+
+// BrokerEnvPrefix is the environment variables prefix for the broker config
+const BrokerEnvPrefix = "BROKER"
+
+// ICEServer is a STUN or TURN server
+type ICEServer struct {
+	URLs       []string `yaml:"urls" toml:"urls"`
+	Username   string   `yaml:"username" toml:"username"`
+	Credential string   `yaml:"credential" toml:"credential"`
+}
+
+// JWTAuth is the jwt auth config, see authentication.JWTConfig
+type JWTAuth struct {
+	HMACSecret    string   `yaml:"hmacSecret" toml:"hmacSecret" env:"HMAC_SECRET"`
+	JWKSFile      string   `yaml:"jwksFile" toml:"jwksFile" env:"JWKS_FILE"`
+	Issuer        string   `yaml:"issuer" toml:"issuer" env:"ISSUER"`
+	Audience      string   `yaml:"audience" toml:"audience" env:"AUDIENCE"`
+	Leeway        Duration `yaml:"leeway" toml:"leeway" env:"LEEWAY"`
+	RoleClaim     string   `yaml:"roleClaim" toml:"roleClaim" env:"ROLE_CLAIM"`
+	IdentityClaim string   `yaml:"identityClaim" toml:"identityClaim" env:"IDENTITY_CLAIM"`
+	// TokenFile is the token sent by this peer, read on every use so it can be renewed externally
+	TokenFile string `yaml:"tokenFile" toml:"tokenFile" env:"TOKEN_FILE"`
+}
+
+// HMACAuth is the server to server shared secret auth config, see authentication.HMACConfig
+type HMACAuth struct {
+	// Secrets are the shared secrets, the first one signs
+	Secrets []string `yaml:"secrets" toml:"secrets" env:"SECRETS"`
+	TTL     Duration `yaml:"ttl" toml:"ttl" env:"TTL"`
+	Leeway  Duration `yaml:"leeway" toml:"leeway" env:"LEEWAY"`
+}
+
+// Auth selects the authentication mechanism. With hmac, clients are authenticated with jwt if JWT is set,
+// otherwise they are not authenticated
+type Auth struct {
+	Type string    `yaml:"type" toml:"type" env:"TYPE"`
+	JWT  *JWTAuth  `yaml:"jwt" toml:"jwt" env:"JWT"`
+	HMAC *HMACAuth `yaml:"hmac" toml:"hmac" env:"HMAC"`
+}
+
+func validateJWTAuth(v *validator, a *JWTAuth) {
+	v.check(a.HMACSecret != "" || a.JWKSFile != "", "auth.jwt: hmacSecret or jwksFile required")
+	v.check(a.Leeway >= 0, "auth.jwt.leeway: cannot be negative")
+}
+
+func validateAuth(v *validator, a *Auth) {
+	switch a.Type {
+	case AuthNoop:
+	case AuthJWT:
+		if a.JWT == nil {
+			v.check(false, "auth.jwt: required for jwt auth")
+			return
+		}
+
+		validateJWTAuth(v, a.JWT)
+	case AuthHMAC:
+		if a.HMAC == nil {
+			v.check(false, "auth.hmac: required for hmac auth")
+			return
+		}
+
+		v.check(len(a.HMAC.Secrets) > 0, "auth.hmac.secrets: cannot be empty")
+
+		for i, secret := range a.HMAC.Secrets {
+			v.check(secret != "", "auth.hmac.secrets[%d]: cannot be empty", i)
+		}
+
+		v.check(a.HMAC.TTL >= 0, "auth.hmac.ttl: cannot be negative")
+		v.check(a.HMAC.Leeway >= 0, "auth.hmac.leeway: cannot be negative")
+
+		if a.JWT != nil {
+			validateJWTAuth(v, a.JWT)
+		}
+	default:
+		v.check(false, "auth.type: unknown auth %q", a.Type)
+	}
+}
+
+type authenticator interface {
+	authentication.ServerAuthenticator
+	authentication.CoordinatorAuthenticator
+}
+
+func (a *Auth) authenticator() (authenticator, error) {
+	switch a.Type {
+	case AuthJWT:
+		return a.jwtAuthenticator()
+	case AuthHMAC:
+		config := authentication.HMACConfig{
+			TTL:     time.Duration(a.HMAC.TTL),
+			Leeway:  time.Duration(a.HMAC.Leeway),
+			Clients: &authentication.NoopAuthenticator{},
+		}
+
+		for _, secret := range a.HMAC.Secrets {
+			config.Secrets = append(config.Secrets, []byte(secret))
+		}
+
+		if a.JWT != nil {
+			clients, err := a.jwtAuthenticator()
+			if err != nil {
+				return nil, err
+			}
+
+			config.Clients = clients
+		}
+
+		return authentication.NewHMACAuthenticator(config)
+	default:
+		return &authentication.NoopAuthenticator{}, nil
+	}
+}
+
+func (a *Auth) jwtAuthenticator() (*authentication.JWTAuthenticator, error) {
+	config := authentication.JWTConfig{
+		JWKSFile:      a.JWT.JWKSFile,
+		Issuer:        a.JWT.Issuer,
+		Audience:      a.JWT.Audience,
+		Leeway:        time.Duration(a.JWT.Leeway),
+		RoleClaim:     a.JWT.RoleClaim,
+		IdentityClaim: a.JWT.IdentityClaim,
+	}
+
+	if a.JWT.HMACSecret != "" {
+		config.HMACSecret = []byte(a.JWT.HMACSecret)
+	}
+
+	if a.JWT.TokenFile != "" {
+		config.TokenProvider = authentication.FileTokenProvider(a.JWT.TokenFile)
+	}
+
+	return authentication.NewJWTAuthenticator(config)
+}
+
+// WriterController selects the peer writer flow control, see broker.WriterController
+type WriterController struct {
+	Type string `yaml:"type" toml:"type" env:"TYPE"`
+	// QueueSize is the initial queue size for the buffered controller and the queue size for the fixed one
+	QueueSize     int    `yaml:"queueSize" toml:"queueSize" env:"QUEUE_SIZE"`
+	MaxBufferSize uint64 `yaml:"maxBufferSize" toml:"maxBufferSize" env:"MAX_BUFFER_SIZE"`
+	// BufferedAmountLowThreshold is the data channel threshold at which the controller resumes writing
+	BufferedAmountLowThreshold uint64 `yaml:"bufferedAmountLowThreshold" toml:"bufferedAmountLowThreshold" env:"BUFFERED_AMOUNT_LOW_THRESHOLD"` //nolint:lll
+}
+
+// Recorder is the traffic recorder config, see broker.RecorderConfig
+type Recorder struct {
+	Path        string   `yaml:"path" toml:"path" env:"PATH"`
+	MaxFileSize int64    `yaml:"maxFileSize" toml:"maxFileSize" env:"MAX_FILE_SIZE"`
+	MaxFiles    int      `yaml:"maxFiles" toml:"maxFiles" env:"MAX_FILES"`
+	Topics      []string `yaml:"topics" toml:"topics" env:"TOPICS"`
+	QueueSize   int      `yaml:"queueSize" toml:"queueSize" env:"QUEUE_SIZE"`
+}
+
+// ClientTLS configures the TLS used to dial a wss url, see ws.ClientTLSConfig
+type ClientTLS struct {
+	// CAFile replaces the system root CAs
+	CAFile string `yaml:"caFile" toml:"caFile" env:"CA_FILE"`
+	// CertFile and KeyFile are the client certificate, for coordinators requiring mutual TLS
+	CertFile   string `yaml:"certFile" toml:"certFile" env:"CERT_FILE"`
+	KeyFile    string `yaml:"keyFile" toml:"keyFile" env:"KEY_FILE"`
+	ServerName string `yaml:"serverName" toml:"serverName" env:"SERVER_NAME"`
+}
+
+// TicketVerifier requires peers to present a coordinator connection ticket, see authentication.TicketVerifier
+type TicketVerifier struct {
+	// VerifyingKeyFiles are PKIX PEM ed25519 public keys, several keys allow the coordinator key rotation
+	VerifyingKeyFiles []string `yaml:"verifyingKeyFiles" toml:"verifyingKeyFiles" env:"VERIFYING_KEY_FILES"`
+}
+
+// DTLS configures the DTLS certificate of the peer connections, a PEM certificate and key pair reloaded when the
+// files change, so peers can pin the broker fingerprint
+type DTLS struct {
+	CertFile string `yaml:"certFile" toml:"certFile" env:"CERT_FILE"`
+	KeyFile  string `yaml:"keyFile" toml:"keyFile" env:"KEY_FILE"`
+}
+
+// Network configures the ICE networking of the peer connections, see server.NetworkConfig
+type Network struct {
+	// PortMin and PortMax limit the ephemeral UDP ports, e.g. to the range open in the firewall
+	PortMin uint16 `yaml:"portMin" toml:"portMin" env:"PORT_MIN"`
+	PortMax uint16 `yaml:"portMax" toml:"portMax" env:"PORT_MAX"`
+	// NAT1To1IPs are the public IPs of a 1:1 NAT, they replace the host candidates IPs, or are added as server
+	// reflexive candidates with nat1To1CandidateType srflx
+	NAT1To1IPs           []string `yaml:"nat1To1IPs" toml:"nat1To1IPs" env:"NAT_1TO1_IPS"`
+	NAT1To1CandidateType string   `yaml:"nat1To1CandidateType" toml:"nat1To1CandidateType" env:"NAT_1TO1_CANDIDATE_TYPE"` //nolint:lll
+	// NetworkTypes are udp4 and udp6, both by default
+	NetworkTypes []string `yaml:"networkTypes" toml:"networkTypes" env:"NETWORK_TYPES"`
+	// Interfaces, if set, are the only network interfaces used, ExcludedInterfaces are never used
+	Interfaces         []string `yaml:"interfaces" toml:"interfaces" env:"INTERFACES"`
+	ExcludedInterfaces []string `yaml:"excludedInterfaces" toml:"excludedInterfaces" env:"EXCLUDED_INTERFACES"`
+}
+
+// Broker is the cmd/broker config
+type Broker struct {
+	CoordinatorURL          string          `yaml:"coordinatorURL" toml:"coordinatorURL" env:"COORDINATOR_URL"`
+	CoordinatorTLS          *ClientTLS      `yaml:"coordinatorTLS" toml:"coordinatorTLS" env:"COORDINATOR_TLS"`
+	Role                    string          `yaml:"role" toml:"role" env:"ROLE"`
+	ICEServers              []ICEServer     `yaml:"iceServers" toml:"iceServers"`
+	MaxPeers                uint16          `yaml:"maxPeers" toml:"maxPeers" env:"MAX_PEERS"`
+	ExitOnCoordinatorClose  bool            `yaml:"exitOnCoordinatorClose" toml:"exitOnCoordinatorClose" env:"EXIT_ON_COORDINATOR_CLOSE"`   //nolint:lll
+	EstablishSessionTimeout Duration        `yaml:"establishSessionTimeout" toml:"establishSessionTimeout" env:"ESTABLISH_SESSION_TIMEOUT"` //nolint:lll
+	LogLevel                string          `yaml:"logLevel" toml:"logLevel" env:"LOG_LEVEL"`
+	WebRtcLogLevel          string          `yaml:"webRtcLogLevel" toml:"webRtcLogLevel" env:"WEBRTC_LOG_LEVEL"`
+	Auth                    Auth            `yaml:"auth" toml:"auth" env:"AUTH"`
+	ReauthWindow            Duration        `yaml:"reauthWindow" toml:"reauthWindow" env:"REAUTH_WINDOW"`
+	Ticket                  *TicketVerifier `yaml:"ticket" toml:"ticket" env:"TICKET"`
+
+	ReliableWriter   WriterController `yaml:"reliableWriter" toml:"reliableWriter" env:"RELIABLE_WRITER"`
+	UnreliableWriter WriterController `yaml:"unreliableWriter" toml:"unreliableWriter" env:"UNRELIABLE_WRITER"`
+
+	ProfilerAddr      string    `yaml:"profilerAddr" toml:"profilerAddr" env:"PROFILER_ADDR"`
+	StatsReportPeriod Duration  `yaml:"statsReportPeriod" toml:"statsReportPeriod" env:"STATS_REPORT_PERIOD"`
+	LoadReportPeriod  Duration  `yaml:"loadReportPeriod" toml:"loadReportPeriod" env:"LOAD_REPORT_PERIOD"`
+	FallbackAddr      string    `yaml:"fallbackAddr" toml:"fallbackAddr" env:"FALLBACK_ADDR"`
+	FallbackURL       string    `yaml:"fallbackURL" toml:"fallbackURL" env:"FALLBACK_URL"`
+	Recorder          *Recorder `yaml:"recorder" toml:"recorder" env:"RECORDER"`
+
+	// FallbackAllowedOrigins are the origins browsers can open the fallback websocket from
+	FallbackAllowedOrigins []string `yaml:"fallbackAllowedOrigins" toml:"fallbackAllowedOrigins" env:"FALLBACK_ALLOWED_ORIGINS"` //nolint:lll
+
+	// Labels are announced to the coordinator, as key=value pairs in the environment, e.g. region=eu,pool=a
+	Labels map[string]string `yaml:"labels" toml:"labels" env:"LABELS"`
+
+	// ReconnectGracePeriod is how long a disconnected peer has to recover before it's reconnected, a negative
+	// period closes the disconnected peers
+	ReconnectGracePeriod Duration `yaml:"reconnectGracePeriod" toml:"reconnectGracePeriod" env:"RECONNECT_GRACE_PERIOD"` //nolint:lll
+
+	// Network configures the ICE networking, e.g. for brokers behind a cloud NAT or a firewall
+	Network *Network `yaml:"network" toml:"network" env:"NETWORK"`
+
+	// DTLS, if set, replaces the DTLS certificate generated on startup
+	DTLS *DTLS `yaml:"dtls" toml:"dtls" env:"DTLS"`
+
+	// ICETimeout (establishSessionTimeout by default), DTLSTimeout, DataChannelTimeout and AuthTimeout limit every
+	// phase of a new peer session, see broker.Config
+	ICETimeout         Duration `yaml:"iceTimeout" toml:"iceTimeout" env:"ICE_TIMEOUT"`
+	DTLSTimeout        Duration `yaml:"dtlsTimeout" toml:"dtlsTimeout" env:"DTLS_TIMEOUT"`
+	DataChannelTimeout Duration `yaml:"dataChannelTimeout" toml:"dataChannelTimeout" env:"DATA_CHANNEL_TIMEOUT"`
+	AuthTimeout        Duration `yaml:"authTimeout" toml:"authTimeout" env:"AUTH_TIMEOUT"`
+
+	// PingPeriod (a negative period disables the pings), MaxRTT, MaxUnreliableLoss and LowQualityPeriods configure
+	// the peers connection quality measurement, see broker.Config
+	PingPeriod        Duration `yaml:"pingPeriod" toml:"pingPeriod" env:"PING_PERIOD"`
+	MaxRTT            Duration `yaml:"maxRTT" toml:"maxRTT" env:"MAX_RTT"`
+	MaxUnreliableLoss float64  `yaml:"maxUnreliableLoss" toml:"maxUnreliableLoss" env:"MAX_UNRELIABLE_LOSS"`
+	LowQualityPeriods int      `yaml:"lowQualityPeriods" toml:"lowQualityPeriods" env:"LOW_QUALITY_PERIODS"`
+
+	// HTTPSignaling registers the WHIP style signaling endpoint on the fallbackAddr listener, see
+	// broker.RegisterHTTPSignaling
+	HTTPSignaling bool `yaml:"httpSignaling" toml:"httpSignaling" env:"HTTP_SIGNALING"`
+}
+
+// DefaultBroker returns the broker defaults, the ones used when no config file is provided
+func DefaultBroker() Broker {
+	return Broker{
+		CoordinatorURL: "ws://localhost:9090",
+		Role:           protocol.Role_COMMUNICATION_SERVER.String(),
+		ICEServers: []ICEServer{
+			{URLs: []string{"stun:stun.l.google.com:19302"}},
+		},
+		LogLevel:       zerolog.DebugLevel.String(),
+		WebRtcLogLevel: zerolog.DebugLevel.String(),
+		Auth:           Auth{Type: AuthNoop},
+		ReliableWriter: WriterController{
+			Type:          WriterControllerBuffered,
+			QueueSize:     10,
+			MaxBufferSize: defaultMaxPeerBufferSize,
+		},
+		UnreliableWriter: WriterController{
+			Type:          WriterControllerFixedQueue,
+			QueueSize:     10,
+			MaxBufferSize: defaultMaxPeerBufferSize,
+		},
+		ProfilerAddr:      "0.0.0.0:9082",
+		StatsReportPeriod: Duration(10 * time.Second),
+	}
+}
+
+func validateWriterController(v *validator, field string, c *WriterController) {
+	switch c.Type {
+	case WriterControllerBuffered, WriterControllerUnbounded, WriterControllerDiscard:
+	case WriterControllerFixedQueue:
+		v.check(c.QueueSize > 0, "%s.queueSize: has to be greater than zero for a fixed queue", field)
+	default:
+		v.check(false, "%s.type: unknown writer controller %q", field, c.Type)
+	}
+
+	v.check(c.QueueSize >= 0, "%s.queueSize: cannot be negative", field)
+	v.check(c.Type == WriterControllerUnbounded || c.MaxBufferSize > 0,
+		"%s.maxBufferSize: has to be greater than zero", field)
+}
+
+func validateNetwork(v *validator, n *Network) {
+	v.check((n.PortMin == 0) == (n.PortMax == 0), "network: portMin and portMax have to be set together")
+	v.check(n.PortMax >= n.PortMin, "network.portMax: cannot be lower than portMin")
+
+	for _, ip := range n.NAT1To1IPs {
+		v.check(net.ParseIP(ip) != nil, "network.nat1To1IPs: invalid IP %q", ip)
+	}
+
+	v.check(n.NAT1To1CandidateType == "" || n.NAT1To1CandidateType == pion.ICECandidateTypeHost.String() ||
+		n.NAT1To1CandidateType == pion.ICECandidateTypeSrflx.String(),
+		"network.nat1To1CandidateType: has to be host or srflx, got %q", n.NAT1To1CandidateType)
+
+	for _, networkType := range n.NetworkTypes {
+		_, err := server.ParseNetworkType(networkType)
+		v.check(err == nil, "network.networkTypes: has to be udp4 or udp6, got %q", networkType)
+	}
+}
+
+// Validate checks the config, returning a ValidationError listing every problem
+func (c *Broker) Validate() error {
+	v := &validator{}
+
+	v.check(c.CoordinatorURL != "", "coordinatorURL: cannot be empty")
+
+	if c.CoordinatorTLS != nil {
+		v.check((c.CoordinatorTLS.CertFile == "") == (c.CoordinatorTLS.KeyFile == ""),
+			"coordinatorTLS: certFile and keyFile have to be set together")
+	}
+
+	role := protocol.Role(protocol.Role_value[c.Role])
+	v.check(role == protocol.Role_COMMUNICATION_SERVER || role == protocol.Role_COMMUNICATION_SERVER_HUB,
+		"role: has to be COMMUNICATION_SERVER or COMMUNICATION_SERVER_HUB, got %q", c.Role)
+
+	for i, s := range c.ICEServers {
+		v.check(len(s.URLs) > 0, "iceServers[%d].urls: cannot be empty", i)
+	}
+
+	v.check(c.EstablishSessionTimeout >= 0, "establishSessionTimeout: cannot be negative")
+	v.check(c.ICETimeout >= 0, "iceTimeout: cannot be negative")
+	v.check(c.DTLSTimeout >= 0, "dtlsTimeout: cannot be negative")
+	v.check(c.DataChannelTimeout >= 0, "dataChannelTimeout: cannot be negative")
+	v.check(c.AuthTimeout >= 0, "authTimeout: cannot be negative")
+	v.check(c.MaxRTT >= 0, "maxRTT: cannot be negative")
+	v.check(c.MaxUnreliableLoss >= 0 && c.MaxUnreliableLoss <= 1, "maxUnreliableLoss: has to be between 0 and 1")
+	v.check(c.LowQualityPeriods >= 0, "lowQualityPeriods: cannot be negative")
+	v.check(c.ReauthWindow >= 0, "reauthWindow: cannot be negative")
+	v.check(c.StatsReportPeriod >= Duration(time.Second), "statsReportPeriod: has to be at least 1s")
+	v.check(c.LoadReportPeriod >= 0, "loadReportPeriod: cannot be negative")
+	v.checkLogLevel("logLevel", c.LogLevel)
+	v.checkLogLevel("webRtcLogLevel", c.WebRtcLogLevel)
+	validateAuth(v, &c.Auth)
+
+	if c.Auth.Type == AuthJWT && c.Auth.JWT != nil {
+		v.check(c.Auth.JWT.TokenFile != "", "auth.jwt.tokenFile: required, the broker authenticates with it")
+	}
+
+	validateWriterController(v, "reliableWriter", &c.ReliableWriter)
+	validateWriterController(v, "unreliableWriter", &c.UnreliableWriter)
+
+	v.check(c.FallbackURL == "" || c.FallbackAddr != "", "fallbackURL: requires fallbackAddr")
+	v.check(c.FallbackURL == "" || c.Ticket != nil, "fallbackURL: requires ticket")
+	v.check(!c.HTTPSignaling || c.FallbackAddr != "", "httpSignaling: requires fallbackAddr")
+
+	for key, value := range c.Labels {
+		v.check(key != "" && !strings.ContainsAny(key, ",="), "labels: invalid key %q", key)
+		v.check(!strings.Contains(value, ","), "labels.%s: cannot contain commas", key)
+	}
+
+	if c.Ticket != nil {
+		v.check(len(c.Ticket.VerifyingKeyFiles) > 0, "ticket.verifyingKeyFiles: cannot be empty")
+	}
+
+	if c.Network != nil {
+		validateNetwork(v, c.Network)
+	}
+
+	if c.DTLS != nil {
+		v.check(c.DTLS.CertFile != "" && c.DTLS.KeyFile != "", "dtls: certFile and keyFile are required")
+	}
+
+	if c.Recorder != nil {
+		v.check(c.Recorder.Path != "", "recorder.path: cannot be empty")
+		v.check(c.Recorder.MaxFileSize >= 0, "recorder.maxFileSize: cannot be negative")
+		v.check(c.Recorder.MaxFiles >= 0, "recorder.maxFiles: cannot be negative")
+		v.check(c.Recorder.QueueSize >= 0, "recorder.queueSize: cannot be negative")
+	}
+
+	return v.err()
+}
+
+// Logger creates the logger with the configured level
+func (c *Broker) Logger() logging.Logger {
+	return logging.New().Level(parseLogLevel(c.LogLevel, zerolog.DebugLevel))
+}
+
+func makeWriterControllerFactory(c WriterController) broker.WriterControllerFactory {
+	switch c.Type {
+	case WriterControllerFixedQueue:
+		return func(alias uint64, writer broker.PeerWriter) broker.WriterController {
+			return broker.NewFixedQueueWriterController(writer, c.QueueSize, c.MaxBufferSize)
+		}
+	case WriterControllerDiscard:
+		return func(alias uint64, writer broker.PeerWriter) broker.WriterController {
+			return broker.NewDiscardWriterController(writer, c.MaxBufferSize)
+		}
+	case WriterControllerUnbounded:
+		return func(alias uint64, writer broker.PeerWriter) broker.WriterController {
+			return broker.NewUnboundedWriterController(writer)
+		}
+	default:
+		return func(alias uint64, writer broker.PeerWriter) broker.WriterController {
+			return broker.NewBufferedWriterController(writer, c.QueueSize, c.MaxBufferSize)
+		}
+	}
+}
+
+// interfaceFilter accepts the allowed interfaces, every one if none is listed, except the excluded ones
+func interfaceFilter(allowed []string, excluded []string) func(name string) bool {
+	return func(name string) bool {
+		for _, e := range excluded {
+			if e == name {
+				return false
+			}
+		}
+
+		if len(allowed) == 0 {
+			return true
+		}
+
+		for _, a := range allowed {
+			if a == name {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func (n *Network) networkConfig() server.NetworkConfig {
+	config := server.NetworkConfig{
+		PortMin:    n.PortMin,
+		PortMax:    n.PortMax,
+		NAT1To1IPs: n.NAT1To1IPs,
+	}
+
+	if n.NAT1To1CandidateType != "" {
+		config.NAT1To1CandidateType, _ = pion.NewICECandidateType(n.NAT1To1CandidateType)
+	}
+
+	for _, networkType := range n.NetworkTypes {
+		t, _ := server.ParseNetworkType(networkType)
+		config.NetworkTypes = append(config.NetworkTypes, t)
+	}
+
+	if len(n.Interfaces) > 0 || len(n.ExcludedInterfaces) > 0 {
+		config.InterfaceFilter = interfaceFilter(n.Interfaces, n.ExcludedInterfaces)
+	}
+
+	return config
+}
+
+// PionICEServers converts the configured ICE servers, as used by the broker and the replay tool
+func PionICEServers(servers []ICEServer) []pion.ICEServer {
+	iceServers := make([]pion.ICEServer, len(servers))
+	for i, s := range servers {
+		iceServers[i] = pion.ICEServer{URLs: s.URLs, Username: s.Username}
+
+		if s.Credential != "" {
+			iceServers[i].Credential = s.Credential
+			iceServers[i].CredentialType = pion.ICECredentialTypePassword
+		}
+	}
+
+	return iceServers
+}
+
+// BrokerConfig builds the broker.Config, the config has to be valid
+func (c *Broker) BrokerConfig(log *logging.Logger) (*broker.Config, error) {
+	iceServers := PionICEServers(c.ICEServers)
+
+	auth, err := c.Auth.authenticator()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &broker.Config{
+		CoordinatorURL:                    c.CoordinatorURL,
+		Log:                               log,
+		ICEServers:                        iceServers,
+		Auth:                              auth,
+		ReliableWriterControllerFactory:   makeWriterControllerFactory(c.ReliableWriter),
+		UnreliableWriterControllerFactory: makeWriterControllerFactory(c.UnreliableWriter),
+		ReliableChannelBufferedAmountLowThreshold:   c.ReliableWriter.BufferedAmountLowThreshold,
+		UnreliableChannelBufferedAmountLowThreshold: c.UnreliableWriter.BufferedAmountLowThreshold,
+		MaxPeers:                c.MaxPeers,
+		ExitOnCoordinatorClose:  c.ExitOnCoordinatorClose,
+		EstablishSessionTimeout: time.Duration(c.EstablishSessionTimeout),
+		ReauthWindow:            time.Duration(c.ReauthWindow),
+		LoadReportPeriod:        time.Duration(c.LoadReportPeriod),
+		WebRtcLogLevel:          parseLogLevel(c.WebRtcLogLevel, zerolog.DebugLevel),
+		Role:                    protocol.Role(protocol.Role_value[c.Role]),
+		FallbackURL:             c.FallbackURL,
+		FallbackAllowedOrigins:  c.FallbackAllowedOrigins,
+		Labels:                  c.Labels,
+		ReconnectGracePeriod:    time.Duration(c.ReconnectGracePeriod),
+		ICETimeout:              time.Duration(c.ICETimeout),
+		DTLSTimeout:             time.Duration(c.DTLSTimeout),
+		DataChannelTimeout:      time.Duration(c.DataChannelTimeout),
+		AuthTimeout:             time.Duration(c.AuthTimeout),
+		PingPeriod:              time.Duration(c.PingPeriod),
+		MaxRTT:                  time.Duration(c.MaxRTT),
+		MaxUnreliableLoss:       c.MaxUnreliableLoss,
+		LowQualityPeriods:       c.LowQualityPeriods,
+	}
+
+	if c.Ticket != nil {
+		keys := make([]ed25519.PublicKey, len(c.Ticket.VerifyingKeyFiles))
+
+		for i, path := range c.Ticket.VerifyingKeyFiles {
+			if keys[i], err = authentication.LoadTicketVerifyingKey(path); err != nil {
+				return nil, err
+			}
+		}
+
+		config.TicketVerifier = authentication.NewTicketVerifier(keys...)
+	}
+
+	if c.Network != nil {
+		config.Network = c.Network.networkConfig()
+	}
+
+	if c.DTLS != nil {
+		provider, err := server.NewFileCertificateProvider(c.DTLS.CertFile, c.DTLS.KeyFile, *log)
+		if err != nil {
+			return nil, err
+		}
+
+		config.CertificateProvider = provider
+	}
+
+	if c.CoordinatorTLS != nil {
+		tlsConfig, err := ws.ClientTLSConfig(c.CoordinatorTLS.CAFile, c.CoordinatorTLS.CertFile,
+			c.CoordinatorTLS.KeyFile, c.CoordinatorTLS.ServerName)
+		if err != nil {
+			return nil, err
+		}
+
+		config.CoordinatorTLSConfig = tlsConfig
+	}
+
+	if c.Recorder != nil {
+		config.Recorder = &broker.RecorderConfig{
+			Path:        c.Recorder.Path,
+			MaxFileSize: c.Recorder.MaxFileSize,
+			MaxFiles:    c.Recorder.MaxFiles,
+			Topics:      c.Recorder.Topics,
+			QueueSize:   c.Recorder.QueueSize,
+		}
+	}
+
+	return config, nil
+}
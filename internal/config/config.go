@@ -0,0 +1,277 @@
+// Package config contains the command config files definition and loading
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v2"
+)
+
+// Duration is a time.Duration that can be decoded from strings like "10s"
+type Duration time.Duration
+
+// UnmarshalText parses a duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// ValidationError lists every problem found in a config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) checkLogLevel(field string, level string) {
+	if level == "" {
+		return
+	}
+
+	_, err := zerolog.ParseLevel(level)
+	v.check(err == nil, "%s: invalid log level %q", field, level)
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Problems: v.problems}
+}
+
+func parseLogLevel(level string, defaultLevel zerolog.Level) zerolog.Level {
+	if level == "" {
+		return defaultLevel
+	}
+
+	l, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return defaultLevel
+	}
+
+	return l
+}
+
+// Load decodes the config file into v, the format is chosen by extension (.yaml, .yml or .toml), and unknown
+// keys are rejected. An empty path skips the file, so only the environment is applied.
+//
+// After the file, fields tagged with `env:"NAME"` are overridden by the environment variable PREFIX_NAME,
+// struct fields tagged the same way nest their children names, i.e. PREFIX_PARENT_NAME.
+func Load(path string, envPrefix string, v interface{}) error {
+	if path != "" {
+		if err := decodeFile(path, v); err != nil {
+			return err
+		}
+	}
+
+	return applyEnv(envPrefix, reflect.ValueOf(v).Elem())
+}
+
+func decodeFile(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.UnmarshalStrict(data, v); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), v)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, key := range undecoded {
+				keys[i] = key.String()
+			}
+
+			return fmt.Errorf("%s: unknown keys %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		return errors.New("unsupported config file format, use .yaml, .yml or .toml")
+	}
+
+	return nil
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+func applyEnv(prefix string, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		name = prefix + "_" + name
+		fv := v.Field(i)
+
+		if fv.Kind() == reflect.Struct {
+			if err := applyEnv(name, fv); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				if !hasEnvPrefix(name + "_") {
+					continue
+				}
+
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+
+			if err := applyEnv(name, fv.Elem()); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := setValue(fv, value); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func hasEnvPrefix(prefix string) bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func setValue(fv reflect.Value, value string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		fv.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetFloat(n)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+
+		items := []string{}
+
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+
+		fv.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String || fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+
+		items := map[string]string{}
+
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid item %q, expected key=value", item)
+			}
+
+			items[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+
+		fv.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	return nil
+}
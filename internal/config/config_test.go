@@ -0,0 +1,411 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	"github.com/decentraland/webrtc-broker/pkg/coordinator"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+	pion "github.com/pion/webrtc/v2"
+)
+
+func setEnv(t *testing.T, env map[string]string) func() {
+	for k, v := range env {
+		require.NoError(t, os.Setenv(k, v))
+	}
+
+	return func() {
+		for k := range env {
+			require.NoError(t, os.Unsetenv(k))
+		}
+	}
+}
+
+func writeConfig(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+	return path
+}
+
+func TestLoadBroker(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		c := DefaultBroker()
+		require.NoError(t, Load("testdata/broker.yaml", BrokerEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+
+		require.Equal(t, "ws://coordinator:9090", c.CoordinatorURL)
+		require.Len(t, c.ICEServers, 2)
+		require.Equal(t, "secret", c.ICEServers[1].Credential)
+		require.Equal(t, Duration(30*time.Second), c.EstablishSessionTimeout)
+		require.Equal(t, WriterControllerDiscard, c.UnreliableWriter.Type)
+		require.Equal(t, []string{"position:"}, c.Recorder.Topics)
+		require.True(t, c.HTTPSignaling)
+		require.Equal(t, []string{"https://play.example.com"}, c.FallbackAllowedOrigins)
+
+		log := logging.New()
+		brokerConfig, err := c.BrokerConfig(&log)
+		require.NoError(t, err)
+
+		require.Equal(t, protocol.Role_COMMUNICATION_SERVER_HUB, brokerConfig.Role)
+		require.Equal(t, uint16(500), brokerConfig.MaxPeers)
+		require.True(t, brokerConfig.ExitOnCoordinatorClose)
+		require.Equal(t, 30*time.Second, brokerConfig.EstablishSessionTimeout)
+		require.Equal(t, 5*time.Second, brokerConfig.AuthTimeout)
+		require.Equal(t, 500*time.Millisecond, brokerConfig.MaxRTT)
+		require.Equal(t, 0.2, brokerConfig.MaxUnreliableLoss)
+		require.Equal(t, zerolog.WarnLevel, brokerConfig.WebRtcLogLevel)
+		require.Equal(t, uint64(65536), brokerConfig.ReliableChannelBufferedAmountLowThreshold)
+		require.Equal(t, "user", brokerConfig.ICEServers[1].Username)
+		require.Equal(t, "secret", brokerConfig.ICEServers[1].Credential)
+		require.Equal(t, int64(67108864), brokerConfig.Recorder.MaxFileSize)
+		require.Equal(t, uint16(50000), brokerConfig.Network.PortMin)
+		require.Equal(t, uint16(50100), brokerConfig.Network.PortMax)
+		require.Equal(t, []string{"203.0.113.10"}, brokerConfig.Network.NAT1To1IPs)
+		require.Equal(t, []server.NetworkType{pion.NetworkTypeUDP4}, brokerConfig.Network.NetworkTypes)
+		require.False(t, brokerConfig.Network.InterfaceFilter("docker0"))
+		require.True(t, brokerConfig.Network.InterfaceFilter("eth0"))
+		require.NotNil(t, brokerConfig.ReliableWriterControllerFactory)
+		require.NotNil(t, brokerConfig.UnreliableWriterControllerFactory)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		c := DefaultBroker()
+		require.NoError(t, Load("", BrokerEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+		require.Nil(t, c.Recorder)
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"BROKER_MAX_PEERS":                    "10",
+			"BROKER_ESTABLISH_SESSION_TIMEOUT":    "5s",
+			"BROKER_RELIABLE_WRITER_TYPE":         "unbounded",
+			"BROKER_RECORDER_PATH":                "/tmp/traffic",
+			"BROKER_RECORDER_TOPICS":              "a, b",
+			"BROKER_EXIT_ON_COORDINATOR_CLOSE":    "false",
+			"BROKER_UNRELIABLE_WRITER_QUEUE_SIZE": "42",
+			"BROKER_LABELS":                       "region=eu, pool=realm1",
+			"BROKER_RECONNECT_GRACE_PERIOD":       "-1s",
+			"BROKER_NETWORK_INTERFACES":           "eth0",
+			"BROKER_DATA_CHANNEL_TIMEOUT":         "3s",
+		})()
+
+		c := DefaultBroker()
+		require.NoError(t, Load("testdata/broker.yaml", BrokerEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+
+		require.Equal(t, uint16(10), c.MaxPeers)
+		require.Equal(t, Duration(5*time.Second), c.EstablishSessionTimeout)
+		require.Equal(t, WriterControllerUnbounded, c.ReliableWriter.Type)
+		require.Equal(t, "/tmp/traffic", c.Recorder.Path)
+		require.Equal(t, []string{"a", "b"}, c.Recorder.Topics)
+		require.False(t, c.ExitOnCoordinatorClose)
+		require.Equal(t, 42, c.UnreliableWriter.QueueSize)
+		require.Equal(t, map[string]string{"region": "eu", "pool": "realm1"}, c.Labels)
+		require.Equal(t, Duration(-time.Second), c.ReconnectGracePeriod)
+		require.Equal(t, []string{"eth0"}, c.Network.Interfaces)
+		require.Equal(t, Duration(3*time.Second), c.DataChannelTimeout)
+	})
+
+	t.Run("invalid env value", func(t *testing.T) {
+		defer setEnv(t, map[string]string{"BROKER_MAX_PEERS": "many"})()
+
+		c := DefaultBroker()
+		require.Error(t, Load("", BrokerEnvPrefix, &c))
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config")
+		require.NoError(t, err)
+
+		defer os.RemoveAll(dir)
+
+		c := DefaultBroker()
+		require.Error(t, Load(writeConfig(t, dir, "broker.yaml", "maxPers: 10\n"), BrokerEnvPrefix, &c))
+		require.Error(t, Load(writeConfig(t, dir, "broker.toml", "maxPers = 10\n"), BrokerEnvPrefix, &c))
+		require.Error(t, Load(writeConfig(t, dir, "broker.json", "{}"), BrokerEnvPrefix, &c))
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		c := DefaultBroker()
+		c.Role = "CLIENT"
+		c.LogLevel = "loud"
+		c.ICEServers = []ICEServer{{}}
+		c.ReliableWriter.Type = "magic"
+		c.UnreliableWriter.QueueSize = 0
+		c.FallbackURL = "wss://broker/fallback"
+		c.Recorder = &Recorder{}
+		c.CoordinatorTLS = &ClientTLS{CertFile: "server.pem"}
+		c.Auth = Auth{Type: AuthJWT, JWT: &JWTAuth{}}
+		c.Ticket = &TicketVerifier{}
+		c.ReauthWindow = Duration(-time.Second)
+		c.LoadReportPeriod = Duration(-time.Second)
+		c.Labels = map[string]string{"pool=a": "b"}
+		c.Network = &Network{PortMax: 10, NAT1To1IPs: []string{"public"}, NetworkTypes: []string{"tcp4"}}
+		c.DTLS = &DTLS{CertFile: "dtls.pem"}
+		c.AuthTimeout = Duration(-time.Second)
+		c.MaxUnreliableLoss = 1.5
+		c.HTTPSignaling = true
+
+		err := c.Validate()
+		require.Error(t, err)
+
+		validationError, ok := err.(*ValidationError)
+		require.True(t, ok)
+		require.Len(t, validationError.Problems, 21)
+	})
+
+	t.Run("fallback without tickets", func(t *testing.T) {
+		c := DefaultBroker()
+		c.FallbackAddr = "0.0.0.0:9083"
+		c.FallbackURL = "wss://broker/fallback"
+		require.Error(t, c.Validate())
+
+		c.Ticket = &TicketVerifier{VerifyingKeyFiles: []string{"ticket.pub"}}
+		require.NoError(t, c.Validate())
+	})
+
+	t.Run("dtls certificate", func(t *testing.T) {
+		c := DefaultBroker()
+		c.DTLS = &DTLS{CertFile: "missing.pem", KeyFile: "missing.key"}
+		require.NoError(t, c.Validate())
+
+		log := logging.New()
+		_, err := c.BrokerConfig(&log)
+		require.Error(t, err)
+	})
+}
+
+func TestLoadCoordinator(t *testing.T) {
+	t.Run("toml", func(t *testing.T) {
+		c := DefaultCoordinator()
+		require.NoError(t, Load("testdata/coordinator.toml", CoordinatorEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+
+		require.Equal(t, "0.0.0.0", c.Host)
+		require.Equal(t, 8080, c.Port)
+		require.Equal(t, "127.0.0.1:9091", c.AdminAddr)
+		require.Equal(t, "admin-secret", c.AdminToken)
+
+		log := logging.New()
+		coordinatorConfig, err := c.CoordinatorConfig(&log)
+		require.NoError(t, err)
+		require.Equal(t, time.Minute, coordinatorConfig.ReportPeriod)
+		require.NotNil(t, coordinatorConfig.Reporter)
+		require.Equal(t, []string{"stun:stun.l.google.com:19302"}, coordinatorConfig.ICE.Servers[0].URLs)
+		require.Equal(t, []string{"turn:turn.example.com:3478"}, coordinatorConfig.ICE.TURNURLs)
+		require.Equal(t, time.Hour, coordinatorConfig.ICE.TURNCredentialTTL)
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"COORDINATOR_PORT":                   "9999",
+			"COORDINATOR_AUTH_TYPE":              "noop",
+			"COORDINATOR_SERVER_SELECTOR":        "loadAware",
+			"COORDINATOR_LABELS_REQUIRED_LABELS": "pool",
+			"COORDINATOR_AFFINITY_REPLICAS":      "50",
+		})()
+
+		c := DefaultCoordinator()
+		require.NoError(t, Load("testdata/coordinator.toml", CoordinatorEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+		require.Equal(t, 9999, c.Port)
+
+		log := logging.New()
+		coordinatorConfig, err := c.CoordinatorConfig(&log)
+		require.NoError(t, err)
+		require.Equal(t, []string{"pool"}, c.Labels.RequiredLabels)
+		require.Equal(t, 50, c.Affinity.Replicas)
+		require.IsType(t, &coordinator.ConsistentHashServerSelector{}, coordinatorConfig.ServerSelector)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config")
+		require.NoError(t, err)
+
+		defer os.RemoveAll(dir)
+
+		defer setEnv(t, map[string]string{
+			"COORDINATOR_CLUSTER_STORE_DIR":   dir,
+			"COORDINATOR_CLUSTER_INSTANCE_ID": "coordinator-1",
+			"COORDINATOR_CLUSTER_SYNC_PERIOD": "2s",
+		})()
+
+		c := DefaultCoordinator()
+		require.NoError(t, Load("", CoordinatorEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+
+		log := logging.New()
+		coordinatorConfig, err := c.CoordinatorConfig(&log)
+		require.NoError(t, err)
+		require.IsType(t, &coordinator.FileStore{}, coordinatorConfig.Store)
+		require.Equal(t, "coordinator-1", coordinatorConfig.InstanceID)
+		require.Equal(t, 2*time.Second, coordinatorConfig.SyncPeriod)
+	})
+
+	t.Run("admission", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"COORDINATOR_ADMISSION_ALLOWED_ORIGINS":        "https://a.example.com,https://b.example.com",
+			"COORDINATOR_ADMISSION_MAX_CONNECTIONS_PER_IP": "10",
+			"COORDINATOR_ADMISSION_SIGNALING_RATE":         "2.5",
+		})()
+
+		c := DefaultCoordinator()
+		require.NoError(t, Load("", CoordinatorEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+
+		log := logging.New()
+		coordinatorConfig, err := c.CoordinatorConfig(&log)
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://a.example.com", "https://b.example.com"},
+			coordinatorConfig.Admission.AllowedOrigins)
+		require.Equal(t, 10, coordinatorConfig.Admission.MaxConnectionsPerIP)
+		require.Equal(t, 2.5, coordinatorConfig.Admission.SignalingRate)
+	})
+
+	t.Run("jwt auth", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"COORDINATOR_AUTH_TYPE":            "jwt",
+			"COORDINATOR_AUTH_JWT_HMAC_SECRET": "secret",
+			"COORDINATOR_AUTH_JWT_AUDIENCE":    "coordinator",
+		})()
+
+		c := DefaultCoordinator()
+		require.NoError(t, Load("", CoordinatorEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+
+		log := logging.New()
+		coordinatorConfig, err := c.CoordinatorConfig(&log)
+		require.NoError(t, err)
+		require.IsType(t, &authentication.JWTAuthenticator{}, coordinatorConfig.Auth)
+
+		c.Auth.JWT = nil
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("hmac auth", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"COORDINATOR_AUTH_TYPE":            "hmac",
+			"COORDINATOR_AUTH_HMAC_SECRETS":    "new,old",
+			"COORDINATOR_AUTH_JWT_HMAC_SECRET": "secret",
+		})()
+
+		c := DefaultCoordinator()
+		require.NoError(t, Load("", CoordinatorEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+		require.Equal(t, []string{"new", "old"}, c.Auth.HMAC.Secrets)
+
+		log := logging.New()
+		coordinatorConfig, err := c.CoordinatorConfig(&log)
+		require.NoError(t, err)
+		require.IsType(t, &authentication.HMACAuthenticator{}, coordinatorConfig.Auth)
+
+		c.Auth.HMAC.Secrets = []string{""}
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("tls env", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"COORDINATOR_TLS_CERT_FILE":                  "cert.pem",
+			"COORDINATOR_TLS_KEY_FILE":                   "key.pem",
+			"COORDINATOR_TLS_CLIENT_CA_FILE":             "ca.pem",
+			"COORDINATOR_TLS_REQUIRE_SERVER_CERTIFICATE": "true",
+		})()
+
+		c := DefaultCoordinator()
+		require.NoError(t, Load("", CoordinatorEnvPrefix, &c))
+		require.NoError(t, c.Validate())
+		require.Equal(t, "cert.pem", c.TLS.CertFile)
+
+		log := logging.New()
+		coordinatorConfig, err := c.CoordinatorConfig(&log)
+		require.NoError(t, err)
+		require.True(t, coordinatorConfig.RequireServerCertificate)
+
+		_, _, err = c.TLSConfig(log)
+		require.Error(t, err)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		c := DefaultCoordinator()
+		c.Port = 0
+		c.AdminAddr = "127.0.0.1:9091"
+		c.ServerSelector = "random"
+		c.Auth.Type = "none"
+		c.TLS = &ServerTLS{RequireServerCertificate: true}
+		c.Ticket = &TicketSigner{TTL: Duration(-time.Second)}
+		c.LoadReportTimeout = Duration(-time.Second)
+		c.Affinity = &AffinitySelector{Replicas: -1}
+		c.Cluster = &Cluster{SyncPeriod: Duration(-time.Second)}
+		c.Admission = &Admission{MaxConnections: -1, SignalingRate: -1}
+		c.FullServerCooldown = Duration(-time.Second)
+		c.ICE = &ICE{Servers: []ICEServer{{}}, TURNURLs: []string{"turn:turn.example.com"}}
+
+		err := c.Validate()
+		require.Error(t, err)
+		require.Len(t, err.(*ValidationError).Problems, 18)
+	})
+}
+
+func TestTicketKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	publicDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	privatePath := writeConfig(t, dir, "ticket.key",
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer})))
+	publicPath := writeConfig(t, dir, "ticket.pub",
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})))
+
+	log := logging.New()
+
+	coordinator := DefaultCoordinator()
+	coordinator.Ticket = &TicketSigner{SigningKeyFile: privatePath, TTL: Duration(30 * time.Second)}
+	require.NoError(t, coordinator.Validate())
+
+	coordinatorConfig, err := coordinator.CoordinatorConfig(&log)
+	require.NoError(t, err)
+	require.NotNil(t, coordinatorConfig.TicketSigner)
+
+	broker := DefaultBroker()
+	broker.Ticket = &TicketVerifier{VerifyingKeyFiles: []string{publicPath}}
+	require.NoError(t, broker.Validate())
+
+	brokerConfig, err := broker.BrokerConfig(&log)
+	require.NoError(t, err)
+	require.NotNil(t, brokerConfig.TicketVerifier)
+
+	ticket, err := coordinatorConfig.TicketSigner.Sign(1, 2, protocol.Role_CLIENT, nil)
+	require.NoError(t, err)
+
+	_, err = brokerConfig.TicketVerifier.VerifyPeer(ticket, 1, 2, protocol.Role_CLIENT, nil)
+	require.NoError(t, err)
+
+	broker.Ticket.VerifyingKeyFiles = []string{privatePath}
+	_, err = broker.BrokerConfig(&log)
+	require.Error(t, err)
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/internal/ws"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	"github.com/decentraland/webrtc-broker/pkg/coordinator"
+)
+
+// Server selector types
+const (
+	ServerSelectorDefault   = "default"
+	ServerSelectorLoadAware = "loadAware"
+)
+
+// CoordinatorEnvPrefix is the environment variables prefix for the coordinator config
+const CoordinatorEnvPrefix = "COORDINATOR"
+
+// ServerTLS enables TLS serving, the certificate files are reloaded when they change
+type ServerTLS struct {
+	CertFile string `yaml:"certFile" toml:"certFile" env:"CERT_FILE"`
+	KeyFile  string `yaml:"keyFile" toml:"keyFile" env:"KEY_FILE"`
+	// ClientCAFile verifies the client certificates presented by the servers
+	ClientCAFile string `yaml:"clientCAFile" toml:"clientCAFile" env:"CLIENT_CA_FILE"`
+	// RequireServerCertificate rejects servers without a certificate verified with ClientCAFile
+	RequireServerCertificate bool `yaml:"requireServerCertificate" toml:"requireServerCertificate" env:"REQUIRE_SERVER_CERTIFICATE"` //nolint:lll
+}
+
+// TicketSigner enables the connection tickets, see authentication.TicketSigner
+type TicketSigner struct {
+	// SigningKeyFile is a PKCS #8 PEM ed25519 private key
+	SigningKeyFile string   `yaml:"signingKeyFile" toml:"signingKeyFile" env:"SIGNING_KEY_FILE"`
+	TTL            Duration `yaml:"ttl" toml:"ttl" env:"TTL"`
+}
+
+// LabelSelector makes the server selection label aware, on top of the configured selector, see
+// coordinator.LabelAwareServerSelectorConfig
+type LabelSelector struct {
+	RequiredLabels []string `yaml:"requiredLabels" toml:"requiredLabels" env:"REQUIRED_LABELS"`
+	MeshLabels     []string `yaml:"meshLabels" toml:"meshLabels" env:"MESH_LABELS"`
+}
+
+// AffinitySelector sends the clients with the same affinity key to the same server, on top of the configured
+// selector, see coordinator.ConsistentHashServerSelector
+type AffinitySelector struct {
+	Replicas int `yaml:"replicas" toml:"replicas" env:"REPLICAS"`
+}
+
+// Cluster enables running several coordinator instances sharing their state, see coordinator.Store
+type Cluster struct {
+	// StoreDir is the coordinator.FileStore directory, shared by every instance
+	StoreDir   string   `yaml:"storeDir" toml:"storeDir" env:"STORE_DIR"`
+	InstanceID string   `yaml:"instanceID" toml:"instanceID" env:"INSTANCE_ID"`
+	SyncPeriod Duration `yaml:"syncPeriod" toml:"syncPeriod" env:"SYNC_PERIOD"`
+}
+
+// Admission protects the coordinator from abusive peers, see coordinator.AdmissionConfig
+type Admission struct {
+	AllowedOrigins       []string `yaml:"allowedOrigins" toml:"allowedOrigins" env:"ALLOWED_ORIGINS"`
+	MaxConnections       int      `yaml:"maxConnections" toml:"maxConnections" env:"MAX_CONNECTIONS"`
+	MaxConnectionsPerIP  int      `yaml:"maxConnectionsPerIP" toml:"maxConnectionsPerIP" env:"MAX_CONNECTIONS_PER_IP"`
+	HandshakeRate        float64  `yaml:"handshakeRate" toml:"handshakeRate" env:"HANDSHAKE_RATE"`
+	HandshakeBurst       int      `yaml:"handshakeBurst" toml:"handshakeBurst" env:"HANDSHAKE_BURST"`
+	SignalingRate        float64  `yaml:"signalingRate" toml:"signalingRate" env:"SIGNALING_RATE"`
+	SignalingBurst       int      `yaml:"signalingBurst" toml:"signalingBurst" env:"SIGNALING_BURST"`
+	ServerSignalingRate  float64  `yaml:"serverSignalingRate" toml:"serverSignalingRate" env:"SERVER_SIGNALING_RATE"`
+	ServerSignalingBurst int      `yaml:"serverSignalingBurst" toml:"serverSignalingBurst" env:"SERVER_SIGNALING_BURST"`
+	// RealIPHeader is only safe behind a reverse proxy that sets the header
+	RealIPHeader string `yaml:"realIPHeader" toml:"realIPHeader" env:"REAL_IP_HEADER"`
+}
+
+// ICE are the STUN and TURN servers handed out to the peers, see coordinator.ICEConfig
+type ICE struct {
+	Servers []ICEServer `yaml:"servers" toml:"servers"`
+	// TURNURLs get time limited credentials generated with TURNSecret, following the TURN REST API
+	TURNURLs          []string `yaml:"turnURLs" toml:"turnURLs" env:"TURN_URLS"`
+	TURNSecret        string   `yaml:"turnSecret" toml:"turnSecret" env:"TURN_SECRET"`
+	TURNCredentialTTL Duration `yaml:"turnCredentialTTL" toml:"turnCredentialTTL" env:"TURN_CREDENTIAL_TTL"`
+}
+
+// Coordinator is the cmd/coordinator config
+type Coordinator struct {
+	Host           string `yaml:"host" toml:"host" env:"HOST"`
+	Port           int    `yaml:"port" toml:"port" env:"PORT"`
+	AdminAddr      string `yaml:"adminAddr" toml:"adminAddr" env:"ADMIN_ADDR"`
+	AdminToken     string `yaml:"adminToken" toml:"adminToken" env:"ADMIN_TOKEN"`
+	LogLevel       string `yaml:"logLevel" toml:"logLevel" env:"LOG_LEVEL"`
+	Auth           Auth   `yaml:"auth" toml:"auth" env:"AUTH"`
+	ServerSelector string `yaml:"serverSelector" toml:"serverSelector" env:"SERVER_SELECTOR"`
+	// LoadReportTimeout is how long a server load report is considered by the loadAware selector
+	LoadReportTimeout Duration `yaml:"loadReportTimeout" toml:"loadReportTimeout" env:"LOAD_REPORT_TIMEOUT"`
+	// FullServerCooldown is how long a server that refused a client because it was full is not advertised
+	FullServerCooldown Duration `yaml:"fullServerCooldown" toml:"fullServerCooldown" env:"FULL_SERVER_COOLDOWN"`
+	// Labels enables the label aware server selection
+	Labels *LabelSelector `yaml:"labels" toml:"labels" env:"LABELS"`
+	// Affinity enables the affinity server selection, with consistent hashing
+	Affinity *AffinitySelector `yaml:"affinity" toml:"affinity" env:"AFFINITY"`
+	// ReportPeriod is the stats report period, zero disables the stats report
+	ReportPeriod Duration      `yaml:"reportPeriod" toml:"reportPeriod" env:"REPORT_PERIOD"`
+	TLS          *ServerTLS    `yaml:"tls" toml:"tls" env:"TLS"`
+	Ticket       *TicketSigner `yaml:"ticket" toml:"ticket" env:"TICKET"`
+	Cluster      *Cluster      `yaml:"cluster" toml:"cluster" env:"CLUSTER"`
+	Admission    *Admission    `yaml:"admission" toml:"admission" env:"ADMISSION"`
+	ICE          *ICE          `yaml:"ice" toml:"ice" env:"ICE"`
+}
+
+// DefaultCoordinator returns the coordinator defaults, the ones used when no config file is provided
+func DefaultCoordinator() Coordinator {
+	return Coordinator{
+		Host:           "localhost",
+		Port:           9090,
+		LogLevel:       zerolog.DebugLevel.String(),
+		Auth:           Auth{Type: AuthNoop},
+		ServerSelector: ServerSelectorDefault,
+	}
+}
+
+// Validate checks the config, returning a ValidationError listing every problem
+func (c *Coordinator) Validate() error {
+	v := &validator{}
+
+	v.check(c.Port > 0 && c.Port <= 65535, "port: invalid port %d", c.Port)
+	v.checkLogLevel("logLevel", c.LogLevel)
+	v.check(c.AdminAddr == "" || c.AdminToken != "", "adminToken: required by adminAddr")
+	validateAuth(v, &c.Auth)
+	v.check(c.ServerSelector == ServerSelectorDefault || c.ServerSelector == ServerSelectorLoadAware,
+		"serverSelector: unknown selector %q", c.ServerSelector)
+	v.check(c.LoadReportTimeout >= 0, "loadReportTimeout: cannot be negative")
+	v.check(c.FullServerCooldown >= 0, "fullServerCooldown: cannot be negative")
+
+	if c.Affinity != nil {
+		v.check(c.Affinity.Replicas >= 0, "affinity.replicas: cannot be negative")
+	}
+
+	v.check(c.ReportPeriod >= 0, "reportPeriod: cannot be negative")
+
+	if c.Ticket != nil {
+		v.check(c.Ticket.SigningKeyFile != "", "ticket.signingKeyFile: cannot be empty")
+		v.check(c.Ticket.TTL >= 0, "ticket.ttl: cannot be negative")
+	}
+
+	if c.Cluster != nil {
+		v.check(c.Cluster.StoreDir != "", "cluster.storeDir: cannot be empty")
+		v.check(c.Cluster.SyncPeriod >= 0, "cluster.syncPeriod: cannot be negative")
+	}
+
+	if c.Admission != nil {
+		a := c.Admission
+		v.check(a.MaxConnections >= 0, "admission.maxConnections: cannot be negative")
+		v.check(a.MaxConnectionsPerIP >= 0, "admission.maxConnectionsPerIP: cannot be negative")
+		v.check(a.HandshakeRate >= 0 && a.HandshakeBurst >= 0, "admission.handshakeRate: rate and burst cannot be negative")
+		v.check(a.SignalingRate >= 0 && a.SignalingBurst >= 0, "admission.signalingRate: rate and burst cannot be negative")
+		v.check(a.ServerSignalingRate >= 0 && a.ServerSignalingBurst >= 0,
+			"admission.serverSignalingRate: rate and burst cannot be negative")
+	}
+
+	if c.ICE != nil {
+		for i, s := range c.ICE.Servers {
+			v.check(len(s.URLs) > 0, "ice.servers[%d].urls: cannot be empty", i)
+		}
+
+		v.check(len(c.ICE.TURNURLs) == 0 || c.ICE.TURNSecret != "", "ice.turnSecret: required by ice.turnURLs")
+		v.check(c.ICE.TURNCredentialTTL >= 0, "ice.turnCredentialTTL: cannot be negative")
+	}
+
+	if c.TLS != nil {
+		v.check(c.TLS.CertFile != "", "tls.certFile: cannot be empty")
+		v.check(c.TLS.KeyFile != "", "tls.keyFile: cannot be empty")
+		v.check(!c.TLS.RequireServerCertificate || c.TLS.ClientCAFile != "",
+			"tls.requireServerCertificate: requires tls.clientCAFile")
+	}
+
+	return v.err()
+}
+
+// Logger creates the logger with the configured level
+func (c *Coordinator) Logger() logging.Logger {
+	return logging.New().Level(parseLogLevel(c.LogLevel, zerolog.DebugLevel))
+}
+
+// CoordinatorConfig builds the coordinator.Config, the config has to be valid
+func (c *Coordinator) CoordinatorConfig(log *logging.Logger) (*coordinator.Config, error) {
+	auth, err := c.Auth.authenticator()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &coordinator.Config{
+		Log:                log,
+		Auth:               auth,
+		ReportPeriod:       time.Duration(c.ReportPeriod),
+		FullServerCooldown: time.Duration(c.FullServerCooldown),
+	}
+
+	if c.ServerSelector == ServerSelectorLoadAware {
+		config.ServerSelector = coordinator.NewLoadAwareServerSelector(time.Duration(c.LoadReportTimeout))
+	}
+
+	if c.Labels != nil {
+		config.ServerSelector = coordinator.NewLabelAwareServerSelector(coordinator.LabelAwareServerSelectorConfig{
+			Selector:       config.ServerSelector,
+			RequiredLabels: c.Labels.RequiredLabels,
+			MeshLabels:     c.Labels.MeshLabels,
+		})
+	}
+
+	if c.Affinity != nil {
+		config.ServerSelector = coordinator.NewConsistentHashServerSelector(coordinator.ConsistentHashServerSelectorConfig{
+			Selector: config.ServerSelector,
+			Replicas: c.Affinity.Replicas,
+		})
+	}
+
+	if c.TLS != nil {
+		config.RequireServerCertificate = c.TLS.RequireServerCertificate
+	}
+
+	if c.Cluster != nil {
+		store, err := coordinator.NewFileStore(c.Cluster.StoreDir)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Store = store
+		config.InstanceID = c.Cluster.InstanceID
+		config.SyncPeriod = time.Duration(c.Cluster.SyncPeriod)
+	}
+
+	if c.Admission != nil {
+		config.Admission = coordinator.AdmissionConfig{
+			AllowedOrigins:       c.Admission.AllowedOrigins,
+			MaxConnections:       c.Admission.MaxConnections,
+			MaxConnectionsPerIP:  c.Admission.MaxConnectionsPerIP,
+			HandshakeRate:        c.Admission.HandshakeRate,
+			HandshakeBurst:       c.Admission.HandshakeBurst,
+			SignalingRate:        c.Admission.SignalingRate,
+			SignalingBurst:       c.Admission.SignalingBurst,
+			ServerSignalingRate:  c.Admission.ServerSignalingRate,
+			ServerSignalingBurst: c.Admission.ServerSignalingBurst,
+			RealIPHeader:         c.Admission.RealIPHeader,
+		}
+	}
+
+	if c.ICE != nil {
+		servers := make([]coordinator.ICEServer, len(c.ICE.Servers))
+		for i, s := range c.ICE.Servers {
+			servers[i] = coordinator.ICEServer{URLs: s.URLs, Username: s.Username, Credential: s.Credential}
+		}
+
+		config.ICE = coordinator.ICEConfig{
+			Servers:           servers,
+			TURNURLs:          c.ICE.TURNURLs,
+			TURNSecret:        c.ICE.TURNSecret,
+			TURNCredentialTTL: time.Duration(c.ICE.TURNCredentialTTL),
+		}
+	}
+
+	if c.Ticket != nil {
+		key, err := authentication.LoadTicketSigningKey(c.Ticket.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.TicketSigner = authentication.NewTicketSigner(key, time.Duration(c.Ticket.TTL))
+	}
+
+	if c.ReportPeriod > 0 {
+		config.Reporter = func(stats coordinator.Stats) {
+			log.Info().
+				Int("serverCount", stats.ServerCount).
+				Int("clientCount", stats.ClientCount).
+				Uint64("rejectedOrigin", stats.Rejections.Origin).
+				Uint64("rejectedCapacity", stats.Rejections.Capacity).
+				Uint64("rejectedConnectionsPerIP", stats.Rejections.ConnectionsPerIP).
+				Uint64("rejectedHandshakeRate", stats.Rejections.HandshakeRate).
+				Uint64("droppedSignaling", stats.Rejections.SignalingRate).
+				Uint64("signalingPolicyViolations", stats.Rejections.SignalingPolicy).
+				Msg("coordinator stats")
+		}
+	}
+
+	return config, nil
+}
+
+// TLSConfig builds the serving TLS config and its certificate reloader, it returns nil if TLS is not enabled
+func (c *Coordinator) TLSConfig(log logging.Logger) (*tls.Config, *ws.CertificateReloader, error) {
+	if c.TLS == nil {
+		return nil, nil, nil
+	}
+
+	reloader, err := ws.NewCertificateReloader(c.TLS.CertFile, c.TLS.KeyFile, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig, err := ws.ServerTLSConfig(reloader, c.TLS.ClientCAFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tlsConfig, reloader, nil
+}
+
+// AdminAuthenticator returns the admin api authenticator, checking the AdminToken bearer token
+func (c *Coordinator) AdminAuthenticator() authentication.AdminAuthenticator {
+	return &authentication.BearerAdminAuthenticator{Token: c.AdminToken}
+}
@@ -0,0 +1,167 @@
+package ws
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+)
+
+const certReloadCheckPeriod = 10 * time.Second
+
+// ClientTLSConfig creates a TLS config for dialing. caFile replaces the system roots if set, certFile and
+// keyFile set the client certificate (mutual TLS), and serverName overrides the SNI and the verified name
+func ClientTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// ServerTLSConfig creates a TLS config for serving, the certificate is taken from the reloader. If clientCAFile
+// is set, client certificates are verified against it when presented
+func ServerTLSConfig(reloader *CertificateReloader, clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in " + caFile)
+	}
+
+	return pool, nil
+}
+
+// CertificateReloader serves a certificate and key pair from disk, reloading them when the files change,
+// so certificates can be renewed without restarting
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	log      logging.Logger
+
+	mux       sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewCertificateReloader loads the certificate and key pair, failing if they are not valid
+func NewCertificateReloader(certFile, keyFile string, log logging.Logger) (*CertificateReloader, error) {
+	r := &CertificateReloader{certFile: certFile, keyFile: keyFile, log: log}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the certificate and key pair from disk, keeping the previous ones on error
+func (r *CertificateReloader) Reload() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.reload()
+}
+
+func (r *CertificateReloader) reload() error {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+	r.lastCheck = time.Now()
+
+	return nil
+}
+
+func (r *CertificateReloader) filesModTime() (time.Time, error) {
+	var modTime time.Time
+
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTime, err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+
+	return modTime, nil
+}
+
+// GetCertificate returns the current certificate, checking for changes at most every certReloadCheckPeriod
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if time.Since(r.lastCheck) > certReloadCheckPeriod {
+		r.lastCheck = time.Now()
+
+		modTime, err := r.filesModTime()
+
+		if err != nil {
+			r.log.Error().Err(err).Msg("cannot check certificate files, keeping the current certificate")
+		} else if modTime.After(r.modTime) {
+			if err := r.reload(); err != nil {
+				r.log.Error().Err(err).Msg("cannot reload certificate, keeping the current certificate")
+			} else {
+				r.log.Info().Msg("certificate reloaded")
+			}
+		}
+	}
+
+	return r.cert, nil
+}
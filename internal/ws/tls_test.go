@@ -0,0 +1,215 @@
+package ws
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func makeTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) writeCA(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+	return path
+}
+
+func (ca *testCA) writeCert(t *testing.T, dir string, name string, serial int64) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+".key")
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	require.NoError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+
+	return certFile, keyFile
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	ca := makeTestCA(t)
+	certFile, keyFile := ca.writeCert(t, dir, "coordinator", 2)
+
+	reloader, err := NewCertificateReloader(certFile, keyFile, logging.New())
+	require.NoError(t, err)
+
+	getSerial := func() int64 {
+		cert, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+
+		return parsed.SerialNumber.Int64()
+	}
+
+	require.Equal(t, int64(2), getSerial())
+
+	t.Run("reload on change", func(t *testing.T) {
+		ca.writeCert(t, dir, "coordinator", 3)
+
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(certFile, future, future))
+
+		reloader.lastCheck = time.Time{}
+		require.Equal(t, int64(3), getSerial())
+	})
+
+	t.Run("keep certificate on error", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(keyFile, []byte("invalid"), 0600))
+
+		future := time.Now().Add(2 * time.Minute)
+		require.NoError(t, os.Chtimes(keyFile, future, future))
+
+		reloader.lastCheck = time.Time{}
+		require.Equal(t, int64(3), getSerial())
+		require.Error(t, reloader.Reload())
+		require.Equal(t, int64(3), getSerial())
+	})
+}
+
+func TestDialWithOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	ca := makeTestCA(t)
+	caFile := ca.writeCA(t, dir)
+	serverCert, serverKey := ca.writeCert(t, dir, "coordinator", 2)
+	clientCert, clientKey := ca.writeCert(t, dir, "server", 3)
+
+	reloader, err := NewCertificateReloader(serverCert, serverKey, logging.New())
+	require.NoError(t, err)
+
+	serverTLSConfig, err := ServerTLSConfig(reloader, caFile)
+	require.NoError(t, err)
+
+	upgrader := MakeUpgrader()
+	verifiedCh := make(chan bool, 1)
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifiedCh <- len(r.TLS.VerifiedChains) > 0
+
+		conn, err := upgrader.Upgrade(w, r)
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	s.TLS = serverTLSConfig
+	s.StartTLS()
+
+	defer s.Close()
+
+	url := "wss" + strings.TrimPrefix(s.URL, "https")
+
+	t.Run("unknown CA", func(t *testing.T) {
+		_, err := Dial(url)
+		require.Error(t, err)
+	})
+
+	t.Run("custom CA and SNI", func(t *testing.T) {
+		tlsConfig, err := ClientTLSConfig(caFile, "", "", "coordinator")
+		require.NoError(t, err)
+
+		conn, err := DialWithOptions(url, DialOptions{TLSConfig: tlsConfig})
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		require.False(t, <-verifiedCh)
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		tlsConfig, err := ClientTLSConfig(caFile, clientCert, clientKey, "coordinator")
+		require.NoError(t, err)
+
+		conn, err := DialWithOptions(url, DialOptions{TLSConfig: tlsConfig})
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		require.True(t, <-verifiedCh)
+	})
+
+	t.Run("wrong server name", func(t *testing.T) {
+		tlsConfig, err := ClientTLSConfig(caFile, "", "", "other")
+		require.NoError(t, err)
+
+		_, err = DialWithOptions(url, DialOptions{TLSConfig: tlsConfig})
+		require.Error(t, err)
+	})
+
+	_, err = ClientTLSConfig(filepath.Join(dir, "missing.pem"), "", "", "")
+	require.Error(t, err)
+
+	_, err = ClientTLSConfig(caFile, clientCert, "", "")
+	require.Error(t, err)
+}
@@ -2,7 +2,9 @@
 package ws
 
 import (
+	"crypto/tls"
 	"net/http"
+	"strings"
 	"time"
 
 	_websocket "github.com/gorilla/websocket"
@@ -91,9 +93,23 @@ func IsUnexpectedCloseError(err error) bool {
 	return _websocket.IsUnexpectedCloseError(err, _websocket.CloseGoingAway, _websocket.CloseAbnormalClosure)
 }
 
+// DialOptions are the optional websocket dial parameters
+type DialOptions struct {
+	// TLSConfig is used for wss urls, if nil the default config is used
+	TLSConfig *tls.Config
+}
+
 // Dial open a websocket connection to the given url
 func Dial(url string) (IWebsocket, error) {
-	conn, resp, err := _websocket.DefaultDialer.Dial(url, nil)
+	return DialWithOptions(url, DialOptions{})
+}
+
+// DialWithOptions open a websocket connection to the given url
+func DialWithOptions(url string, options DialOptions) (IWebsocket, error) {
+	dialer := *_websocket.DefaultDialer
+	dialer.TLSClientConfig = options.TLSConfig
+
+	conn, resp, err := dialer.Dial(url, nil)
 	if err != nil {
 		return &websocket{}, err
 	}
@@ -105,17 +121,48 @@ func Dial(url string) (IWebsocket, error) {
 	return &websocket{conn: conn}, nil
 }
 
+// UpgraderOptions are the optional upgrader parameters
+type UpgraderOptions struct {
+	// AllowedOrigins are the origins browsers can connect from, see IsOriginAllowed
+	AllowedOrigins []string
+}
+
 // MakeUpgrader creates default upgrader
 func MakeUpgrader() IUpgrader {
+	return MakeUpgraderWithOptions(UpgraderOptions{})
+}
+
+// MakeUpgraderWithOptions creates an upgrader with the given options
+func MakeUpgraderWithOptions(options UpgraderOptions) IUpgrader {
+	allowedOrigins := options.AllowedOrigins
+
 	upgrader := _websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
-		CheckOrigin:     func(r *http.Request) bool { return true },
+		CheckOrigin:     func(r *http.Request) bool { return IsOriginAllowed(allowedOrigins, r) },
 	}
 
 	return &Upgrader{upgrader: upgrader}
 }
 
+// IsOriginAllowed checks the request Origin header against the allowed origins (e.g. https://play.example.com),
+// the comparison is case insensitive and "*" allows any origin. Every origin is allowed if the list is empty, and
+// requests without an Origin header, not sent by a browser, are always allowed
+func IsOriginAllowed(allowedOrigins []string, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(allowedOrigins) == 0 || origin == "" {
+		return true
+	}
+
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Upgrade upgrades a websocket HTTP request into ws protocol
 func (upgrader *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (IWebsocket, error) {
 	conn, err := upgrader.upgrader.Upgrade(w, r, nil)
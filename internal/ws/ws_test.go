@@ -0,0 +1,28 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsOriginAllowed(t *testing.T) {
+	allowed := []string{"https://play.example.com"}
+
+	check := func(allowedOrigins []string, origin string) bool {
+		r := httptest.NewRequest("GET", "/connect", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+
+		return IsOriginAllowed(allowedOrigins, r)
+	}
+
+	require.True(t, check(allowed, "https://play.example.com"))
+	require.True(t, check(allowed, "HTTPS://PLAY.EXAMPLE.COM"))
+	require.False(t, check(allowed, "https://evil.example.com"))
+	require.True(t, check(allowed, ""))
+	require.True(t, check(nil, "https://evil.example.com"))
+	require.True(t, check([]string{"*"}, "https://evil.example.com"))
+}
@@ -0,0 +1,25 @@
+package authentication
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// BearerAdminAuthenticator authenticates the coordinator admin api requests with a static token, sent in the
+// Authorization header ("Bearer <token>"). An empty token rejects every request
+type BearerAdminAuthenticator struct {
+	Token string
+}
+
+// AuthenticateAdmin returns true if the request carries the token
+func (a *BearerAdminAuthenticator) AuthenticateAdmin(r *http.Request) (bool, error) {
+	header := r.Header.Get("Authorization")
+	if a.Token == "" || !strings.HasPrefix(header, "Bearer ") {
+		return false, nil
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1, nil
+}
@@ -0,0 +1,28 @@
+package authentication
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearerAdminAuthenticator(t *testing.T) {
+	authenticate := func(t *testing.T, token string, header string) bool {
+		r := httptest.NewRequest("GET", "/admin/servers", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+
+		ok, err := (&BearerAdminAuthenticator{Token: token}).AuthenticateAdmin(r)
+		require.NoError(t, err)
+
+		return ok
+	}
+
+	require.True(t, authenticate(t, "secret", "Bearer secret"))
+	require.False(t, authenticate(t, "secret", "Bearer other"))
+	require.False(t, authenticate(t, "secret", "secret"))
+	require.False(t, authenticate(t, "secret", ""))
+	require.False(t, authenticate(t, "", "Bearer "))
+}
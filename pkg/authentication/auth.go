@@ -4,6 +4,7 @@ package authentication
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/decentraland/webrtc-broker/pkg/protocol"
 )
@@ -15,11 +16,28 @@ type ServerAuthenticator interface {
 	GenerateServerConnectURL(coordinatorURL string, role protocol.Role) (string, error)
 }
 
+// ExpiringAuthenticator is an optional ServerAuthenticator extension returning when the peer credentials
+// expire, the zero time means they don't. The broker asks the peers to authenticate again before they expire
+type ExpiringAuthenticator interface {
+	AuthenticateFromMessageWithExpiry(role protocol.Role, bytes []byte) (bool, []byte, time.Time, error)
+}
+
 // CoordinatorAuthenticator is the coordinator authentication mechanism
 type CoordinatorAuthenticator interface {
 	AuthenticateFromURL(role protocol.Role, r *http.Request) (bool, error)
 }
 
+// CoordinatorIdentityAuthenticator is an optional CoordinatorAuthenticator extension returning the peer identity,
+// the coordinator includes it in the connection tickets
+type CoordinatorIdentityAuthenticator interface {
+	AuthenticateIdentityFromURL(role protocol.Role, r *http.Request) (bool, []byte, error)
+}
+
+// AdminAuthenticator is the coordinator admin api authentication mechanism
+type AdminAuthenticator interface {
+	AuthenticateAdmin(r *http.Request) (bool, error)
+}
+
 // ClientAuthenticator is the client authentication mechanism, used for simulation only
 type ClientAuthenticator interface {
 	GenerateClientAuthMessage() (*protocol.AuthMessage, error)
@@ -39,6 +57,11 @@ func (a *NoopAuthenticator) AuthenticateFromURL(role protocol.Role, r *http.Requ
 	return true, nil
 }
 
+// AuthenticateAdmin always return true
+func (a *NoopAuthenticator) AuthenticateAdmin(r *http.Request) (bool, error) {
+	return true, nil
+}
+
 // GenerateServerAuthMessage generates server empty auth message
 func (a *NoopAuthenticator) GenerateServerAuthMessage() (*protocol.AuthMessage, error) {
 	m := &protocol.AuthMessage{
@@ -0,0 +1,289 @@
+package authentication
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const (
+	hmacTokenVersion = "h1"
+	hmacTokenParam   = "server_token"
+	hmacNonceSize    = 16
+	defaultHMACTTL   = time.Minute
+
+	hmacPurposeURL     = "url"
+	hmacPurposeMessage = "message"
+)
+
+var (
+	// ErrTokenReplayed indicates the token was already used
+	ErrTokenReplayed = errors.New("token replayed")
+)
+
+// PeerAuthenticator verifies peers, both on the coordinator and on the communication servers
+type PeerAuthenticator interface {
+	AuthenticateFromMessage(role protocol.Role, bytes []byte) (bool, []byte, error)
+	AuthenticateFromURL(role protocol.Role, r *http.Request) (bool, error)
+}
+
+// HMACConfig is the HMACAuthenticator config
+type HMACConfig struct {
+	// Secrets are the shared secrets, the first one signs and all of them verify, so the secret can be rotated
+	// by prepending the new one and removing the old one once every server is updated
+	Secrets [][]byte
+	// TTL is the token validity, one minute by default
+	TTL time.Duration
+	// Leeway is the clock skew tolerance
+	Leeway time.Duration
+	// Clients authenticates CLIENT peers, if nil clients are rejected
+	Clients PeerAuthenticator
+}
+
+// HMACAuthenticator is a Server|Coordinator authenticator for server to server links, servers prove they know
+// a shared secret with short lived signed tokens that can be used only once. Clients are delegated to
+// HMACConfig.Clients
+type HMACAuthenticator struct {
+	secrets [][]byte
+	ttl     time.Duration
+	leeway  time.Duration
+	clients PeerAuthenticator
+	now     func() time.Time
+
+	nonceMux  sync.Mutex
+	nonces    map[string]time.Time
+	nextPrune time.Time
+}
+
+// NewHMACAuthenticator creates a HMACAuthenticator
+func NewHMACAuthenticator(config HMACConfig) (*HMACAuthenticator, error) {
+	if len(config.Secrets) == 0 {
+		return nil, errors.New("at least one secret is required")
+	}
+
+	for _, secret := range config.Secrets {
+		if len(secret) == 0 {
+			return nil, errors.New("secrets cannot be empty")
+		}
+	}
+
+	ttl := config.TTL
+	if ttl == 0 {
+		ttl = defaultHMACTTL
+	}
+
+	return &HMACAuthenticator{
+		secrets: config.Secrets,
+		ttl:     ttl,
+		leeway:  config.Leeway,
+		clients: config.Clients,
+		now:     time.Now,
+		nonces:  make(map[string]time.Time),
+	}, nil
+}
+
+func isServerRole(role protocol.Role) bool {
+	return role == protocol.Role_COMMUNICATION_SERVER || role == protocol.Role_COMMUNICATION_SERVER_HUB
+}
+
+func hmacSign(secret []byte, purpose string, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose + "|" + payload)) //nolint:errcheck
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// generateToken creates a token as version.role.issuedAt.nonce.signature, the purpose is signed but not
+// included so a token cannot be used for a different purpose
+func (a *HMACAuthenticator) generateToken(purpose string, role protocol.Role) (string, error) {
+	nonce := make([]byte, hmacNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	payload := strings.Join([]string{
+		hmacTokenVersion,
+		role.String(),
+		strconv.FormatInt(a.now().Unix(), 10),
+		hex.EncodeToString(nonce),
+	}, ".")
+
+	return payload + "." + hmacSign(a.secrets[0], purpose, payload), nil
+}
+
+func (a *HMACAuthenticator) verifyToken(purpose string, role protocol.Role, token string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 || parts[0] != hmacTokenVersion {
+		return ErrInvalidToken
+	}
+
+	payload := strings.Join(parts[:4], ".")
+	verified := false
+
+	for _, secret := range a.secrets {
+		if hmac.Equal([]byte(hmacSign(secret, purpose, payload)), []byte(parts[4])) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return ErrInvalidToken
+	}
+
+	if parts[1] != role.String() {
+		return fmt.Errorf("%w: token issued for %s", ErrInvalidClaims, parts[1])
+	}
+
+	issuedAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	now := a.now()
+	expiresAt := time.Unix(issuedAt, 0).Add(a.ttl)
+
+	if now.After(expiresAt.Add(a.leeway)) {
+		return ErrTokenExpired
+	}
+
+	if now.Add(a.leeway).Before(time.Unix(issuedAt, 0)) {
+		return fmt.Errorf("%w: token issued in the future", ErrInvalidClaims)
+	}
+
+	return a.useNonce(parts[3], expiresAt.Add(a.leeway))
+}
+
+// useNonce registers the nonce until the token expires, failing if it was already used
+func (a *HMACAuthenticator) useNonce(nonce string, expiresAt time.Time) error {
+	a.nonceMux.Lock()
+	defer a.nonceMux.Unlock()
+
+	now := a.now()
+
+	if now.After(a.nextPrune) {
+		for n, t := range a.nonces {
+			if now.After(t) {
+				delete(a.nonces, n)
+			}
+		}
+
+		a.nextPrune = now.Add(a.ttl)
+	}
+
+	if _, ok := a.nonces[nonce]; ok {
+		return ErrTokenReplayed
+	}
+
+	a.nonces[nonce] = expiresAt
+
+	return nil
+}
+
+// AuthenticateFromMessage verifies server tokens, clients are delegated
+func (a *HMACAuthenticator) AuthenticateFromMessage(role protocol.Role, bytes []byte) (bool, []byte, error) {
+	ok, identity, _, err := a.AuthenticateFromMessageWithExpiry(role, bytes)
+	return ok, identity, err
+}
+
+// AuthenticateFromMessageWithExpiry is AuthenticateFromMessage returning the client credentials expiry, if the
+// clients authenticator provides it. Server tokens only prove the server knows the secret when the link is
+// established, so server links don't expire
+func (a *HMACAuthenticator) AuthenticateFromMessageWithExpiry(role protocol.Role,
+	bytes []byte) (bool, []byte, time.Time, error) {
+	if !isServerRole(role) {
+		if a.clients == nil {
+			return false, nil, time.Time{}, nil
+		}
+
+		if auth, ok := a.clients.(ExpiringAuthenticator); ok {
+			return auth.AuthenticateFromMessageWithExpiry(role, bytes)
+		}
+
+		ok, identity, err := a.clients.AuthenticateFromMessage(role, bytes)
+
+		return ok, identity, time.Time{}, err
+	}
+
+	if err := a.verifyToken(hmacPurposeMessage, role, string(bytes)); err != nil {
+		return false, nil, time.Time{}, err
+	}
+
+	return true, nil, time.Time{}, nil
+}
+
+// AuthenticateFromURL verifies the server_token query param for servers, clients are delegated
+func (a *HMACAuthenticator) AuthenticateFromURL(role protocol.Role, r *http.Request) (bool, error) {
+	ok, _, err := a.AuthenticateIdentityFromURL(role, r)
+	return ok, err
+}
+
+// AuthenticateIdentityFromURL is AuthenticateFromURL returning the client identity if the clients authenticator
+// provides it, servers have no identity
+func (a *HMACAuthenticator) AuthenticateIdentityFromURL(role protocol.Role, r *http.Request) (bool, []byte, error) {
+	if !isServerRole(role) {
+		if a.clients == nil {
+			return false, nil, nil
+		}
+
+		if auth, ok := a.clients.(CoordinatorIdentityAuthenticator); ok {
+			return auth.AuthenticateIdentityFromURL(role, r)
+		}
+
+		ok, err := a.clients.AuthenticateFromURL(role, r)
+
+		return ok, nil, err
+	}
+
+	token := r.URL.Query().Get(hmacTokenParam)
+	if token == "" {
+		return false, nil, nil
+	}
+
+	if err := a.verifyToken(hmacPurposeURL, role, token); err != nil {
+		return false, nil, err
+	}
+
+	return true, nil, nil
+}
+
+// GenerateServerAuthMessage generates a server auth message with a signed token
+func (a *HMACAuthenticator) GenerateServerAuthMessage() (*protocol.AuthMessage, error) {
+	token, err := a.generateToken(hmacPurposeMessage, protocol.Role_COMMUNICATION_SERVER)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &protocol.AuthMessage{
+		Type: protocol.MessageType_AUTH,
+		Role: protocol.Role_COMMUNICATION_SERVER,
+		Body: []byte(token),
+	}
+
+	return m, nil
+}
+
+// GenerateServerConnectURL generates the coordinator discover url with a signed token
+func (a *HMACAuthenticator) GenerateServerConnectURL(coordinatorURL string, role protocol.Role) (string, error) {
+	token, err := a.generateToken(hmacPurposeURL, role)
+	if err != nil {
+		return "", err
+	}
+
+	u := fmt.Sprintf("%s/discover?role=%s&%s=%s", coordinatorURL, role.String(), hmacTokenParam,
+		url.QueryEscape(token))
+
+	return u, nil
+}
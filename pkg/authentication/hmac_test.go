@@ -0,0 +1,144 @@
+package authentication
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestHMACAuthenticator(t *testing.T) {
+	newAuth := func(t *testing.T, secrets ...string) *HMACAuthenticator {
+		config := HMACConfig{Clients: &NoopAuthenticator{}}
+		for _, s := range secrets {
+			config.Secrets = append(config.Secrets, []byte(s))
+		}
+
+		auth, err := NewHMACAuthenticator(config)
+		require.NoError(t, err)
+
+		return auth
+	}
+
+	t.Run("auth message", func(t *testing.T) {
+		server1 := newAuth(t, "secret")
+		server2 := newAuth(t, "secret")
+
+		msg, err := server1.GenerateServerAuthMessage()
+		require.NoError(t, err)
+
+		ok, _, err := server2.AuthenticateFromMessage(msg.Role, msg.Body)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, _, err = server2.AuthenticateFromMessage(msg.Role, msg.Body)
+		require.Equal(t, ErrTokenReplayed, err)
+		require.False(t, ok)
+
+		msg, err = server1.GenerateServerAuthMessage()
+		require.NoError(t, err)
+
+		_, _, err = server2.AuthenticateFromMessage(protocol.Role_COMMUNICATION_SERVER_HUB, msg.Body)
+		require.Error(t, err)
+	})
+
+	t.Run("connect url", func(t *testing.T) {
+		server := newAuth(t, "secret")
+		coordinator := newAuth(t, "secret")
+
+		u, err := server.GenerateServerConnectURL("ws://coordinator", protocol.Role_COMMUNICATION_SERVER_HUB)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("GET", u, nil)
+		require.NoError(t, err)
+
+		ok, err := coordinator.AuthenticateFromURL(protocol.Role_COMMUNICATION_SERVER_HUB, req)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, err = coordinator.AuthenticateFromURL(protocol.Role_COMMUNICATION_SERVER_HUB, req)
+		require.Equal(t, ErrTokenReplayed, err)
+
+		// url tokens are not valid as auth messages
+		token := req.URL.Query().Get(hmacTokenParam)
+		_, _, err = newAuth(t, "secret").AuthenticateFromMessage(protocol.Role_COMMUNICATION_SERVER_HUB, []byte(token))
+		require.Equal(t, ErrInvalidToken, err)
+
+		req, err = http.NewRequest("GET", "ws://coordinator/discover", nil)
+		require.NoError(t, err)
+
+		ok, err = coordinator.AuthenticateFromURL(protocol.Role_COMMUNICATION_SERVER, req)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		msg, err := newAuth(t, "other").GenerateServerAuthMessage()
+		require.NoError(t, err)
+
+		ok, _, err := newAuth(t, "secret").AuthenticateFromMessage(msg.Role, msg.Body)
+		require.Equal(t, ErrInvalidToken, err)
+		require.False(t, ok)
+	})
+
+	t.Run("secret rotation", func(t *testing.T) {
+		msg, err := newAuth(t, "old").GenerateServerAuthMessage()
+		require.NoError(t, err)
+
+		ok, _, err := newAuth(t, "new", "old").AuthenticateFromMessage(msg.Role, msg.Body)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		server := newAuth(t, "secret")
+		server.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+
+		msg, err := server.GenerateServerAuthMessage()
+		require.NoError(t, err)
+
+		_, _, err = newAuth(t, "secret").AuthenticateFromMessage(msg.Role, msg.Body)
+		require.Equal(t, ErrTokenExpired, err)
+	})
+
+	t.Run("nonces are pruned", func(t *testing.T) {
+		server := newAuth(t, "secret")
+		verifier := newAuth(t, "secret")
+
+		msg, err := server.GenerateServerAuthMessage()
+		require.NoError(t, err)
+
+		_, _, err = verifier.AuthenticateFromMessage(msg.Role, msg.Body)
+		require.NoError(t, err)
+		require.Len(t, verifier.nonces, 1)
+
+		verifier.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		server.now = verifier.now
+
+		msg, err = server.GenerateServerAuthMessage()
+		require.NoError(t, err)
+
+		_, _, err = verifier.AuthenticateFromMessage(msg.Role, msg.Body)
+		require.NoError(t, err)
+		require.Len(t, verifier.nonces, 1)
+	})
+
+	t.Run("clients", func(t *testing.T) {
+		ok, _, err := newAuth(t, "secret").AuthenticateFromMessage(protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		auth, err := NewHMACAuthenticator(HMACConfig{Secrets: [][]byte{[]byte("secret")}})
+		require.NoError(t, err)
+
+		ok, _, err = auth.AuthenticateFromMessage(protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	_, err := NewHMACAuthenticator(HMACConfig{})
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package authentication
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	jwksCheckPeriod      = 10 * time.Second
+	jwksForceCheckPeriod = time.Second
+)
+
+// jwk is a verification key, key is one of []byte (HS256), *rsa.PublicKey (RS256) or *ecdsa.PublicKey (ES256)
+type jwk struct {
+	kid string
+	alg string
+	key interface{}
+}
+
+type jwkJSON struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeBase64Int(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k *jwkJSON) parse() (*jwk, error) {
+	key := &jwk{kid: k.Kid, alg: k.Alg}
+
+	switch k.Kty {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, err
+		}
+
+		key.key = secret
+
+		if key.alg == "" {
+			key.alg = AlgHS256
+		}
+	case "RSA":
+		n, err := decodeBase64Int(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBase64Int(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		key.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+
+		if key.alg == "" {
+			key.alg = AlgRS256
+		}
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBase64Int(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBase64Int(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC key")
+		}
+
+		key.key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+		if key.alg == "" {
+			key.alg = AlgES256
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	return key, nil
+}
+
+// parseJWKS parses a JSON Web Key Set, keys not meant for signatures are skipped
+func parseJWKS(data []byte) ([]*jwk, error) {
+	set := struct {
+		Keys []jwkJSON `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*jwk, 0, len(set.Keys))
+
+	for i := range set.Keys {
+		k := &set.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.parse()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (%s): %v", i, k.Kid, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// jwksFile is a JWKS file reloaded when it changes, so keys can be rotated without restarting
+type jwksFile struct {
+	path string
+
+	mux       sync.Mutex
+	keys      []*jwk
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func loadJWKSFile(path string) (*jwksFile, error) {
+	f := &jwksFile{path: path}
+
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *jwksFile) reload() error {
+	f.lastCheck = time.Now()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+
+	if !info.ModTime().After(f.modTime) {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", f.path, err)
+	}
+
+	f.keys = keys
+	f.modTime = info.ModTime()
+
+	return nil
+}
+
+// getKeys returns the current keys, checking the file for changes at most every jwksCheckPeriod unless
+// force is set (i.e. an unknown kid), then every jwksForceCheckPeriod. On reload errors the previous keys are kept
+func (f *jwksFile) getKeys(force bool) ([]*jwk, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	period := jwksCheckPeriod
+	if force {
+		period = jwksForceCheckPeriod
+	}
+
+	var err error
+	if time.Since(f.lastCheck) > period {
+		err = f.reload()
+	}
+
+	return f.keys, err
+}
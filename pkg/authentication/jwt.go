@@ -0,0 +1,568 @@
+package authentication
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+// JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+const (
+	defaultRoleClaim     = "role"
+	defaultIdentityClaim = "sub"
+	accessTokenParam     = "access_token"
+	es256KeySize         = 32
+)
+
+var (
+	// ErrInvalidToken indicates a malformed token or an invalid signature
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrUnknownKey indicates there is no key for the token algorithm and key id
+	ErrUnknownKey = errors.New("unknown token key")
+	// ErrTokenExpired indicates the token exp is in the past
+	ErrTokenExpired = errors.New("token expired")
+	// ErrInvalidClaims indicates the token claims don't match the expected ones (issuer, audience, nbf, etc)
+	ErrInvalidClaims = errors.New("invalid token claims")
+)
+
+// JWTClaims are the decoded token claims, numbers are decoded as json.Number
+type JWTClaims map[string]interface{}
+
+// JWTConfig is the JWTAuthenticator config
+type JWTConfig struct {
+	// HMACSecret verifies HS256 tokens
+	HMACSecret []byte
+	// JWKSFile is a JSON Web Key Set file, it's reloaded when it changes so keys can be rotated. Keys are
+	// selected by the token kid
+	JWKSFile string
+
+	// Issuer and Audience are checked if set
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerance for exp and nbf
+	Leeway time.Duration
+
+	// RoleClaim is the claim holding the roles allowed for the token, a role name or a list of role names
+	// (e.g. "CLIENT"), "role" by default
+	RoleClaim string
+	// IdentityClaim is the claim returned as the peer identity by AuthenticateFromMessage, "sub" by default.
+	// String values are returned as is, any other value json encoded
+	IdentityClaim string
+
+	// TokenProvider returns the token sent by this peer, used by the Generate* methods
+	TokenProvider func() (string, error)
+}
+
+// JWTAuthenticator is a Server|Coordinator|Client authenticator based on JSON Web Tokens. Tokens are sent in the
+// auth message body and in the access_token query param (or an Authorization bearer header) to the coordinator
+type JWTAuthenticator struct {
+	config JWTConfig
+	jwks   *jwksFile
+	now    func() time.Time
+}
+
+// NewJWTAuthenticator creates a JWTAuthenticator, loading the JWKS file if set
+func NewJWTAuthenticator(config JWTConfig) (*JWTAuthenticator, error) {
+	if config.RoleClaim == "" {
+		config.RoleClaim = defaultRoleClaim
+	}
+
+	if config.IdentityClaim == "" {
+		config.IdentityClaim = defaultIdentityClaim
+	}
+
+	a := &JWTAuthenticator{config: config, now: time.Now}
+
+	if config.JWKSFile != "" {
+		jwks, err := loadJWKSFile(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+
+		a.jwks = jwks
+	}
+
+	return a, nil
+}
+
+// FileTokenProvider returns a TokenProvider reading the token from a file on every call, so it can be renewed
+// externally
+func FileTokenProvider(path string) func() (string, error) {
+	return func() (string, error) {
+		token, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+
+		return string(bytes.TrimSpace(token)), nil
+	}
+}
+
+// SignJWT creates a token, key is a []byte for HS256, a *rsa.PrivateKey for RS256 and an *ecdsa.PrivateKey
+// (P-256) for ES256
+func SignJWT(alg string, kid string, key interface{}, claims JWTClaims) (string, error) {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	rawHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+
+	rawClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(rawHeader) + "." +
+		base64.RawURLEncoding.EncodeToString(rawClaims)
+	hash := sha256.Sum256([]byte(signingInput))
+
+	var sig []byte
+
+	switch k := key.(type) {
+	case []byte:
+		if alg != AlgHS256 {
+			return "", fmt.Errorf("invalid key for %s", alg)
+		}
+
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput)) //nolint:errcheck
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return "", fmt.Errorf("invalid key for %s", alg)
+		}
+
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:]); err != nil {
+			return "", err
+		}
+	case *ecdsa.PrivateKey:
+		if alg != AlgES256 {
+			return "", fmt.Errorf("invalid key for %s", alg)
+		}
+
+		r, s, err := ecdsa.Sign(rand.Reader, k, hash[:])
+		if err != nil {
+			return "", err
+		}
+
+		sig = make([]byte, 2*es256KeySize)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(sig[es256KeySize-len(rBytes):es256KeySize], rBytes)
+		copy(sig[2*es256KeySize-len(sBytes):], sBytes)
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func verifySignature(k *jwk, signingInput string, sig []byte) bool {
+	hash := sha256.Sum256([]byte(signingInput))
+
+	switch key := k.key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signingInput)) //nolint:errcheck
+
+		return hmac.Equal(mac.Sum(nil), sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) == nil
+	case *ecdsa.PublicKey:
+		if len(sig) != 2*es256KeySize {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(sig[:es256KeySize])
+		s := new(big.Int).SetBytes(sig[es256KeySize:])
+
+		return ecdsa.Verify(key, hash[:], r, s)
+	}
+
+	return false
+}
+
+func (a *JWTAuthenticator) findKeys(alg string, kid string, forceReload bool) ([]*jwk, error) {
+	candidates := []*jwk{}
+
+	if alg == AlgHS256 && a.config.HMACSecret != nil && kid == "" {
+		candidates = append(candidates, &jwk{alg: AlgHS256, key: a.config.HMACSecret})
+	}
+
+	if a.jwks == nil {
+		return candidates, nil
+	}
+
+	keys, err := a.jwks.getKeys(forceReload)
+	if err != nil && len(keys) == 0 {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if k.alg == alg && (kid == "" || k.kid == kid) {
+			candidates = append(candidates, k)
+		}
+	}
+
+	return candidates, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+func (a *JWTAuthenticator) numericClaim(claims JWTClaims, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, true, fmt.Errorf("%w: %s is not a number", ErrInvalidClaims, name)
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("%w: %s is not a number", ErrInvalidClaims, name)
+	}
+
+	return time.Unix(0, int64(f*float64(time.Second))), true, nil
+}
+
+func hasAudience(claims JWTClaims, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, v := range aud {
+			if s, ok := v.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *JWTAuthenticator) validateClaims(claims JWTClaims) error {
+	now := a.now()
+
+	exp, ok, err := a.numericClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: missing exp", ErrInvalidClaims)
+	}
+
+	if now.After(exp.Add(a.config.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	nbf, ok, err := a.numericClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+
+	if ok && now.Add(a.config.Leeway).Before(nbf) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidClaims)
+	}
+
+	if a.config.Issuer != "" && claims["iss"] != a.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidClaims)
+	}
+
+	if a.config.Audience != "" && !hasAudience(claims, a.config.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidClaims)
+	}
+
+	return nil
+}
+
+// Verify checks the token signature and its exp, nbf, iss and aud claims, returning the token claims
+func (a *JWTAuthenticator) Verify(token string) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if header.Alg != AlgHS256 && header.Alg != AlgRS256 && header.Alg != AlgES256 {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	keys, err := a.findKeys(header.Alg, header.Kid, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: an unknown kid may be a rotated key, so the key set is reloaded before failing
+	if len(keys) == 0 && header.Kid != "" && a.jwks != nil {
+		if keys, err = a.findKeys(header.Alg, header.Kid, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrUnknownKey
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	verified := false
+
+	for _, k := range keys {
+		if verifySignature(k, signingInput, sig) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return nil, ErrInvalidToken
+	}
+
+	claims := JWTClaims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Roles returns the roles in the configured role claim, unknown role names are ignored
+func (a *JWTAuthenticator) Roles(claims JWTClaims) []protocol.Role {
+	var names []string
+
+	switch v := claims[a.config.RoleClaim].(type) {
+	case string:
+		names = []string{v}
+	case []interface{}:
+		for _, name := range v {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+
+	roles := make([]protocol.Role, 0, len(names))
+
+	for _, name := range names {
+		if role, ok := protocol.Role_value[strings.ToUpper(name)]; ok {
+			roles = append(roles, protocol.Role(role))
+		}
+	}
+
+	return roles
+}
+
+// Identity returns the configured identity claim
+func (a *JWTAuthenticator) Identity(claims JWTClaims) ([]byte, error) {
+	v, ok := claims[a.config.IdentityClaim]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidClaims, a.config.IdentityClaim)
+	}
+
+	if s, ok := v.(string); ok {
+		return []byte(s), nil
+	}
+
+	return json.Marshal(v)
+}
+
+func (a *JWTAuthenticator) hasRole(claims JWTClaims, role protocol.Role) bool {
+	for _, r := range a.Roles(claims) {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AuthenticateFromMessage verifies the token in the message body, the role has to be in the role claim.
+// The identity is taken from the identity claim
+func (a *JWTAuthenticator) AuthenticateFromMessage(role protocol.Role, bytes []byte) (bool, []byte, error) {
+	ok, identity, _, err := a.AuthenticateFromMessageWithExpiry(role, bytes)
+	return ok, identity, err
+}
+
+// AuthenticateFromMessageWithExpiry is AuthenticateFromMessage returning the token expiry (the exp claim)
+func (a *JWTAuthenticator) AuthenticateFromMessageWithExpiry(role protocol.Role,
+	bytes []byte) (bool, []byte, time.Time, error) {
+	claims, err := a.Verify(string(bytes))
+	if err != nil {
+		return false, nil, time.Time{}, err
+	}
+
+	if !a.hasRole(claims, role) {
+		return false, nil, time.Time{}, nil
+	}
+
+	identity, err := a.Identity(claims)
+	if err != nil {
+		return false, nil, time.Time{}, err
+	}
+
+	expiresAt, _, err := a.numericClaim(claims, "exp")
+	if err != nil {
+		return false, nil, time.Time{}, err
+	}
+
+	return true, identity, expiresAt, nil
+}
+
+// AuthenticateFromURL verifies the token in the access_token query param, or in the Authorization header as a
+// bearer token. The role has to be in the role claim
+func (a *JWTAuthenticator) AuthenticateFromURL(role protocol.Role, r *http.Request) (bool, error) {
+	ok, _, err := a.AuthenticateIdentityFromURL(role, r)
+	return ok, err
+}
+
+// AuthenticateIdentityFromURL is AuthenticateFromURL returning the identity claim
+func (a *JWTAuthenticator) AuthenticateIdentityFromURL(role protocol.Role, r *http.Request) (bool, []byte, error) {
+	token := r.URL.Query().Get(accessTokenParam)
+
+	if token == "" {
+		authorization := r.Header.Get("Authorization")
+		if strings.HasPrefix(authorization, "Bearer ") {
+			token = strings.TrimPrefix(authorization, "Bearer ")
+		}
+	}
+
+	if token == "" {
+		return false, nil, nil
+	}
+
+	claims, err := a.Verify(token)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !a.hasRole(claims, role) {
+		return false, nil, nil
+	}
+
+	identity, err := a.Identity(claims)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, identity, nil
+}
+
+func (a *JWTAuthenticator) token() (string, error) {
+	if a.config.TokenProvider == nil {
+		return "", errors.New("no token provider configured")
+	}
+
+	return a.config.TokenProvider()
+}
+
+// GenerateServerAuthMessage generates a server auth message with the provided token
+func (a *JWTAuthenticator) GenerateServerAuthMessage() (*protocol.AuthMessage, error) {
+	token, err := a.token()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &protocol.AuthMessage{
+		Type: protocol.MessageType_AUTH,
+		Role: protocol.Role_COMMUNICATION_SERVER,
+		Body: []byte(token),
+	}
+
+	return m, nil
+}
+
+// GenerateClientAuthMessage generates a client auth message with the provided token
+func (a *JWTAuthenticator) GenerateClientAuthMessage() (*protocol.AuthMessage, error) {
+	token, err := a.token()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &protocol.AuthMessage{
+		Type: protocol.MessageType_AUTH,
+		Role: protocol.Role_CLIENT,
+		Body: []byte(token),
+	}
+
+	return m, nil
+}
+
+// GenerateServerConnectURL generates the coordinator discover url with the provided token
+func (a *JWTAuthenticator) GenerateServerConnectURL(coordinatorURL string, role protocol.Role) (string, error) {
+	token, err := a.token()
+	if err != nil {
+		return "", err
+	}
+
+	u := fmt.Sprintf("%s/discover?role=%s&%s=%s", coordinatorURL, role.String(), accessTokenParam,
+		url.QueryEscape(token))
+
+	return u, nil
+}
+
+// GenerateClientConnectURL generates the coordinator connect url with the provided token
+func (a *JWTAuthenticator) GenerateClientConnectURL(coordinatorURL string) (string, error) {
+	token, err := a.token()
+	if err != nil {
+		return "", err
+	}
+
+	u := fmt.Sprintf("%s/connect?%s=%s", coordinatorURL, accessTokenParam, url.QueryEscape(token))
+
+	return u, nil
+}
@@ -0,0 +1,275 @@
+package authentication
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func encodeBase64Int(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"n":   encodeBase64Int(key.N),
+		"e":   encodeBase64Int(big.NewInt(int64(key.E))),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   encodeBase64Int(key.X),
+		"y":   encodeBase64Int(key.Y),
+	}
+}
+
+func validClaims(role string) JWTClaims {
+	return JWTClaims{
+		"sub":  "user1",
+		"role": role,
+		"iss":  "issuer",
+		"aud":  []string{"broker"},
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	secret := []byte("secret")
+	jwksPath := filepath.Join(dir, "jwks.json")
+	writeJWKS(t, jwksPath, rsaJWK("rsa1", rsaKey), ecJWK("ec1", ecKey))
+
+	auth, err := NewJWTAuthenticator(JWTConfig{
+		HMACSecret: secret,
+		JWKSFile:   jwksPath,
+		Issuer:     "issuer",
+		Audience:   "broker",
+	})
+	require.NoError(t, err)
+
+	t.Run("algorithms", func(t *testing.T) {
+		for _, tc := range []struct {
+			alg string
+			kid string
+			key interface{}
+		}{
+			{AlgHS256, "", secret},
+			{AlgRS256, "rsa1", rsaKey},
+			{AlgES256, "ec1", ecKey},
+		} {
+			token, err := SignJWT(tc.alg, tc.kid, tc.key, validClaims("CLIENT"))
+			require.NoError(t, err)
+
+			ok, identity, err := auth.AuthenticateFromMessage(protocol.Role_CLIENT, []byte(token))
+			require.NoError(t, err, tc.alg)
+			require.True(t, ok, tc.alg)
+			require.Equal(t, []byte("user1"), identity)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		claims := validClaims("CLIENT")
+		expiresAt := time.Now().Add(10 * time.Minute).Unix()
+		claims["exp"] = expiresAt
+
+		token, err := SignJWT(AlgHS256, "", secret, claims)
+		require.NoError(t, err)
+
+		ok, _, expiry, err := auth.AuthenticateFromMessageWithExpiry(protocol.Role_CLIENT, []byte(token))
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, expiresAt, expiry.Unix())
+
+		hmacAuth, err := NewHMACAuthenticator(HMACConfig{Secrets: [][]byte{[]byte("secret")}, Clients: auth})
+		require.NoError(t, err)
+
+		_, _, expiry, err = hmacAuth.AuthenticateFromMessageWithExpiry(protocol.Role_CLIENT, []byte(token))
+		require.NoError(t, err)
+		require.Equal(t, expiresAt, expiry.Unix())
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		token, err := SignJWT(AlgHS256, "", []byte("other"), validClaims("CLIENT"))
+		require.NoError(t, err)
+
+		ok, _, err := auth.AuthenticateFromMessage(protocol.Role_CLIENT, []byte(token))
+		require.Equal(t, ErrInvalidToken, err)
+		require.False(t, ok)
+
+		_, err = auth.Verify("not.a.token")
+		require.Error(t, err)
+
+		_, err = auth.Verify("eyJhbGciOiJub25lIn0.e30.")
+		require.Error(t, err)
+	})
+
+	t.Run("claims", func(t *testing.T) {
+		expired := validClaims("CLIENT")
+		expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+		noExpiry := validClaims("CLIENT")
+		delete(noExpiry, "exp")
+
+		wrongIssuer := validClaims("CLIENT")
+		wrongIssuer["iss"] = "other"
+
+		wrongAudience := validClaims("CLIENT")
+		wrongAudience["aud"] = "other"
+
+		notYetValid := validClaims("CLIENT")
+		notYetValid["nbf"] = time.Now().Add(time.Minute).Unix()
+
+		for claims, expectedErr := range map[*JWTClaims]error{
+			&expired:       ErrTokenExpired,
+			&noExpiry:      ErrInvalidClaims,
+			&wrongIssuer:   ErrInvalidClaims,
+			&wrongAudience: ErrInvalidClaims,
+			&notYetValid:   ErrInvalidClaims,
+		} {
+			token, err := SignJWT(AlgES256, "ec1", ecKey, *claims)
+			require.NoError(t, err)
+
+			_, err = auth.Verify(token)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, expectedErr), err.Error())
+		}
+	})
+
+	t.Run("roles", func(t *testing.T) {
+		claims := validClaims("")
+		claims["role"] = []string{"communication_server", "COMMUNICATION_SERVER_HUB"}
+
+		token, err := SignJWT(AlgRS256, "rsa1", rsaKey, claims)
+		require.NoError(t, err)
+
+		ok, _, err := auth.AuthenticateFromMessage(protocol.Role_COMMUNICATION_SERVER_HUB, []byte(token))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, _, err = auth.AuthenticateFromMessage(protocol.Role_CLIENT, []byte(token))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		token, err := SignJWT(AlgES256, "ec2", newKey, validClaims("CLIENT"))
+		require.NoError(t, err)
+
+		_, err = auth.Verify(token)
+		require.Equal(t, ErrUnknownKey, err)
+
+		writeJWKS(t, jwksPath, ecJWK("ec2", newKey))
+
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(jwksPath, future, future))
+
+		auth.jwks.lastCheck = time.Time{}
+
+		_, err = auth.Verify(token)
+		require.NoError(t, err)
+
+		oldToken, err := SignJWT(AlgES256, "ec1", ecKey, validClaims("CLIENT"))
+		require.NoError(t, err)
+
+		_, err = auth.Verify(oldToken)
+		require.Equal(t, ErrUnknownKey, err)
+	})
+}
+
+func TestJWTAuthenticatorURL(t *testing.T) {
+	secret := []byte("secret")
+
+	token, err := SignJWT(AlgHS256, "", secret, validClaims("COMMUNICATION_SERVER"))
+	require.NoError(t, err)
+
+	auth, err := NewJWTAuthenticator(JWTConfig{
+		HMACSecret:    secret,
+		TokenProvider: func() (string, error) { return token, nil },
+	})
+	require.NoError(t, err)
+
+	t.Run("query param", func(t *testing.T) {
+		u, err := auth.GenerateServerConnectURL("ws://coordinator", protocol.Role_COMMUNICATION_SERVER)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("GET", u, nil)
+		require.NoError(t, err)
+		require.Equal(t, "COMMUNICATION_SERVER", req.URL.Query().Get("role"))
+
+		ok, err := auth.AuthenticateFromURL(protocol.Role_COMMUNICATION_SERVER, req)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = auth.AuthenticateFromURL(protocol.Role_CLIENT, req)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("bearer header", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "ws://coordinator/discover", nil)
+		require.NoError(t, err)
+
+		ok, err := auth.AuthenticateFromURL(protocol.Role_COMMUNICATION_SERVER, req)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		ok, err = auth.AuthenticateFromURL(protocol.Role_COMMUNICATION_SERVER, req)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("auth message", func(t *testing.T) {
+		msg, err := auth.GenerateServerAuthMessage()
+		require.NoError(t, err)
+		require.Equal(t, protocol.Role_COMMUNICATION_SERVER, msg.Role)
+		require.Equal(t, token, string(msg.Body))
+
+		noToken, err := NewJWTAuthenticator(JWTConfig{HMACSecret: secret})
+		require.NoError(t, err)
+
+		_, err = noToken.GenerateClientAuthMessage()
+		require.Error(t, err)
+	})
+}
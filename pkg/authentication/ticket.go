@@ -0,0 +1,193 @@
+package authentication
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/golang/protobuf/proto"
+)
+
+const defaultTicketTTL = time.Minute
+
+var (
+	// ErrMissingTicket indicates a peer was not introduced with a connection ticket
+	ErrMissingTicket = errors.New("missing connection ticket")
+	// ErrInvalidTicket indicates the connection ticket is malformed, its signature is invalid or it doesn't
+	// match the peer
+	ErrInvalidTicket = errors.New("invalid connection ticket")
+	// ErrTicketExpired indicates the connection ticket expired
+	ErrTicketExpired = errors.New("connection ticket expired")
+)
+
+// TicketSigner issues the coordinator connection tickets, binding the alias of a peer being introduced to a
+// server with its role and identity
+type TicketSigner struct {
+	key ed25519.PrivateKey
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewTicketSigner creates a TicketSigner, ttl is one minute by default
+func NewTicketSigner(key ed25519.PrivateKey, ttl time.Duration) *TicketSigner {
+	if ttl == 0 {
+		ttl = defaultTicketTTL
+	}
+
+	return &TicketSigner{key: key, ttl: ttl, now: time.Now}
+}
+
+// Sign creates a signed ticket for the peer alias, introduced to the toAlias server
+func (s *TicketSigner) Sign(alias uint64, toAlias uint64, role protocol.Role, identity []byte) ([]byte, error) {
+	ticket, err := proto.Marshal(&protocol.ConnectionTicket{
+		Alias:     alias,
+		ToAlias:   toAlias,
+		Role:      role,
+		Identity:  identity,
+		ExpiresAt: s.now().Add(s.ttl).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(&protocol.SignedConnectionTicket{
+		Ticket:    ticket,
+		Signature: ed25519.Sign(s.key, ticket),
+	})
+}
+
+// TicketVerifier verifies the coordinator connection tickets
+type TicketVerifier struct {
+	keys []ed25519.PublicKey
+	now  func() time.Time
+}
+
+// NewTicketVerifier creates a TicketVerifier, every key is accepted so the coordinator key can be rotated
+func NewTicketVerifier(keys ...ed25519.PublicKey) *TicketVerifier {
+	return &TicketVerifier{keys: keys, now: time.Now}
+}
+
+// Verify checks the ticket signature and expiry, returning the ticket
+func (v *TicketVerifier) Verify(signedTicket []byte) (*protocol.ConnectionTicket, error) {
+	if len(signedTicket) == 0 {
+		return nil, ErrMissingTicket
+	}
+
+	signed := &protocol.SignedConnectionTicket{}
+	if err := proto.Unmarshal(signedTicket, signed); err != nil {
+		return nil, ErrInvalidTicket
+	}
+
+	verified := false
+
+	for _, key := range v.keys {
+		if ed25519.Verify(key, signed.Ticket, signed.Signature) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return nil, ErrInvalidTicket
+	}
+
+	ticket := &protocol.ConnectionTicket{}
+	if err := proto.Unmarshal(signed.Ticket, ticket); err != nil {
+		return nil, ErrInvalidTicket
+	}
+
+	if v.now().After(time.Unix(ticket.ExpiresAt, 0)) {
+		return nil, ErrTicketExpired
+	}
+
+	return ticket, nil
+}
+
+// VerifyPeer checks the ticket was issued for the alias peer connecting to the serverAlias server, claiming
+// role with identity. A hub ticket allows claiming the communication server role. The identity is only
+// compared when both the ticket and the peer have one
+func (v *TicketVerifier) VerifyPeer(signedTicket []byte, alias uint64, serverAlias uint64, role protocol.Role,
+	identity []byte) (*protocol.ConnectionTicket, error) {
+	ticket, err := v.Verify(signedTicket)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Alias != alias || ticket.ToAlias != serverAlias {
+		return nil, fmt.Errorf("%w: issued for peer %d to %d", ErrInvalidTicket, ticket.Alias, ticket.ToAlias)
+	}
+
+	roleAllowed := ticket.Role == role ||
+		(ticket.Role == protocol.Role_COMMUNICATION_SERVER_HUB && role == protocol.Role_COMMUNICATION_SERVER)
+
+	if !roleAllowed {
+		return nil, fmt.Errorf("%w: issued for role %s", ErrInvalidTicket, ticket.Role)
+	}
+
+	if len(ticket.Identity) > 0 && len(identity) > 0 && !bytes.Equal(ticket.Identity, identity) {
+		return nil, fmt.Errorf("%w: identity mismatch", ErrInvalidTicket)
+	}
+
+	return ticket, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	return block, nil
+}
+
+// LoadTicketSigningKey loads a PKCS #8 PEM ed25519 private key, as generated by
+// `openssl genpkey -algorithm ed25519`
+func LoadTicketSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an ed25519 private key", path)
+	}
+
+	return privateKey, nil
+}
+
+// LoadTicketVerifyingKey loads a PKIX PEM ed25519 public key, as generated by `openssl pkey -pubout`
+func LoadTicketVerifyingKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an ed25519 public key", path)
+	}
+
+	return publicKey, nil
+}
@@ -0,0 +1,140 @@
+package authentication
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestConnectionTicket(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer := NewTicketSigner(privateKey, 0)
+	verifier := NewTicketVerifier(publicKey)
+
+	t.Run("success", func(t *testing.T) {
+		ticket, err := signer.Sign(1, 2, protocol.Role_CLIENT, []byte("user1"))
+		require.NoError(t, err)
+
+		verified, err := verifier.VerifyPeer(ticket, 1, 2, protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("user1"), verified.Identity)
+
+		_, err = verifier.VerifyPeer(ticket, 1, 2, protocol.Role_CLIENT, []byte("user1"))
+		require.NoError(t, err)
+	})
+
+	t.Run("client claiming a server role", func(t *testing.T) {
+		ticket, err := signer.Sign(1, 2, protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyPeer(ticket, 1, 2, protocol.Role_COMMUNICATION_SERVER, nil)
+		require.True(t, errors.Is(err, ErrInvalidTicket))
+	})
+
+	t.Run("hub claiming the server role", func(t *testing.T) {
+		ticket, err := signer.Sign(1, 2, protocol.Role_COMMUNICATION_SERVER_HUB, nil)
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyPeer(ticket, 1, 2, protocol.Role_COMMUNICATION_SERVER, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("alias mismatch", func(t *testing.T) {
+		ticket, err := signer.Sign(1, 2, protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyPeer(ticket, 3, 2, protocol.Role_CLIENT, nil)
+		require.True(t, errors.Is(err, ErrInvalidTicket))
+
+		_, err = verifier.VerifyPeer(ticket, 1, 3, protocol.Role_CLIENT, nil)
+		require.True(t, errors.Is(err, ErrInvalidTicket))
+	})
+
+	t.Run("identity mismatch", func(t *testing.T) {
+		ticket, err := signer.Sign(1, 2, protocol.Role_CLIENT, []byte("user1"))
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyPeer(ticket, 1, 2, protocol.Role_CLIENT, []byte("user2"))
+		require.True(t, errors.Is(err, ErrInvalidTicket))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := verifier.Verify(nil)
+		require.Equal(t, ErrMissingTicket, err)
+
+		_, err = verifier.Verify([]byte("ticket"))
+		require.Equal(t, ErrInvalidTicket, err)
+
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		ticket, err := NewTicketSigner(otherKey, 0).Sign(1, 2, protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(ticket)
+		require.Equal(t, ErrInvalidTicket, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expiredSigner := NewTicketSigner(privateKey, time.Minute)
+		expiredSigner.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+
+		ticket, err := expiredSigner.Sign(1, 2, protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(ticket)
+		require.Equal(t, ErrTicketExpired, err)
+	})
+}
+
+func TestLoadTicketKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ticket")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	publicDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	privatePath := filepath.Join(dir, "ticket.key")
+	publicPath := filepath.Join(dir, "ticket.pub")
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})
+
+	require.NoError(t, ioutil.WriteFile(privatePath, privatePEM, 0600))
+	require.NoError(t, ioutil.WriteFile(publicPath, publicPEM, 0600))
+
+	loadedPrivate, err := LoadTicketSigningKey(privatePath)
+	require.NoError(t, err)
+	require.Equal(t, privateKey, loadedPrivate)
+
+	loadedPublic, err := LoadTicketVerifyingKey(publicPath)
+	require.NoError(t, err)
+	require.Equal(t, publicKey, loadedPublic)
+
+	_, err = LoadTicketSigningKey(publicPath)
+	require.Error(t, err)
+
+	_, err = LoadTicketVerifyingKey(filepath.Join(dir, "missing.pub"))
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ package broker
 
 import (
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"runtime/debug"
@@ -31,6 +32,13 @@ const (
 	verbose                                                   = false
 )
 
+// ErrUnauthorized indicates that a peer is not authorized for the role
+var ErrUnauthorized = errors.New("unauthorized")
+
+var errFallbackServerRole = errors.New("fallback peers can only be clients")
+
+var errUnknownPeer = errors.New("unknown peer")
+
 // Broker ...
 type Broker struct {
 	*server.Server
@@ -38,14 +46,37 @@ type Broker struct {
 	subscriptionCh chan subscriptionChange
 	messagesCh     chan *peerMessage
 
-	subscriptions        topicSubscriptions
-	subscriptionsLock    sync.RWMutex
-	initiatedConnections map[uint64]protocol.Role
-	peers                map[uint64]*peer
-	peersMux             sync.Mutex
+	subscriptions           topicSubscriptions
+	subscriptionsLock       sync.RWMutex
+	initiatedConnections    map[uint64]protocol.Role
+	initiatedConnectionsMux sync.Mutex
+	peers                   map[uint64]*peer
+	peersMux                sync.Mutex
 
 	coordinatorURL string
+	fallbackURL    string
+	labels         map[string]string
 	auth           authentication.ServerAuthenticator
+	ticketVerifier *authentication.TicketVerifier
+	reauthWindow   time.Duration
+
+	fallbackAllowedOrigins []string
+
+	loadReportPeriod time.Duration
+	traffic          *trafficCounters
+	revoked          map[string]time.Time
+	revokedMux       sync.Mutex
+
+	dataChannelTimeout  time.Duration
+	authTimeout         time.Duration
+	dataChannelTimeouts uint32
+	authTimeouts        uint32
+
+	pingPeriod            time.Duration
+	maxRTT                time.Duration
+	maxUnreliableLoss     float64
+	lowQualityPeriods     int
+	lowQualityDisconnects uint32
 
 	zipper                                      ZipCompression
 	reliableWriterControllerFactory             WriterControllerFactory
@@ -53,6 +84,13 @@ type Broker struct {
 	reliableChannelBufferedAmountLowThreshold   uint64
 	unreliableChannelBufferedAmountLowThreshold uint64
 
+	hooks    Hooks
+	eventsCh chan func()
+	// eventsMux guards eventsClosed, so no event is queued once eventsCh is closed
+	eventsMux    sync.RWMutex
+	eventsClosed bool
+	recorder     *Recorder
+
 	log logging.Logger
 }
 
@@ -68,10 +106,78 @@ type Config struct {
 	ReliableChannelBufferedAmountLowThreshold   uint64
 	UnreliableChannelBufferedAmountLowThreshold uint64
 
-	MaxPeers               uint16
-	ExitOnCoordinatorClose bool
-	WebRtcLogLevel         zerolog.Level
-	Role                   protocol.Role
+	MaxPeers                uint16
+	ExitOnCoordinatorClose  bool
+	EstablishSessionTimeout time.Duration
+	WebRtcLogLevel          zerolog.Level
+	Role                    protocol.Role
+
+	// TicketVerifier, if set, requires peers to be introduced by the coordinator with a connection ticket
+	// matching their alias, role and identity, see coordinator.Config.TicketSigner
+	TicketVerifier *authentication.TicketVerifier
+
+	// ReauthWindow is how long before the peer credentials expire it's asked to authenticate again, 30 seconds
+	// by default. Only used with an authentication.ExpiringAuthenticator
+	ReauthWindow time.Duration
+
+	// LoadReportPeriod is how often the broker reports its load to the coordinator, 10 seconds by default
+	LoadReportPeriod time.Duration
+
+	// ReconnectGracePeriod is how long a disconnected peer has to recover before it's reconnected, 5 seconds by
+	// default. The peer keeps its topics, it's only closed if the reconnect fails, see
+	// server.Config.ReconnectGracePeriod
+	ReconnectGracePeriod time.Duration
+
+	// Network configures the ICE networking of every peer connection, e.g. an ephemeral UDP port range, the
+	// public IPs of a 1:1 NAT or udp4 only, see server.NetworkConfig
+	Network server.NetworkConfig
+
+	// CertificateProvider provides the DTLS certificate, e.g. a server.FileCertificateProvider so peers can pin
+	// the broker fingerprint. A certificate is generated on startup by default
+	CertificateProvider server.CertificateProvider
+
+	// ICETimeout, DTLSTimeout, DataChannelTimeout and AuthTimeout limit every phase of a new peer session: ICE
+	// has to connect (EstablishSessionTimeout by default), then the DTLS handshake complete, the data channels
+	// open and the peer authenticate (10 seconds each by default), otherwise the peer is closed and the timeout
+	// counted in Stats
+	ICETimeout         time.Duration
+	DTLSTimeout        time.Duration
+	DataChannelTimeout time.Duration
+	AuthTimeout        time.Duration
+
+	// PingPeriod is how often the broker pings the authenticated peers on both channels to measure their RTT and
+	// unreliable loss, see PeerStats. 5 seconds by default, a negative period disables the pings. Peers echo the
+	// pings they didn't send, the ones that don't are not measured
+	PingPeriod time.Duration
+
+	// MaxRTT and MaxUnreliableLoss, if set, close the peers whose average RTT or unreliable loss is above them for
+	// LowQualityPeriods consecutive ping periods, 3 by default
+	MaxRTT            time.Duration
+	MaxUnreliableLoss float64
+	LowQualityPeriods int
+
+	// CoordinatorTLSConfig is used when connecting to a wss coordinator url, it allows a custom CA, a client
+	// certificate and the server name (SNI) to be set, see ws.ClientTLSConfig
+	CoordinatorTLSConfig *tls.Config
+
+	// FallbackURL is the public websocket url announced to the coordinator for clients that cannot use
+	// webrtc, the endpoint itself is served by RegisterFallback, which requires the TicketVerifier
+	FallbackURL string
+
+	// FallbackAllowedOrigins are the origins browsers can open the fallback websocket from, any origin is allowed
+	// if empty, see ws.IsOriginAllowed
+	FallbackAllowedOrigins []string
+
+	// Labels are announced to the coordinator, e.g. the broker region or pool, see
+	// coordinator.LabelAwareServerSelector
+	Labels map[string]string
+
+	// Hooks are called asynchronously, in order, by a single goroutine, the events are dropped when its queue is
+	// full (a slow hook delays the other hooks, never the broker) and once the broker is shut down
+	Hooks Hooks
+
+	// Recorder enables traffic recording if set
+	Recorder *RecorderConfig
 }
 
 type peer struct {
@@ -80,24 +186,53 @@ type peer struct {
 	identity atomic.Value
 	role     int32
 
+	// initiated is set for the connections this broker opened to another server, authenticated with the server
+	// credentials, only those servers may request them again
+	initiated bool
+
 	topics map[string]struct{}
 
 	subscriptionCh chan subscriptionChange
 	messagesCh     chan *peerMessage
+	recorder       *Recorder
+	authHdlr       func(p *peer, msgType protocol.MessageType, rawMsg []byte)
 
+	authMux        sync.Mutex
+	authTimer      *time.Timer
+	authGeneration uint64
+
+	quality connectionQuality
+
+	// NOTE: the data channels, and the connection they belong to, are replaced when the peer is reconnected
 	reliableDC       *pion.DataChannel
 	reliableRWCMutex sync.RWMutex
 	reliableRWC      datachannel.ReadWriteCloser
+	reliableConn     *server.PeerConnection
 	reliableBuffer   []byte
 	reliableWriter   WriterController
 
 	unreliableDC       *pion.DataChannel
 	unreliableRWCMutex sync.RWMutex
 	unreliableRWC      datachannel.ReadWriteCloser
+	unreliableConn     *server.PeerConnection
 	unreliableBuffer   []byte
 	unreliableWriter   WriterController
 }
 
+func (p *peer) getReliableDC() *pion.DataChannel {
+	p.reliableRWCMutex.RLock()
+	defer p.reliableRWCMutex.RUnlock()
+
+	return p.reliableDC
+}
+
+func (p *peer) getUnreliableDC() *pion.DataChannel {
+	p.unreliableRWCMutex.RLock()
+	defer p.unreliableRWCMutex.RUnlock()
+
+	return p.unreliableDC
+}
+
 func (p *peer) getRole() protocol.Role {
 	return protocol.Role(atomic.LoadInt32(&p.role))
 }
@@ -124,7 +259,11 @@ type reliablePeerWriter struct {
 }
 
 func (w *reliablePeerWriter) BufferedAmount() uint64 {
-	return w.p.reliableDC.BufferedAmount()
+	if w.p.Fallback != nil {
+		return w.p.Fallback.Reliable.BufferedAmount()
+	}
+
+	return w.p.getReliableDC().BufferedAmount()
 }
 
 func (w *reliablePeerWriter) Write(p []byte) error {
@@ -135,13 +274,14 @@ func (w *reliablePeerWriter) Write(p []byte) error {
 
 	w.p.reliableRWCMutex.RLock()
 	reliableRWC := w.p.reliableRWC
+	conn := w.p.reliableConn
 	w.p.reliableRWCMutex.RUnlock()
 
 	if reliableRWC != nil {
 		_, err := reliableRWC.Write(p)
 		if err != nil {
 			w.p.Log.Error().Err(err).Msg("Error writing reliable datachannel")
-			w.p.Close()
+			w.p.CloseConn(conn)
 
 			return err
 		}
@@ -155,7 +295,11 @@ type unreliablePeerWriter struct {
 }
 
 func (w *unreliablePeerWriter) BufferedAmount() uint64 {
-	return w.p.unreliableDC.BufferedAmount()
+	if w.p.Fallback != nil {
+		return w.p.Fallback.Unreliable.BufferedAmount()
+	}
+
+	return w.p.getUnreliableDC().BufferedAmount()
 }
 
 func (w *unreliablePeerWriter) Write(p []byte) error {
@@ -166,13 +310,14 @@ func (w *unreliablePeerWriter) Write(p []byte) error {
 
 	w.p.unreliableRWCMutex.RLock()
 	unreliableRWC := w.p.unreliableRWC
+	conn := w.p.unreliableConn
 	w.p.unreliableRWCMutex.RUnlock()
 
 	if unreliableRWC != nil {
 		_, err := unreliableRWC.Write(p)
 		if err != nil {
 			w.p.Log.Error().Err(err).Msg("Error writing unreliable datachannel")
-			w.p.Close()
+			w.p.CloseConn(conn)
 
 			return err
 		}
@@ -187,7 +332,7 @@ type WriterController interface {
 	OnBufferedAmountLow()
 }
 
-func (p *peer) readReliablePump() {
+func (p *peer) readReliablePump(conn *server.PeerConnection) {
 	header := protocol.MessageHeader{}
 
 	if p.reliableBuffer == nil {
@@ -205,7 +350,7 @@ func (p *peer) readReliablePump() {
 
 		if err != nil {
 			p.Log.Info().Err(err).Msg("exit peer.readReliablePump(), datachannel closed")
-			p.Close()
+			p.CloseConn(conn)
 
 			return
 		}
@@ -238,6 +383,10 @@ func (p *peer) readReliablePump() {
 					Msg("got a new message")
 			}
 
+			if p.recorder != nil {
+				p.recorder.RecordSubscription(p.Alias, p.getRole(), rawMsg)
+			}
+
 			p.subscriptionCh <- subscriptionChange{
 				peer:      p,
 				format:    topicSubscriptionMessage.Format,
@@ -269,14 +418,16 @@ func (p *peer) readReliablePump() {
 					Msg("got a new message")
 			}
 
-			p.WriteReliable(rawMsg)
+			p.readPing(true, rawMsg)
+		case protocol.MessageType_AUTH, protocol.MessageType_AUTH_REQUEST:
+			p.authHdlr(p, msgType, rawMsg)
 		default:
 			p.Log.Debug().Str("type", msgType.String()).Msg("unhandled reliable message from peer")
 		}
 	}
 }
 
-func (p *peer) readUnreliablePump() {
+func (p *peer) readUnreliablePump(conn *server.PeerConnection) {
 	header := protocol.MessageHeader{}
 
 	if p.unreliableBuffer == nil {
@@ -294,7 +445,7 @@ func (p *peer) readUnreliablePump() {
 
 		if err != nil {
 			p.Log.Info().Err(err).Msg("exit peer.readUnreliablePump(), datachannel closed")
-			p.Close()
+			p.CloseConn(conn)
 
 			return
 		}
@@ -339,7 +490,7 @@ func (p *peer) readUnreliablePump() {
 					Msg("got a new message")
 			}
 
-			p.WriteUnreliable(rawMsg)
+			p.readPing(false, rawMsg)
 		default:
 			p.Log.Debug().Str("type", msgType.String()).Msg("unhandled unreliable message from peer")
 		}
@@ -362,6 +513,10 @@ func (p *peer) readTopicMessage(reliable bool, rawMsg []byte) {
 			Msg("message received")
 	}
 
+	if p.recorder != nil {
+		p.recorder.RecordTopic(p.Alias, p.getRole(), reliable, message.Topic, rawMsg)
+	}
+
 	msg := &peerMessage{
 		fromServer: p.getRole() == protocol.Role_COMMUNICATION_SERVER,
 		reliable:   reliable,
@@ -416,6 +571,10 @@ func (p *peer) readTopicIdentityMessage(reliable bool, rawMsg []byte) {
 			Msg("identity message received")
 	}
 
+	if p.recorder != nil {
+		p.recorder.RecordTopic(p.Alias, p.getRole(), reliable, message.Topic, rawMsg)
+	}
+
 	role := p.getRole()
 	msg := &peerMessage{
 		fromServer: role == protocol.Role_COMMUNICATION_SERVER,
@@ -623,30 +782,81 @@ func NewBroker(config *Config) (*Broker, error) {
 		initiatedConnections:              make(map[uint64]protocol.Role),
 		peers:                             make(map[uint64]*peer),
 		auth:                              config.Auth,
+		ticketVerifier:                    config.TicketVerifier,
+		reauthWindow:                      config.ReauthWindow,
+		loadReportPeriod:                  config.LoadReportPeriod,
+		dataChannelTimeout:                config.DataChannelTimeout,
+		authTimeout:                       config.AuthTimeout,
+		pingPeriod:                        config.PingPeriod,
+		maxRTT:                            config.MaxRTT,
+		maxUnreliableLoss:                 config.MaxUnreliableLoss,
+		lowQualityPeriods:                 config.LowQualityPeriods,
+		traffic:                           &trafficCounters{},
+		revoked:                           make(map[string]time.Time),
 		coordinatorURL:                    config.CoordinatorURL,
+		fallbackURL:                       config.FallbackURL,
+		fallbackAllowedOrigins:            config.FallbackAllowedOrigins,
+		labels:                            config.Labels,
 		zipper:                            config.Zipper,
 		role:                              config.Role,
 		reliableWriterControllerFactory:   config.ReliableWriterControllerFactory,
 		unreliableWriterControllerFactory: config.UnreliableWriterControllerFactory,
 		reliableChannelBufferedAmountLowThreshold:   config.ReliableChannelBufferedAmountLowThreshold,
 		unreliableChannelBufferedAmountLowThreshold: config.UnreliableChannelBufferedAmountLowThreshold,
+		hooks:    config.Hooks,
+		eventsCh: make(chan func(), eventsQueueSize),
 	}
 
 	var err error
 
 	broker.Server, err = server.NewServer(&server.Config{
-		WebRtcLogLevel:         config.WebRtcLogLevel,
-		Log:                    &log,
-		ICEServers:             config.ICEServers,
-		OnNewPeerHdlr:          broker.onNewPeer,
-		OnPeerDisconnectedHdlr: broker.onPeerDisconnected,
-		ExitOnCoordinatorClose: config.ExitOnCoordinatorClose,
-		MaxPeers:               config.MaxPeers,
+		WebRtcLogLevel:             config.WebRtcLogLevel,
+		Log:                        &log,
+		ICEServers:                 config.ICEServers,
+		OnNewPeerHdlr:              broker.onNewPeer,
+		OnPeerDisconnectedHdlr:     broker.onPeerDisconnected,
+		OnRevokeHdlr:               broker.Revoke,
+		OnCoordinatorReconnectHdlr: broker.onCoordinatorReconnect,
+		OnPeerReconnectHdlr:        broker.onPeerReconnect,
+		OnPeerConnectedHdlr:        broker.onPeerConnected,
+		ExitOnCoordinatorClose:     config.ExitOnCoordinatorClose,
+		EstablishSessionTimeout:    config.EstablishSessionTimeout,
+		ICETimeout:                 config.ICETimeout,
+		DTLSTimeout:                config.DTLSTimeout,
+		ReconnectGracePeriod:       config.ReconnectGracePeriod,
+		MaxPeers:                   config.MaxPeers,
+		CoordinatorTLSConfig:       config.CoordinatorTLSConfig,
+		Network:                    config.Network,
+		CertificateProvider:        config.CertificateProvider,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if broker.loadReportPeriod == 0 {
+		broker.loadReportPeriod = defaultLoadReportPeriod
+	}
+
+	if broker.reauthWindow == 0 {
+		broker.reauthWindow = defaultReauthWindow
+	}
+
+	if broker.dataChannelTimeout == 0 {
+		broker.dataChannelTimeout = defaultDataChannelTimeout
+	}
+
+	if broker.authTimeout == 0 {
+		broker.authTimeout = defaultAuthTimeout
+	}
+
+	if broker.pingPeriod == 0 {
+		broker.pingPeriod = defaultPingPeriod
+	}
+
+	if broker.lowQualityPeriods == 0 {
+		broker.lowQualityPeriods = defaultLowQualityPeriods
+	}
+
 	if broker.zipper == nil {
 		broker.zipper = &GzipCompression{}
 	}
@@ -671,6 +881,17 @@ func NewBroker(config *Config) (*Broker, error) {
 		broker.unreliableChannelBufferedAmountLowThreshold = defaultUnreliableChannelBufferedAmountLowThreshold
 	}
 
+	if config.Recorder != nil {
+		broker.recorder, err = NewRecorder(*config.Recorder, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !broker.hooks.isEmpty() {
+		go broker.processEvents()
+	}
+
 	return broker, nil
 }
 
@@ -742,6 +963,7 @@ func (b *Broker) processTopicMessage(msg *peerMessage) {
 	}
 
 	clientCount := uint32(0)
+	bytesSent := 0
 
 	for _, p := range subscription.clients {
 		if p == msg.from {
@@ -751,6 +973,7 @@ func (b *Broker) processTopicMessage(msg *peerMessage) {
 		clientCount++
 
 		rawMsg := msg.rawMsgToClient
+		bytesSent += len(rawMsg)
 
 		if reliable {
 			p.WriteReliable(rawMsg)
@@ -769,6 +992,7 @@ func (b *Broker) processTopicMessage(msg *peerMessage) {
 			serverCount++
 
 			rawMsg := msg.rawMsgToServer
+			bytesSent += len(rawMsg)
 
 			if reliable {
 				p.WriteReliable(rawMsg)
@@ -778,6 +1002,8 @@ func (b *Broker) processTopicMessage(msg *peerMessage) {
 		}
 	}
 
+	b.traffic.count(len(msg.rawMsgToClient), bytesSent)
+
 	if verbose {
 		b.log.Debug().
 			Bool("reliable", reliable).
@@ -823,13 +1049,13 @@ func (b *Broker) broadcastSubscriptionChange() error {
 
 	if verbose {
 		b.log.Debug().
-			Uint64("serverAlias", b.Alias).
+			Uint64("serverAlias", b.GetAlias()).
 			Uint32("serverCount", serverCount).
 			Str("topics", string(topics)).
 			Msg("subscription message broadcasted")
 	} else {
 		b.log.Debug().
-			Uint64("serverAlias", b.Alias).
+			Uint64("serverAlias", b.GetAlias()).
 			Uint32("serverCount", serverCount).
 			Msg("subscription message broadcasted")
 	}
@@ -869,6 +1095,7 @@ func (b *Broker) processSubscriptionChange(change subscriptionChange) error {
 			}
 
 			p.topics[topic] = struct{}{}
+			b.emitSubscriptionAdded(p, topic)
 
 			b.subscriptionsLock.Lock()
 			if role == protocol.Role_COMMUNICATION_SERVER {
@@ -894,6 +1121,7 @@ func (b *Broker) processSubscriptionChange(change subscriptionChange) error {
 		}
 
 		delete(p.topics, topic)
+		b.emitSubscriptionRemoved(p, topic)
 
 		b.subscriptionsLock.Lock()
 		if role == protocol.Role_COMMUNICATION_SERVER {
@@ -922,25 +1150,62 @@ func (b *Broker) GenerateCoordinatorConnectURL() (string, error) {
 		return "", err
 	}
 
+	if b.fallbackURL != "" {
+		url, err = addFallbackURL(url, b.fallbackURL)
+		if err != nil {
+			b.log.Error().Err(err).Msg("error adding fallback url to the coordinator url")
+			return "", err
+		}
+	}
+
+	if len(b.labels) > 0 {
+		url, err = addLabels(url, b.labels)
+		if err != nil {
+			b.log.Error().Err(err).Msg("error adding labels to the coordinator url")
+			return "", err
+		}
+	}
+
 	return url, nil
 }
 
 // Connect connects the broker to the coordinator
 func (b *Broker) Connect() error {
-	connectURL, err := b.GenerateCoordinatorConnectURL()
+	welcomeMessage, err := b.ConnectCoordinatorFunc(b.GenerateCoordinatorConnectURL)
 	if err != nil {
 		return err
 	}
 
-	welcomeMessage, err := b.ConnectCoordinator(connectURL)
-	if err != nil {
+	if err := b.connectServers(welcomeMessage.AvailableServers); err != nil {
+		b.log.Error().Err(err).Msg("init peer error creating server (processing welcome)")
 		return err
 	}
 
-	for _, alias := range welcomeMessage.AvailableServers {
+	go b.reportLoad()
+
+	if b.pingPeriod > 0 {
+		go b.pingPeers()
+	}
+
+	return nil
+}
+
+// connectServers connects to the servers announced in a welcome message, skipping the ones already connected
+func (b *Broker) connectServers(aliases []uint64) error {
+	for _, alias := range aliases {
+		b.peersMux.Lock()
+		_, connected := b.peers[alias]
+		b.peersMux.Unlock()
+
+		if connected {
+			continue
+		}
+
+		b.initiatedConnectionsMux.Lock()
 		b.initiatedConnections[alias] = protocol.Role_COMMUNICATION_SERVER
+		b.initiatedConnectionsMux.Unlock()
+
 		if err := b.ConnectPeer(alias); err != nil {
-			b.log.Error().Err(err).Msg("init peer error creating server (processing welcome)")
 			return err
 		}
 	}
@@ -948,9 +1213,22 @@ func (b *Broker) Connect() error {
 	return nil
 }
 
+// onCoordinatorReconnect connects to the servers that joined while the broker was disconnected from the
+// coordinator, the existing peers are kept
+func (b *Broker) onCoordinatorReconnect(welcomeMessage *protocol.WelcomeMessage) {
+	if err := b.connectServers(welcomeMessage.AvailableServers); err != nil {
+		b.log.Error().Err(err).Msg("init peer error creating server (processing reconnection welcome)")
+	}
+}
+
 // Shutdown ...
 func (b *Broker) Shutdown() {
 	server.Shutdown(b.Server)
+	b.closeEvents()
+
+	if b.recorder != nil {
+		b.recorder.Close()
+	}
 }
 
 // Stats ...
@@ -964,14 +1242,31 @@ type Stats struct {
 	SubscriptionChSize  int
 	MessagesChSize      int
 	Peers               map[uint64]PeerStats
+
+	CoordinatorState      server.CoordinatorState
+	CoordinatorReconnects uint32
+	Reconnects            uint32
+	FailedReconnects      uint32
+
+	// ICETimeouts, DTLSTimeouts, DataChannelTimeouts and AuthTimeouts count the new peers closed because the
+	// phase didn't complete in time
+	ICETimeouts         uint32
+	DTLSTimeouts        uint32
+	DataChannelTimeouts uint32
+	AuthTimeouts        uint32
+
+	// LowQualityDisconnects counts the peers closed because of their connection quality, see Config.MaxRTT
+	LowQualityDisconnects uint32
 }
 
 // PeerStats ...
 type PeerStats struct {
 	Alias      uint64
 	Identity   []byte
+	Role       protocol.Role
 	State      pion.ICEConnectionState
 	TopicCount uint32
+	Fallback   bool
 
 	Nomination          bool
 	LocalCandidateType  pion.ICECandidateType
@@ -1003,6 +1298,8 @@ type PeerStats struct {
 
 	SCTPTransportBytesSent     uint64
 	SCTPTransportBytesReceived uint64
+
+	ConnectionQuality
 }
 
 // GetBrokerStats ...
@@ -1051,6 +1348,18 @@ func (b *Broker) GetBrokerStats() Stats {
 		TopicCount:          topicCount,
 		SubscriptionChSize:  len(b.subscriptionCh),
 		MessagesChSize:      len(b.messagesCh),
+
+		CoordinatorState:      serverStats.CoordinatorState,
+		CoordinatorReconnects: serverStats.CoordinatorReconnects,
+		Reconnects:            serverStats.Reconnects,
+		FailedReconnects:      serverStats.FailedReconnects,
+
+		ICETimeouts:         serverStats.ICETimeouts,
+		DTLSTimeouts:        serverStats.DTLSTimeouts,
+		DataChannelTimeouts: atomic.LoadUint32(&b.dataChannelTimeouts),
+		AuthTimeouts:        atomic.LoadUint32(&b.authTimeouts),
+
+		LowQualityDisconnects: atomic.LoadUint32(&b.lowQualityDisconnects),
 	}
 
 	for _, report := range serverStats.Peers {
@@ -1059,21 +1368,49 @@ func (b *Broker) GetBrokerStats() Stats {
 		b.peersMux.Unlock()
 
 		stats := PeerStats{
-			Alias:      p.Alias,
-			Identity:   p.GetIdentity(),
-			TopicCount: uint32(len(p.topics)),
-			State:      p.Conn.ICEConnectionState(),
+			Alias:             p.Alias,
+			Identity:          p.GetIdentity(),
+			Role:              p.getRole(),
+			TopicCount:        uint32(len(p.topics)),
+			Fallback:          report.Fallback,
+			ConnectionQuality: p.quality.stats(),
 		}
 
-		if p.reliableDC != nil {
-			stats.ReliableBufferedAmount = p.reliableDC.BufferedAmount()
+		if p.Fallback != nil {
+			reliableStats := p.Fallback.Reliable.Stats()
+			stats.ReliableMessagesSent = reliableStats.MessagesSent
+			stats.ReliableBytesSent = reliableStats.BytesSent
+			stats.ReliableMessagesReceived = reliableStats.MessagesReceived
+			stats.ReliableBytesReceived = reliableStats.BytesReceived
+			stats.ReliableBufferedAmount = p.Fallback.Reliable.BufferedAmount()
+
+			unreliableStats := p.Fallback.Unreliable.Stats()
+			stats.UnreliableMessagesSent = unreliableStats.MessagesSent
+			stats.UnreliableBytesSent = unreliableStats.BytesSent
+			stats.UnreliableMessagesReceived = unreliableStats.MessagesReceived
+			stats.UnreliableBytesReceived = unreliableStats.BytesReceived
+			stats.UnreliableBufferedAmount = p.Fallback.Unreliable.BufferedAmount()
+
+			brokerStats.Peers[report.Alias] = stats
+
+			continue
+		}
+
+		conn := p.GetConn()
+		reliableDC := p.getReliableDC()
+		unreliableDC := p.getUnreliableDC()
+
+		stats.State = conn.ICEConnectionState()
+
+		if reliableDC != nil {
+			stats.ReliableBufferedAmount = reliableDC.BufferedAmount()
 		}
 
-		if p.unreliableDC != nil {
-			stats.UnreliableBufferedAmount = p.unreliableDC.BufferedAmount()
+		if unreliableDC != nil {
+			stats.UnreliableBufferedAmount = unreliableDC.BufferedAmount()
 		}
 
-		connStats, ok := report.GetConnectionStats(p.Conn)
+		connStats, ok := report.GetConnectionStats(conn)
 		if ok {
 			stats.DataChannelsOpened = connStats.DataChannelsOpened
 			stats.DataChannelsClosed = connStats.DataChannelsClosed
@@ -1081,7 +1418,7 @@ func (b *Broker) GetBrokerStats() Stats {
 			stats.DataChannelsAccepted = connStats.DataChannelsAccepted
 		}
 
-		reliableStats, ok := report.GetDataChannelStats(p.reliableDC)
+		reliableStats, ok := report.GetDataChannelStats(reliableDC)
 		if ok {
 			stats.ReliableProtocol = reliableStats.Protocol
 			stats.ReliableState = reliableStats.State
@@ -1091,7 +1428,7 @@ func (b *Broker) GetBrokerStats() Stats {
 			stats.ReliableBytesReceived = reliableStats.BytesReceived
 		}
 
-		unreliableStats, ok := report.GetDataChannelStats(p.unreliableDC)
+		unreliableStats, ok := report.GetDataChannelStats(unreliableDC)
 		if ok {
 			stats.UnreliableProtocol = unreliableStats.Protocol
 			stats.UnreliableState = unreliableStats.State
@@ -1141,36 +1478,76 @@ func (b *Broker) GetBrokerStats() Stats {
 
 func (b *Broker) onNewPeer(rawPeer *server.Peer) error {
 	role := protocol.Role_UNKNOWN_ROLE
+	initiated := false
 
+	b.initiatedConnectionsMux.Lock()
 	if knownRole, ok := b.initiatedConnections[rawPeer.Alias]; ok {
 		role = knownRole
+		initiated = true
 
 		delete(b.initiatedConnections, rawPeer.Alias)
 	}
-
-	var err error
+	b.initiatedConnectionsMux.Unlock()
 
 	p := &peer{
 		Peer:           rawPeer,
 		topics:         make(map[string]struct{}),
 		subscriptionCh: b.subscriptionCh,
 		messagesCh:     b.messagesCh,
+		recorder:       b.recorder,
+		authHdlr:       b.processAuthMessage,
 		role:           int32(role),
+		initiated:      initiated,
 	}
 
-	p.reliableDC, err = p.Conn.CreateDataChannel("reliable", nil)
-	if err != nil {
-		p.Log.Error().Err(err).Msg("cannot create new reliable data channel")
+	if p.Fallback != nil {
+		b.initFallbackPeer(p)
+		return nil
+	}
 
-		if err = p.Conn.Close(); err != nil {
+	p.reliableWriter = b.reliableWriterControllerFactory(p.Alias, &reliablePeerWriter{p})
+	p.unreliableWriter = b.unreliableWriterControllerFactory(p.Alias, &unreliablePeerWriter{p})
+
+	if err := b.initDataChannels(p, p.Conn, false); err != nil {
+		if err := p.Conn.Close(); err != nil {
 			p.Log.Debug().Err(err).Msg("error closing connection")
-			return err
 		}
 
 		return err
 	}
 
-	p.reliableDC.SetBufferedAmountLowThreshold(b.reliableChannelBufferedAmountLowThreshold)
+	b.peersMux.Lock()
+	b.peers[p.Alias] = p
+	b.peersMux.Unlock()
+
+	return nil
+}
+
+// onPeerReconnect creates the data channels of the connection replacing a disconnected one, the peer keeps its
+// topics and writers
+func (b *Broker) onPeerReconnect(rawPeer *server.Peer, conn *server.PeerConnection) error {
+	b.peersMux.Lock()
+	p := b.peers[rawPeer.Alias]
+	b.peersMux.Unlock()
+
+	if p == nil {
+		return errUnknownPeer
+	}
+
+	return b.initDataChannels(p, conn, true)
+}
+
+// initDataChannels creates the peer data channels on conn. The channels of a reconnect connection replace the
+// existing ones once open, and the peer is not authenticated again
+func (b *Broker) initDataChannels(p *peer, conn *server.PeerConnection, reconnect bool) error {
+	reliableDC, err := conn.CreateDataChannel("reliable", nil)
+	if err != nil {
+		p.Log.Error().Err(err).Msg("cannot create new reliable data channel")
+		return err
+	}
+
+	reliableDC.SetBufferedAmountLowThreshold(b.reliableChannelBufferedAmountLowThreshold)
+	reliableDC.OnBufferedAmountLow(p.reliableWriter.OnBufferedAmountLow)
 
 	maxRetransmits := uint16(0)
 	ordered := false
@@ -1179,131 +1556,45 @@ func (b *Broker) onNewPeer(rawPeer *server.Peer) error {
 		Ordered:        &ordered,
 	}
 
-	p.unreliableDC, err = p.Conn.CreateDataChannel("unreliable", options)
+	unreliableDC, err := conn.CreateDataChannel("unreliable", options)
 	if err != nil {
 		p.Log.Error().Err(err).Msg("cannot create new unreliable data channel")
-
-		if err = p.Conn.Close(); err != nil {
-			p.Log.Debug().Err(err).Msg("error closing connection")
-			return err
-		}
-
 		return err
 	}
 
-	p.unreliableDC.SetBufferedAmountLowThreshold(b.unreliableChannelBufferedAmountLowThreshold)
-
-	unreliableDCReady := make(chan bool)
-
-	p.reliableWriter = b.reliableWriterControllerFactory(p.Alias, &reliablePeerWriter{p})
-	p.reliableDC.OnBufferedAmountLow(p.reliableWriter.OnBufferedAmountLow)
+	unreliableDC.SetBufferedAmountLowThreshold(b.unreliableChannelBufferedAmountLowThreshold)
+	unreliableDC.OnBufferedAmountLow(p.unreliableWriter.OnBufferedAmountLow)
 
-	p.unreliableWriter = b.unreliableWriterControllerFactory(p.Alias, &unreliablePeerWriter{p})
-	p.unreliableDC.OnBufferedAmountLow(p.unreliableWriter.OnBufferedAmountLow)
+	if !reconnect {
+		p.reliableDC = reliableDC
+		p.unreliableDC = unreliableDC
+	}
 
-	b.peersMux.Lock()
-	b.peers[p.Alias] = p
-	b.peersMux.Unlock()
+	unreliableDCReady := make(chan bool)
 
-	p.reliableDC.OnOpen(func() {
-		p.Log.Info().Msg("Reliable data channel open")
-		d, err := p.reliableDC.Detach()
+	reliableDC.OnOpen(func() {
+		p.Log.Info().Bool("reconnect", reconnect).Msg("Reliable data channel open")
+		d, err := reliableDC.Detach()
 		if err != nil {
 			p.Log.Error().Err(err).Msg("cannot detach data channel")
-			p.Close()
+			p.CloseConn(conn)
 			return
 		}
 
 		p.reliableRWCMutex.Lock()
+		p.reliableDC = reliableDC
 		p.reliableRWC = d
+		p.reliableConn = conn
 		p.reliableRWCMutex.Unlock()
 
-		if p.getRole() == protocol.Role_UNKNOWN_ROLE {
-			p.Log.Debug().Msg("unknown role, waiting for auth message")
-			header := protocol.MessageHeader{}
-			buffer := make([]byte, maxWorldCommMessageSize)
-			n, err := d.Read(buffer)
-
-			if err != nil {
-				p.Log.Error().Err(err).Msg("datachannel closed before auth")
-				p.Close()
-				return
-			}
-
-			rawMsg := buffer[:n]
-			if err = proto.Unmarshal(rawMsg, &header); err != nil {
-				p.Log.Error().Err(err).Msg("decode auth header message failure")
-				p.Close()
-				return
-			}
-
-			msgType := header.GetType()
-
-			if msgType != protocol.MessageType_AUTH {
-				p.Log.Info().Str("msgType", msgType.String()).
-					Msg("closing connection: sending data without authorization")
-				p.Close()
-				return
-			}
-
-			authMessage := protocol.AuthMessage{}
-			if err = proto.Unmarshal(rawMsg, &authMessage); err != nil {
-				p.Log.Error().Err(err).Msg("decode auth message failure")
-				p.Close()
-				return
-			}
-
-			if authMessage.Role == protocol.Role_UNKNOWN_ROLE {
-				p.Log.Error().Err(err).Msg("unknown role")
-				p.Close()
-				return
-			}
-
-			isValid, identity, err := b.auth.AuthenticateFromMessage(authMessage.Role, authMessage.Body)
-			if err != nil {
-				p.Log.Error().Err(err).Msg("authentication error")
-				p.Close()
-				return
-			}
-
-			if isValid {
-				atomic.StoreInt32(&p.role, int32(authMessage.Role))
-				p.identity.Store(identity)
-				p.Log.Debug().Msg("peer authorized")
-
-				if authMessage.Role == protocol.Role_COMMUNICATION_SERVER {
-					b.subscriptionsLock.Lock()
-					topics, err := b.subscriptions.buildTopicsBuffer()
-					b.subscriptionsLock.Unlock()
-					if err != nil {
-						p.Log.Error().Err(err).Msg("build topic buffer error")
-						p.Close()
-						return
-					}
-
-					if len(topics) > 0 {
-						topicSubscriptionMessage := &protocol.SubscriptionMessage{
-							Type:   protocol.MessageType_SUBSCRIPTION,
-							Format: protocol.Format_PLAIN,
-							Topics: topics,
-						}
-
-						rawMsg, err := proto.Marshal(topicSubscriptionMessage)
-						if err != nil {
-							p.Log.Error().Err(err).Msg("encode topic subscription message failure")
-							p.Close()
-							return
-						}
-
-						p.WriteReliable(rawMsg)
-					}
-				}
-			} else {
-				p.Log.Info().Msg("closing connection: not authorized")
-				p.Close()
+		switch {
+		case reconnect:
+			p.Log.Debug().Msg("reconnected, the peer is already authenticated")
+		case p.getRole() == protocol.Role_UNKNOWN_ROLE:
+			if !b.authenticate(p, d) {
 				return
 			}
-		} else {
+		default:
 			p.Log.Debug().Msg("role already identified, sending auth message")
 			authMessage, err := b.auth.GenerateServerAuthMessage()
 
@@ -1325,25 +1616,29 @@ func (b *Broker) onNewPeer(rawPeer *server.Peer) error {
 				p.Close()
 				return
 			}
+
+			b.emitServerLinkEstablished(p.Alias)
 		}
 
-		go p.readReliablePump()
+		go p.readReliablePump(conn)
 
 		<-unreliableDCReady
-		go p.readUnreliablePump()
+		go p.readUnreliablePump(conn)
 	})
 
-	p.unreliableDC.OnOpen(func() {
-		p.Log.Info().Msg("Unreliable data channel open")
-		d, err := p.unreliableDC.Detach()
+	unreliableDC.OnOpen(func() {
+		p.Log.Info().Bool("reconnect", reconnect).Msg("Unreliable data channel open")
+		d, err := unreliableDC.Detach()
 		if err != nil {
 			p.Log.Error().Err(err).Msg("cannot detach datachannel")
-			p.Close()
+			p.CloseConn(conn)
 			return
 		}
 
 		p.unreliableRWCMutex.Lock()
+		p.unreliableDC = unreliableDC
 		p.unreliableRWC = d
+		p.unreliableConn = conn
 		p.unreliableRWCMutex.Unlock()
 		unreliableDCReady <- true
 	})
@@ -1351,6 +1646,171 @@ func (b *Broker) onNewPeer(rawPeer *server.Peer) error {
 	return nil
 }
 
+// authenticate waits for the peer auth message on the reliable channel, the connection is closed
+// if the peer cannot be authenticated
+func (b *Broker) authenticate(p *peer, d datachannel.ReadWriteCloser) bool {
+	p.Log.Debug().Msg("unknown role, waiting for auth message")
+	header := protocol.MessageHeader{}
+	buffer := make([]byte, maxWorldCommMessageSize)
+	stopAuthTimeout := b.startAuthTimeout(p)
+	n, err := d.Read(buffer)
+
+	if !stopAuthTimeout() {
+		return false
+	}
+
+	if err != nil {
+		p.Log.Error().Err(err).Msg("datachannel closed before auth")
+		p.Close()
+		return false
+	}
+
+	rawMsg := buffer[:n]
+	if err = proto.Unmarshal(rawMsg, &header); err != nil {
+		p.Log.Error().Err(err).Msg("decode auth header message failure")
+		p.Close()
+		return false
+	}
+
+	msgType := header.GetType()
+
+	if msgType != protocol.MessageType_AUTH {
+		p.Log.Info().Str("msgType", msgType.String()).
+			Msg("closing connection: sending data without authorization")
+		b.emitAuthRejected(p.Alias, protocol.Role_UNKNOWN_ROLE,
+			fmt.Errorf("unexpected %s message before auth", msgType.String()))
+		p.Close()
+		return false
+	}
+
+	authMessage := protocol.AuthMessage{}
+	if err = proto.Unmarshal(rawMsg, &authMessage); err != nil {
+		p.Log.Error().Err(err).Msg("decode auth message failure")
+		p.Close()
+		return false
+	}
+
+	if authMessage.Role == protocol.Role_UNKNOWN_ROLE {
+		p.Log.Error().Err(err).Msg("unknown role")
+		b.emitAuthRejected(p.Alias, authMessage.Role, errors.New("unknown role"))
+		p.Close()
+		return false
+	}
+
+	if p.Fallback != nil && authMessage.Role != protocol.Role_CLIENT {
+		p.Log.Info().Str("role", authMessage.Role.String()).Msg("closing connection: fallback peer claims a server role")
+		b.emitAuthRejected(p.Alias, authMessage.Role, errFallbackServerRole)
+		p.Close()
+		return false
+	}
+
+	isValid, identity, expiresAt, err := b.verifyAuth(authMessage.Role, authMessage.Body)
+	if err != nil {
+		p.Log.Error().Err(err).Msg("authentication error")
+		b.emitAuthRejected(p.Alias, authMessage.Role, err)
+		p.Close()
+		return false
+	}
+
+	if !isValid {
+		p.Log.Info().Msg("closing connection: not authorized")
+		b.emitAuthRejected(p.Alias, authMessage.Role, ErrUnauthorized)
+		p.Close()
+		return false
+	}
+
+	if b.ticketVerifier != nil {
+		ticket, err := b.ticketVerifier.VerifyPeer(p.ConnectTicket, p.Alias, b.GetAlias(), authMessage.Role, identity)
+		if err != nil {
+			p.Log.Info().Err(err).Msg("closing connection: invalid connection ticket")
+			b.emitAuthRejected(p.Alias, authMessage.Role, err)
+			p.Close()
+			return false
+		}
+
+		if len(identity) == 0 {
+			identity = ticket.Identity
+		}
+	}
+
+	if b.isRevoked(identity) {
+		p.Log.Info().Msg("closing connection: identity revoked")
+		b.emitAuthRejected(p.Alias, authMessage.Role, ErrRevoked)
+		p.Close()
+		return false
+	}
+
+	atomic.StoreInt32(&p.role, int32(authMessage.Role))
+	p.identity.Store(identity)
+	p.Log.Debug().Msg("peer authorized")
+	b.emitPeerAuthenticated(p.Alias, authMessage.Role, identity)
+	b.scheduleReauth(p, expiresAt)
+
+	if authMessage.Role == protocol.Role_COMMUNICATION_SERVER {
+		b.subscriptionsLock.Lock()
+		topics, err := b.subscriptions.buildTopicsBuffer()
+		b.subscriptionsLock.Unlock()
+		if err != nil {
+			p.Log.Error().Err(err).Msg("build topic buffer error")
+			p.Close()
+			return false
+		}
+
+		if len(topics) > 0 {
+			topicSubscriptionMessage := &protocol.SubscriptionMessage{
+				Type:   protocol.MessageType_SUBSCRIPTION,
+				Format: protocol.Format_PLAIN,
+				Topics: topics,
+			}
+
+			rawMsg, err := proto.Marshal(topicSubscriptionMessage)
+			if err != nil {
+				p.Log.Error().Err(err).Msg("encode topic subscription message failure")
+				p.Close()
+				return false
+			}
+
+			p.WriteReliable(rawMsg)
+		}
+
+		b.emitServerLinkEstablished(p.Alias)
+	}
+
+	return true
+}
+
+// initFallbackPeer plugs the websocket fallback emulated channels in place of the data channels, fallback
+// peers are always clients or servers connecting to us, so they are authenticated first
+func (b *Broker) initFallbackPeer(p *peer) {
+	reliable := p.Fallback.Reliable
+	unreliable := p.Fallback.Unreliable
+
+	reliable.SetBufferedAmountLowThreshold(b.reliableChannelBufferedAmountLowThreshold)
+	unreliable.SetBufferedAmountLowThreshold(b.unreliableChannelBufferedAmountLowThreshold)
+
+	p.reliableWriter = b.reliableWriterControllerFactory(p.Alias, &reliablePeerWriter{p})
+	reliable.OnBufferedAmountLow(p.reliableWriter.OnBufferedAmountLow)
+
+	p.unreliableWriter = b.unreliableWriterControllerFactory(p.Alias, &unreliablePeerWriter{p})
+	unreliable.OnBufferedAmountLow(p.unreliableWriter.OnBufferedAmountLow)
+
+	p.reliableRWC = reliable
+	p.unreliableRWC = unreliable
+
+	b.peersMux.Lock()
+	b.peers[p.Alias] = p
+	b.peersMux.Unlock()
+
+	go func() {
+		if !b.authenticate(p, reliable) {
+			return
+		}
+
+		go p.readReliablePump(nil)
+		go p.readUnreliablePump(nil)
+	}()
+}
+
 func (b *Broker) onPeerDisconnected(rawPeer *server.Peer) {
 	b.peersMux.Lock()
 
@@ -1365,10 +1825,13 @@ func (b *Broker) onPeerDisconnected(rawPeer *server.Peer) {
 		return
 	}
 
+	b.scheduleReauth(p, time.Time{})
+
+	role := p.getRole()
 	topicsChanged := false
 
 	b.subscriptionsLock.Lock()
-	if p.getRole() == protocol.Role_COMMUNICATION_SERVER {
+	if role == protocol.Role_COMMUNICATION_SERVER {
 		for topic := range p.topics {
 			if b.subscriptions.RemoveServerSubscription(topic, p) &&
 				b.role == protocol.Role_COMMUNICATION_SERVER_HUB {
@@ -1384,6 +1847,20 @@ func (b *Broker) onPeerDisconnected(rawPeer *server.Peer) {
 	}
 	b.subscriptionsLock.Unlock()
 
+	if !b.hooks.isEmpty() {
+		for topic := range p.topics {
+			b.emitSubscriptionRemoved(p, topic)
+		}
+
+		if role != protocol.Role_UNKNOWN_ROLE {
+			b.emitPeerDisconnected(rawPeer.Alias, role, p.GetIdentity())
+		}
+
+		if role == protocol.Role_COMMUNICATION_SERVER {
+			b.emitServerLinkLost(rawPeer.Alias)
+		}
+	}
+
 	if topicsChanged {
 		if err := b.broadcastSubscriptionChange(); err != nil {
 			b.log.Error().Err(err).Msg("cannot broadcast subscription change on peer disconnected")
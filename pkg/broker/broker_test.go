@@ -1,6 +1,7 @@
 package broker
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/decentraland/webrtc-broker/internal/logging"
@@ -203,6 +204,11 @@ func TestProcessTopicMessage(t *testing.T) {
 	serverReliableWriter.AssertExpectations(t)
 	c1ReliableWriter.AssertExpectations(t)
 	c2ReliableWriter.AssertExpectations(t)
+
+	traffic := b.traffic.load()
+	require.Equal(t, uint64(1), traffic.messagesReceived)
+	require.Equal(t, uint64(10), traffic.bytesReceived)
+	require.Equal(t, uint64(10), traffic.bytesSent)
 }
 
 func TestProcessSubscriptionChange(t *testing.T) {
@@ -280,3 +286,22 @@ func TestOnPeerDisconnected(t *testing.T) {
 	require.Len(t, b.peers, 0)
 	require.Len(t, b.subscriptions, 0)
 }
+
+func TestGenerateCoordinatorConnectURL(t *testing.T) {
+	b, err := NewBroker(&Config{
+		CoordinatorURL: "ws://coordinator:9090/discover",
+		Role:           protocol.Role_COMMUNICATION_SERVER,
+		Auth:           &authentication.NoopAuthenticator{},
+		FallbackURL:    "wss://broker/fallback",
+		Labels:         map[string]string{"region": "eu", "pool": "realm1"},
+	})
+	require.NoError(t, err)
+
+	connectURL, err := b.GenerateCoordinatorConnectURL()
+	require.NoError(t, err)
+
+	u, err := url.Parse(connectURL)
+	require.NoError(t, err)
+	require.Equal(t, "pool=realm1,region=eu", u.Query().Get("labels"))
+	require.Equal(t, "wss://broker/fallback", u.Query().Get("fallbackURL"))
+}
@@ -0,0 +1,109 @@
+package broker
+
+import (
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const eventsQueueSize = 1024
+
+// Hooks are optional callbacks invoked on broker lifecycle events. They are executed asynchronously,
+// in order, by a single goroutine, so a slow hook delays other hooks but never the broker itself.
+// If the queue is full, or the broker is shut down, the event is dropped.
+type Hooks struct {
+	OnPeerAuthenticated     func(alias uint64, role protocol.Role, identity []byte)
+	OnPeerDisconnected      func(alias uint64, role protocol.Role, identity []byte)
+	OnSubscriptionAdded     func(alias uint64, topic string)
+	OnSubscriptionRemoved   func(alias uint64, topic string)
+	OnServerLinkEstablished func(alias uint64)
+	OnServerLinkLost        func(alias uint64)
+	OnAuthRejected          func(alias uint64, role protocol.Role, reason error)
+}
+
+func (h *Hooks) isEmpty() bool {
+	return h.OnPeerAuthenticated == nil &&
+		h.OnPeerDisconnected == nil &&
+		h.OnSubscriptionAdded == nil &&
+		h.OnSubscriptionRemoved == nil &&
+		h.OnServerLinkEstablished == nil &&
+		h.OnServerLinkLost == nil &&
+		h.OnAuthRejected == nil
+}
+
+func (b *Broker) processEvents() {
+	for fn := range b.eventsCh {
+		fn()
+	}
+}
+
+// emit queues the event for the hooks goroutine, it's dropped if the queue is full or closed
+func (b *Broker) emit(fn func()) {
+	b.eventsMux.RLock()
+	defer b.eventsMux.RUnlock()
+
+	if b.eventsClosed {
+		return
+	}
+
+	select {
+	case b.eventsCh <- fn:
+	default:
+		b.log.Warn().Msg("events queue is full, dropping event")
+	}
+}
+
+// closeEvents stops the hooks goroutine, once the queued events are processed
+func (b *Broker) closeEvents() {
+	b.eventsMux.Lock()
+	defer b.eventsMux.Unlock()
+
+	if b.eventsClosed {
+		return
+	}
+
+	b.eventsClosed = true
+	close(b.eventsCh)
+}
+
+func (b *Broker) emitPeerAuthenticated(alias uint64, role protocol.Role, identity []byte) {
+	if hdlr := b.hooks.OnPeerAuthenticated; hdlr != nil {
+		b.emit(func() { hdlr(alias, role, identity) })
+	}
+}
+
+func (b *Broker) emitPeerDisconnected(alias uint64, role protocol.Role, identity []byte) {
+	if hdlr := b.hooks.OnPeerDisconnected; hdlr != nil {
+		b.emit(func() { hdlr(alias, role, identity) })
+	}
+}
+
+func (b *Broker) emitSubscriptionAdded(p *peer, topic string) {
+	if hdlr := b.hooks.OnSubscriptionAdded; hdlr != nil {
+		alias := p.Alias
+		b.emit(func() { hdlr(alias, topic) })
+	}
+}
+
+func (b *Broker) emitSubscriptionRemoved(p *peer, topic string) {
+	if hdlr := b.hooks.OnSubscriptionRemoved; hdlr != nil {
+		alias := p.Alias
+		b.emit(func() { hdlr(alias, topic) })
+	}
+}
+
+func (b *Broker) emitServerLinkEstablished(alias uint64) {
+	if hdlr := b.hooks.OnServerLinkEstablished; hdlr != nil {
+		b.emit(func() { hdlr(alias) })
+	}
+}
+
+func (b *Broker) emitServerLinkLost(alias uint64) {
+	if hdlr := b.hooks.OnServerLinkLost; hdlr != nil {
+		b.emit(func() { hdlr(alias) })
+	}
+}
+
+func (b *Broker) emitAuthRejected(alias uint64, role protocol.Role, reason error) {
+	if hdlr := b.hooks.OnAuthRejected; hdlr != nil {
+		b.emit(func() { hdlr(alias, role, reason) })
+	}
+}
@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	added := make(chan string, 10)
+	removed := make(chan string, 10)
+	disconnected := make(chan uint64, 10)
+	linkLost := make(chan uint64, 10)
+
+	b, err := NewBroker(&Config{
+		Role: protocol.Role_COMMUNICATION_SERVER,
+		Auth: &authentication.NoopAuthenticator{},
+		Hooks: Hooks{
+			OnSubscriptionAdded: func(alias uint64, topic string) {
+				added <- topic
+			},
+			OnSubscriptionRemoved: func(alias uint64, topic string) {
+				removed <- topic
+			},
+			OnPeerDisconnected: func(alias uint64, role protocol.Role, identity []byte) {
+				disconnected <- alias
+			},
+			OnServerLinkLost: func(alias uint64) {
+				linkLost <- alias
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	c1 := &peer{
+		Peer:   &server.Peer{Alias: 1},
+		role:   clientRole,
+		topics: make(map[string]struct{}),
+	}
+	b.peers[1] = c1
+
+	require.NoError(t, b.processSubscriptionChange(subscriptionChange{
+		peer:      c1,
+		format:    protocol.Format_PLAIN,
+		rawTopics: []byte("topic1 topic2"),
+	}))
+
+	require.ElementsMatch(t, []string{"topic1", "topic2"}, []string{<-added, <-added})
+
+	require.NoError(t, b.processSubscriptionChange(subscriptionChange{
+		peer:      c1,
+		format:    protocol.Format_PLAIN,
+		rawTopics: []byte("topic2"),
+	}))
+
+	require.Equal(t, "topic1", <-removed)
+
+	b.onPeerDisconnected(c1.Peer)
+
+	require.Equal(t, "topic2", <-removed)
+	require.Equal(t, uint64(1), <-disconnected)
+	require.Len(t, linkLost, 0)
+}
+
+func TestHooksShutdown(t *testing.T) {
+	linkLost := make(chan uint64, 10)
+
+	b, err := NewBroker(&Config{
+		Role: protocol.Role_COMMUNICATION_SERVER,
+		Auth: &authentication.NoopAuthenticator{},
+		Hooks: Hooks{
+			OnServerLinkLost: func(alias uint64) {
+				linkLost <- alias
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	b.emitServerLinkLost(1)
+	b.Shutdown()
+	b.emitServerLinkLost(2)
+
+	require.Equal(t, uint64(1), <-linkLost)
+
+	_, open := <-b.eventsCh
+	require.False(t, open)
+	require.Len(t, linkLost, 0)
+}
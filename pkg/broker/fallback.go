@@ -0,0 +1,77 @@
+package broker
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/decentraland/webrtc-broker/internal/ws"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+// ErrFallbackRequiresTickets indicates that the websocket fallback cannot be served without connection tickets
+var ErrFallbackRequiresTickets = errors.New("the websocket fallback requires a ticket verifier")
+
+// RegisterFallback registers the websocket fallback endpoint, clients that cannot establish a webrtc
+// connection connect to it with their coordinator alias and the ticket from the welcome message fallback
+// endpoint as the ticket query param (base64 url encoded), and then authenticate as usual. The endpoint is only
+// registered if the broker verifies connection tickets, otherwise anyone could claim the alias the coordinator
+// gave another client before it connects, and ErrFallbackRequiresTickets is returned. Browsers can only connect
+// from the FallbackAllowedOrigins
+func (b *Broker) RegisterFallback(mux *http.ServeMux) error {
+	if b.ticketVerifier == nil {
+		return ErrFallbackRequiresTickets
+	}
+
+	upgrader := ws.MakeUpgraderWithOptions(ws.UpgraderOptions{AllowedOrigins: b.fallbackAllowedOrigins})
+
+	mux.HandleFunc("/fallback", func(w http.ResponseWriter, r *http.Request) {
+		qs := r.URL.Query()
+
+		alias, err := strconv.ParseUint(qs.Get("alias"), 10, 64)
+		if err != nil || alias == 0 {
+			http.Error(w, "invalid alias", http.StatusBadRequest)
+			return
+		}
+
+		// NOTE: the ticket is checked before accepting the peer, only a peer with a valid ticket can take the alias
+		// or replace a pending connection of the same alias, it's fully verified again on auth
+		ticket, err := base64.RawURLEncoding.DecodeString(qs.Get("ticket"))
+		if err != nil {
+			http.Error(w, "invalid ticket", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := b.ticketVerifier.VerifyPeer(ticket, alias, b.GetAlias(), protocol.Role_CLIENT, nil); err != nil {
+			b.log.Info().Err(err).Uint64("peer", alias).Msg("reject fallback peer, invalid connection ticket")
+			http.Error(w, "invalid ticket", http.StatusUnauthorized)
+
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r)
+		if err != nil {
+			b.log.Error().Err(err).Msg("socket connect error (fallback)")
+			return
+		}
+
+		b.AcceptFallbackPeerWithTicket(alias, ticket, conn)
+	})
+
+	return nil
+}
+
+func addFallbackURL(connectURL string, fallbackURL string) (string, error) {
+	u, err := url.Parse(connectURL)
+	if err != nil {
+		return "", err
+	}
+
+	qs := u.Query()
+	qs.Set("fallbackURL", fallbackURL)
+	u.RawQuery = qs.Encode()
+
+	return u.String(), nil
+}
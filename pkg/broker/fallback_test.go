@@ -0,0 +1,50 @@
+package broker
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestRegisterFallback(t *testing.T) {
+	t.Run("without tickets", func(t *testing.T) {
+		b, err := NewBroker(&Config{
+			Role: protocol.Role_COMMUNICATION_SERVER,
+			Auth: &authentication.NoopAuthenticator{},
+		})
+		require.NoError(t, err)
+
+		mux := http.NewServeMux()
+		require.Equal(t, ErrFallbackRequiresTickets, b.RegisterFallback(mux))
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fallback?alias=10", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("with tickets", func(t *testing.T) {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		b, err := NewBroker(&Config{
+			Role:           protocol.Role_COMMUNICATION_SERVER,
+			Auth:           &authentication.NoopAuthenticator{},
+			TicketVerifier: authentication.NewTicketVerifier(publicKey),
+		})
+		require.NoError(t, err)
+
+		mux := http.NewServeMux()
+		require.NoError(t, b.RegisterFallback(mux))
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fallback?alias=10", nil))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
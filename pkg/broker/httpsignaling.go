@@ -0,0 +1,146 @@
+package broker
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+)
+
+const (
+	sdpContentType        = "application/sdp"
+	trickleICEContentType = "application/trickle-ice-sdpfrag"
+	maxHTTPSDPSize        = 64 * 1024
+)
+
+// RegisterHTTPSignaling registers the HTTP signaling endpoint, for peers that negotiate their connection with a
+// single request (WHIP style) instead of the coordinator websocket:
+//
+//   - POST /whip with an application/sdp offer creates the peer, the 201 response has the answer, including the
+//     broker candidates, and the session url in the Location header. The offer has to include a data channel, the
+//     broker opens the reliable and unreliable ones as usual and the peer authenticates on the reliable one
+//   - PATCH <session url> with an application/trickle-ice-sdpfrag adds the peer candidates, if they were not
+//     gathered before sending the offer
+//   - DELETE <session url> closes the peer
+//
+// If the broker verifies connection tickets the alias and the ticket (base64 url encoded) query params from the
+// coordinator are required, as in RegisterFallback, otherwise the broker allocates the peer alias
+func (b *Broker) RegisterHTTPSignaling(mux *http.ServeMux) {
+	mux.HandleFunc("/whip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), sdpContentType) {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		qs := r.URL.Query()
+
+		var (
+			alias  uint64
+			ticket []byte
+		)
+
+		// NOTE: without tickets the alias cannot be verified, so the broker allocates one, otherwise a client could
+		// take the alias of another peer. With tickets, the ticket is checked before creating the peer and fully
+		// verified on auth, as with the fallback
+		if b.ticketVerifier != nil {
+			var err error
+			if alias, err = strconv.ParseUint(qs.Get("alias"), 10, 64); err != nil || alias == 0 {
+				http.Error(w, "invalid alias", http.StatusBadRequest)
+				return
+			}
+
+			if ticket, err = base64.RawURLEncoding.DecodeString(qs.Get("ticket")); err != nil {
+				http.Error(w, "invalid ticket", http.StatusBadRequest)
+				return
+			}
+
+			if _, err := b.ticketVerifier.VerifyPeer(ticket, alias, b.GetAlias(), protocol.Role_CLIENT, nil); err != nil {
+				b.log.Info().Err(err).Uint64("peer", alias).Msg("reject HTTP peer, invalid connection ticket")
+				http.Error(w, "invalid ticket", http.StatusUnauthorized)
+
+				return
+			}
+		}
+
+		offer, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPSDPSize))
+		if err != nil {
+			http.Error(w, "cannot read offer", http.StatusBadRequest)
+			return
+		}
+
+		session, err := b.AcceptHTTPOffer(alias, ticket, string(offer))
+		if err != nil {
+			writeHTTPSignalingError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", sdpContentType)
+		w.Header().Set("Location", r.URL.Path+"/"+session.ID)
+		w.WriteHeader(http.StatusCreated)
+
+		if _, err := w.Write([]byte(session.Answer)); err != nil {
+			b.log.Debug().Err(err).Uint64("peer", session.Alias).Msg("cannot write HTTP answer")
+		}
+	})
+
+	mux.HandleFunc("/whip/", func(w http.ResponseWriter, r *http.Request) {
+		session := strings.TrimPrefix(r.URL.Path, "/whip/")
+
+		switch r.Method {
+		case http.MethodPatch:
+			if !strings.HasPrefix(r.Header.Get("Content-Type"), trickleICEContentType) {
+				http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+				return
+			}
+
+			fragment, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPSDPSize))
+			if err != nil {
+				http.Error(w, "cannot read candidates", http.StatusBadRequest)
+				return
+			}
+
+			if err := b.AddHTTPCandidates(session, string(fragment)); err != nil {
+				writeHTTPSignalingError(w, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			if err := b.CloseHTTPSession(session); err != nil {
+				writeHTTPSignalingError(w, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("Allow", http.MethodPatch+", "+http.MethodDelete)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeHTTPSignalingError(w http.ResponseWriter, err error) {
+	switch err {
+	case server.ErrInvalidSDP:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case server.ErrUnknownHTTPSession:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case server.ErrPeerAlreadyConnected:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case server.ErrServerFull, server.ErrServerClosed:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "cannot create peer", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,86 @@
+package broker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pion "github.com/pion/webrtc/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+)
+
+func TestHTTPSignaling(t *testing.T) {
+	b, err := NewBroker(&Config{
+		Role: protocol.Role_COMMUNICATION_SERVER,
+		Auth: &authentication.NoopAuthenticator{},
+	})
+	require.NoError(t, err)
+
+	b.Alias = 1
+
+	go b.ProcessControlMessages()
+
+	defer server.Shutdown(b.Server)
+
+	mux := http.NewServeMux()
+	b.RegisterHTTPSignaling(mux)
+
+	do := func(method, url, contentType, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		return w
+	}
+
+	t.Run("offer", func(t *testing.T) {
+		client, err := pion.NewPeerConnection(pion.Configuration{})
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		_, err = client.CreateDataChannel("data", nil)
+		require.NoError(t, err)
+
+		offer, err := client.CreateOffer(nil)
+		require.NoError(t, err)
+		require.NoError(t, client.SetLocalDescription(offer))
+
+		w := do(http.MethodPost, "/whip?alias=10", sdpContentType, offer.SDP)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, sdpContentType, w.Header().Get("Content-Type"))
+		require.True(t, strings.HasPrefix(w.Header().Get("Location"), "/whip/"))
+		require.Contains(t, w.Body.String(), "a=candidate:")
+
+		location := w.Header().Get("Location")
+
+		// NOTE: without tickets the alias is allocated by the broker
+		b.peersMux.Lock()
+		require.NotContains(t, b.peers, uint64(10))
+		b.peersMux.Unlock()
+
+		w = do(http.MethodPatch, location, trickleICEContentType, "a=candidate:1 1 udp 1 192.0.2.1 5000 typ host\r\n")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = do(http.MethodDelete, location, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		require.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, "/whip", "", "").Code)
+		require.Equal(t, http.StatusUnsupportedMediaType, do(http.MethodPost, "/whip", "text/plain", "").Code)
+		require.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/whip", sdpContentType, "invalid").Code)
+		require.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, "/whip/unknown", "", "").Code)
+		require.Equal(t, http.StatusNotFound, do(http.MethodPatch, "/whip/unknown", trickleICEContentType, "").Code)
+		require.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/whip/unknown", "", "").Code)
+	})
+}
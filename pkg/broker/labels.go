@@ -0,0 +1,29 @@
+package broker
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// addLabels adds the labels to the coordinator connect url as a comma separated list of key=value pairs,
+// see coordinator.ParseLabels
+func addLabels(connectURL string, labels map[string]string) (string, error) {
+	u, err := url.Parse(connectURL)
+	if err != nil {
+		return "", err
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	qs := u.Query()
+	qs.Set("labels", strings.Join(pairs, ","))
+	u.RawQuery = qs.Encode()
+
+	return u.String(), nil
+}
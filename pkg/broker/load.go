@@ -0,0 +1,71 @@
+package broker
+
+import (
+	"sync/atomic"
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+)
+
+const defaultLoadReportPeriod = 10 * time.Second
+
+// trafficCounters are updated atomically, they are allocated on their own so they are 64-bit aligned
+type trafficCounters struct {
+	messagesReceived uint64
+	bytesReceived    uint64
+	bytesSent        uint64
+}
+
+func (c *trafficCounters) count(received int, sent int) {
+	atomic.AddUint64(&c.messagesReceived, 1)
+	atomic.AddUint64(&c.bytesReceived, uint64(received))
+	atomic.AddUint64(&c.bytesSent, uint64(sent))
+}
+
+func (c *trafficCounters) load() trafficCounters {
+	return trafficCounters{
+		messagesReceived: atomic.LoadUint64(&c.messagesReceived),
+		bytesReceived:    atomic.LoadUint64(&c.bytesReceived),
+		bytesSent:        atomic.LoadUint64(&c.bytesSent),
+	}
+}
+
+// reportLoad sends a load report to the coordinator right away and then every loadReportPeriod, until the
+// coordinator connection is closed
+func (b *Broker) reportLoad() {
+	ticker := time.NewTicker(b.loadReportPeriod)
+	defer ticker.Stop()
+
+	last := b.traffic.load()
+	lastTime := time.Now()
+
+	for {
+		current := b.traffic.load()
+		now := time.Now()
+		elapsed := now.Sub(lastTime).Seconds()
+
+		report := &protocol.LoadReportMessage{}
+
+		if elapsed > 0 {
+			report.MessagesPerSecond = float64(current.messagesReceived-last.messagesReceived) / elapsed
+			report.BytesReceivedPerSecond = uint64(float64(current.bytesReceived-last.bytesReceived) / elapsed)
+			report.BytesSentPerSecond = uint64(float64(current.bytesSent-last.bytesSent) / elapsed)
+		}
+
+		// NOTE: reports fail while the broker reconnects to the coordinator, they stop once it's shut down
+		if err := b.ReportLoad(report); err != nil {
+			if b.GetCoordinatorState() == server.CoordinatorClosed {
+				b.log.Info().Err(err).Msg("stop reporting load")
+				return
+			}
+
+			b.log.Debug().Err(err).Msg("cannot report load")
+		}
+
+		last = current
+		lastTime = now
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,303 @@
+package broker
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	defaultPingPeriod        = 5 * time.Second
+	defaultLowQualityPeriods = 3
+
+	// qualityWindow is how many RTT samples and unreliable ping outcomes are kept per peer, the peer quality is
+	// only checked once there are minQualitySamples of them
+	qualityWindow     = 20
+	minQualitySamples = 5
+)
+
+// ConnectionQuality is measured with the broker pings, over the last qualityWindow pings echoed by the peer on
+// both channels. UnreliableLoss is the ratio of unreliable pings not echoed before the next ping
+type ConnectionQuality struct {
+	RTTMin                time.Duration
+	RTTAvg                time.Duration
+	RTTP95                time.Duration
+	RTTSamples            int
+	UnreliableLoss        float64
+	UnreliableLossSamples int
+}
+
+type pendingPing struct {
+	sent     time.Time
+	reliable bool
+}
+
+// connectionQuality tracks the pings sent to a peer, the zero value is ready to use
+type connectionQuality struct {
+	mux sync.Mutex
+
+	seq         uint32
+	fromAliases map[uint64]struct{}
+	pending     map[uint32]pendingPing
+
+	rtts     []time.Duration
+	rttsNext int
+	lost     []bool
+	lostNext int
+
+	lowQualityPeriods int
+}
+
+// ping registers a new ping, returning its sequence number
+func (q *connectionQuality) ping(fromAlias uint64, reliable bool, now time.Time) uint32 {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if q.pending == nil {
+		q.pending = make(map[uint32]pendingPing)
+		q.fromAliases = make(map[uint64]struct{})
+	}
+
+	// NOTE: the server alias may change when it reconnects to the coordinator, the previous one is kept so the
+	// pings still in flight are not echoed
+	q.fromAliases[fromAlias] = struct{}{}
+
+	q.seq++
+	q.pending[q.seq] = pendingPing{sent: now, reliable: reliable}
+
+	return q.seq
+}
+
+// isOwn returns true if the ping was sent by this server to the peer
+func (q *connectionQuality) isOwn(fromAlias uint64) bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	_, ok := q.fromAliases[fromAlias]
+
+	return ok
+}
+
+// pong records the RTT of a ping echoed by the peer, the late and duplicated ones are ignored
+func (q *connectionQuality) pong(seq uint32, now time.Time) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	ping, ok := q.pending[seq]
+	if !ok {
+		return
+	}
+
+	delete(q.pending, seq)
+
+	rtt := now.Sub(ping.sent)
+
+	if len(q.rtts) < qualityWindow {
+		q.rtts = append(q.rtts, rtt)
+	} else {
+		q.rtts[q.rttsNext] = rtt
+		q.rttsNext = (q.rttsNext + 1) % qualityWindow
+	}
+
+	if !ping.reliable {
+		q.addOutcome(false)
+	}
+}
+
+// expire drops the pings not echoed yet, the unreliable ones are counted as lost
+func (q *connectionQuality) expire() {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	for seq, ping := range q.pending {
+		delete(q.pending, seq)
+
+		if !ping.reliable {
+			q.addOutcome(true)
+		}
+	}
+}
+
+func (q *connectionQuality) addOutcome(lost bool) {
+	if len(q.lost) < qualityWindow {
+		q.lost = append(q.lost, lost)
+		return
+	}
+
+	q.lost[q.lostNext] = lost
+	q.lostNext = (q.lostNext + 1) % qualityWindow
+}
+
+func (q *connectionQuality) stats() ConnectionQuality {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	stats := ConnectionQuality{
+		RTTSamples:            len(q.rtts),
+		UnreliableLossSamples: len(q.lost),
+	}
+
+	if len(q.rtts) > 0 {
+		rtts := make([]time.Duration, len(q.rtts))
+		copy(rtts, q.rtts)
+		sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+
+		var sum time.Duration
+		for _, rtt := range rtts {
+			sum += rtt
+		}
+
+		stats.RTTMin = rtts[0]
+		stats.RTTAvg = sum / time.Duration(len(rtts))
+		stats.RTTP95 = rtts[int(math.Ceil(0.95*float64(len(rtts))))-1]
+	}
+
+	if len(q.lost) > 0 {
+		lost := 0
+
+		for _, l := range q.lost {
+			if l {
+				lost++
+			}
+		}
+
+		stats.UnreliableLoss = float64(lost) / float64(len(q.lost))
+	}
+
+	return stats
+}
+
+// countLowQuality returns the number of consecutive ping periods the peer quality has been low
+func (q *connectionQuality) countLowQuality(low bool) int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if low {
+		q.lowQualityPeriods++
+	} else {
+		q.lowQualityPeriods = 0
+	}
+
+	return q.lowQualityPeriods
+}
+
+// readPing records the RTT of the server pings echoed by the peer, any other ping is echoed back on the same
+// channel
+func (p *peer) readPing(reliable bool, rawMsg []byte) {
+	ping := protocol.PingMessage{}
+	if err := proto.Unmarshal(rawMsg, &ping); err != nil {
+		p.Log.Debug().Err(err).Msg("decode ping message failure")
+		return
+	}
+
+	if ping.FromAlias != 0 && p.quality.isOwn(ping.FromAlias) {
+		p.quality.pong(ping.Seq, time.Now())
+		return
+	}
+
+	if reliable {
+		p.WriteReliable(rawMsg)
+	} else {
+		p.WriteUnreliable(rawMsg)
+	}
+}
+
+// pingPeers pings every peer each pingPeriod, until the coordinator connection is closed
+func (b *Broker) pingPeers() {
+	ticker := time.NewTicker(b.pingPeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if b.GetCoordinatorState() == server.CoordinatorClosed {
+			b.log.Info().Msg("stop pinging peers")
+			return
+		}
+
+		b.pingAll()
+	}
+}
+
+// pingAll closes the authenticated peers whose quality has been low for too long, and pings the rest on both
+// channels. The pings not echoed since the previous call are expired
+func (b *Broker) pingAll() {
+	b.peersMux.Lock()
+	peers := make([]*peer, 0, len(b.peers))
+
+	for _, p := range b.peers {
+		peers = append(peers, p)
+	}
+	b.peersMux.Unlock()
+
+	alias := b.GetAlias()
+	now := time.Now()
+
+	for _, p := range peers {
+		if p.IsClosed() || p.getRole() == protocol.Role_UNKNOWN_ROLE || !p.dataChannelsOpen() {
+			continue
+		}
+
+		p.quality.expire()
+
+		if b.checkQuality(p) {
+			continue
+		}
+
+		for _, reliable := range []bool{true, false} {
+			ping := &protocol.PingMessage{
+				Type:      protocol.MessageType_PING,
+				Time:      float64(now.UnixNano()) / float64(time.Millisecond),
+				FromAlias: alias,
+				Seq:       p.quality.ping(alias, reliable, now),
+			}
+
+			rawMsg, err := proto.Marshal(ping)
+			if err != nil {
+				p.Log.Error().Err(err).Msg("encode ping message failure")
+				break
+			}
+
+			if reliable {
+				p.WriteReliable(rawMsg)
+			} else {
+				p.WriteUnreliable(rawMsg)
+			}
+		}
+	}
+}
+
+// checkQuality closes the peer and returns true if its average RTT or its unreliable loss have been above the
+// max for lowQualityPeriods ping periods. Peers are only checked once they echoed minQualitySamples pings
+func (b *Broker) checkQuality(p *peer) bool {
+	if b.maxRTT == 0 && b.maxUnreliableLoss == 0 {
+		return false
+	}
+
+	stats := p.quality.stats()
+
+	low := false
+
+	if stats.RTTSamples >= minQualitySamples {
+		low = (b.maxRTT > 0 && stats.RTTAvg > b.maxRTT) ||
+			(b.maxUnreliableLoss > 0 && stats.UnreliableLossSamples >= minQualitySamples &&
+				stats.UnreliableLoss > b.maxUnreliableLoss)
+	}
+
+	if p.quality.countLowQuality(low) < b.lowQualityPeriods {
+		return false
+	}
+
+	p.Log.Info().
+		Dur("rttAvg", stats.RTTAvg).
+		Float64("unreliableLoss", stats.UnreliableLoss).
+		Msg("closing connection: low connection quality")
+	atomic.AddUint32(&b.lowQualityDisconnects, 1)
+	p.Close()
+
+	return true
+}
@@ -0,0 +1,155 @@
+package broker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	_testing "github.com/decentraland/webrtc-broker/internal/testing"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+)
+
+func TestConnectionQuality(t *testing.T) {
+	q := connectionQuality{}
+	now := time.Now()
+
+	for i := 1; i <= 20; i++ {
+		seq := q.ping(1, true, now)
+		q.pong(seq, now.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	// NOTE: unknown and duplicated pongs are ignored
+	q.pong(1, now.Add(time.Second))
+	q.pong(100, now.Add(time.Second))
+
+	stats := q.stats()
+	require.Equal(t, 20, stats.RTTSamples)
+	require.Equal(t, time.Millisecond, stats.RTTMin)
+	require.Equal(t, 10500*time.Microsecond, stats.RTTAvg)
+	require.Equal(t, 19*time.Millisecond, stats.RTTP95)
+	require.Equal(t, 0, stats.UnreliableLossSamples)
+
+	q.pong(q.ping(1, false, now), now.Add(time.Millisecond))
+	q.ping(1, false, now)
+	q.ping(1, false, now)
+	q.ping(1, true, now)
+	q.expire()
+
+	stats = q.stats()
+	require.Equal(t, 20, stats.RTTSamples)
+	require.Equal(t, 3, stats.UnreliableLossSamples)
+	require.InDelta(t, 2.0/3.0, stats.UnreliableLoss, 0.001)
+}
+
+func TestReadPing(t *testing.T) {
+	log := logging.New()
+	writer := &mockWriterController{}
+	p := &peer{
+		Peer:           &server.Peer{Alias: 2, Log: log},
+		reliableWriter: writer,
+	}
+
+	clientPing, err := proto.Marshal(&protocol.PingMessage{Type: protocol.MessageType_PING, Time: 1})
+	require.NoError(t, err)
+
+	writer.On("Write", clientPing).Return().Once()
+	p.readPing(true, clientPing)
+
+	ownPing, err := proto.Marshal(&protocol.PingMessage{
+		Type:      protocol.MessageType_PING,
+		Seq:       p.quality.ping(1, true, time.Now()),
+		FromAlias: 1,
+	})
+	require.NoError(t, err)
+
+	p.readPing(true, ownPing)
+
+	writer.AssertExpectations(t)
+	require.Equal(t, 1, p.quality.stats().RTTSamples)
+}
+
+func TestPingAll(t *testing.T) {
+	b, err := NewBroker(&Config{
+		Role:              protocol.Role_COMMUNICATION_SERVER,
+		Auth:              &authentication.NoopAuthenticator{},
+		MaxRTT:            100 * time.Millisecond,
+		LowQualityPeriods: 2,
+	})
+	require.NoError(t, err)
+
+	b.Alias = 1
+
+	log := logging.New()
+	conn := &_testing.MockWebsocket{}
+	closed := make(chan struct{})
+	conn.On("Close").Run(func(mock.Arguments) { close(closed) }).Return(nil).Once()
+
+	reliableWriter := &mockWriterController{}
+	reliableWriter.On("Write", mock.Anything).Return()
+
+	unreliableWriter := &mockWriterController{}
+	unreliableWriter.On("Write", mock.Anything).Return()
+
+	p := &peer{
+		Peer:             &server.Peer{Alias: 2, Fallback: server.NewFallbackConn(conn, log), Log: log},
+		role:             clientRole,
+		topics:           make(map[string]struct{}),
+		reliableWriter:   reliableWriter,
+		unreliableWriter: unreliableWriter,
+	}
+	p.reliableRWC = p.Fallback.Reliable
+	p.unreliableRWC = p.Fallback.Unreliable
+	b.peers[2] = p
+
+	b.pingAll()
+
+	reliableWriter.AssertNumberOfCalls(t, "Write", 1)
+	unreliableWriter.AssertNumberOfCalls(t, "Write", 1)
+
+	ping := protocol.PingMessage{}
+	require.NoError(t, proto.Unmarshal(reliableWriter.Calls[0].Arguments.Get(0).([]byte), &ping))
+	require.Equal(t, uint64(1), ping.FromAlias)
+	require.True(t, p.quality.isOwn(ping.FromAlias))
+
+	for i := 0; i < minQualitySamples; i++ {
+		seq := p.quality.ping(1, true, time.Now().Add(-time.Second))
+		p.quality.pong(seq, time.Now())
+	}
+
+	b.pingAll()
+	require.False(t, p.IsClosed())
+
+	b.pingAll()
+	<-closed
+
+	stats := b.GetBrokerStats()
+	require.Equal(t, uint32(1), stats.LowQualityDisconnects)
+}
+
+func TestStatsSummaryQuality(t *testing.T) {
+	g := NewStatsSummaryGenerator()
+	summary := g.Generate(Stats{Peers: map[uint64]PeerStats{
+		1: {Role: protocol.Role_CLIENT, ConnectionQuality: ConnectionQuality{
+			RTTAvg: 10 * time.Millisecond, RTTP95: 40 * time.Millisecond, RTTSamples: 5,
+			UnreliableLoss: 0.5, UnreliableLossSamples: 5,
+		}},
+		2: {Role: protocol.Role_COMMUNICATION_SERVER, ConnectionQuality: ConnectionQuality{
+			RTTAvg: 30 * time.Millisecond, RTTP95: 35 * time.Millisecond, RTTSamples: 5,
+			UnreliableLossSamples: 5,
+		}},
+		3: {Role: protocol.Role_CLIENT},
+	}})
+
+	require.Equal(t, 20*time.Millisecond, summary.RTTAvg)
+	require.Equal(t, 40*time.Millisecond, summary.RTTP95)
+	require.Equal(t, 0.25, summary.UnreliableLoss)
+	require.Equal(t, 30*time.Millisecond, summary.ServerRTTAvg)
+	require.Equal(t, 35*time.Millisecond, summary.ServerRTTP95)
+	require.Equal(t, 0.0, summary.ServerUnreliableLoss)
+}
@@ -0,0 +1,215 @@
+package broker
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/golang/protobuf/proto"
+)
+
+const defaultReauthWindow = 30 * time.Second
+
+var (
+	// ErrRevoked indicates that the peer identity was revoked
+	ErrRevoked = errors.New("identity revoked")
+	// ErrReauthTimeout indicates that the peer didn't authenticate again before its credentials expired
+	ErrReauthTimeout = errors.New("re-authentication timeout")
+
+	errIdentityChanged = errors.New("identity changed on re-authentication")
+	errRoleChanged     = errors.New("role changed on re-authentication")
+)
+
+// verifyAuth authenticates the auth message body, returning the credentials expiry if the authenticator
+// provides it
+func (b *Broker) verifyAuth(role protocol.Role, body []byte) (bool, []byte, time.Time, error) {
+	if auth, ok := b.auth.(authentication.ExpiringAuthenticator); ok {
+		return auth.AuthenticateFromMessageWithExpiry(role, body)
+	}
+
+	isValid, identity, err := b.auth.AuthenticateFromMessage(role, body)
+
+	return isValid, identity, time.Time{}, err
+}
+
+// scheduleReauth asks the peer to authenticate again reauthWindow before expiresAt, the peer is disconnected
+// if it doesn't before expiresAt. The zero time cancels any scheduled request
+func (b *Broker) scheduleReauth(p *peer, expiresAt time.Time) {
+	p.authMux.Lock()
+	defer p.authMux.Unlock()
+
+	// NOTE: a timer may fire while it's being replaced, so the callbacks are ignored if the generation changed
+	p.authGeneration++
+	generation := p.authGeneration
+
+	if p.authTimer != nil {
+		p.authTimer.Stop()
+		p.authTimer = nil
+	}
+
+	if expiresAt.IsZero() {
+		return
+	}
+
+	p.authTimer = time.AfterFunc(time.Until(expiresAt.Add(-b.reauthWindow)), func() {
+		b.requestReauth(p, generation, expiresAt)
+	})
+}
+
+func (b *Broker) requestReauth(p *peer, generation uint64, deadline time.Time) {
+	p.authMux.Lock()
+	defer p.authMux.Unlock()
+
+	if generation != p.authGeneration {
+		return
+	}
+
+	p.Log.Debug().Time("deadline", deadline).Msg("requesting re-authentication")
+
+	rawMsg, err := proto.Marshal(&protocol.AuthRequestMessage{
+		Type:     protocol.MessageType_AUTH_REQUEST,
+		Deadline: deadline.Unix(),
+	})
+	if err != nil {
+		p.Log.Error().Err(err).Msg("encode auth request message failure")
+		return
+	}
+
+	p.WriteReliable(rawMsg)
+
+	p.authTimer = time.AfterFunc(time.Until(deadline), func() {
+		p.authMux.Lock()
+		expired := generation == p.authGeneration
+		p.authMux.Unlock()
+
+		if !expired {
+			return
+		}
+
+		p.Log.Info().Msg("closing connection: credentials expired")
+		b.emitAuthRejected(p.Alias, p.getRole(), ErrReauthTimeout)
+		p.Close()
+	})
+}
+
+// processAuthMessage handles the auth messages received after the initial authentication: auth requests from
+// the servers we authenticated against, and new credentials from the peers we authenticated
+func (b *Broker) processAuthMessage(p *peer, msgType protocol.MessageType, rawMsg []byte) {
+	if msgType == protocol.MessageType_AUTH_REQUEST {
+		// NOTE: the server credentials are only sent to the servers this broker authenticated against, any other
+		// peer could replay them to join a broker as a server
+		if !p.initiated {
+			p.Log.Info().Msg("ignoring auth request from a peer this broker didn't authenticate against")
+			return
+		}
+
+		authMessage, err := b.auth.GenerateServerAuthMessage()
+		if err != nil {
+			p.Log.Error().Err(err).Msg("cannot create auth message")
+			return
+		}
+
+		rawMsg, err := proto.Marshal(authMessage)
+		if err != nil {
+			p.Log.Error().Err(err).Msg("cannot encode auth message")
+			return
+		}
+
+		p.WriteReliable(rawMsg)
+
+		return
+	}
+
+	authMessage := protocol.AuthMessage{}
+	if err := proto.Unmarshal(rawMsg, &authMessage); err != nil {
+		p.Log.Debug().Err(err).Msg("decode auth message failure")
+		return
+	}
+
+	role := p.getRole()
+
+	reject := func(err error) {
+		p.Log.Info().Err(err).Msg("closing connection: re-authentication failed")
+		b.emitAuthRejected(p.Alias, role, err)
+		p.Close()
+	}
+
+	if authMessage.Role != role {
+		reject(errRoleChanged)
+		return
+	}
+
+	isValid, identity, expiresAt, err := b.verifyAuth(role, authMessage.Body)
+	if err != nil {
+		reject(err)
+		return
+	}
+
+	if !isValid {
+		reject(ErrUnauthorized)
+		return
+	}
+
+	currentIdentity := p.GetIdentity()
+	if len(identity) > 0 && len(currentIdentity) > 0 && !bytes.Equal(identity, currentIdentity) {
+		reject(errIdentityChanged)
+		return
+	}
+
+	if b.isRevoked(currentIdentity) {
+		reject(ErrRevoked)
+		return
+	}
+
+	p.Log.Debug().Msg("peer re-authenticated")
+	b.scheduleReauth(p, expiresAt)
+}
+
+// Revoke disconnects the peers authenticated with the identity, and rejects it until the given time
+func (b *Broker) Revoke(identity []byte, until time.Time) {
+	if len(identity) == 0 {
+		return
+	}
+
+	b.revokedMux.Lock()
+
+	now := time.Now()
+	for id, t := range b.revoked {
+		if now.After(t) {
+			delete(b.revoked, id)
+		}
+	}
+
+	b.revoked[string(identity)] = until
+	b.revokedMux.Unlock()
+
+	var revoked []*peer
+
+	b.peersMux.Lock()
+	for _, p := range b.peers {
+		if bytes.Equal(p.GetIdentity(), identity) {
+			revoked = append(revoked, p)
+		}
+	}
+	b.peersMux.Unlock()
+
+	for _, p := range revoked {
+		p.Log.Info().Msg("closing connection: identity revoked")
+		b.emitAuthRejected(p.Alias, p.getRole(), ErrRevoked)
+		p.Close()
+	}
+}
+
+func (b *Broker) isRevoked(identity []byte) bool {
+	if len(identity) == 0 {
+		return false
+	}
+
+	b.revokedMux.Lock()
+	until, ok := b.revoked[string(identity)]
+	b.revokedMux.Unlock()
+
+	return ok && time.Now().Before(until)
+}
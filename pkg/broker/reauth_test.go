@@ -0,0 +1,152 @@
+package broker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	_testing "github.com/decentraland/webrtc-broker/internal/testing"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+)
+
+func TestReauthentication(t *testing.T) {
+	secret := []byte("secret")
+
+	signToken := func(t *testing.T, sub string, expiresAt time.Time) []byte {
+		token, err := authentication.SignJWT(authentication.AlgHS256, "", secret, authentication.JWTClaims{
+			"sub":  sub,
+			"role": "CLIENT",
+			"exp":  expiresAt.Unix(),
+		})
+		require.NoError(t, err)
+
+		return []byte(token)
+	}
+
+	authMessage := func(t *testing.T, token []byte) []byte {
+		rawMsg, err := proto.Marshal(&protocol.AuthMessage{
+			Type: protocol.MessageType_AUTH,
+			Role: protocol.Role_CLIENT,
+			Body: token,
+		})
+		require.NoError(t, err)
+
+		return rawMsg
+	}
+
+	setup := func(t *testing.T) (*Broker, *peer, *_testing.MockWebsocket, chan error) {
+		auth, err := authentication.NewJWTAuthenticator(authentication.JWTConfig{
+			HMACSecret:    secret,
+			TokenProvider: func() (string, error) { return "server-token", nil },
+		})
+		require.NoError(t, err)
+
+		rejected := make(chan error, 10)
+
+		b, err := NewBroker(&Config{
+			Role:         protocol.Role_COMMUNICATION_SERVER,
+			Auth:         auth,
+			ReauthWindow: 50 * time.Millisecond,
+			Hooks: Hooks{
+				OnAuthRejected: func(alias uint64, role protocol.Role, reason error) {
+					rejected <- reason
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		log := logging.New()
+		conn := &_testing.MockWebsocket{}
+
+		p := &peer{
+			Peer:   &server.Peer{Alias: 1, Fallback: server.NewFallbackConn(conn, log), Log: log},
+			role:   clientRole,
+			topics: make(map[string]struct{}),
+		}
+		p.identity.Store([]byte("user1"))
+		b.peers[1] = p
+
+		return b, p, conn, rejected
+	}
+
+	t.Run("request and timeout", func(t *testing.T) {
+		b, p, conn, rejected := setup(t)
+		conn.On("Close").Return(nil).Once()
+
+		requests := make(chan []byte, 1)
+		writer := &mockWriterController{}
+		writer.On("Write", mock.Anything).Run(func(args mock.Arguments) {
+			requests <- args.Get(0).([]byte)
+		}).Return().Once()
+		p.reliableWriter = writer
+
+		b.scheduleReauth(p, time.Now().Add(100*time.Millisecond))
+
+		request := protocol.AuthRequestMessage{}
+		require.NoError(t, proto.Unmarshal(<-requests, &request))
+		require.Equal(t, protocol.MessageType_AUTH_REQUEST, request.Type)
+
+		require.Equal(t, ErrReauthTimeout, <-rejected)
+		require.Eventually(t, p.IsClosed, time.Second, 10*time.Millisecond)
+		conn.AssertExpectations(t)
+	})
+
+	t.Run("re-authentication", func(t *testing.T) {
+		b, p, conn, rejected := setup(t)
+
+		b.scheduleReauth(p, time.Now().Add(time.Hour))
+		token := signToken(t, "user1", time.Now().Add(time.Hour))
+		b.processAuthMessage(p, protocol.MessageType_AUTH, authMessage(t, token))
+		require.False(t, p.IsClosed())
+		require.Len(t, rejected, 0)
+
+		conn.On("Close").Return(nil).Once()
+		token = signToken(t, "user2", time.Now().Add(time.Hour))
+		b.processAuthMessage(p, protocol.MessageType_AUTH, authMessage(t, token))
+		require.Equal(t, errIdentityChanged, <-rejected)
+		require.Eventually(t, p.IsClosed, time.Second, 10*time.Millisecond)
+
+		b.scheduleReauth(p, time.Time{})
+		conn.AssertExpectations(t)
+	})
+
+	t.Run("auth request", func(t *testing.T) {
+		b, p, _, _ := setup(t)
+
+		writer := &mockWriterController{}
+		p.reliableWriter = writer
+
+		b.processAuthMessage(p, protocol.MessageType_AUTH_REQUEST, nil)
+		writer.AssertNotCalled(t, "Write", mock.Anything)
+
+		p.initiated = true
+		writer.On("Write", mock.MatchedBy(func(rawMsg []byte) bool {
+			msg := protocol.AuthMessage{}
+			return proto.Unmarshal(rawMsg, &msg) == nil && string(msg.Body) == "server-token"
+		})).Return().Once()
+
+		b.processAuthMessage(p, protocol.MessageType_AUTH_REQUEST, nil)
+		writer.AssertExpectations(t)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		b, p, conn, rejected := setup(t)
+		conn.On("Close").Return(nil).Once()
+
+		b.Revoke([]byte("user1"), time.Now().Add(time.Minute))
+		require.Equal(t, ErrRevoked, <-rejected)
+		require.Eventually(t, p.IsClosed, time.Second, 10*time.Millisecond)
+		require.True(t, b.isRevoked([]byte("user1")))
+		require.False(t, b.isRevoked([]byte("user2")))
+
+		b.Revoke([]byte("user2"), time.Now().Add(-time.Minute))
+		require.False(t, b.isRevoked([]byte("user2")))
+		conn.AssertExpectations(t)
+	})
+}
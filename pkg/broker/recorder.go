@@ -0,0 +1,405 @@
+package broker
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const (
+	recordingMagic          = "WBRR"
+	recordingVersion   byte = 1
+	recordFlagReliable byte = 1 << 0
+
+	defaultRecorderQueueSize = 4096
+	recorderFlushPeriod      = 1 * time.Second
+)
+
+// ErrInvalidRecording indicates the file is not a recording, or it was written by an unsupported version
+var ErrInvalidRecording = errors.New("invalid recording")
+
+// RecorderConfig is the traffic recorder config
+type RecorderConfig struct {
+	// Path is the recording base path, files are named <Path>.<sequence>, starting after the last existing one
+	Path string
+	// MaxFileSize rotates the current file when exceeded, zero means no rotation
+	MaxFileSize int64
+	// MaxFiles is the amount of files kept when rotating, older files are removed, zero keeps all
+	MaxFiles int
+	// Topics are the topic prefixes recorded, if empty all topics are recorded. Subscription messages
+	// are always recorded
+	Topics []string
+	// QueueSize is the amount of records waiting to be written, if the queue is full records are dropped
+	QueueSize int
+}
+
+// Record is a recorded message, as received from the peer
+type Record struct {
+	Time     time.Time
+	Alias    uint64
+	Role     protocol.Role
+	Reliable bool
+	Message  []byte
+}
+
+// Recorder writes received topic and subscription messages to an append-only file
+//
+// The file starts with a magic header and a version byte, then each record is encoded as:
+// uvarint record size, varint unix nano timestamp, uvarint alias, role byte, flags byte, message bytes
+type Recorder struct {
+	config    RecorderConfig
+	recordsCh chan *Record
+	done      chan struct{}
+	closeOnce sync.Once
+	log       logging.Logger
+
+	file    *os.File
+	writer  *bufio.Writer
+	size    int64
+	seq     int
+	scratch []byte
+}
+
+// NewRecorder creates a new recorder and opens the first file
+func NewRecorder(config RecorderConfig, log logging.Logger) (*Recorder, error) {
+	if config.Path == "" {
+		return nil, errors.New("recorder path cannot be empty")
+	}
+
+	queueSize := config.QueueSize
+	if queueSize == 0 {
+		queueSize = defaultRecorderQueueSize
+	}
+
+	seqs, err := recordingSequences(config.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		config:    config,
+		recordsCh: make(chan *Record, queueSize),
+		done:      make(chan struct{}),
+		log:       log,
+		scratch:   make([]byte, 0, 3*binary.MaxVarintLen64+2),
+	}
+
+	if len(seqs) > 0 {
+		r.seq = seqs[len(seqs)-1]
+	}
+
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+
+	go r.run()
+
+	return r, nil
+}
+
+func (r *Recorder) shouldRecordTopic(topic string) bool {
+	if len(r.config.Topics) == 0 {
+		return true
+	}
+
+	for _, prefix := range r.config.Topics {
+		if strings.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RecordTopic queues a topic message if it matches the topic filters, rawMsg is copied
+func (r *Recorder) RecordTopic(alias uint64, role protocol.Role, reliable bool, topic string, rawMsg []byte) {
+	if r.shouldRecordTopic(topic) {
+		r.record(alias, role, reliable, rawMsg)
+	}
+}
+
+// RecordSubscription queues a subscription message, rawMsg is copied
+func (r *Recorder) RecordSubscription(alias uint64, role protocol.Role, rawMsg []byte) {
+	r.record(alias, role, true, rawMsg)
+}
+
+func (r *Recorder) record(alias uint64, role protocol.Role, reliable bool, rawMsg []byte) {
+	msg := make([]byte, len(rawMsg))
+	copy(msg, rawMsg)
+
+	record := &Record{
+		Time:     time.Now(),
+		Alias:    alias,
+		Role:     role,
+		Reliable: reliable,
+		Message:  msg,
+	}
+
+	select {
+	case r.recordsCh <- record:
+	default:
+		r.log.Warn().Msg("recorder queue is full, dropping record")
+	}
+}
+
+// Close flushes the pending records and closes the current file
+func (r *Recorder) Close() {
+	r.closeOnce.Do(func() {
+		close(r.recordsCh)
+		<-r.done
+	})
+}
+
+func (r *Recorder) run() {
+	ticker := time.NewTicker(recorderFlushPeriod)
+
+	defer func() {
+		ticker.Stop()
+
+		if err := r.closeFile(); err != nil {
+			r.log.Error().Err(err).Msg("error closing recording")
+		}
+
+		close(r.done)
+	}()
+
+	for {
+		select {
+		case record, ok := <-r.recordsCh:
+			if !ok {
+				return
+			}
+
+			if err := r.write(record); err != nil {
+				r.log.Error().Err(err).Msg("error writing record, stopping recorder")
+
+				// NOTE: keep consuming so peers don't flood the log with dropped records
+				for range r.recordsCh {
+				}
+
+				return
+			}
+		case <-ticker.C:
+			if err := r.writer.Flush(); err != nil {
+				r.log.Error().Err(err).Msg("error flushing recording")
+			}
+		}
+	}
+}
+
+func (r *Recorder) write(record *Record) error {
+	header := r.scratch[:0]
+	header = appendVarint(header, record.Time.UnixNano())
+	header = appendUvarint(header, record.Alias)
+	header = append(header, byte(record.Role))
+
+	flags := byte(0)
+	if record.Reliable {
+		flags |= recordFlagReliable
+	}
+
+	header = append(header, flags)
+
+	size := uint64(len(header) + len(record.Message))
+	prefix := appendUvarint(make([]byte, 0, binary.MaxVarintLen64), size)
+	total := int64(len(prefix)) + int64(size)
+
+	if r.config.MaxFileSize > 0 && r.size > int64(len(recordingMagic)+1) && r.size+total > r.config.MaxFileSize {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range [][]byte{prefix, header, record.Message} {
+		if _, err := r.writer.Write(b); err != nil {
+			return err
+		}
+	}
+
+	r.size += total
+
+	return nil
+}
+
+func (r *Recorder) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+
+	file := r.file
+	r.file = nil
+
+	if err := r.writer.Flush(); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			r.log.Debug().Err(closeErr).Msg("error closing recording file")
+		}
+
+		return err
+	}
+
+	return file.Close()
+}
+
+func (r *Recorder) rotate() error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+
+	r.seq++
+	path := fmt.Sprintf("%s.%d", r.config.Path, r.seq)
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	r.file = file
+	r.writer = bufio.NewWriter(file)
+
+	if _, err := r.writer.WriteString(recordingMagic); err != nil {
+		return err
+	}
+
+	if err := r.writer.WriteByte(recordingVersion); err != nil {
+		return err
+	}
+
+	r.size = int64(len(recordingMagic) + 1)
+
+	r.log.Info().Str("path", path).Msg("recording to new file")
+
+	if r.config.MaxFiles > 0 {
+		seqs, err := recordingSequences(r.config.Path)
+		if err != nil {
+			return err
+		}
+
+		for len(seqs) > r.config.MaxFiles {
+			old := fmt.Sprintf("%s.%d", r.config.Path, seqs[0])
+			if err := os.Remove(old); err != nil {
+				return err
+			}
+
+			seqs = seqs[1:]
+		}
+	}
+
+	return nil
+}
+
+// RecordingFiles returns the files of a recording in order
+func RecordingFiles(path string) ([]string, error) {
+	seqs, err := recordingSequences(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, len(seqs))
+	for i, seq := range seqs {
+		files[i] = fmt.Sprintf("%s.%d", path, seq)
+	}
+
+	return files, nil
+}
+
+func recordingSequences(path string) ([]int, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	seqs := make([]int, 0, len(matches))
+
+	for _, match := range matches {
+		seq, err := strconv.Atoi(strings.TrimPrefix(match, path+"."))
+		if err == nil && seq > 0 {
+			seqs = append(seqs, seq)
+		}
+	}
+
+	sort.Ints(seqs)
+
+	return seqs, nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+
+	return append(b, buf[:n]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+
+	return append(b, buf[:n]...)
+}
+
+// RecordReader reads records from a recording file
+type RecordReader struct {
+	r *bufio.Reader
+}
+
+// NewRecordReader creates a new reader, validating the recording header
+func NewRecordReader(r io.Reader) (*RecordReader, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(recordingMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, ErrInvalidRecording
+	}
+
+	if string(header[:len(recordingMagic)]) != recordingMagic || header[len(recordingMagic)] != recordingVersion {
+		return nil, ErrInvalidRecording
+	}
+
+	return &RecordReader{r: br}, nil
+}
+
+// Next returns the next record, or io.EOF at the end of the recording
+func (rr *RecordReader) Next() (*Record, error) {
+	size, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(rr.r, buf); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	timestamp, n := binary.Varint(buf)
+	if n <= 0 {
+		return nil, ErrInvalidRecording
+	}
+
+	buf = buf[n:]
+
+	alias, n := binary.Uvarint(buf)
+	if n <= 0 || len(buf) < n+2 {
+		return nil, ErrInvalidRecording
+	}
+
+	buf = buf[n:]
+
+	return &Record{
+		Time:     time.Unix(0, timestamp),
+		Alias:    alias,
+		Role:     protocol.Role(buf[0]),
+		Reliable: buf[1]&recordFlagReliable != 0,
+		Message:  buf[2:],
+	}, nil
+}
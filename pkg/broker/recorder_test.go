@@ -0,0 +1,109 @@
+package broker
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func readRecording(t *testing.T, path string) []*Record {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	reader, err := NewRecordReader(file)
+	require.NoError(t, err)
+
+	records := []*Record{}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records
+		}
+
+		require.NoError(t, err)
+
+		records = append(records, record)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "traffic")
+
+	t.Run("filters and reads back", func(t *testing.T) {
+		recorder, err := NewRecorder(RecorderConfig{Path: path, Topics: []string{"pos:"}}, logging.New())
+		require.NoError(t, err)
+
+		recorder.RecordTopic(1, protocol.Role_CLIENT, false, "pos:1:1", []byte("position"))
+		recorder.RecordTopic(1, protocol.Role_CLIENT, true, "chat:1:1", []byte("chat"))
+		recorder.RecordSubscription(2, protocol.Role_COMMUNICATION_SERVER, []byte("subscription"))
+		recorder.Close()
+
+		files, err := RecordingFiles(path)
+		require.NoError(t, err)
+		require.Equal(t, []string{path + ".1"}, files)
+
+		records := readRecording(t, files[0])
+		require.Len(t, records, 2)
+
+		require.Equal(t, uint64(1), records[0].Alias)
+		require.Equal(t, protocol.Role_CLIENT, records[0].Role)
+		require.False(t, records[0].Reliable)
+		require.Equal(t, []byte("position"), records[0].Message)
+
+		require.Equal(t, uint64(2), records[1].Alias)
+		require.Equal(t, protocol.Role_COMMUNICATION_SERVER, records[1].Role)
+		require.True(t, records[1].Reliable)
+		require.Equal(t, []byte("subscription"), records[1].Message)
+		require.False(t, records[1].Time.Before(records[0].Time))
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		recorder, err := NewRecorder(RecorderConfig{Path: path, MaxFileSize: 64, MaxFiles: 2}, logging.New())
+		require.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			recorder.RecordTopic(1, protocol.Role_CLIENT, true, "topic", make([]byte, 20))
+		}
+
+		recorder.Close()
+
+		files, err := RecordingFiles(path)
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+
+		for _, file := range files {
+			info, err := os.Stat(file)
+			require.NoError(t, err)
+			require.True(t, info.Size() <= 64)
+
+			require.NotEmpty(t, readRecording(t, file))
+		}
+	})
+
+	t.Run("invalid recording", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid")
+		require.NoError(t, ioutil.WriteFile(invalid, []byte("not a recording"), 0644))
+
+		file, err := os.Open(invalid)
+		require.NoError(t, err)
+
+		defer file.Close()
+
+		_, err = NewRecordReader(file)
+		require.Equal(t, ErrInvalidRecording, err)
+	})
+}
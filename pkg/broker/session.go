@@ -0,0 +1,58 @@
+package broker
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/pkg/server"
+)
+
+const (
+	defaultDataChannelTimeout = 10 * time.Second
+	defaultAuthTimeout        = 10 * time.Second
+)
+
+// dataChannelsOpen returns true once both data channels are open and detached
+func (p *peer) dataChannelsOpen() bool {
+	p.reliableRWCMutex.RLock()
+	reliableOpen := p.reliableRWC != nil
+	p.reliableRWCMutex.RUnlock()
+
+	p.unreliableRWCMutex.RLock()
+	unreliableOpen := p.unreliableRWC != nil
+	p.unreliableRWCMutex.RUnlock()
+
+	return reliableOpen && unreliableOpen
+}
+
+// onPeerConnected closes the peer if its data channels are not open before the data channel timeout, the
+// authentication timeout starts once the reliable one is
+func (b *Broker) onPeerConnected(rawPeer *server.Peer) {
+	b.peersMux.Lock()
+	p := b.peers[rawPeer.Alias]
+	b.peersMux.Unlock()
+
+	if p == nil {
+		return
+	}
+
+	time.AfterFunc(b.dataChannelTimeout, func() {
+		if !p.dataChannelsOpen() && !p.IsClosed() {
+			p.Log.Info().Msg("data channels not open after the data channel timeout, closing connection")
+			atomic.AddUint32(&b.dataChannelTimeouts, 1)
+			p.Close()
+		}
+	})
+}
+
+// startAuthTimeout closes the peer if it doesn't authenticate before the auth timeout, the returned function
+// stops the timer and returns false if it already fired
+func (b *Broker) startAuthTimeout(p *peer) func() bool {
+	timer := time.AfterFunc(b.authTimeout, func() {
+		p.Log.Info().Msg("closing connection: no auth message before the auth timeout")
+		atomic.AddUint32(&b.authTimeouts, 1)
+		p.Close()
+	})
+
+	return timer.Stop
+}
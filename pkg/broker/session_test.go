@@ -0,0 +1,70 @@
+package broker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	_testing "github.com/decentraland/webrtc-broker/internal/testing"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
+)
+
+func TestSessionTimeouts(t *testing.T) {
+	setup := func(t *testing.T) (*Broker, *peer, chan struct{}) {
+		b, err := NewBroker(&Config{
+			Role:               protocol.Role_COMMUNICATION_SERVER,
+			Auth:               &authentication.NoopAuthenticator{},
+			DataChannelTimeout: 10 * time.Millisecond,
+			AuthTimeout:        10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		log := logging.New()
+		conn := &_testing.MockWebsocket{}
+		closed := make(chan struct{})
+		conn.On("Close").Run(func(mock.Arguments) { close(closed) }).Return(nil).Once()
+
+		p := &peer{
+			Peer:   &server.Peer{Alias: 1, Fallback: server.NewFallbackConn(conn, log), Log: log},
+			topics: make(map[string]struct{}),
+		}
+		b.peers[1] = p
+
+		return b, p, closed
+	}
+
+	t.Run("auth timeout", func(t *testing.T) {
+		b, p, closed := setup(t)
+
+		require.False(t, b.authenticate(p, p.Fallback.Reliable))
+		<-closed
+		require.Equal(t, uint32(1), b.GetBrokerStats().AuthTimeouts)
+	})
+
+	t.Run("data channel timeout", func(t *testing.T) {
+		b, p, closed := setup(t)
+
+		b.onPeerConnected(p.Peer)
+		<-closed
+		require.Equal(t, uint32(1), b.GetBrokerStats().DataChannelTimeouts)
+	})
+
+	t.Run("data channels open", func(t *testing.T) {
+		b, p, closed := setup(t)
+		p.reliableRWC = p.Fallback.Reliable
+		p.unreliableRWC = p.Fallback.Unreliable
+
+		b.onPeerConnected(p.Peer)
+		time.Sleep(50 * time.Millisecond)
+		require.False(t, p.IsClosed())
+		require.Equal(t, uint32(0), b.GetBrokerStats().DataChannelTimeouts)
+
+		require.NoError(t, p.Fallback.Close())
+		<-closed
+	})
+}
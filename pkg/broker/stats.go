@@ -3,6 +3,7 @@ package broker
 import (
 	"time"
 
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
 	pion "github.com/pion/webrtc/v2"
 )
 
@@ -17,6 +18,52 @@ type StatsSummary struct {
 
 	MessagesSentByDC, BytesSentByDC, BytesSentByICE, BytesSentBySCTP                 uint64
 	MessagesReceivedByDC, BytesReceivedByDC, BytesReceivedByICE, BytesReceivedBySCTP uint64
+
+	// RTTAvg and UnreliableLoss are the average of the peers measured, RTTP95 the worst peer one. The Server ones
+	// only consider the links to other servers, see ConnectionQuality
+	RTTAvg, RTTP95, ServerRTTAvg, ServerRTTP95 time.Duration
+	UnreliableLoss, ServerUnreliableLoss       float64
+}
+
+// qualitySummary aggregates the peers ConnectionQuality
+type qualitySummary struct {
+	rttSum    time.Duration
+	rttCount  int
+	rttP95    time.Duration
+	lossSum   float64
+	lossCount int
+}
+
+func (s *qualitySummary) add(q ConnectionQuality) {
+	if q.RTTSamples > 0 {
+		s.rttSum += q.RTTAvg
+		s.rttCount++
+
+		if q.RTTP95 > s.rttP95 {
+			s.rttP95 = q.RTTP95
+		}
+	}
+
+	if q.UnreliableLossSamples > 0 {
+		s.lossSum += q.UnreliableLoss
+		s.lossCount++
+	}
+}
+
+func (s *qualitySummary) rttAvg() time.Duration {
+	if s.rttCount == 0 {
+		return 0
+	}
+
+	return s.rttSum / time.Duration(s.rttCount)
+}
+
+func (s *qualitySummary) unreliableLoss() float64 {
+	if s.lossCount == 0 {
+		return 0
+	}
+
+	return s.lossSum / float64(s.lossCount)
 }
 
 // StatsSummaryGenerator ...
@@ -41,6 +88,8 @@ func (g *StatsSummaryGenerator) Generate(stats Stats) StatsSummary {
 		RemoteCandidateTypeCount: make(map[pion.ICECandidateType]uint32),
 	}
 
+	var quality, serverQuality qualitySummary
+
 	for alias, pStats := range stats.Peers {
 		pLastStats := g.lastStats.Peers[alias]
 
@@ -60,8 +109,21 @@ func (g *StatsSummaryGenerator) Generate(stats Stats) StatsSummary {
 		summary.BytesReceivedByDC += g.getBytesReceivedByDC(pStats) - g.getBytesReceivedByDC(pLastStats)
 		summary.BytesReceivedByICE += pStats.ICETransportBytesReceived - pLastStats.ICETransportBytesReceived
 		summary.BytesReceivedBySCTP += pStats.SCTPTransportBytesReceived - pLastStats.SCTPTransportBytesReceived
+
+		quality.add(pStats.ConnectionQuality)
+
+		if pStats.Role == protocol.Role_COMMUNICATION_SERVER || pStats.Role == protocol.Role_COMMUNICATION_SERVER_HUB {
+			serverQuality.add(pStats.ConnectionQuality)
+		}
 	}
 
+	summary.RTTAvg = quality.rttAvg()
+	summary.RTTP95 = quality.rttP95
+	summary.UnreliableLoss = quality.unreliableLoss()
+	summary.ServerRTTAvg = serverQuality.rttAvg()
+	summary.ServerRTTP95 = serverQuality.rttP95
+	summary.ServerUnreliableLoss = serverQuality.unreliableLoss()
+
 	g.lastStats = stats
 
 	return summary
@@ -0,0 +1,270 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+// ServerInfo is the admin view of a registered server
+type ServerInfo struct {
+	Alias       uint64            `json:"alias"`
+	Role        string            `json:"role"`
+	ConnectedAt time.Time         `json:"connectedAt"`
+	Selectable  bool              `json:"selectable"`
+	Full        bool              `json:"full"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// ClientInfo is the admin view of a connected client
+type ClientInfo struct {
+	Alias       uint64    `json:"alias"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	ServerAlias uint64    `json:"serverAlias"`
+}
+
+// ErrStopped indicates that the coordinator is stopped, so it cannot run admin requests
+var ErrStopped = errors.New("coordinator stopped")
+
+// adminRequest is executed by the coordinator loop, so it can safely access the state
+type adminRequest struct {
+	exec  func(state *State) interface{}
+	reply chan interface{}
+}
+
+// runAdminRequest runs exec in the coordinator loop, it returns ErrStopped once the coordinator is stopped
+func runAdminRequest(state *State, exec func(state *State) interface{}) (interface{}, error) {
+	req := &adminRequest{
+		exec:  exec,
+		reply: make(chan interface{}, 1),
+	}
+
+	select {
+	case state.adminQueue <- req:
+	case <-state.stop:
+		return nil, ErrStopped
+	}
+
+	select {
+	case res := <-req.reply:
+		return res, nil
+	case <-state.stop:
+		return nil, ErrStopped
+	}
+}
+
+func listServers(state *State) interface{} {
+	servers := make([]ServerInfo, 0)
+	now := time.Now()
+
+	for alias, p := range state.Peers {
+		if p.role == protocol.Role_CLIENT {
+			continue
+		}
+
+		servers = append(servers, ServerInfo{
+			Alias:       alias,
+			Role:        p.role.String(),
+			ConnectedAt: p.connectedAt,
+			Selectable:  !state.unselectable[alias],
+			Full:        isServerFull(state, alias, now),
+			Labels:      p.labels,
+		})
+	}
+
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Alias < servers[j].Alias })
+
+	return servers
+}
+
+func listClients(state *State) interface{} {
+	clients := make([]ClientInfo, 0)
+
+	for alias, p := range state.Peers {
+		if p.role != protocol.Role_CLIENT {
+			continue
+		}
+
+		clients = append(clients, ClientInfo{
+			Alias:       alias,
+			ConnectedAt: p.connectedAt,
+			ServerAlias: p.serverAlias,
+		})
+	}
+
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Alias < clients[j].Alias })
+
+	return clients
+}
+
+func disconnectPeer(alias uint64) func(state *State) interface{} {
+	return func(state *State) interface{} {
+		p, ok := state.Peers[alias]
+		if !ok {
+			return false
+		}
+
+		state.log.Info().Uint64("peer", alias).Msg("admin: force disconnect")
+		p.close()
+
+		return true
+	}
+}
+
+func setSelectable(alias uint64, selectable bool) func(state *State) interface{} {
+	return func(state *State) interface{} {
+		p, ok := state.Peers[alias]
+		if !ok || p.role == protocol.Role_CLIENT {
+			return false
+		}
+
+		state.log.Info().Uint64("peer", alias).Bool("selectable", selectable).Msg("admin: server selectable changed")
+
+		if selectable {
+			delete(state.unselectable, alias)
+		} else {
+			state.unselectable[alias] = true
+		}
+
+		return true
+	}
+}
+
+func writeJSON(state *State, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		state.log.Error().Err(err).Msg("admin: cannot encode response")
+	}
+}
+
+func parseAlias(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	alias, err := strconv.ParseUint(r.URL.Query().Get("alias"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid alias", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return alias, true
+}
+
+// RegisterAdmin register coordinator admin endpoints, every request is authenticated with the given
+// authenticator
+func RegisterAdmin(state *State, mux *http.ServeMux, auth authentication.AdminAuthenticator) {
+	handle := func(path string, method string, hdlr func(w http.ResponseWriter, r *http.Request)) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != method {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			isValid, err := auth.AuthenticateAdmin(r)
+			if err != nil {
+				state.log.Error().Err(err).Msg("admin: authentication error")
+				http.Error(w, "authentication error", http.StatusInternalServerError)
+
+				return
+			}
+
+			if !isValid {
+				http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			hdlr(w, r)
+		})
+	}
+
+	// run writes a 503 if the coordinator is stopped
+	run := func(w http.ResponseWriter, exec func(state *State) interface{}) (interface{}, bool) {
+		res, err := runAdminRequest(state, exec)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return nil, false
+		}
+
+		return res, true
+	}
+
+	handle("/admin/servers", http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		if servers, ok := run(w, listServers); ok {
+			writeJSON(state, w, servers)
+		}
+	})
+
+	handle("/admin/clients", http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		if clients, ok := run(w, listClients); ok {
+			writeJSON(state, w, clients)
+		}
+	})
+
+	handle("/admin/disconnect", http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		alias, ok := parseAlias(w, r)
+		if !ok {
+			return
+		}
+
+		found, ok := run(w, disconnectPeer(alias))
+		if !ok {
+			return
+		}
+
+		if !found.(bool) {
+			http.Error(w, "peer not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handle("/admin/revoke", http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		qs := r.URL.Query()
+
+		identity := qs.Get("identity")
+		if identity == "" {
+			http.Error(w, "invalid identity", http.StatusBadRequest)
+			return
+		}
+
+		duration, err := time.ParseDuration(qs.Get("duration"))
+		if err != nil || duration <= 0 {
+			http.Error(w, "invalid duration", http.StatusBadRequest)
+			return
+		}
+
+		if disconnected, ok := run(w, revokeIdentity([]byte(identity), time.Now().Add(duration))); ok {
+			writeJSON(state, w, map[string]int{"disconnected": disconnected.(int)})
+		}
+	})
+
+	handle("/admin/selectable", http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		alias, ok := parseAlias(w, r)
+		if !ok {
+			return
+		}
+
+		selectable, err := strconv.ParseBool(r.URL.Query().Get("value"))
+		if err != nil {
+			http.Error(w, "invalid value", http.StatusBadRequest)
+			return
+		}
+
+		found, ok := run(w, setSelectable(alias, selectable))
+		if !ok {
+			return
+		}
+
+		if !found.(bool) {
+			http.Error(w, "server not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
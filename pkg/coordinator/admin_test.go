@@ -0,0 +1,240 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/golang/protobuf/proto"
+)
+
+type mockAdminAuthenticator struct{ mock.Mock }
+
+func (m *mockAdminAuthenticator) AuthenticateAdmin(r *http.Request) (bool, error) {
+	args := m.Called(r)
+	return args.Bool(0), args.Error(1)
+}
+
+func makeAdminTestState() (*State, *http.ServeMux) {
+	state := makeTestState()
+
+	auth := &mockAdminAuthenticator{}
+	auth.On("AuthenticateAdmin", mock.Anything).Return(true, nil)
+
+	mux := http.NewServeMux()
+	RegisterAdmin(state, mux, auth)
+
+	return state, mux
+}
+
+func TestAdminListPeers(t *testing.T) {
+	state, mux := makeAdminTestState()
+	defer closeState(state)
+
+	s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s.Alias = 1
+	state.Peers[s.Alias] = s
+
+	c := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+	c.Alias = 2
+	c.serverAlias = 1
+	state.Peers[c.Alias] = c
+
+	go Start(state)
+
+	t.Run("servers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/servers", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		servers := []ServerInfo{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &servers))
+		require.Len(t, servers, 1)
+		require.Equal(t, uint64(1), servers[0].Alias)
+		require.Equal(t, protocol.Role_COMMUNICATION_SERVER.String(), servers[0].Role)
+		require.True(t, servers[0].Selectable)
+	})
+
+	t.Run("clients", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/clients", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		clients := []ClientInfo{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clients))
+		require.Len(t, clients, 1)
+		require.Equal(t, uint64(2), clients[0].Alias)
+		require.Equal(t, uint64(1), clients[0].ServerAlias)
+	})
+
+	state.stop <- true
+}
+
+func TestAdminSelectable(t *testing.T) {
+	state, mux := makeAdminTestState()
+	defer closeState(state)
+
+	s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s.Alias = 1
+	state.Peers[s.Alias] = s
+	state.serverSelector.ServerRegistered(s.role, s.Alias)
+
+	go Start(state)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/selectable?alias=1&value=false", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/selectable?alias=3&value=false", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	state.stop <- true
+
+	require.True(t, state.unselectable[1])
+	require.Empty(t, filterSelectable(state, []uint64{1}))
+}
+
+func TestAdminDisconnect(t *testing.T) {
+	state, mux := makeAdminTestState()
+	defer closeState(state)
+
+	conn := &MockWebsocket{}
+	conn.On("Close").Return(nil).Once()
+	c := makePeer(state, conn, protocol.Role_CLIENT)
+	c.Alias = 1
+	state.Peers[c.Alias] = c
+
+	go Start(state)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/disconnect?alias=1", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	state.stop <- true
+
+	require.True(t, c.isClosed())
+	conn.AssertExpectations(t)
+}
+
+func TestAdminRevoke(t *testing.T) {
+	state, mux := makeAdminTestState()
+	defer closeState(state)
+
+	s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s.Alias = 1
+	state.Peers[s.Alias] = s
+
+	conn := &MockWebsocket{}
+	conn.On("Close").Return(nil).Once()
+	c := makePeer(state, conn, protocol.Role_CLIENT)
+	c.Alias = 2
+	c.identity = []byte("user1")
+	state.Peers[c.Alias] = c
+
+	c2 := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+	c2.Alias = 3
+	c2.identity = []byte("user2")
+	state.Peers[c2.Alias] = c2
+
+	go Start(state)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/revoke?identity=user1&duration=10m", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"disconnected": 1}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/revoke?identity=user1", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	revokeMessage := &protocol.RevokeMessage{}
+	require.NoError(t, proto.Unmarshal(<-s.sendCh, revokeMessage))
+	require.Equal(t, protocol.MessageType_REVOKE, revokeMessage.Type)
+	require.Equal(t, []byte("user1"), revokeMessage.Identity)
+
+	// new servers get the active revocations after the welcome message
+	s2 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	state.registerCommServer <- s2
+
+	<-s2.sendCh
+	require.NoError(t, proto.Unmarshal(<-s2.sendCh, revokeMessage))
+	require.Equal(t, []byte("user1"), revokeMessage.Identity)
+
+	state.stop <- true
+
+	// revoked identities cannot connect again
+	conn3 := &MockWebsocket{}
+	conn3.On("Close").Return(nil).Once()
+	c3 := makePeer(state, conn3, protocol.Role_CLIENT)
+	c3.identity = []byte("user1")
+	require.Equal(t, ErrRevoked, registerClient(state, c3))
+
+	// closed servers wait in the state to be unregistered, they are skipped
+	conn4 := &MockWebsocket{}
+	conn4.On("Close").Return(nil).Once()
+	s3 := makePeer(state, conn4, protocol.Role_COMMUNICATION_SERVER)
+	s3.Alias = 10
+	state.Peers[s3.Alias] = s3
+	s3.close()
+	require.Equal(t, 0, applyRevocation(state, []byte("user3"), time.Now().Add(time.Minute)))
+	require.Equal(t, ErrPeerClosed, sendRevocations(state, s3))
+
+	require.True(t, c.isClosed())
+	require.False(t, c2.isClosed())
+	require.True(t, c3.isClosed())
+	conn4.AssertExpectations(t)
+	require.Len(t, c2.sendCh, 0)
+	conn.AssertExpectations(t)
+	conn3.AssertExpectations(t)
+	conn4.AssertExpectations(t)
+}
+
+func TestAdminUnauthorized(t *testing.T) {
+	state := makeTestState()
+	defer closeState(state)
+
+	auth := &mockAdminAuthenticator{}
+	auth.On("AuthenticateAdmin", mock.Anything).Return(false, nil).Once()
+
+	mux := http.NewServeMux()
+	RegisterAdmin(state, mux, auth)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/servers", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	auth.AssertExpectations(t)
+}
+
+func TestAdminStopped(t *testing.T) {
+	state := makeTestState()
+	closeState(state)
+
+	auth := &mockAdminAuthenticator{}
+	auth.On("AuthenticateAdmin", mock.Anything).Return(true, nil)
+
+	mux := http.NewServeMux()
+	RegisterAdmin(state, mux, auth)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/servers", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	require.Equal(t, 0, Revoke(state, []byte("user1"), time.Now().Add(time.Minute)))
+}
@@ -0,0 +1,287 @@
+package coordinator
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/internal/ws"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const (
+	// handshakeSweepPeriod is how often the idle handshake buckets are removed
+	handshakeSweepPeriod = time.Minute
+	// serverSignalingFactor scales the client signaling limit into the default server one
+	serverSignalingFactor = 10
+)
+
+// AdmissionConfig protects the coordinator from abusive peers, a zero value disables the limit
+type AdmissionConfig struct {
+	// AllowedOrigins are the origins browsers can connect from, see ws.IsOriginAllowed
+	AllowedOrigins []string
+	// MaxConnections caps the client connections, servers are not counted so the cluster can always grow
+	MaxConnections int
+	// MaxConnectionsPerIP caps the client connections from the same address
+	MaxConnectionsPerIP int
+	// HandshakeRate is how many connection attempts per second an address can make, both clients and servers,
+	// with bursts of up to HandshakeBurst attempts
+	HandshakeRate  float64
+	HandshakeBurst int
+	// SignalingRate is how many signaling messages per second a client can send, with bursts of up to
+	// SignalingBurst messages. The messages exceeding the rate are dropped
+	SignalingRate  float64
+	SignalingBurst int
+	// ServerSignalingRate and ServerSignalingBurst limit the signaling messages of a server the same way. A server
+	// signals every client connecting to it, so its limit is higher, 10 times the client one if not set
+	ServerSignalingRate  float64
+	ServerSignalingBurst int
+	// RealIPHeader is the header a trusted reverse proxy sets with the client address (e.g. X-Forwarded-For), the
+	// last address of the header is used. The connection address is used if empty
+	RealIPHeader string
+}
+
+// RejectionStats counts the connections and the messages refused by the admission control, and the peers
+// dropped by the signaling policy
+type RejectionStats struct {
+	Origin           uint64
+	Capacity         uint64
+	ConnectionsPerIP uint64
+	HandshakeRate    uint64
+	SignalingRate    uint64
+	SignalingPolicy  uint64
+}
+
+// admissionError is a refused connection, with the http status and a message explaining the refusal
+type admissionError struct {
+	status  int
+	message string
+}
+
+func (e *admissionError) Error() string {
+	return e.message
+}
+
+var (
+	errOriginNotAllowed   = &admissionError{status: http.StatusForbidden, message: "origin not allowed"}
+	errCoordinatorFull    = &admissionError{status: http.StatusServiceUnavailable, message: "coordinator is full"}
+	errTooManyConnections = &admissionError{
+		status:  http.StatusTooManyRequests,
+		message: "too many connections from this address",
+	}
+	errTooManyHandshakes = &admissionError{
+		status:  http.StatusTooManyRequests,
+		message: "too many connection attempts from this address",
+	}
+)
+
+// tokenBucket allows rate events per second, with bursts of up to burst events. It's not safe for concurrent use
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+
+	b.last = now
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// admission tracks the client connections and the handshakes per address, it's used concurrently by the http
+// handlers and the peer read pumps
+type admission struct {
+	config AdmissionConfig
+
+	mux              sync.Mutex
+	connections      int
+	connectionsPerIP map[string]int
+	handshakes       map[string]*tokenBucket
+	lastSweep        time.Time
+	rejections       RejectionStats
+}
+
+func newAdmission(config AdmissionConfig) *admission {
+	return &admission{
+		config:           config,
+		connectionsPerIP: make(map[string]int),
+		handshakes:       make(map[string]*tokenBucket),
+		lastSweep:        time.Now(),
+	}
+}
+
+func (a *admission) remoteIP(r *http.Request) string {
+	if a.config.RealIPHeader != "" {
+		if value := r.Header.Get(a.config.RealIPHeader); value != "" {
+			addresses := strings.Split(value, ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// sweep removes the buckets of the addresses that stopped connecting, their buckets are full again
+func (a *admission) sweep(now time.Time) {
+	if now.Sub(a.lastSweep) < handshakeSweepPeriod {
+		return
+	}
+
+	a.lastSweep = now
+
+	for ip, b := range a.handshakes {
+		b.refill(now)
+
+		if b.tokens >= b.burst {
+			delete(a.handshakes, ip)
+		}
+	}
+}
+
+// admit checks a connection request before upgrading it. The release function has to be called once the
+// connection is closed
+func (a *admission) admit(role protocol.Role, r *http.Request) (release func(), refusal *admissionError) {
+	now := time.Now()
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	if !ws.IsOriginAllowed(a.config.AllowedOrigins, r) {
+		a.rejections.Origin++
+		return nil, errOriginNotAllowed
+	}
+
+	ip := a.remoteIP(r)
+
+	if a.config.HandshakeRate > 0 {
+		a.sweep(now)
+
+		b, ok := a.handshakes[ip]
+		if !ok {
+			b = newTokenBucket(a.config.HandshakeRate, a.config.HandshakeBurst, now)
+			a.handshakes[ip] = b
+		}
+
+		if !b.allow(now) {
+			a.rejections.HandshakeRate++
+			return nil, errTooManyHandshakes
+		}
+	}
+
+	if role != protocol.Role_CLIENT {
+		return func() {}, nil
+	}
+
+	if a.config.MaxConnections > 0 && a.connections >= a.config.MaxConnections {
+		a.rejections.Capacity++
+		return nil, errCoordinatorFull
+	}
+
+	if a.config.MaxConnectionsPerIP > 0 && a.connectionsPerIP[ip] >= a.config.MaxConnectionsPerIP {
+		a.rejections.ConnectionsPerIP++
+		return nil, errTooManyConnections
+	}
+
+	a.connections++
+	a.connectionsPerIP[ip]++
+
+	var once sync.Once
+
+	return func() { once.Do(func() { a.release(ip) }) }, nil
+}
+
+func (a *admission) release(ip string) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	a.connections--
+
+	a.connectionsPerIP[ip]--
+	if a.connectionsPerIP[ip] <= 0 {
+		delete(a.connectionsPerIP, ip)
+	}
+}
+
+// signalingLimit returns the signaling rate limit of a new peer, nil if there is no limit
+func (a *admission) signalingLimit(role protocol.Role) *tokenBucket {
+	rate := a.config.SignalingRate
+	burst := a.config.SignalingBurst
+
+	if role != protocol.Role_CLIENT {
+		rate = a.config.ServerSignalingRate
+		if rate == 0 {
+			rate = a.config.SignalingRate * serverSignalingFactor
+		}
+
+		burst = a.config.ServerSignalingBurst
+		if burst == 0 {
+			burst = a.config.SignalingBurst * serverSignalingFactor
+		}
+	}
+
+	if rate <= 0 {
+		return nil
+	}
+
+	return newTokenBucket(rate, burst, time.Now())
+}
+
+// allowSignaling checks the peer signaling rate, counting the dropped messages
+func (a *admission) allowSignaling(p *Peer) bool {
+	if p.signalingLimit == nil || p.signalingLimit.allow(time.Now()) {
+		return true
+	}
+
+	a.mux.Lock()
+	a.rejections.SignalingRate++
+	a.mux.Unlock()
+
+	return false
+}
+
+func (a *admission) getRejections() RejectionStats {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	return a.rejections
+}
+
+// refuse answers a refused connection request with its status and a message explaining the refusal
+func refuse(w http.ResponseWriter, err *admissionError) {
+	if err.status == http.StatusTooManyRequests || err.status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+
+	http.Error(w, err.message, err.status)
+}
@@ -0,0 +1,197 @@
+package coordinator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func makeAdmissionRequest(remoteAddr string, origin string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/connect", nil)
+	r.RemoteAddr = remoteAddr
+
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+
+	return r
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(2, 2, now)
+
+	require.True(t, b.allow(now))
+	require.True(t, b.allow(now))
+	require.False(t, b.allow(now))
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, b.allow(now))
+	require.False(t, b.allow(now))
+
+	// NOTE: the tokens never exceed the burst
+	now = now.Add(time.Minute)
+	require.True(t, b.allow(now))
+	require.True(t, b.allow(now))
+	require.False(t, b.allow(now))
+}
+
+func TestAdmission(t *testing.T) {
+	t.Run("origin", func(t *testing.T) {
+		a := newAdmission(AdmissionConfig{AllowedOrigins: []string{"https://play.example.com"}})
+
+		_, refusal := a.admit(protocol.Role_CLIENT, makeAdmissionRequest("1.1.1.1:1000", "https://play.example.com"))
+		require.Nil(t, refusal)
+
+		_, refusal = a.admit(protocol.Role_CLIENT, makeAdmissionRequest("1.1.1.1:1000", "https://evil.example.com"))
+		require.Equal(t, errOriginNotAllowed, refusal)
+		require.Equal(t, uint64(1), a.getRejections().Origin)
+	})
+
+	t.Run("connection caps", func(t *testing.T) {
+		a := newAdmission(AdmissionConfig{MaxConnections: 3, MaxConnectionsPerIP: 2})
+
+		release1, refusal := a.admit(protocol.Role_CLIENT, makeAdmissionRequest("1.1.1.1:1000", ""))
+		require.Nil(t, refusal)
+
+		_, refusal = a.admit(protocol.Role_CLIENT, makeAdmissionRequest("1.1.1.1:1001", ""))
+		require.Nil(t, refusal)
+
+		_, refusal = a.admit(protocol.Role_CLIENT, makeAdmissionRequest("1.1.1.1:1002", ""))
+		require.Equal(t, errTooManyConnections, refusal)
+
+		_, refusal = a.admit(protocol.Role_CLIENT, makeAdmissionRequest("2.2.2.2:1000", ""))
+		require.Nil(t, refusal)
+
+		_, refusal = a.admit(protocol.Role_CLIENT, makeAdmissionRequest("3.3.3.3:1000", ""))
+		require.Equal(t, errCoordinatorFull, refusal)
+
+		// NOTE: servers are not counted
+		_, refusal = a.admit(protocol.Role_COMMUNICATION_SERVER, makeAdmissionRequest("3.3.3.3:1000", ""))
+		require.Nil(t, refusal)
+
+		release1()
+		release1()
+
+		_, refusal = a.admit(protocol.Role_CLIENT, makeAdmissionRequest("1.1.1.1:1003", ""))
+		require.Nil(t, refusal)
+
+		_, refusal = a.admit(protocol.Role_CLIENT, makeAdmissionRequest("3.3.3.3:1000", ""))
+		require.Equal(t, errCoordinatorFull, refusal)
+
+		rejections := a.getRejections()
+		require.Equal(t, uint64(1), rejections.ConnectionsPerIP)
+		require.Equal(t, uint64(2), rejections.Capacity)
+	})
+
+	t.Run("handshake rate", func(t *testing.T) {
+		a := newAdmission(AdmissionConfig{HandshakeRate: 0.001, HandshakeBurst: 2, RealIPHeader: "X-Forwarded-For"})
+
+		r := makeAdmissionRequest("10.0.0.1:1000", "")
+		r.Header.Set("X-Forwarded-For", "6.6.6.6, 1.1.1.1")
+
+		for i := 0; i < 2; i++ {
+			_, refusal := a.admit(protocol.Role_COMMUNICATION_SERVER, r)
+			require.Nil(t, refusal)
+		}
+
+		_, refusal := a.admit(protocol.Role_COMMUNICATION_SERVER, r)
+		require.Equal(t, errTooManyHandshakes, refusal)
+
+		// NOTE: the address is the last one in the header
+		r.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+		_, refusal = a.admit(protocol.Role_COMMUNICATION_SERVER, r)
+		require.Nil(t, refusal)
+
+		require.Equal(t, uint64(1), a.getRejections().HandshakeRate)
+	})
+}
+
+func TestRegisterRefusal(t *testing.T) {
+	state := MakeState(&Config{
+		ServerSelector: makeDefaultServerSelector(),
+		Admission:      AdmissionConfig{AllowedOrigins: []string{"https://play.example.com"}},
+	})
+	defer closeState(state)
+
+	mux := http.NewServeMux()
+	Register(state, mux)
+
+	r := makeAdmissionRequest("1.1.1.1:1000", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, "origin not allowed\n", w.Body.String())
+}
+
+func TestSignalingRate(t *testing.T) {
+	state := MakeState(&Config{
+		ServerSelector: makeDefaultServerSelector(),
+		Admission:      AdmissionConfig{SignalingRate: 0.001, SignalingBurst: 1},
+	})
+	defer closeState(state)
+
+	msg := &protocol.WebRtcMessage{Type: protocol.MessageType_WEBRTC_OFFER, ToAlias: 2}
+	encodedMsg, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	conn := &MockWebsocket{}
+	conn.
+		On("Close").Return(nil).Once().
+		On("ReadMessage").Return(encodedMsg, nil).Times(3).
+		On("ReadMessage").Return([]byte{}, errors.New("stop")).Once().
+		On("SetReadLimit", mock.Anything).Return(nil).Once().
+		On("SetReadDeadline", mock.Anything).Return(nil).Once().
+		On("SetPongHandler", mock.Anything).Once()
+
+	p := makePeer(state, conn, protocol.Role_CLIENT)
+	p.Alias = 1
+	p.signalingLimit = state.admission.signalingLimit(protocol.Role_CLIENT)
+
+	go readPump(state, p)
+
+	<-state.unregister
+
+	require.Len(t, state.signalingQueue, 1)
+	require.Equal(t, uint64(2), state.admission.getRejections().SignalingRate)
+}
+
+func TestServerSignalingLimit(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		a := newAdmission(AdmissionConfig{SignalingRate: 0.001, SignalingBurst: 1})
+
+		limit := a.signalingLimit(protocol.Role_COMMUNICATION_SERVER)
+		require.NotNil(t, limit)
+
+		for i := 0; i < 10; i++ {
+			require.True(t, limit.allow(time.Now()))
+		}
+
+		require.False(t, limit.allow(time.Now()))
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		a := newAdmission(AdmissionConfig{SignalingRate: 0.001, SignalingBurst: 1, ServerSignalingRate: 0.001,
+			ServerSignalingBurst: 2})
+
+		limit := a.signalingLimit(protocol.Role_COMMUNICATION_SERVER_HUB)
+		require.True(t, limit.allow(time.Now()))
+		require.True(t, limit.allow(time.Now()))
+		require.False(t, limit.allow(time.Now()))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		a := newAdmission(AdmissionConfig{})
+
+		require.Nil(t, a.signalingLimit(protocol.Role_COMMUNICATION_SERVER))
+	})
+}
@@ -0,0 +1,179 @@
+package coordinator
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const defaultHashRingReplicas = 100
+
+// IAffinityServerSelector is an optional IServerSelector extension, for clients connecting with an affinity
+// key, see ClientOptions.AffinityKey
+type IAffinityServerSelector interface {
+	GetAffinityServerAliasList(forRole protocol.Role, labels map[string]string, key string) []uint64
+}
+
+type ringPoint struct {
+	hash  uint64
+	alias uint64
+}
+
+// ConsistentHashServerSelectorConfig is the ConsistentHashServerSelector config
+type ConsistentHashServerSelectorConfig struct {
+	// Selector provides the candidate servers, which are then ranked by the affinity key. A
+	// DefaultServerSelector by default
+	Selector IServerSelector
+
+	// Replicas is the number of points each server has in the hash ring, 100 by default. More points
+	// spread the keys more evenly
+	Replicas int
+}
+
+// ConsistentHashServerSelector ranks the servers for clients with an affinity key (a scene, a room or the
+// client identity) walking a consistent hash ring from the key, so clients with the same key are sent to the
+// same server, and only the keys of a server are remapped when it joins or leaves. Servers the underlying
+// selector doesn't return (e.g. full servers for the LoadAwareServerSelector) are skipped, so their keys move
+// to the next server in the ring
+type ConsistentHashServerSelector struct {
+	selector IServerSelector
+	replicas int
+	ring     []ringPoint
+}
+
+// NewConsistentHashServerSelector creates a ConsistentHashServerSelector
+func NewConsistentHashServerSelector(config ConsistentHashServerSelectorConfig) *ConsistentHashServerSelector {
+	selector := config.Selector
+	if selector == nil {
+		selector = &DefaultServerSelector{ServerAliases: make(map[uint64]bool)}
+	}
+
+	replicas := config.Replicas
+	if replicas == 0 {
+		replicas = defaultHashRingReplicas
+	}
+
+	return &ConsistentHashServerSelector{
+		selector: selector,
+		replicas: replicas,
+	}
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return h.Sum64()
+}
+
+func (r *ConsistentHashServerSelector) addToRing(alias uint64) {
+	prefix := strconv.FormatUint(alias, 10) + "#"
+
+	for i := 0; i < r.replicas; i++ {
+		r.ring = append(r.ring, ringPoint{hash: hashKey(prefix + strconv.Itoa(i)), alias: alias})
+	}
+
+	sort.Slice(r.ring, func(i, j int) bool { return r.ring[i].hash < r.ring[j].hash })
+}
+
+// ServerRegistered register a new server
+func (r *ConsistentHashServerSelector) ServerRegistered(role protocol.Role, alias uint64) {
+	r.addToRing(alias)
+	r.selector.ServerRegistered(role, alias)
+}
+
+// LabeledServerRegistered register a new server, forwarding the labels to the underlying selector if it's
+// label aware
+func (r *ConsistentHashServerSelector) LabeledServerRegistered(role protocol.Role, alias uint64,
+	labels map[string]string) {
+	selector, ok := r.selector.(ILabelAwareServerSelector)
+	if !ok {
+		r.ServerRegistered(role, alias)
+		return
+	}
+
+	r.addToRing(alias)
+	selector.LabeledServerRegistered(role, alias, labels)
+}
+
+// ServerUnregistered removes an unregistered server from the list
+func (r *ConsistentHashServerSelector) ServerUnregistered(alias uint64) {
+	ring := r.ring[:0]
+
+	for _, point := range r.ring {
+		if point.alias != alias {
+			ring = append(ring, point)
+		}
+	}
+
+	r.ring = ring
+	r.selector.ServerUnregistered(alias)
+}
+
+// ServerLoadReported forwards the load report to the underlying selector, if it's load aware
+func (r *ConsistentHashServerSelector) ServerLoadReported(alias uint64, report *protocol.LoadReportMessage) {
+	if selector, ok := r.selector.(ILoadAwareServerSelector); ok {
+		selector.ServerLoadReported(alias, report)
+	}
+}
+
+// GetServerAliasList returns the underlying selector list
+func (r *ConsistentHashServerSelector) GetServerAliasList(role protocol.Role) []uint64 {
+	return r.selector.GetServerAliasList(role)
+}
+
+// GetLabeledServerAliasList returns the underlying selector list, taking the labels into account if it's
+// label aware
+func (r *ConsistentHashServerSelector) GetLabeledServerAliasList(role protocol.Role,
+	labels map[string]string) []uint64 {
+	if selector, ok := r.selector.(ILabelAwareServerSelector); ok {
+		return selector.GetLabeledServerAliasList(role, labels)
+	}
+
+	return r.selector.GetServerAliasList(role)
+}
+
+// GetAffinityServerAliasList returns the underlying selector list for clients ranked by the ring order from
+// the key, the list is not changed for servers
+func (r *ConsistentHashServerSelector) GetAffinityServerAliasList(role protocol.Role, labels map[string]string,
+	key string) []uint64 {
+	servers := r.GetLabeledServerAliasList(role, labels)
+
+	if role != protocol.Role_CLIENT || key == "" || len(servers) < 2 {
+		return servers
+	}
+
+	candidates := make(map[uint64]bool, len(servers))
+	for _, alias := range servers {
+		candidates[alias] = true
+	}
+
+	hash := hashKey(key)
+	start := sort.Search(len(r.ring), func(i int) bool { return r.ring[i].hash >= hash })
+
+	ranked := make([]uint64, 0, len(servers))
+
+	for i := 0; i < len(r.ring) && len(ranked) < len(servers); i++ {
+		point := r.ring[(start+i)%len(r.ring)]
+		if candidates[point.alias] {
+			ranked = append(ranked, point.alias)
+			delete(candidates, point.alias)
+		}
+	}
+
+	// NOTE: the servers the ring doesn't know about keep the underlying selector order
+	for _, alias := range servers {
+		if candidates[alias] {
+			ranked = append(ranked, alias)
+		}
+	}
+
+	return ranked
+}
+
+// GetServerCount return amount of servers registered
+func (r *ConsistentHashServerSelector) GetServerCount() int {
+	return r.selector.GetServerCount()
+}
@@ -0,0 +1,104 @@
+package coordinator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestConsistentHashServerSelector(t *testing.T) {
+	selector := NewConsistentHashServerSelector(ConsistentHashServerSelectorConfig{
+		Selector: NewLoadAwareServerSelector(0),
+	})
+
+	for alias := uint64(1); alias <= 4; alias++ {
+		selector.ServerRegistered(protocol.Role_COMMUNICATION_SERVER, alias)
+	}
+
+	first := func(key string) uint64 {
+		return selector.GetAffinityServerAliasList(protocol.Role_CLIENT, nil, key)[0]
+	}
+
+	keys := make(map[string]uint64)
+	for i := 0; i < 1000; i++ {
+		key := "scene:" + strconv.Itoa(i)
+		keys[key] = first(key)
+	}
+
+	t.Run("same key, same server", func(t *testing.T) {
+		list := selector.GetAffinityServerAliasList(protocol.Role_CLIENT, nil, "scene:1")
+		require.Len(t, list, 4)
+		require.Equal(t, list, selector.GetAffinityServerAliasList(protocol.Role_CLIENT, nil, "scene:1"))
+
+		servers := make(map[uint64]bool)
+		for _, alias := range keys {
+			servers[alias] = true
+		}
+
+		require.Len(t, servers, 4)
+	})
+
+	t.Run("servers", func(t *testing.T) {
+		list := selector.GetAffinityServerAliasList(protocol.Role_COMMUNICATION_SERVER, nil, "scene:1")
+		require.Equal(t, []uint64{1, 2, 3, 4}, list)
+	})
+
+	t.Run("server joins", func(t *testing.T) {
+		selector.ServerRegistered(protocol.Role_COMMUNICATION_SERVER, 5)
+		defer selector.ServerUnregistered(5)
+
+		for key, alias := range keys {
+			if current := first(key); current != alias {
+				require.Equal(t, uint64(5), current)
+			}
+		}
+	})
+
+	t.Run("full server", func(t *testing.T) {
+		selector.ServerLoadReported(2, &protocol.LoadReportMessage{PeerCount: 10, MaxPeers: 10})
+		defer selector.ServerLoadReported(2, &protocol.LoadReportMessage{PeerCount: 0, MaxPeers: 10})
+
+		for key, alias := range keys {
+			if alias != 2 {
+				require.Equal(t, alias, first(key))
+			} else {
+				require.NotEqual(t, uint64(2), first(key))
+			}
+		}
+	})
+
+	t.Run("server leaves", func(t *testing.T) {
+		selector.ServerUnregistered(3)
+		require.Equal(t, 3, selector.GetServerCount())
+
+		for key, alias := range keys {
+			if alias != 3 {
+				require.Equal(t, alias, first(key))
+			}
+		}
+	})
+}
+
+func TestRegisterClientWithAffinity(t *testing.T) {
+	selector := NewConsistentHashServerSelector(ConsistentHashServerSelectorConfig{})
+	state := MakeState(&Config{ServerSelector: selector})
+	defer closeState(state)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, registerCommServer(state, makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)))
+	}
+
+	expected := selector.GetAffinityServerAliasList(protocol.Role_CLIENT, nil, "room1")
+
+	c := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+	c.affinityKey = "room1"
+	require.NoError(t, registerClient(state, c))
+
+	welcomeMessage := &protocol.WelcomeMessage{}
+	require.NoError(t, proto.Unmarshal(<-c.sendCh, welcomeMessage))
+	require.Equal(t, expected, welcomeMessage.AvailableServers)
+}
@@ -0,0 +1,196 @@
+package coordinator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	defaultSyncPeriod = 1 * time.Second
+	relayPollPeriod   = 50 * time.Millisecond
+	// instanceTimeoutFactor is how many sync periods an instance can miss before its peers are removed
+	instanceTimeoutFactor = 5
+)
+
+func generateInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format(time.RFC3339Nano)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func nextAlias(state *State) (uint64, error) {
+	alias, err := state.store.NextAlias()
+	if err != nil {
+		state.log.Error().Err(err).Msg("cannot allocate peer alias")
+		return 0, err
+	}
+
+	state.LastPeerAlias = alias
+
+	return alias, nil
+}
+
+// claimAlias gives the peer the alias it asks for, if the alias was allocated before and nobody holds it
+func claimAlias(state *State, p *Peer, alias uint64) bool {
+	record := peerRecord(state, p)
+	record.Alias = alias
+
+	claimed, err := state.store.ClaimAlias(record)
+	if err != nil {
+		state.log.Error().Err(err).Uint64("alias", alias).Msg("cannot claim peer alias")
+		return false
+	}
+
+	return claimed
+}
+
+func peerRecord(state *State, p *Peer) PeerRecord {
+	return PeerRecord{
+		Alias:       p.Alias,
+		Role:        p.role,
+		Instance:    state.instanceID,
+		FallbackURL: p.fallbackURL,
+		Labels:      p.labels,
+		Load:        p.load,
+		LoadTime:    p.loadTime,
+		Identity:    p.identity,
+	}
+}
+
+func putPeer(state *State, p *Peer) {
+	if err := state.store.PutPeer(peerRecord(state, p)); err != nil {
+		state.log.Error().Err(err).Uint64("alias", p.Alias).Msg("cannot store peer")
+	}
+}
+
+// relay forwards a signaling message to the instance the recipient is attached to, if it's not a local peer
+func relay(state *State, inMsg *inMessage) {
+	record, err := state.store.GetPeer(inMsg.toAlias)
+	if err != nil {
+		state.log.Error().Err(err).Msg("cannot lookup peer")
+		return
+	}
+
+	if record == nil || record.Instance == state.instanceID {
+		state.log.Debug().Uint64("to", inMsg.toAlias).Msg("dropping message to unknown peer")
+		return
+	}
+
+	msg := RelayedMessage{From: inMsg.from.Alias, FromRole: inMsg.from.role, To: inMsg.toAlias, Bytes: inMsg.bytes}
+	if err := state.store.Relay(record.Instance, msg); err != nil {
+		state.log.Error().Err(err).Str("instance", record.Instance).Msg("cannot relay message")
+	}
+}
+
+// deliverRelayed delivers the messages relayed by other instances to the local peers, the ones the signaling
+// policy doesn't allow are dropped
+func deliverRelayed(state *State) {
+	messages, err := state.store.Receive(state.instanceID)
+	if err != nil {
+		state.log.Error().Err(err).Msg("cannot receive relayed messages")
+		return
+	}
+
+	for _, msg := range messages {
+		p := state.Peers[msg.To]
+		if p == nil || p.isClosed() {
+			continue
+		}
+
+		if !allowRelayed(msg, p) {
+			relayedSignalingPolicyViolated(state, msg)
+			continue
+		}
+
+		if err := p.write(msg.Bytes); err != nil {
+			state.log.Debug().Err(err).Uint64("peer", p.Alias).Msg("cannot deliver relayed message")
+		}
+	}
+}
+
+// syncCluster records this instance is alive, removes the instances that are not, updates the server selector
+// with the servers attached to other instances, and applies their revocations
+func syncCluster(state *State) {
+	log := state.log
+	now := time.Now()
+
+	if err := state.store.Heartbeat(state.instanceID, now); err != nil {
+		log.Error().Err(err).Msg("cannot record heartbeat")
+		return
+	}
+
+	instances, err := state.store.Instances()
+	if err != nil {
+		log.Error().Err(err).Msg("cannot list instances")
+		return
+	}
+
+	for instance, at := range instances {
+		if instance != state.instanceID && now.Sub(at) > instanceTimeoutFactor*state.syncPeriod {
+			log.Info().Str("instance", instance).Msg("removing dead coordinator instance")
+
+			if err := state.store.RemoveInstance(instance); err != nil {
+				log.Error().Err(err).Msg("cannot remove instance")
+			}
+		}
+	}
+
+	servers, err := state.store.ListServers()
+	if err != nil {
+		log.Error().Err(err).Msg("cannot list servers")
+		return
+	}
+
+	remoteServers := make(map[uint64]*PeerRecord)
+
+	for i := range servers {
+		s := &servers[i]
+		if s.Instance == state.instanceID {
+			continue
+		}
+
+		remoteServers[s.Alias] = s
+
+		known, ok := state.remoteServers[s.Alias]
+		if !ok {
+			if selector, ok := state.serverSelector.(ILabelAwareServerSelector); ok {
+				selector.LabeledServerRegistered(s.Role, s.Alias, s.Labels)
+			} else {
+				state.serverSelector.ServerRegistered(s.Role, s.Alias)
+			}
+		}
+
+		// NOTE: only new reports are forwarded, so the selector can tell when they are stale
+		isNewLoad := s.Load != nil && (!ok || s.LoadTime.After(known.LoadTime))
+		if selector, ok := state.serverSelector.(ILoadAwareServerSelector); ok && isNewLoad {
+			selector.ServerLoadReported(s.Alias, s.Load)
+		}
+	}
+
+	for alias := range state.remoteServers {
+		if _, ok := remoteServers[alias]; !ok {
+			state.serverSelector.ServerUnregistered(alias)
+		}
+	}
+
+	state.remoteServers = remoteServers
+
+	syncRevocations(state)
+}
+
+// getFallbackURL returns the fallback url of a local or remote server
+func getFallbackURL(state *State, alias uint64) string {
+	if s := state.Peers[alias]; s != nil {
+		return s.fallbackURL
+	}
+
+	if s := state.remoteServers[alias]; s != nil {
+		return s.FallbackURL
+	}
+
+	return ""
+}
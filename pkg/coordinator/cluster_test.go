@@ -0,0 +1,128 @@
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestCluster(t *testing.T) {
+	store := NewMemoryStore()
+
+	makeInstance := func(id string) *State {
+		return MakeState(&Config{
+			ServerSelector: NewLoadAwareServerSelector(0),
+			Store:          store,
+			InstanceID:     id,
+		})
+	}
+
+	a := makeInstance("a")
+	defer closeState(a)
+
+	b := makeInstance("b")
+	defer closeState(b)
+
+	s := makePeer(a, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s.fallbackURL = "wss://server/fallback"
+	require.NoError(t, registerCommServer(a, s))
+	<-s.sendCh
+
+	reportLoad(a, &loadReport{from: s, report: &protocol.LoadReportMessage{PeerCount: 1, MaxPeers: 10}})
+
+	syncCluster(a)
+	syncCluster(b)
+
+	t.Run("remote servers", func(t *testing.T) {
+		require.Equal(t, 1, b.serverSelector.GetServerCount())
+
+		c := makePeer(b, &MockWebsocket{}, protocol.Role_CLIENT)
+		require.NoError(t, registerClient(b, c))
+		require.NotEqual(t, s.Alias, c.Alias)
+
+		welcomeMessage := &protocol.WelcomeMessage{}
+		require.NoError(t, proto.Unmarshal(<-c.sendCh, welcomeMessage))
+		require.Equal(t, []uint64{s.Alias}, welcomeMessage.AvailableServers)
+		require.Equal(t, "wss://server/fallback", welcomeMessage.FallbackEndpoints[0].Url)
+	})
+
+	t.Run("relay", func(t *testing.T) {
+		c := makePeer(b, &MockWebsocket{}, protocol.Role_CLIENT)
+		require.NoError(t, registerClient(b, c))
+		<-c.sendCh
+
+		signal(b, &inMessage{msgType: protocol.MessageType_CONNECT, from: c, bytes: []byte("connect"), toAlias: s.Alias})
+		require.Equal(t, s.Alias, c.serverAlias)
+
+		deliverRelayed(a)
+		require.Equal(t, []byte("connect"), <-s.sendCh)
+
+		signal(a, &inMessage{msgType: protocol.MessageType_WEBRTC_OFFER, from: s, bytes: []byte("offer"), toAlias: c.Alias})
+		deliverRelayed(b)
+		require.Equal(t, []byte("offer"), <-c.sendCh)
+
+		// servers can only signal the remote clients that contacted them
+		other := makePeer(b, &MockWebsocket{}, protocol.Role_CLIENT)
+		require.NoError(t, registerClient(b, other))
+		<-other.sendCh
+
+		offer := &inMessage{msgType: protocol.MessageType_WEBRTC_OFFER, from: s, bytes: []byte("offer"), toAlias: other.Alias}
+		signal(a, offer)
+		deliverRelayed(b)
+		require.Len(t, other.sendCh, 0)
+		require.Equal(t, uint64(1), b.signalingViolations)
+
+		// messages to unknown peers are dropped
+		signal(a, &inMessage{msgType: protocol.MessageType_WEBRTC_OFFER, from: s, bytes: []byte("offer"), toAlias: 1000})
+		deliverRelayed(b)
+		require.Len(t, c.sendCh, 0)
+	})
+
+	t.Run("revocations", func(t *testing.T) {
+		conn := &MockWebsocket{}
+		conn.On("Close").Return(nil).Once()
+
+		c := makePeer(b, conn, protocol.Role_CLIENT)
+		c.identity = []byte("user1")
+		require.NoError(t, registerClient(b, c))
+		<-c.sendCh
+
+		require.Equal(t, 0, revokeIdentity([]byte("user1"), time.Now().Add(time.Minute))(a))
+
+		revokeMessage := &protocol.RevokeMessage{}
+		require.NoError(t, proto.Unmarshal(<-s.sendCh, revokeMessage))
+		require.Equal(t, []byte("user1"), revokeMessage.Identity)
+
+		syncCluster(b)
+		require.True(t, c.isClosed())
+		require.True(t, isRevoked(b, []byte("user1")))
+		conn.AssertExpectations(t)
+	})
+
+	t.Run("server leaves", func(t *testing.T) {
+		unregister(a, s)
+		syncCluster(b)
+		require.Equal(t, 0, b.serverSelector.GetServerCount())
+	})
+
+	t.Run("dead instance", func(t *testing.T) {
+		s := makePeer(a, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+		require.NoError(t, registerCommServer(a, s))
+		<-s.sendCh
+
+		syncCluster(b)
+		require.Equal(t, 1, b.serverSelector.GetServerCount())
+
+		require.NoError(t, store.Heartbeat("a", time.Now().Add(-time.Minute)))
+		syncCluster(b)
+		require.Equal(t, 0, b.serverSelector.GetServerCount())
+
+		instances, err := store.Instances()
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+	})
+}
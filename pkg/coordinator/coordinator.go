@@ -5,6 +5,8 @@ import (
 	"errors"
 	"net/http"
 	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/decentraland/webrtc-broker/internal/logging"
@@ -24,11 +26,15 @@ const (
 type Stats struct {
 	ServerCount int
 	ClientCount int
+	Rejections  RejectionStats
 }
 
 // ErrUnauthorized indicates that a peer is not authorized for the role
 var ErrUnauthorized = errors.New("unauthorized")
 
+// ErrPeerClosed indicates that the peer connection is closed
+var ErrPeerClosed = errors.New("peer closed")
+
 // IServerSelector is in charge of tracking and processing the server list
 type IServerSelector interface {
 	ServerRegistered(role protocol.Role, alias uint64)
@@ -82,16 +88,41 @@ type inMessage struct {
 	from    *Peer
 	bytes   []byte
 	toAlias uint64
+	refusal *protocol.ConnectionRefusedMessage
+}
+
+type loadReport struct {
+	from   *Peer
+	report *protocol.LoadReportMessage
 }
 
 // Peer represents any peer, both server and clients
 type Peer struct {
-	Alias    uint64
-	conn     ws.IWebsocket
-	sendCh   chan []byte
-	isClosed bool
-	role     protocol.Role
-	log      logging.Logger
+	Alias       uint64
+	conn        ws.IWebsocket
+	sendCh      chan []byte
+	closeMux    sync.Mutex
+	closed      bool
+	role        protocol.Role
+	connectedAt time.Time
+	serverAlias uint64
+	fallbackURL string
+	identity    []byte
+	labels      map[string]string
+	affinityKey string
+	load        *protocol.LoadReportMessage
+	loadTime    time.Time
+	log         logging.Logger
+
+	// requestedAlias is the alias a reconnecting server had before
+	requestedAlias uint64
+	// signalingLimit is the peer signaling rate limit, see AdmissionConfig
+	signalingLimit *tokenBucket
+	// release frees the connection admission, once the connection is closed
+	release func()
+	// offeredServers are the servers a client can signal, contactedServers the servers that can signal it
+	offeredServers   map[uint64]bool
+	contactedServers map[uint64]bool
 }
 
 // State represent the state of the coordinator
@@ -104,13 +135,33 @@ type State struct {
 	reporter       func(stats Stats)
 	reportPeriod   time.Duration
 
+	requireServerCertificate bool
+	ticketSigner             *authentication.TicketSigner
+
+	store         Store
+	instanceID    string
+	syncPeriod    time.Duration
+	remoteServers map[uint64]*PeerRecord
+
+	admission *admission
+	ice       ICEConfig
+
 	LastPeerAlias uint64
 
 	Peers              map[uint64]*Peer
+	unselectable       map[uint64]bool
+	fullServers        map[uint64]time.Time
+	fullServerCooldown time.Duration
+
+	signalingViolations uint64
+
+	revoked            map[string]time.Time
 	registerCommServer chan *Peer
 	registerClient     chan *Peer
 	unregister         chan *Peer
 	signalingQueue     chan *inMessage
+	loadReportQueue    chan *loadReport
+	adminQueue         chan *adminRequest
 	stop               chan bool
 	softStop           bool
 }
@@ -122,6 +173,32 @@ type Config struct {
 	Auth           authentication.CoordinatorAuthenticator
 	Reporter       func(stats Stats)
 	ReportPeriod   time.Duration
+
+	// RequireServerCertificate rejects server connections without a verified TLS client certificate, the
+	// http server has to be configured to request them, see ws.ServerTLSConfig
+	RequireServerCertificate bool
+
+	// TicketSigner issues connection tickets when a peer is introduced to a server, so the server can verify
+	// the peer alias, role and identity, see authentication.TicketVerifier
+	TicketSigner *authentication.TicketSigner
+
+	// Store is shared by the coordinator instances of a cluster, a private MemoryStore by default
+	Store Store
+	// InstanceID identifies this instance in the store, a random id by default
+	InstanceID string
+	// SyncPeriod is how often the instance records its heartbeat and updates the servers attached to other
+	// instances, 1 second by default
+	SyncPeriod time.Duration
+
+	// Admission limits the connections and the signaling messages accepted by Register, see AdmissionConfig
+	Admission AdmissionConfig
+
+	// FullServerCooldown is how long a server that refused a client because it was full is not advertised,
+	// unless it reports it has room before, 30 seconds by default
+	FullServerCooldown time.Duration
+
+	// ICE are the STUN and TURN servers handed out to the peers, see ICEConfig
+	ICE ICEConfig
 }
 
 // MakeState creates a new CoordinatorState
@@ -138,6 +215,21 @@ func MakeState(config *Config) *State {
 		reportPeriod = defaultReportPeriod
 	}
 
+	store := config.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	instanceID := config.InstanceID
+	if instanceID == "" {
+		instanceID = generateInstanceID()
+	}
+
+	syncPeriod := config.SyncPeriod
+	if syncPeriod == 0 {
+		syncPeriod = defaultSyncPeriod
+	}
+
 	var log logging.Logger
 	if config.Log == nil {
 		log = logging.New()
@@ -145,29 +237,56 @@ func MakeState(config *Config) *State {
 		log = *config.Log
 	}
 
+	allowedOrigins := config.Admission.AllowedOrigins
+
+	fullServerCooldown := config.FullServerCooldown
+	if fullServerCooldown == 0 {
+		fullServerCooldown = defaultFullServerCooldown
+	}
+
+	ice := config.ICE
+	if ice.TURNCredentialTTL == 0 {
+		ice.TURNCredentialTTL = defaultTURNCredentialTTL
+	}
+
 	return &State{
-		serverSelector:     serverSelector,
-		reporter:           config.Reporter,
-		reportPeriod:       reportPeriod,
-		upgrader:           ws.MakeUpgrader(),
-		auth:               config.Auth,
-		marshaller:         &protocol.Marshaller{},
-		log:                log,
-		Peers:              make(map[uint64]*Peer),
-		registerCommServer: make(chan *Peer, 255),
-		registerClient:     make(chan *Peer, 255),
-		unregister:         make(chan *Peer, 255),
-		signalingQueue:     make(chan *inMessage, 255),
-		stop:               make(chan bool),
+		serverSelector:           serverSelector,
+		reporter:                 config.Reporter,
+		reportPeriod:             reportPeriod,
+		requireServerCertificate: config.RequireServerCertificate,
+		ticketSigner:             config.TicketSigner,
+		store:                    store,
+		instanceID:               instanceID,
+		syncPeriod:               syncPeriod,
+		remoteServers:            make(map[uint64]*PeerRecord),
+		admission:                newAdmission(config.Admission),
+		ice:                      ice,
+		upgrader:                 ws.MakeUpgraderWithOptions(ws.UpgraderOptions{AllowedOrigins: allowedOrigins}),
+		auth:                     config.Auth,
+		marshaller:               &protocol.Marshaller{},
+		log:                      log,
+		Peers:                    make(map[uint64]*Peer),
+		unselectable:             make(map[uint64]bool),
+		fullServers:              make(map[uint64]time.Time),
+		fullServerCooldown:       fullServerCooldown,
+		revoked:                  make(map[string]time.Time),
+		registerCommServer:       make(chan *Peer, 255),
+		registerClient:           make(chan *Peer, 255),
+		unregister:               make(chan *Peer, 255),
+		signalingQueue:           make(chan *inMessage, 255),
+		loadReportQueue:          make(chan *loadReport, 255),
+		adminQueue:               make(chan *adminRequest, 255),
+		stop:                     make(chan bool),
 	}
 }
 
 func makePeer(state *State, conn ws.IWebsocket, role protocol.Role) *Peer {
 	return &Peer{
-		conn:   conn,
-		sendCh: make(chan []byte, 256),
-		role:   role,
-		log:    state.log,
+		conn:        conn,
+		sendCh:      make(chan []byte, 256),
+		role:        role,
+		connectedAt: time.Now(),
+		log:         state.log,
 	}
 }
 
@@ -180,6 +299,18 @@ func (p *Peer) send(state *State, msg protocol.Message) error {
 		return err
 	}
 
+	return p.write(bytes)
+}
+
+// write queues the bytes to be sent to the peer, unless the peer is closed
+func (p *Peer) write(bytes []byte) error {
+	p.closeMux.Lock()
+	defer p.closeMux.Unlock()
+
+	if p.closed {
+		return ErrPeerClosed
+	}
+
 	p.sendCh <- bytes
 
 	return nil
@@ -235,20 +366,36 @@ func (p *Peer) writePump(state *State) {
 	}
 }
 
+// close closes the peer connection, it's called both from the peer read pump and from the control loop
 func (p *Peer) close() {
-	if !p.isClosed {
+	p.closeMux.Lock()
+	defer p.closeMux.Unlock()
+
+	if !p.closed {
 		if err := p.conn.Close(); err != nil {
 			p.log.Debug().Err(err).Msg("error closing peer")
 		}
 
 		close(p.sendCh)
-		p.isClosed = true
+		p.closed = true
 	}
 }
 
+func (p *Peer) isClosed() bool {
+	p.closeMux.Lock()
+	defer p.closeMux.Unlock()
+
+	return p.closed
+}
+
 func readPump(state *State, p *Peer) {
 	defer func() {
 		p.close()
+
+		if p.release != nil {
+			p.release()
+		}
+
 		state.unregister <- p
 	}()
 
@@ -270,7 +417,6 @@ func readPump(state *State, p *Peer) {
 	header := &protocol.CoordinatorMessage{}
 	webRtcMessage := &protocol.WebRtcMessage{}
 	connectMessage := &protocol.ConnectMessage{}
-	connectionRefusedMessage := &protocol.ConnectionRefusedMessage{}
 
 	for {
 		bytes, err := p.conn.ReadMessage()
@@ -291,6 +437,11 @@ func readPump(state *State, p *Peer) {
 
 		msgType := header.GetType()
 
+		if isSignalingMessage(msgType) && !state.admission.allowSignaling(p) {
+			log.Debug().Uint64("peer", p.Alias).Msg("signaling rate exceeded, dropping message")
+			continue
+		}
+
 		switch msgType {
 		case protocol.MessageType_WEBRTC_OFFER, protocol.MessageType_WEBRTC_ANSWER, protocol.MessageType_WEBRTC_ICE_CANDIDATE:
 			bytes, err = repackageWebRtcMessage(state, p, bytes, webRtcMessage)
@@ -311,6 +462,17 @@ func readPump(state *State, p *Peer) {
 			}
 
 			connectMessage.FromAlias = p.Alias
+			connectMessage.Ticket = nil
+
+			if state.ticketSigner != nil {
+				ticket, err := state.ticketSigner.Sign(p.Alias, connectMessage.ToAlias, p.role, p.identity)
+				if err != nil {
+					log.Error().Err(err).Msg("cannot sign connection ticket")
+					continue
+				}
+
+				connectMessage.Ticket = ticket
+			}
 
 			bytes, err := marshaller.Marshal(connectMessage)
 			if err != nil {
@@ -325,12 +487,16 @@ func readPump(state *State, p *Peer) {
 				toAlias: connectMessage.ToAlias,
 			}
 		case protocol.MessageType_CONNECTION_REFUSED:
+			// NOTE: the message is kept by the coordinator loop, so it's not reused
+			connectionRefusedMessage := &protocol.ConnectionRefusedMessage{}
 			if err := marshaller.Unmarshal(bytes, connectionRefusedMessage); err != nil {
 				log.Debug().Err(err).Msg("decode connection refused connection message failure")
 				continue
 			}
 
 			connectionRefusedMessage.FromAlias = p.Alias
+			connectionRefusedMessage.AvailableServers = nil
+			connectionRefusedMessage.FallbackEndpoints = nil
 
 			bytes, err := marshaller.Marshal(connectionRefusedMessage)
 			if err != nil {
@@ -343,7 +509,21 @@ func readPump(state *State, p *Peer) {
 				from:    p,
 				bytes:   bytes,
 				toAlias: connectionRefusedMessage.ToAlias,
+				refusal: connectionRefusedMessage,
 			}
+		case protocol.MessageType_LOAD_REPORT:
+			if p.role == protocol.Role_CLIENT {
+				log.Debug().Msg("ignoring load report from client")
+				continue
+			}
+
+			report := &protocol.LoadReportMessage{}
+			if err := marshaller.Unmarshal(bytes, report); err != nil {
+				log.Debug().Err(err).Msg("decode load report message failure")
+				continue
+			}
+
+			state.loadReportQueue <- &loadReport{from: p, report: report}
 		default:
 			log.Debug().Str("type", msgType.String()).Msg("unhandled message")
 		}
@@ -352,17 +532,52 @@ func readPump(state *State, p *Peer) {
 
 // UpgradeRequest upgrades a HTTP request to ws protocol and authenticates for the role
 func UpgradeRequest(state *State, role protocol.Role, w http.ResponseWriter, r *http.Request) (ws.IWebsocket, error) {
-	isValid, err := state.auth.AuthenticateFromURL(role, r)
+	conn, _, err := upgradeRequest(state, role, w, r)
+	return conn, err
+}
+
+// upgradeRequest is UpgradeRequest returning the peer identity, if the authenticator provides it
+func upgradeRequest(state *State, role protocol.Role, w http.ResponseWriter,
+	r *http.Request) (ws.IWebsocket, []byte, error) {
+	if role != protocol.Role_CLIENT && state.requireServerCertificate &&
+		(r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+		return nil, nil, ErrUnauthorized
+	}
+
+	var (
+		isValid  bool
+		identity []byte
+		err      error
+	)
+
+	if auth, ok := state.auth.(authentication.CoordinatorIdentityAuthenticator); ok {
+		isValid, identity, err = auth.AuthenticateIdentityFromURL(role, r)
+	} else {
+		isValid, err = state.auth.AuthenticateFromURL(role, r)
+	}
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if !isValid {
-		return nil, ErrUnauthorized
+		return nil, nil, ErrUnauthorized
 	}
 
-	return state.upgrader.Upgrade(w, r)
+	conn, err := state.upgrader.Upgrade(w, r)
+
+	return conn, identity, err
+}
+
+func isSignalingMessage(msgType protocol.MessageType) bool {
+	switch msgType {
+	case protocol.MessageType_WEBRTC_OFFER, protocol.MessageType_WEBRTC_ANSWER,
+		protocol.MessageType_WEBRTC_ICE_CANDIDATE, protocol.MessageType_CONNECT,
+		protocol.MessageType_CONNECTION_REFUSED:
+		return true
+	default:
+		return false
+	}
 }
 
 func closeState(state *State) {
@@ -370,15 +585,55 @@ func closeState(state *State) {
 	close(state.registerCommServer)
 	close(state.unregister)
 	close(state.signalingQueue)
+	close(state.loadReportQueue)
+	// NOTE: adminQueue is not closed, the admin api may still be serving, requests select on stop instead
 	close(state.stop)
 }
 
+// ServerOptions are the optional communication server connection parameters
+type ServerOptions struct {
+	// FallbackURL is the websocket url clients can use to reach the server when webrtc is not available
+	FallbackURL string
+	// Identity is the authenticated server identity, included in its connection tickets
+	Identity []byte
+	// Labels describe where the server is, e.g. its region or pool, see LabelAwareServerSelector
+	Labels map[string]string
+	// Alias is the alias the server had before reconnecting, it's kept if nobody else holds it
+	Alias uint64
+
+	release func()
+}
+
+// ClientOptions are the optional client connection parameters
+type ClientOptions struct {
+	// Identity is the authenticated client identity, included in its connection tickets
+	Identity []byte
+	// Labels are the labels the client wants its servers to have, see LabelAwareServerSelector
+	Labels map[string]string
+	// AffinityKey groups the clients that should share a server, e.g. a scene or a room. The client identity is
+	// used if empty, see ConsistentHashServerSelector
+	AffinityKey string
+
+	release func()
+}
+
 // ConnectCommServer establish a ws connection to a communication server
 func ConnectCommServer(state *State, conn ws.IWebsocket, role protocol.Role) {
+	ConnectCommServerWithOptions(state, conn, role, ServerOptions{})
+}
+
+// ConnectCommServerWithOptions establish a ws connection to a communication server
+func ConnectCommServerWithOptions(state *State, conn ws.IWebsocket, role protocol.Role, options ServerOptions) {
 	log := state.log
 	log.Info().Msg("socket connect (server)")
 
 	p := makePeer(state, conn, role)
+	p.fallbackURL = options.FallbackURL
+	p.identity = options.Identity
+	p.labels = options.Labels
+	p.requestedAlias = options.Alias
+	p.signalingLimit = state.admission.signalingLimit(role)
+	p.release = options.release
 	state.registerCommServer <- p
 
 	go readPump(state, p)
@@ -388,10 +643,25 @@ func ConnectCommServer(state *State, conn ws.IWebsocket, role protocol.Role) {
 
 // ConnectClient establish a ws connection to a client
 func ConnectClient(state *State, conn ws.IWebsocket) {
+	ConnectClientWithOptions(state, conn, ClientOptions{})
+}
+
+// ConnectClientWithOptions establish a ws connection to a client
+func ConnectClientWithOptions(state *State, conn ws.IWebsocket, options ClientOptions) {
 	log := state.log
 	log.Info().Msg("socket connect (client)")
 
 	p := makePeer(state, conn, protocol.Role_CLIENT)
+	p.identity = options.Identity
+	p.labels = options.Labels
+	p.affinityKey = options.AffinityKey
+	p.signalingLimit = state.admission.signalingLimit(protocol.Role_CLIENT)
+	p.release = options.release
+
+	if p.affinityKey == "" {
+		p.affinityKey = string(options.Identity)
+	}
+
 	state.registerClient <- p
 
 	go readPump(state, p)
@@ -403,8 +673,16 @@ func ConnectClient(state *State, conn ws.IWebsocket) {
 func Start(state *State) {
 	log := state.log
 	ticker := time.NewTicker(state.reportPeriod)
+	syncTicker := time.NewTicker(state.syncPeriod)
+	relayTicker := time.NewTicker(relayPollPeriod)
+
+	defer func() {
+		ticker.Stop()
+		syncTicker.Stop()
+		relayTicker.Stop()
+	}()
 
-	defer ticker.Stop()
+	syncCluster(state)
 
 	ignoreError := func(err error) {
 		if err != nil {
@@ -446,19 +724,41 @@ func Start(state *State) {
 				inMsg = <-state.signalingQueue
 				signal(state, inMsg)
 			}
+		case r := <-state.loadReportQueue:
+			reportLoad(state, r)
+
+			n := len(state.loadReportQueue)
+			for i := 0; i < n; i++ {
+				r = <-state.loadReportQueue
+				reportLoad(state, r)
+			}
+		case req := <-state.adminQueue:
+			req.reply <- req.exec(state)
+		case <-syncTicker.C:
+			syncCluster(state)
+		case <-relayTicker.C:
+			deliverRelayed(state)
 		case <-ticker.C:
 			if state.reporter != nil {
 				serverCount := state.serverSelector.GetServerCount()
-				clientCount := len(state.Peers) - serverCount
+				clientCount := len(state.Peers) - (serverCount - len(state.remoteServers))
 
 				stats := Stats{
 					ServerCount: serverCount,
 					ClientCount: clientCount,
+					Rejections:  state.admission.getRejections(),
 				}
+				stats.Rejections.SignalingPolicy = state.signalingViolations
+
 				state.reporter(stats)
 			}
 		case <-state.stop:
 			log.Debug().Msg("stop signal")
+
+			if err := state.store.RemoveInstance(state.instanceID); err != nil {
+				log.Error().Err(err).Msg("cannot remove instance from the store")
+			}
+
 			return
 		}
 
@@ -482,60 +782,156 @@ func Register(state *State, mux *http.ServeMux) {
 			role = protocol.Role_COMMUNICATION_SERVER_HUB
 		}
 
-		ws, err := UpgradeRequest(state, role, w, r)
+		release, refusal := state.admission.admit(role, r)
+		if refusal != nil {
+			state.log.Info().Err(refusal).Str("remoteAddr", r.RemoteAddr).Msg("socket connect refused (discovery)")
+			refuse(w, refusal)
+
+			return
+		}
+
+		labels, err := ParseLabels(qs.Get("labels"))
+		if err != nil {
+			state.log.Error().Err(err).Msg("socket connect error (discovery)")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		var alias uint64
+
+		if s := qs.Get("alias"); s != "" {
+			if alias, err = strconv.ParseUint(s, 10, 64); err != nil {
+				state.log.Error().Err(err).Msg("socket connect error (discovery)")
+				http.Error(w, "invalid alias", http.StatusBadRequest)
+
+				return
+			}
+		}
+
+		ws, identity, err := upgradeRequest(state, role, w, r)
 
 		if err != nil {
 			state.log.Error().Err(err).Msg("socket connect error (discovery)")
 			return
 		}
 
-		ConnectCommServer(state, ws, role)
+		options := ServerOptions{
+			FallbackURL: qs.Get("fallbackURL"),
+			Identity:    identity,
+			Labels:      labels,
+			Alias:       alias,
+			release:     release,
+		}
+
+		ConnectCommServerWithOptions(state, ws, role, options)
 	})
 
 	mux.HandleFunc("/connect", func(w http.ResponseWriter, r *http.Request) {
-		ws, err := UpgradeRequest(state, protocol.Role_CLIENT, w, r)
+		qs := r.URL.Query()
+
+		release, refusal := state.admission.admit(protocol.Role_CLIENT, r)
+		if refusal != nil {
+			state.log.Info().Err(refusal).Str("remoteAddr", r.RemoteAddr).Msg("socket connect refused (client)")
+			refuse(w, refusal)
+
+			return
+		}
+
+		labels, err := ParseLabels(qs.Get("labels"))
+		if err != nil {
+			release()
+			state.log.Error().Err(err).Msg("socket connect error (client)")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		ws, identity, err := upgradeRequest(state, protocol.Role_CLIENT, w, r)
 
 		if err != nil {
+			release()
 			state.log.Error().Err(err).Msg("socket connect error (client)")
+
 			return
 		}
 
-		ConnectClient(state, ws)
+		options := ClientOptions{
+			Identity:    identity,
+			Labels:      labels,
+			AffinityKey: qs.Get("affinity"),
+			release:     release,
+		}
+
+		ConnectClientWithOptions(state, ws, options)
 	})
 }
 
 func registerCommServer(state *State, p *Peer) error {
-	state.LastPeerAlias++
-	alias := state.LastPeerAlias
+	if isRevoked(state, p.identity) {
+		p.close()
+		return ErrRevoked
+	}
+
+	alias := p.requestedAlias
+
+	if alias == 0 || !claimAlias(state, p, alias) {
+		var err error
+
+		if alias, err = nextAlias(state); err != nil {
+			p.close()
+			return err
+		}
+	}
+
 	p.Alias = alias
 
-	servers := state.serverSelector.GetServerAliasList(p.role)
+	servers := getServerAliasList(state, p)
 
 	state.Peers[alias] = p
-	state.serverSelector.ServerRegistered(p.role, p.Alias)
+	serverRegistered(state, p)
+	putPeer(state, p)
 
 	msg := &protocol.WelcomeMessage{
 		Type:             protocol.MessageType_WELCOME,
 		Alias:            alias,
 		AvailableServers: servers,
+		IceServers:       getICEServers(state, alias),
+	}
+
+	if err := p.send(state, msg); err != nil {
+		return err
 	}
 
-	return p.send(state, msg)
+	return sendRevocations(state, p)
 }
 
 func registerClient(state *State, p *Peer) error {
-	state.LastPeerAlias++
-	alias := state.LastPeerAlias
+	if isRevoked(state, p.identity) {
+		p.close()
+		return ErrRevoked
+	}
+
+	alias, err := nextAlias(state)
+	if err != nil {
+		p.close()
+		return err
+	}
+
 	p.Alias = alias
 
-	servers := state.serverSelector.GetServerAliasList(p.role)
+	servers := filterSelectable(state, getServerAliasList(state, p))
+	offerServers(p, servers)
 
 	state.Peers[alias] = p
+	putPeer(state, p)
 
 	msg := &protocol.WelcomeMessage{
-		Type:             protocol.MessageType_WELCOME,
-		Alias:            alias,
-		AvailableServers: servers,
+		Type:              protocol.MessageType_WELCOME,
+		Alias:             alias,
+		AvailableServers:  servers,
+		FallbackEndpoints: getFallbackEndpoints(state, p, servers),
+		IceServers:        getICEServers(state, alias),
 	}
 
 	if err := p.send(state, msg); err != nil {
@@ -546,8 +942,60 @@ func registerClient(state *State, p *Peer) error {
 	return nil
 }
 
+// getFallbackEndpoints returns the fallback endpoints for the client, with a connection ticket for each one
+// since fallback connections are not introduced through a connect message
+func getFallbackEndpoints(state *State, client *Peer, servers []uint64) []*protocol.FallbackEndpoint {
+	var endpoints []*protocol.FallbackEndpoint
+
+	for _, alias := range servers {
+		fallbackURL := getFallbackURL(state, alias)
+		if fallbackURL == "" {
+			continue
+		}
+
+		endpoint := &protocol.FallbackEndpoint{Alias: alias, Url: fallbackURL}
+
+		if state.ticketSigner != nil {
+			ticket, err := state.ticketSigner.Sign(client.Alias, alias, client.role, client.identity)
+			if err != nil {
+				state.log.Error().Err(err).Msg("cannot sign fallback connection ticket")
+				continue
+			}
+
+			endpoint.Ticket = ticket
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints
+}
+
+func filterSelectable(state *State, servers []uint64) []uint64 {
+	if len(state.unselectable) == 0 && len(state.fullServers) == 0 {
+		return servers
+	}
+
+	now := time.Now()
+	selectable := make([]uint64, 0, len(servers))
+
+	for _, alias := range servers {
+		if !state.unselectable[alias] && !isServerFull(state, alias, now) {
+			selectable = append(selectable, alias)
+		}
+	}
+
+	return selectable
+}
+
 func unregister(state *State, p *Peer) {
 	delete(state.Peers, p.Alias)
+	delete(state.unselectable, p.Alias)
+	delete(state.fullServers, p.Alias)
+
+	if err := state.store.DeletePeer(p.Alias); err != nil {
+		state.log.Error().Err(err).Uint64("alias", p.Alias).Msg("cannot remove peer from the store")
+	}
 
 	switch p.role {
 	case protocol.Role_CLIENT:
@@ -560,12 +1008,53 @@ func unregister(state *State, p *Peer) {
 	}
 }
 
+func reportLoad(state *State, r *loadReport) {
+	// NOTE: the server may be already unregistered
+	if _, ok := state.Peers[r.from.Alias]; !ok {
+		return
+	}
+
+	if selector, ok := state.serverSelector.(ILoadAwareServerSelector); ok {
+		selector.ServerLoadReported(r.from.Alias, r.report)
+	}
+
+	serverLoadReported(state, r.from.Alias, r.report)
+
+	r.from.load = r.report
+	r.from.loadTime = time.Now()
+	putPeer(state, r.from)
+}
+
 func signal(state *State, inMsg *inMessage) {
 	toAlias := inMsg.toAlias
 	p := state.Peers[toAlias]
 
-	if p != nil && !p.isClosed {
-		p.sendCh <- inMsg.bytes
+	if !allowSignal(inMsg, p) {
+		signalingPolicyViolated(state, inMsg)
+		return
+	}
+
+	if inMsg.msgType == protocol.MessageType_CONNECT && inMsg.from.role == protocol.Role_CLIENT {
+		inMsg.from.serverAlias = toAlias
+
+		if inMsg.from.contactedServers == nil {
+			inMsg.from.contactedServers = make(map[uint64]bool)
+		}
+
+		inMsg.from.contactedServers[toAlias] = true
+	}
+
+	if inMsg.refusal != nil && inMsg.from.role != protocol.Role_CLIENT {
+		serverRefused(state, inMsg, p)
+	}
+
+	if p == nil {
+		relay(state, inMsg)
+		return
+	}
+
+	if err := p.write(inMsg.bytes); err != nil {
+		state.log.Debug().Err(err).Uint64("peer", p.Alias).Msg("cannot forward message")
 	}
 }
 
@@ -1,14 +1,19 @@
 package coordinator
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
 	"errors"
 	"net/http"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
 
 	_testing "github.com/decentraland/webrtc-broker/internal/testing"
 	"github.com/decentraland/webrtc-broker/internal/ws"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
 	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/require"
@@ -95,6 +100,31 @@ func TestUpgradeRequest(t *testing.T) {
 		require.Equal(t, err, ErrUnauthorized)
 		upgrader.AssertExpectations(t)
 	})
+
+	t.Run("upgrade request (server certificate required)", func(t *testing.T) {
+		auth := &mockCoordinatorAuthenticator{}
+		config := Config{
+			ServerSelector:           makeDefaultServerSelector(),
+			Auth:                     auth,
+			RequireServerCertificate: true,
+		}
+
+		upgrader := &mockUpgrader{}
+		state := MakeState(&config)
+		state.upgrader = upgrader
+
+		req, err := http.NewRequest("GET", "/discover?method=fake", nil)
+		require.NoError(t, err)
+		_, err = UpgradeRequest(state, protocol.Role_COMMUNICATION_SERVER, nil, req)
+		require.Equal(t, err, ErrUnauthorized)
+
+		req.TLS = &tls.ConnectionState{}
+		_, err = UpgradeRequest(state, protocol.Role_COMMUNICATION_SERVER, nil, req)
+		require.Equal(t, err, ErrUnauthorized)
+
+		auth.AssertExpectations(t)
+		upgrader.AssertExpectations(t)
+	})
 }
 
 func TestReadPump(t *testing.T) {
@@ -175,6 +205,93 @@ func TestReadPump(t *testing.T) {
 		require.Equal(t, p, in.from)
 		require.Equal(t, uint64(2), in.toAlias)
 	})
+
+	t.Run("connect message (with ticket)", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		state := MakeState(&Config{
+			ServerSelector: makeDefaultServerSelector(),
+			TicketSigner:   authentication.NewTicketSigner(privateKey, 0),
+		})
+		defer closeState(state)
+
+		conn := &MockWebsocket{}
+		p := makePeer(state, conn, protocol.Role_CLIENT)
+		p.Alias = 1
+		p.identity = []byte("user1")
+
+		// NOTE: tickets sent by peers are discarded
+		msg := &protocol.ConnectMessage{
+			Type:    protocol.MessageType_CONNECT,
+			ToAlias: 2,
+			Ticket:  []byte("forged"),
+		}
+		encodedMsg, err := proto.Marshal(msg)
+		require.NoError(t, err)
+
+		conn.
+			On("Close").Return(nil).Once().
+			On("ReadMessage").Return(encodedMsg, nil).Once().
+			On("ReadMessage").Return([]byte{}, errors.New("stop")).Once().
+			On("SetReadLimit", mock.Anything).Return(nil).Once().
+			On("SetReadDeadline", mock.Anything).Return(nil).Once().
+			On("SetPongHandler", mock.Anything).Once()
+
+		go readPump(state, p)
+
+		<-state.unregister
+
+		in := <-state.signalingQueue
+
+		connectMessage := &protocol.ConnectMessage{}
+		require.NoError(t, proto.Unmarshal(in.bytes, connectMessage))
+		require.Equal(t, uint64(1), connectMessage.FromAlias)
+
+		ticket, err := authentication.NewTicketVerifier(publicKey).
+			VerifyPeer(connectMessage.Ticket, 1, 2, protocol.Role_CLIENT, nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("user1"), ticket.Identity)
+	})
+
+	t.Run("load report", func(t *testing.T) {
+		state := makeTestState()
+		defer closeState(state)
+
+		msg := &protocol.LoadReportMessage{
+			Type:      protocol.MessageType_LOAD_REPORT,
+			PeerCount: 10,
+			MaxPeers:  100,
+		}
+		encodedMsg, err := proto.Marshal(msg)
+		require.NoError(t, err)
+
+		for _, role := range []protocol.Role{protocol.Role_COMMUNICATION_SERVER, protocol.Role_CLIENT} {
+			conn := &MockWebsocket{}
+			p := makePeer(state, conn, role)
+			p.Alias = 1
+
+			conn.
+				On("Close").Return(nil).Once().
+				On("ReadMessage").Return(encodedMsg, nil).Once().
+				On("ReadMessage").Return([]byte{}, errors.New("stop")).Once().
+				On("SetReadLimit", mock.Anything).Return(nil).Once().
+				On("SetReadDeadline", mock.Anything).Return(nil).Once().
+				On("SetPongHandler", mock.Anything).Once()
+
+			go readPump(state, p)
+
+			<-state.unregister
+		}
+
+		// NOTE: client reports are ignored
+		require.Len(t, state.loadReportQueue, 1)
+
+		r := <-state.loadReportQueue
+		require.Equal(t, protocol.Role_COMMUNICATION_SERVER, r.from.role)
+		require.Equal(t, uint32(10), r.report.PeerCount)
+		require.Equal(t, uint32(100), r.report.MaxPeers)
+	})
 }
 
 func TestWritePump(t *testing.T) {
@@ -270,6 +387,33 @@ func TestConnectClient(t *testing.T) {
 	conn.AssertExpectations(t)
 }
 
+func TestPeerClose(t *testing.T) {
+	state := makeTestState()
+	defer closeState(state)
+
+	conn := &MockWebsocket{}
+	conn.On("Close").Return(nil).Once()
+	p := makePeer(state, conn, protocol.Role_CLIENT)
+
+	// NOTE: the read pump and the control loop may close the peer at the same time
+	var wg sync.WaitGroup
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			p.close()
+		}()
+	}
+
+	wg.Wait()
+
+	require.True(t, p.isClosed())
+	require.Equal(t, ErrPeerClosed, p.send(state, &protocol.PingMessage{Type: protocol.MessageType_PING}))
+	conn.AssertExpectations(t)
+}
+
 func TestRegisterCommServer(t *testing.T) {
 	state := makeTestState()
 	defer closeState(state)
@@ -308,6 +452,49 @@ func TestRegisterCommServer(t *testing.T) {
 	conn2.AssertExpectations(t)
 }
 
+func TestRegisterCommServerWithAlias(t *testing.T) {
+	state := makeTestState()
+	defer closeState(state)
+
+	s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	require.NoError(t, registerCommServer(state, s))
+	<-s.sendCh
+
+	other := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	require.NoError(t, registerCommServer(state, other))
+	<-other.sendCh
+
+	unregister(state, s)
+
+	t.Run("alias is kept", func(t *testing.T) {
+		reconnected := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+		reconnected.requestedAlias = s.Alias
+		require.NoError(t, registerCommServer(state, reconnected))
+
+		welcomeMessage := &protocol.WelcomeMessage{}
+		require.NoError(t, proto.Unmarshal(<-reconnected.sendCh, welcomeMessage))
+		require.Equal(t, s.Alias, welcomeMessage.Alias)
+		require.Equal(t, []uint64{other.Alias}, welcomeMessage.AvailableServers)
+		require.Equal(t, reconnected, state.Peers[s.Alias])
+	})
+
+	t.Run("alias in use", func(t *testing.T) {
+		p := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+		p.requestedAlias = other.Alias
+		require.NoError(t, registerCommServer(state, p))
+		<-p.sendCh
+		require.NotEqual(t, other.Alias, p.Alias)
+	})
+
+	t.Run("alias never allocated", func(t *testing.T) {
+		p := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+		p.requestedAlias = 1000
+		require.NoError(t, registerCommServer(state, p))
+		<-p.sendCh
+		require.NotEqual(t, uint64(1000), p.Alias)
+	})
+}
+
 func TestRegisterClient(t *testing.T) {
 	state := makeTestState()
 	defer closeState(state)
@@ -346,6 +533,87 @@ func TestRegisterClient(t *testing.T) {
 	conn2.AssertExpectations(t)
 }
 
+func TestRegisterClientWithFallback(t *testing.T) {
+	state := makeTestState()
+	defer closeState(state)
+
+	s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s.fallbackURL = "ws://server1/fallback"
+	s2 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+
+	state.registerCommServer <- s
+	state.registerCommServer <- s2
+
+	conn := &MockWebsocket{}
+	conn.On("Close").Return(nil).Once()
+	c := makePeer(state, conn, protocol.Role_CLIENT)
+
+	go Start(state)
+
+	<-s.sendCh
+	<-s2.sendCh
+
+	state.registerClient <- c
+
+	welcomeMessage := &protocol.WelcomeMessage{}
+
+	bytes := <-c.sendCh
+	require.NoError(t, proto.Unmarshal(bytes, welcomeMessage))
+	require.Len(t, welcomeMessage.AvailableServers, 2)
+	require.Len(t, welcomeMessage.FallbackEndpoints, 1)
+	require.Equal(t, s.Alias, welcomeMessage.FallbackEndpoints[0].Alias)
+	require.Equal(t, "ws://server1/fallback", welcomeMessage.FallbackEndpoints[0].Url)
+	require.Empty(t, welcomeMessage.FallbackEndpoints[0].Ticket)
+
+	state.stop <- true
+
+	c.close()
+
+	conn.AssertExpectations(t)
+}
+
+func TestRegisterClientWithFallbackTicket(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	state := MakeState(&Config{
+		ServerSelector: makeDefaultServerSelector(),
+		TicketSigner:   authentication.NewTicketSigner(privateKey, 0),
+	})
+	defer closeState(state)
+
+	s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s.fallbackURL = "ws://server1/fallback"
+
+	state.registerCommServer <- s
+
+	conn := &MockWebsocket{}
+	conn.On("Close").Return(nil).Once()
+	c := makePeer(state, conn, protocol.Role_CLIENT)
+
+	go Start(state)
+
+	<-s.sendCh
+
+	state.registerClient <- c
+
+	welcomeMessage := &protocol.WelcomeMessage{}
+
+	bytes := <-c.sendCh
+	require.NoError(t, proto.Unmarshal(bytes, welcomeMessage))
+	require.Len(t, welcomeMessage.FallbackEndpoints, 1)
+
+	_, err = authentication.NewTicketVerifier(publicKey).
+		VerifyPeer(welcomeMessage.FallbackEndpoints[0].Ticket, welcomeMessage.Alias, s.Alias, protocol.Role_CLIENT, nil)
+	require.NoError(t, err)
+
+	state.stop <- true
+
+	c.close()
+
+	conn.AssertExpectations(t)
+}
+
 func TestUnregister(t *testing.T) {
 	selector := makeDefaultServerSelector()
 
@@ -385,11 +653,11 @@ func TestSignaling(t *testing.T) {
 		defer closeState(state)
 
 		conn := &MockWebsocket{}
-		p := makePeer(state, conn, protocol.Role_CLIENT)
+		p := makePeer(state, conn, protocol.Role_COMMUNICATION_SERVER)
 		p.Alias = 1
 
 		conn2 := &MockWebsocket{}
-		p2 := makePeer(state, conn2, protocol.Role_CLIENT)
+		p2 := makePeer(state, conn2, protocol.Role_COMMUNICATION_SERVER)
 		p2.Alias = 2
 
 		state.Peers[p.Alias] = p
@@ -423,7 +691,7 @@ func TestSignaling(t *testing.T) {
 		defer closeState(state)
 
 		conn := &MockWebsocket{}
-		p := makePeer(state, conn, protocol.Role_CLIENT)
+		p := makePeer(state, conn, protocol.Role_COMMUNICATION_SERVER)
 		p.Alias = 1
 
 		state.Peers[p.Alias] = p
@@ -446,12 +714,12 @@ func TestSignaling(t *testing.T) {
 		defer closeState(state)
 
 		conn := &MockWebsocket{}
-		p := makePeer(state, conn, protocol.Role_CLIENT)
+		p := makePeer(state, conn, protocol.Role_COMMUNICATION_SERVER)
 		p.Alias = 1
 
 		conn2 := &MockWebsocket{}
 		conn2.On("Close").Return(nil).Once()
-		p2 := makePeer(state, conn2, protocol.Role_CLIENT)
+		p2 := makePeer(state, conn2, protocol.Role_COMMUNICATION_SERVER)
 		p2.Alias = 2
 		p2.close()
 
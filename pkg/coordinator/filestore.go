@@ -0,0 +1,243 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	fileStoreLockRetryPeriod = 5 * time.Millisecond
+	fileStoreLockTimeout     = 10 * time.Second
+)
+
+// FileStore is a Store persisted in a directory shared by the coordinator instances, meant for tests and small
+// deployments. Writes lock the directory and rewrite the whole state, reads don't lock. The directory has to be on
+// a filesystem supporting advisory locks (flock), it's not supported on windows
+type FileStore struct {
+	statePath string
+	lockPath  string
+}
+
+// NewFileStore creates a FileStore in dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		statePath: filepath.Join(dir, "state.json"),
+		lockPath:  filepath.Join(dir, "state.lock"),
+	}, nil
+}
+
+// lock takes the advisory lock of the lock file, waiting up to fileStoreLockTimeout. The lock is released by the
+// system if its holder dies, so it's never stolen from a live instance
+func (s *FileStore) lock() (*os.File, error) {
+	f, err := os.OpenFile(s.lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(fileStoreLockTimeout)
+
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		if locked {
+			return f, nil
+		}
+
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("cannot lock %s", s.lockPath)
+		}
+
+		time.Sleep(fileStoreLockRetryPeriod)
+	}
+}
+
+func (s *FileStore) unlock(f *os.File) {
+	_ = unlockFile(f)
+	_ = f.Close()
+}
+
+// read loads the state, without locking since the state is always replaced with a rename
+func (s *FileStore) read() (*storeData, error) {
+	data := newStoreData()
+
+	content, err := ioutil.ReadFile(s.statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// update runs fn with the state locked, the state is saved if fn returns true
+func (s *FileStore) update(fn func(data *storeData) bool) error {
+	f, err := s.lock()
+	if err != nil {
+		return err
+	}
+
+	defer s.unlock(f)
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if !fn(data) {
+		return nil
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: the state is replaced with a rename, so it's never read half written
+	tmpPath := s.statePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.statePath)
+}
+
+// NextAlias allocates a unique peer alias
+func (s *FileStore) NextAlias() (alias uint64, err error) {
+	err = s.update(func(data *storeData) bool {
+		alias = data.nextAlias()
+		return true
+	})
+
+	return alias, err
+}
+
+// ClaimAlias stores the peer under an already allocated alias, if no other peer holds it
+func (s *FileStore) ClaimAlias(record PeerRecord) (claimed bool, err error) {
+	err = s.update(func(data *storeData) bool {
+		claimed = data.claimAlias(record)
+		return claimed
+	})
+
+	return claimed, err
+}
+
+// Heartbeat records the instance is alive
+func (s *FileStore) Heartbeat(instance string, at time.Time) error {
+	return s.update(func(data *storeData) bool {
+		data.Instances[instance] = at
+		return true
+	})
+}
+
+// Instances returns the last heartbeat of every instance
+func (s *FileStore) Instances() (map[string]time.Time, error) {
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Instances, nil
+}
+
+// RemoveInstance removes the instance, its peers and its pending messages
+func (s *FileStore) RemoveInstance(instance string) error {
+	return s.update(func(data *storeData) bool {
+		data.removeInstance(instance)
+		return true
+	})
+}
+
+// PutPeer adds or replaces a peer
+func (s *FileStore) PutPeer(record PeerRecord) error {
+	return s.update(func(data *storeData) bool {
+		data.putPeer(record)
+		return true
+	})
+}
+
+// DeletePeer removes a peer
+func (s *FileStore) DeletePeer(alias uint64) error {
+	return s.update(func(data *storeData) bool {
+		delete(data.Peers, alias)
+		return true
+	})
+}
+
+// GetPeer returns the peer, nil if it's unknown
+func (s *FileStore) GetPeer(alias uint64) (*PeerRecord, error) {
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.getPeer(alias), nil
+}
+
+// ListServers returns every server, sorted by alias
+func (s *FileStore) ListServers() ([]PeerRecord, error) {
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.listServers(), nil
+}
+
+// Relay queues a message for the instance
+func (s *FileStore) Relay(instance string, msg RelayedMessage) error {
+	return s.update(func(data *storeData) bool {
+		data.Inboxes[instance] = append(data.Inboxes[instance], msg)
+		return true
+	})
+}
+
+// Receive returns and removes the messages queued for the instance, the state is only locked if there are any
+func (s *FileStore) Receive(instance string) (messages []RelayedMessage, err error) {
+	data, err := s.read()
+	if err != nil || len(data.Inboxes[instance]) == 0 {
+		return nil, err
+	}
+
+	err = s.update(func(data *storeData) bool {
+		messages = data.receive(instance)
+		return len(messages) > 0
+	})
+
+	return messages, err
+}
+
+// Revoke records a revoked identity, until the given time
+func (s *FileStore) Revoke(identity string, until time.Time) error {
+	return s.update(func(data *storeData) bool {
+		data.revoke(identity, until)
+		return true
+	})
+}
+
+// Revocations returns the active revocations
+func (s *FileStore) Revocations() (map[string]time.Time, error) {
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.revocations(), nil
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package coordinator
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLockFile takes the exclusive advisory lock of f without blocking, it returns false if another file holds it
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,16 @@
+package coordinator
+
+import (
+	"errors"
+	"os"
+)
+
+var errFileLockUnsupported = errors.New("file store locks are not supported on windows")
+
+func tryLockFile(f *os.File) (bool, error) {
+	return false, errFileLockUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return errFileLockUnsupported
+}
@@ -0,0 +1,68 @@
+package coordinator
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const defaultTURNCredentialTTL = 24 * time.Hour
+
+// ICEServer is a STUN or TURN server with static credentials
+type ICEServer struct {
+	URLs       []string
+	Username   string
+	Credential string
+}
+
+// ICEConfig is the ICE configuration handed out to every peer in the welcome message
+type ICEConfig struct {
+	// Servers are the STUN and TURN servers with static credentials, if any
+	Servers []ICEServer
+	// TURNURLs are the TURN servers sharing TURNSecret, every peer gets its own time limited credentials for
+	// them, following the TURN REST API (e.g. coturn's use-auth-secret)
+	TURNURLs   []string
+	TURNSecret string
+	// TURNCredentialTTL is how long the TURN credentials are valid, 24 hours by default
+	TURNCredentialTTL time.Duration
+}
+
+// makeTURNCredential returns the TURN REST API credential of a peer: the username is the expiration unix time
+// and the peer alias, the credential the base64 HMAC-SHA1 of the username with the shared secret
+func makeTURNCredential(secret string, alias uint64, expires time.Time) (username string, credential string) {
+	username = fmt.Sprintf("%d:%d", expires.Unix(), alias)
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	_, _ = mac.Write([]byte(username))
+
+	return username, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// getICEServers returns the ICE servers of a peer, with fresh TURN credentials
+func getICEServers(state *State, alias uint64) []*protocol.IceServer {
+	config := state.ice
+	iceServers := make([]*protocol.IceServer, 0, len(config.Servers)+1)
+
+	for _, s := range config.Servers {
+		iceServers = append(iceServers, &protocol.IceServer{
+			Urls:       s.URLs,
+			Username:   s.Username,
+			Credential: s.Credential,
+		})
+	}
+
+	if len(config.TURNURLs) > 0 {
+		username, credential := makeTURNCredential(config.TURNSecret, alias, time.Now().Add(config.TURNCredentialTTL))
+		iceServers = append(iceServers, &protocol.IceServer{
+			Urls:       config.TURNURLs,
+			Username:   username,
+			Credential: credential,
+		})
+	}
+
+	return iceServers
+}
@@ -0,0 +1,74 @@
+package coordinator
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestMakeTURNCredential(t *testing.T) {
+	expires := time.Unix(1600000000, 0)
+	username, credential := makeTURNCredential("secret", 5, expires)
+	require.Equal(t, "1600000000:5", username)
+
+	mac := hmac.New(sha1.New, []byte("secret"))
+	_, _ = mac.Write([]byte(username))
+	require.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), credential)
+
+	_, otherCredential := makeTURNCredential("other", 5, expires)
+	require.NotEqual(t, credential, otherCredential)
+}
+
+func TestWelcomeICEServers(t *testing.T) {
+	state := MakeState(&Config{
+		ServerSelector: makeDefaultServerSelector(),
+		ICE: ICEConfig{
+			Servers:           []ICEServer{{URLs: []string{"stun:stun.example.com"}}},
+			TURNURLs:          []string{"turn:turn.example.com", "turns:turn.example.com"},
+			TURNSecret:        "secret",
+			TURNCredentialTTL: time.Hour,
+		},
+	})
+	defer closeState(state)
+
+	s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	require.NoError(t, registerCommServer(state, s))
+
+	serverWelcome := &protocol.WelcomeMessage{}
+	require.NoError(t, proto.Unmarshal(<-s.sendCh, serverWelcome))
+	require.Len(t, serverWelcome.IceServers, 2)
+
+	c := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+	require.NoError(t, registerClient(state, c))
+
+	clientWelcome := &protocol.WelcomeMessage{}
+	require.NoError(t, proto.Unmarshal(<-c.sendCh, clientWelcome))
+	require.Len(t, clientWelcome.IceServers, 2)
+
+	stun := clientWelcome.IceServers[0]
+	require.Equal(t, []string{"stun:stun.example.com"}, stun.Urls)
+	require.Empty(t, stun.Username)
+
+	turn := clientWelcome.IceServers[1]
+	require.Equal(t, []string{"turn:turn.example.com", "turns:turn.example.com"}, turn.Urls)
+
+	var expires int64
+
+	var alias uint64
+
+	_, err := fmt.Sscanf(turn.Username, "%d:%d", &expires, &alias)
+	require.NoError(t, err)
+	require.Equal(t, c.Alias, alias)
+	require.InDelta(t, time.Now().Add(time.Hour).Unix(), expires, 5)
+
+	// NOTE: every peer gets its own credentials
+	require.NotEqual(t, turn.Credential, serverWelcome.IceServers[1].Credential)
+}
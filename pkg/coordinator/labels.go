@@ -0,0 +1,228 @@
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+// ILabelAwareServerSelector is an optional IServerSelector extension, for servers registering labels and
+// clients asking for them
+type ILabelAwareServerSelector interface {
+	LabeledServerRegistered(role protocol.Role, alias uint64, labels map[string]string)
+	GetLabeledServerAliasList(forRole protocol.Role, labels map[string]string) []uint64
+}
+
+// ParseLabels parses a comma separated list of key=value labels, as sent in the labels query string
+// parameter of /discover and /connect
+func ParseLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	labels := make(map[string]string)
+
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", pair)
+		}
+
+		labels[kv[0]] = kv[1]
+	}
+
+	return labels, nil
+}
+
+type labeledServer struct {
+	role   protocol.Role
+	labels map[string]string
+}
+
+// LabelAwareServerSelectorConfig is the LabelAwareServerSelector config
+type LabelAwareServerSelectorConfig struct {
+	// Selector ranks the servers, its list is then filtered and grouped by label. A DefaultServerSelector by
+	// default
+	Selector IServerSelector
+
+	// RequiredLabels are the label keys a server has to match when a client asks for them, the rest of the
+	// client labels are preferences
+	RequiredLabels []string
+
+	// MeshLabels are the label keys communication servers have to share to connect to each other, so each
+	// pool forms its own mesh. Hubs connect to every server
+	MeshLabels []string
+}
+
+// LabelAwareServerSelector filters and prefers servers by label. Clients get the servers matching all their
+// required labels, the ones matching more of the preferred labels first, falling back to the rest.
+// Communication servers get the servers in their pool, and the hubs
+type LabelAwareServerSelector struct {
+	selector       IServerSelector
+	servers        map[uint64]*labeledServer
+	requiredLabels []string
+	meshLabels     []string
+}
+
+// NewLabelAwareServerSelector creates a LabelAwareServerSelector
+func NewLabelAwareServerSelector(config LabelAwareServerSelectorConfig) *LabelAwareServerSelector {
+	selector := config.Selector
+	if selector == nil {
+		selector = &DefaultServerSelector{ServerAliases: make(map[uint64]bool)}
+	}
+
+	return &LabelAwareServerSelector{
+		selector:       selector,
+		servers:        make(map[uint64]*labeledServer),
+		requiredLabels: config.RequiredLabels,
+		meshLabels:     config.MeshLabels,
+	}
+}
+
+// ServerRegistered register a new server without labels
+func (r *LabelAwareServerSelector) ServerRegistered(role protocol.Role, alias uint64) {
+	r.LabeledServerRegistered(role, alias, nil)
+}
+
+// LabeledServerRegistered register a new server
+func (r *LabelAwareServerSelector) LabeledServerRegistered(role protocol.Role, alias uint64,
+	labels map[string]string) {
+	r.servers[alias] = &labeledServer{role: role, labels: labels}
+	r.selector.ServerRegistered(role, alias)
+}
+
+// ServerUnregistered removes an unregistered server from the list
+func (r *LabelAwareServerSelector) ServerUnregistered(alias uint64) {
+	delete(r.servers, alias)
+	r.selector.ServerUnregistered(alias)
+}
+
+// ServerLoadReported forwards the load report to the underlying selector, if it's load aware
+func (r *LabelAwareServerSelector) ServerLoadReported(alias uint64, report *protocol.LoadReportMessage) {
+	if selector, ok := r.selector.(ILoadAwareServerSelector); ok {
+		selector.ServerLoadReported(alias, report)
+	}
+}
+
+// GetServerAliasList returns the server list for a peer without labels
+func (r *LabelAwareServerSelector) GetServerAliasList(role protocol.Role) []uint64 {
+	return r.GetLabeledServerAliasList(role, nil)
+}
+
+// GetLabeledServerAliasList returns the server list for a peer with the given labels
+func (r *LabelAwareServerSelector) GetLabeledServerAliasList(role protocol.Role, labels map[string]string) []uint64 {
+	servers := r.selector.GetServerAliasList(role)
+
+	switch role {
+	case protocol.Role_CLIENT:
+		return r.selectForClient(servers, labels)
+	case protocol.Role_COMMUNICATION_SERVER:
+		return r.selectForServer(servers, labels)
+	default:
+		return servers
+	}
+}
+
+func (r *LabelAwareServerSelector) selectForClient(servers []uint64, labels map[string]string) []uint64 {
+	if len(labels) == 0 {
+		return servers
+	}
+
+	required := make(map[string]bool, len(r.requiredLabels))
+	for _, key := range r.requiredLabels {
+		required[key] = true
+	}
+
+	selected := make([]uint64, 0, len(servers))
+	score := make(map[uint64]int, len(servers))
+
+	for _, alias := range servers {
+		s, ok := r.servers[alias]
+		if !ok {
+			continue
+		}
+
+		matches := true
+
+		for key, value := range labels {
+			if s.labels[key] == value {
+				score[alias]++
+			} else if required[key] {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			selected = append(selected, alias)
+		}
+	}
+
+	// NOTE: stable, so the underlying selector ranking is kept among the servers with the same score
+	sort.SliceStable(selected, func(i, j int) bool { return score[selected[i]] > score[selected[j]] })
+
+	return selected
+}
+
+func (r *LabelAwareServerSelector) selectForServer(servers []uint64, labels map[string]string) []uint64 {
+	if len(r.meshLabels) == 0 {
+		return servers
+	}
+
+	selected := make([]uint64, 0, len(servers))
+
+	for _, alias := range servers {
+		s, ok := r.servers[alias]
+		if !ok {
+			continue
+		}
+
+		if s.role == protocol.Role_COMMUNICATION_SERVER_HUB || r.samePool(s.labels, labels) {
+			selected = append(selected, alias)
+		}
+	}
+
+	return selected
+}
+
+func (r *LabelAwareServerSelector) samePool(a map[string]string, b map[string]string) bool {
+	for _, key := range r.meshLabels {
+		if a[key] != b[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetServerCount return amount of servers registered
+func (r *LabelAwareServerSelector) GetServerCount() int {
+	return len(r.servers)
+}
+
+// getServerAliasList returns the server list for the peer, taking its affinity key and labels into account if
+// the selector supports them
+func getServerAliasList(state *State, p *Peer) []uint64 {
+	if p.affinityKey != "" {
+		if selector, ok := state.serverSelector.(IAffinityServerSelector); ok {
+			return selector.GetAffinityServerAliasList(p.role, p.labels, p.affinityKey)
+		}
+	}
+
+	if selector, ok := state.serverSelector.(ILabelAwareServerSelector); ok {
+		return selector.GetLabeledServerAliasList(p.role, p.labels)
+	}
+
+	return state.serverSelector.GetServerAliasList(p.role)
+}
+
+func serverRegistered(state *State, p *Peer) {
+	if selector, ok := state.serverSelector.(ILabelAwareServerSelector); ok {
+		selector.LabeledServerRegistered(p.role, p.Alias, p.labels)
+		return
+	}
+
+	state.serverSelector.ServerRegistered(p.role, p.Alias)
+}
@@ -0,0 +1,115 @@
+package coordinator
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels("region=eu,pool=realm1,empty=")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"region": "eu", "pool": "realm1", "empty": ""}, labels)
+
+	labels, err = ParseLabels("")
+	require.NoError(t, err)
+	require.Len(t, labels, 0)
+
+	_, err = ParseLabels("region")
+	require.Error(t, err)
+
+	_, err = ParseLabels("=eu")
+	require.Error(t, err)
+}
+
+func TestLabelAwareServerSelector(t *testing.T) {
+	selector := NewLabelAwareServerSelector(LabelAwareServerSelectorConfig{
+		RequiredLabels: []string{"pool"},
+		MeshLabels:     []string{"pool"},
+	})
+
+	selector.LabeledServerRegistered(protocol.Role_COMMUNICATION_SERVER, 1, map[string]string{"pool": "a", "region": "us"})
+	selector.LabeledServerRegistered(protocol.Role_COMMUNICATION_SERVER, 2, map[string]string{"pool": "a", "region": "eu"})
+	selector.LabeledServerRegistered(protocol.Role_COMMUNICATION_SERVER, 3, map[string]string{"pool": "b", "region": "eu"})
+	selector.ServerRegistered(protocol.Role_COMMUNICATION_SERVER, 4)
+	selector.ServerRegistered(protocol.Role_COMMUNICATION_SERVER_HUB, 5)
+
+	t.Run("clients", func(t *testing.T) {
+		require.Equal(t, []uint64{1, 2, 3, 4, 5}, selector.GetServerAliasList(protocol.Role_CLIENT))
+
+		list := selector.GetLabeledServerAliasList(protocol.Role_CLIENT, map[string]string{"region": "eu"})
+		require.Equal(t, []uint64{2, 3, 1, 4, 5}, list)
+
+		list = selector.GetLabeledServerAliasList(protocol.Role_CLIENT, map[string]string{"pool": "a", "region": "eu"})
+		require.Equal(t, []uint64{2, 1}, list)
+
+		list = selector.GetLabeledServerAliasList(protocol.Role_CLIENT, map[string]string{"pool": "c"})
+		require.Len(t, list, 0)
+	})
+
+	t.Run("servers", func(t *testing.T) {
+		list := selector.GetLabeledServerAliasList(protocol.Role_COMMUNICATION_SERVER, map[string]string{"pool": "a"})
+		require.Equal(t, []uint64{1, 2, 5}, list)
+
+		require.Equal(t, []uint64{4, 5}, selector.GetServerAliasList(protocol.Role_COMMUNICATION_SERVER))
+		require.Equal(t, []uint64{1, 2, 3, 4, 5}, selector.GetServerAliasList(protocol.Role_COMMUNICATION_SERVER_HUB))
+		require.Equal(t, 5, selector.GetServerCount())
+	})
+
+	t.Run("load aware", func(t *testing.T) {
+		selector := NewLabelAwareServerSelector(LabelAwareServerSelectorConfig{
+			Selector: NewLoadAwareServerSelector(0),
+		})
+
+		selector.LabeledServerRegistered(protocol.Role_COMMUNICATION_SERVER, 1, map[string]string{"region": "eu"})
+		selector.LabeledServerRegistered(protocol.Role_COMMUNICATION_SERVER, 2, map[string]string{"region": "eu"})
+		selector.LabeledServerRegistered(protocol.Role_COMMUNICATION_SERVER, 3, map[string]string{"region": "us"})
+
+		selector.ServerLoadReported(1, &protocol.LoadReportMessage{PeerCount: 9, MaxPeers: 10})
+		selector.ServerLoadReported(2, &protocol.LoadReportMessage{PeerCount: 1, MaxPeers: 10})
+		selector.ServerLoadReported(3, &protocol.LoadReportMessage{PeerCount: 0, MaxPeers: 10})
+
+		list := selector.GetLabeledServerAliasList(protocol.Role_CLIENT, map[string]string{"region": "eu"})
+		require.Equal(t, []uint64{2, 1, 3}, list)
+
+		selector.ServerUnregistered(2)
+		list = selector.GetLabeledServerAliasList(protocol.Role_CLIENT, map[string]string{"region": "eu"})
+		require.Equal(t, []uint64{1, 3}, list)
+	})
+}
+
+func TestRegisterWithLabels(t *testing.T) {
+	state := MakeState(&Config{
+		ServerSelector: NewLabelAwareServerSelector(LabelAwareServerSelectorConfig{MeshLabels: []string{"pool"}}),
+	})
+	defer closeState(state)
+
+	register := func(labels map[string]string) *Peer {
+		s := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+		s.labels = labels
+		require.NoError(t, registerCommServer(state, s))
+
+		return s
+	}
+
+	s1 := register(map[string]string{"pool": "a"})
+	register(map[string]string{"pool": "b"})
+	s3 := register(map[string]string{"pool": "a"})
+
+	welcomeMessage := &protocol.WelcomeMessage{}
+	require.NoError(t, proto.Unmarshal(<-s3.sendCh, welcomeMessage))
+	require.Equal(t, []uint64{s1.Alias}, welcomeMessage.AvailableServers)
+
+	c := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+	c.labels = map[string]string{"pool": "a"}
+	require.NoError(t, registerClient(state, c))
+
+	require.NoError(t, proto.Unmarshal(<-c.sendCh, welcomeMessage))
+	require.Equal(t, []uint64{s1.Alias, s3.Alias, 2}, welcomeMessage.AvailableServers)
+
+	servers := listServers(state).([]ServerInfo)
+	require.Equal(t, map[string]string{"pool": "a"}, servers[0].Labels)
+}
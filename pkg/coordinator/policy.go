@@ -0,0 +1,75 @@
+package coordinator
+
+import (
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+// offerServers records the servers offered to a client, the only ones it can signal
+func offerServers(client *Peer, servers []uint64) {
+	if client.offeredServers == nil {
+		client.offeredServers = make(map[uint64]bool, len(servers))
+	}
+
+	for _, alias := range servers {
+		client.offeredServers[alias] = true
+	}
+}
+
+// allowSignal is the signaling policy: clients only signal the servers they were offered, servers signal other
+// servers and the clients that contacted them.
+// NOTE: the clients attached to other coordinator instances are not known, their instance checks the messages
+// relayed to them, see allowRelayed
+func allowSignal(inMsg *inMessage, to *Peer) bool {
+	from := inMsg.from
+
+	if from.role == protocol.Role_CLIENT {
+		return from.offeredServers[inMsg.toAlias]
+	}
+
+	return allowServerSignal(from.Alias, to)
+}
+
+// allowRelayed is the signaling policy of the messages relayed by other instances, which already checked the
+// servers offered to their clients
+func allowRelayed(msg RelayedMessage, to *Peer) bool {
+	if msg.FromRole == protocol.Role_CLIENT {
+		return to.role != protocol.Role_CLIENT
+	}
+
+	return allowServerSignal(msg.From, to)
+}
+
+func allowServerSignal(from uint64, to *Peer) bool {
+	if to == nil || to.role != protocol.Role_CLIENT {
+		return true
+	}
+
+	return to.contactedServers[from]
+}
+
+// signalingPolicyViolated drops the peer that signaled a peer it's not allowed to
+func signalingPolicyViolated(state *State, inMsg *inMessage) {
+	state.signalingViolations++
+
+	state.log.Warn().
+		Uint64("peer", inMsg.from.Alias).
+		Str("role", inMsg.from.role.String()).
+		Str("type", inMsg.msgType.String()).
+		Uint64("to", inMsg.toAlias).
+		Msg("signaling policy violated, dropping peer")
+
+	// NOTE: the peer read pump may be closing it too, close is safe to call from both
+	inMsg.from.close()
+}
+
+// relayedSignalingPolicyViolated drops a message relayed by another instance, its sender is dropped by that
+// instance only if it violates the policy there
+func relayedSignalingPolicyViolated(state *State, msg RelayedMessage) {
+	state.signalingViolations++
+
+	state.log.Warn().
+		Uint64("peer", msg.From).
+		Str("role", msg.FromRole.String()).
+		Uint64("to", msg.To).
+		Msg("relayed signaling policy violated, dropping message")
+}
@@ -0,0 +1,116 @@
+package coordinator
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestSignalingPolicy(t *testing.T) {
+	state := makeTestState()
+	defer closeState(state)
+
+	s1 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	require.NoError(t, registerCommServer(state, s1))
+	<-s1.sendCh
+
+	c := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+	require.NoError(t, registerClient(state, c))
+
+	welcomeMessage := &protocol.WelcomeMessage{}
+	require.NoError(t, proto.Unmarshal(<-c.sendCh, welcomeMessage))
+	require.Equal(t, []uint64{s1.Alias}, welcomeMessage.AvailableServers)
+
+	// NOTE: s2 registers after the client was welcomed, so it was not offered to it
+	s2 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	require.NoError(t, registerCommServer(state, s2))
+	<-s2.sendCh
+
+	t.Run("servers signal other servers", func(t *testing.T) {
+		signal(state, &inMessage{msgType: protocol.MessageType_CONNECT, from: s2, bytes: []byte("connect"), toAlias: s1.Alias})
+		require.Equal(t, []byte("connect"), <-s1.sendCh)
+	})
+
+	t.Run("servers signal the clients that contacted them", func(t *testing.T) {
+		signal(state, &inMessage{msgType: protocol.MessageType_CONNECT, from: c, bytes: []byte("connect"), toAlias: s1.Alias})
+		require.Equal(t, []byte("connect"), <-s1.sendCh)
+
+		signal(state, &inMessage{msgType: protocol.MessageType_WEBRTC_OFFER, from: s1, bytes: []byte("offer"), toAlias: c.Alias})
+		require.Equal(t, []byte("offer"), <-c.sendCh)
+
+		signal(state, &inMessage{msgType: protocol.MessageType_WEBRTC_ANSWER, from: c, bytes: []byte("answer"), toAlias: s1.Alias})
+		require.Equal(t, []byte("answer"), <-s1.sendCh)
+	})
+
+	t.Run("servers cannot signal other clients", func(t *testing.T) {
+		conn := &MockWebsocket{}
+		conn.On("Close").Return(nil).Once()
+
+		s := makePeer(state, conn, protocol.Role_COMMUNICATION_SERVER)
+		s.Alias = 100
+		state.Peers[s.Alias] = s
+
+		signal(state, &inMessage{msgType: protocol.MessageType_WEBRTC_OFFER, from: s, bytes: []byte("offer"), toAlias: c.Alias})
+		require.Len(t, c.sendCh, 0)
+		require.True(t, s.isClosed())
+		conn.AssertExpectations(t)
+	})
+
+	t.Run("clients only signal the servers they were offered", func(t *testing.T) {
+		conn := &MockWebsocket{}
+		conn.On("Close").Return(nil).Once()
+
+		c := makePeer(state, conn, protocol.Role_CLIENT)
+		c.Alias = 101
+		offerServers(c, []uint64{s1.Alias})
+		state.Peers[c.Alias] = c
+
+		signal(state, &inMessage{msgType: protocol.MessageType_CONNECT, from: c, bytes: []byte("connect"), toAlias: s2.Alias})
+		require.Len(t, s2.sendCh, 0)
+		require.True(t, c.isClosed())
+		conn.AssertExpectations(t)
+	})
+
+	t.Run("clients cannot signal other clients", func(t *testing.T) {
+		conn := &MockWebsocket{}
+		conn.On("Close").Return(nil).Once()
+
+		other := makePeer(state, conn, protocol.Role_CLIENT)
+		other.Alias = 102
+		offerServers(other, []uint64{s1.Alias})
+		state.Peers[other.Alias] = other
+
+		signal(state, &inMessage{msgType: protocol.MessageType_WEBRTC_OFFER, from: other, bytes: []byte("offer"), toAlias: c.Alias})
+		require.Len(t, c.sendCh, 0)
+		require.True(t, other.isClosed())
+		conn.AssertExpectations(t)
+	})
+
+	t.Run("peer closed by its read pump at the same time", func(t *testing.T) {
+		conn := &MockWebsocket{}
+		conn.On("Close").Return(nil).Once()
+
+		other := makePeer(state, conn, protocol.Role_CLIENT)
+		other.Alias = 103
+		state.Peers[other.Alias] = other
+
+		closed := make(chan struct{})
+
+		go func() {
+			other.close()
+			close(closed)
+		}()
+
+		offer := &inMessage{msgType: protocol.MessageType_WEBRTC_OFFER, from: other, bytes: []byte("offer"), toAlias: c.Alias}
+		signal(state, offer)
+		<-closed
+		require.Len(t, c.sendCh, 0)
+		require.True(t, other.isClosed())
+		conn.AssertExpectations(t)
+	})
+
+	require.Equal(t, uint64(4), state.signalingViolations)
+}
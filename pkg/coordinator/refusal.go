@@ -0,0 +1,75 @@
+package coordinator
+
+import (
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const defaultFullServerCooldown = 30 * time.Second
+
+// serverRefused handles a server refusing a client connection. A full server is not advertised for a while, and
+// the refusal sent to a local client lists the other servers the client can connect to, so it can retry right away.
+// NOTE: the full servers are tracked per instance, the other instances rely on the server load reports
+func serverRefused(state *State, inMsg *inMessage, client *Peer) {
+	refusal := inMsg.refusal
+	server := inMsg.from
+
+	if refusal.Reason != protocol.ConnectionRefusedReason_SERVER_FULL {
+		return
+	}
+
+	state.log.Info().Uint64("server", server.Alias).Msg("server is full, not advertising it for a while")
+	state.fullServers[server.Alias] = time.Now().Add(state.fullServerCooldown)
+
+	if client == nil || client.role != protocol.Role_CLIENT {
+		return
+	}
+
+	if client.serverAlias == server.Alias {
+		client.serverAlias = 0
+	}
+
+	servers := make([]uint64, 0)
+
+	for _, alias := range filterSelectable(state, getServerAliasList(state, client)) {
+		if alias != server.Alias {
+			servers = append(servers, alias)
+		}
+	}
+
+	offerServers(client, servers)
+
+	refusal.AvailableServers = servers
+	refusal.FallbackEndpoints = getFallbackEndpoints(state, client, servers)
+
+	bytes, err := state.marshaller.Marshal(refusal)
+	if err != nil {
+		state.log.Error().Err(err).Msg("cannot encode refused connection message")
+		return
+	}
+
+	inMsg.bytes = bytes
+}
+
+// isServerFull returns true if the server refused a client because it was full, during the cooldown
+func isServerFull(state *State, alias uint64, now time.Time) bool {
+	until, ok := state.fullServers[alias]
+	if !ok {
+		return false
+	}
+
+	if now.After(until) {
+		delete(state.fullServers, alias)
+		return false
+	}
+
+	return true
+}
+
+// serverLoadReported advertises a full server again as soon as it reports it has room
+func serverLoadReported(state *State, alias uint64, report *protocol.LoadReportMessage) {
+	if report.MaxPeers > 0 && report.PeerCount < report.MaxPeers {
+		delete(state.fullServers, alias)
+	}
+}
@@ -0,0 +1,100 @@
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestServerRefused(t *testing.T) {
+	state := makeTestState()
+	defer closeState(state)
+
+	s1 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	require.NoError(t, registerCommServer(state, s1))
+	<-s1.sendCh
+
+	s2 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s2.fallbackURL = "wss://s2/fallback"
+	require.NoError(t, registerCommServer(state, s2))
+	<-s2.sendCh
+
+	registerTestClient := func() []uint64 {
+		c := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+		require.NoError(t, registerClient(state, c))
+
+		welcomeMessage := &protocol.WelcomeMessage{}
+		require.NoError(t, proto.Unmarshal(<-c.sendCh, welcomeMessage))
+
+		return welcomeMessage.AvailableServers
+	}
+
+	refuse := func(c *Peer, reason protocol.ConnectionRefusedReason) *protocol.ConnectionRefusedMessage {
+		refusal := &protocol.ConnectionRefusedMessage{
+			Type:      protocol.MessageType_CONNECTION_REFUSED,
+			FromAlias: s1.Alias,
+			ToAlias:   c.Alias,
+			Reason:    reason,
+		}
+
+		bytes, err := proto.Marshal(refusal)
+		require.NoError(t, err)
+
+		signal(state, &inMessage{
+			msgType: protocol.MessageType_CONNECTION_REFUSED,
+			from:    s1,
+			bytes:   bytes,
+			toAlias: c.Alias,
+			refusal: refusal,
+		})
+
+		received := &protocol.ConnectionRefusedMessage{}
+		require.NoError(t, proto.Unmarshal(<-c.sendCh, received))
+
+		return received
+	}
+
+	c := makePeer(state, &MockWebsocket{}, protocol.Role_CLIENT)
+	require.NoError(t, registerClient(state, c))
+	<-c.sendCh
+
+	signal(state, &inMessage{msgType: protocol.MessageType_CONNECT, from: c, bytes: []byte("connect"), toAlias: s1.Alias})
+	<-s1.sendCh
+	require.Equal(t, s1.Alias, c.serverAlias)
+
+	t.Run("other reasons are relayed", func(t *testing.T) {
+		refusal := refuse(c, protocol.ConnectionRefusedReason_AUTH_FAILED)
+		require.Empty(t, refusal.AvailableServers)
+		require.Equal(t, []uint64{s1.Alias, s2.Alias}, registerTestClient())
+	})
+
+	t.Run("full server", func(t *testing.T) {
+		refusal := refuse(c, protocol.ConnectionRefusedReason_SERVER_FULL)
+		require.Equal(t, protocol.ConnectionRefusedReason_SERVER_FULL, refusal.Reason)
+		require.Equal(t, []uint64{s2.Alias}, refusal.AvailableServers)
+		require.Len(t, refusal.FallbackEndpoints, 1)
+		require.Equal(t, "wss://s2/fallback", refusal.FallbackEndpoints[0].Url)
+		require.Zero(t, c.serverAlias)
+
+		require.Equal(t, []uint64{s2.Alias}, registerTestClient())
+		require.True(t, listServers(state).([]ServerInfo)[0].Full)
+	})
+
+	t.Run("full server reports room", func(t *testing.T) {
+		reportLoad(state, &loadReport{from: s1, report: &protocol.LoadReportMessage{PeerCount: 9, MaxPeers: 10}})
+		require.Equal(t, []uint64{s1.Alias, s2.Alias}, registerTestClient())
+	})
+
+	t.Run("cooldown", func(t *testing.T) {
+		refuse(c, protocol.ConnectionRefusedReason_SERVER_FULL)
+		require.Equal(t, []uint64{s2.Alias}, registerTestClient())
+
+		state.fullServers[s1.Alias] = time.Now().Add(-time.Second)
+		require.Equal(t, []uint64{s1.Alias, s2.Alias}, registerTestClient())
+		require.Empty(t, state.fullServers)
+	})
+}
@@ -0,0 +1,134 @@
+package coordinator
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+// ErrRevoked indicates that the peer identity was revoked
+var ErrRevoked = errors.New("identity revoked")
+
+// Revoke disconnects the peers with the identity, both from the coordinator and from every server, and rejects
+// the identity until the given time. It returns the number of peers disconnected from the coordinator, zero if
+// the coordinator is stopped
+func Revoke(state *State, identity []byte, until time.Time) int {
+	disconnected, err := runAdminRequest(state, revokeIdentity(identity, until))
+	if err != nil {
+		return 0
+	}
+
+	return disconnected.(int)
+}
+
+func revokeIdentity(identity []byte, until time.Time) func(state *State) interface{} {
+	return func(state *State) interface{} {
+		disconnected := applyRevocation(state, identity, until)
+
+		// NOTE: the other coordinator instances apply the revocation on their next sync
+		if err := state.store.Revoke(string(identity), until); err != nil {
+			state.log.Error().Err(err).Msg("cannot store revocation")
+		}
+
+		return disconnected
+	}
+}
+
+// applyRevocation disconnects the local peers with the identity and forwards the revocation to the local
+// servers
+func applyRevocation(state *State, identity []byte, until time.Time) int {
+	state.log.Info().Str("identity", string(identity)).Time("until", until).Msg("identity revoked")
+
+	pruneRevocations(state)
+	state.revoked[string(identity)] = until
+
+	msg := &protocol.RevokeMessage{
+		Type:      protocol.MessageType_REVOKE,
+		Identity:  identity,
+		ExpiresAt: until.Unix(),
+	}
+
+	disconnected := 0
+
+	for _, p := range state.Peers {
+		// NOTE: closed peers stay in the state until their read pump unregisters them
+		if p.isClosed() {
+			continue
+		}
+
+		if bytes.Equal(p.identity, identity) {
+			p.close()
+			disconnected++
+
+			continue
+		}
+
+		if p.role != protocol.Role_CLIENT {
+			if err := p.send(state, msg); err != nil {
+				state.log.Error().Err(err).Uint64("peer", p.Alias).Msg("cannot send revoke message")
+			}
+		}
+	}
+
+	return disconnected
+}
+
+// syncRevocations applies the revocations made by other coordinator instances
+func syncRevocations(state *State) {
+	revocations, err := state.store.Revocations()
+	if err != nil {
+		state.log.Error().Err(err).Msg("cannot list revocations")
+		return
+	}
+
+	now := time.Now()
+
+	for identity, until := range revocations {
+		if current, ok := state.revoked[identity]; (ok && current.Equal(until)) || now.After(until) {
+			continue
+		}
+
+		applyRevocation(state, []byte(identity), until)
+	}
+}
+
+func pruneRevocations(state *State) {
+	now := time.Now()
+
+	for identity, until := range state.revoked {
+		if now.After(until) {
+			delete(state.revoked, identity)
+		}
+	}
+}
+
+func isRevoked(state *State, identity []byte) bool {
+	if len(identity) == 0 {
+		return false
+	}
+
+	until, ok := state.revoked[string(identity)]
+
+	return ok && time.Now().Before(until)
+}
+
+// sendRevocations sends the active revocations to a new server
+func sendRevocations(state *State, p *Peer) error {
+	pruneRevocations(state)
+
+	for identity, until := range state.revoked {
+		msg := &protocol.RevokeMessage{
+			Type:      protocol.MessageType_REVOKE,
+			Identity:  []byte(identity),
+			ExpiresAt: until.Unix(),
+		}
+
+		if err := p.send(state, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1 +1,150 @@
 package coordinator
+
+import (
+	"sort"
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+const defaultLoadReportTimeout = 30 * time.Second
+
+// ILoadAwareServerSelector is an optional IServerSelector extension, notified of the server load reports
+type ILoadAwareServerSelector interface {
+	ServerLoadReported(alias uint64, report *protocol.LoadReportMessage)
+}
+
+type serverLoad struct {
+	report     *protocol.LoadReportMessage
+	reportedAt time.Time
+}
+
+// LoadAwareServerSelector returns the servers ranked by headroom for clients, the fraction of free peer slots
+// in the last server load report, excluding the full servers. Servers without a recent report are ranked last.
+// Servers connect to every other server, so they get the whole list, sorted by alias
+type LoadAwareServerSelector struct {
+	servers       map[uint64]*serverLoad
+	reportTimeout time.Duration
+	now           func() time.Time
+}
+
+// NewLoadAwareServerSelector creates a LoadAwareServerSelector, load reports older than reportTimeout are
+// ignored (30 seconds by default)
+func NewLoadAwareServerSelector(reportTimeout time.Duration) *LoadAwareServerSelector {
+	if reportTimeout == 0 {
+		reportTimeout = defaultLoadReportTimeout
+	}
+
+	return &LoadAwareServerSelector{
+		servers:       make(map[uint64]*serverLoad),
+		reportTimeout: reportTimeout,
+		now:           time.Now,
+	}
+}
+
+// ServerRegistered register a new server
+func (r *LoadAwareServerSelector) ServerRegistered(role protocol.Role, alias uint64) {
+	r.servers[alias] = &serverLoad{}
+}
+
+// ServerUnregistered removes an unregistered server from the list
+func (r *LoadAwareServerSelector) ServerUnregistered(alias uint64) {
+	delete(r.servers, alias)
+}
+
+// ServerLoadReported updates the server load
+func (r *LoadAwareServerSelector) ServerLoadReported(alias uint64, report *protocol.LoadReportMessage) {
+	s, ok := r.servers[alias]
+	if !ok {
+		return
+	}
+
+	s.report = report
+	s.reportedAt = r.now()
+}
+
+// headroom returns the fraction of free peer slots, 1 for servers without limit, and false if the
+// server load is unknown
+func (r *LoadAwareServerSelector) headroom(s *serverLoad) (float64, bool) {
+	if s.report == nil || r.now().Sub(s.reportedAt) > r.reportTimeout {
+		return 0, false
+	}
+
+	if s.report.MaxPeers == 0 {
+		return 1, true
+	}
+
+	if s.report.PeerCount >= s.report.MaxPeers {
+		return 0, true
+	}
+
+	return 1 - float64(s.report.PeerCount)/float64(s.report.MaxPeers), true
+}
+
+// GetServerAliasList returns the servers ranked by headroom for clients, every server sorted by alias
+// otherwise
+func (r *LoadAwareServerSelector) GetServerAliasList(role protocol.Role) []uint64 {
+	peers := make([]uint64, 0, len(r.servers))
+
+	if role != protocol.Role_CLIENT {
+		for alias := range r.servers {
+			peers = append(peers, alias)
+		}
+
+		sort.Sort(ByAlias(peers))
+
+		return peers
+	}
+
+	type rankedServer struct {
+		alias    uint64
+		headroom float64
+		known    bool
+		rate     float64
+	}
+
+	ranked := make([]rankedServer, 0, len(r.servers))
+
+	for alias, s := range r.servers {
+		headroom, known := r.headroom(s)
+		if known && headroom == 0 {
+			continue
+		}
+
+		rs := rankedServer{alias: alias, headroom: headroom, known: known}
+		if known {
+			rs.rate = s.report.MessagesPerSecond
+		}
+
+		ranked = append(ranked, rs)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+
+		if a.known != b.known {
+			return a.known
+		}
+
+		if a.headroom != b.headroom {
+			return a.headroom > b.headroom
+		}
+
+		if a.rate != b.rate {
+			return a.rate < b.rate
+		}
+
+		return a.alias < b.alias
+	})
+
+	for _, s := range ranked {
+		peers = append(peers, s.alias)
+	}
+
+	return peers
+}
+
+// GetServerCount return amount of servers registered
+func (r *LoadAwareServerSelector) GetServerCount() int {
+	return len(r.servers)
+}
@@ -0,0 +1,73 @@
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func TestLoadAwareServerSelector(t *testing.T) {
+	selector := NewLoadAwareServerSelector(time.Minute)
+
+	for alias := uint64(1); alias <= 5; alias++ {
+		selector.ServerRegistered(protocol.Role_COMMUNICATION_SERVER, alias)
+	}
+
+	selector.ServerLoadReported(1, &protocol.LoadReportMessage{PeerCount: 90, MaxPeers: 100})
+	selector.ServerLoadReported(2, &protocol.LoadReportMessage{PeerCount: 10, MaxPeers: 100})
+	selector.ServerLoadReported(3, &protocol.LoadReportMessage{PeerCount: 100, MaxPeers: 100})
+	selector.ServerLoadReported(4, &protocol.LoadReportMessage{PeerCount: 10, MaxPeers: 100, MessagesPerSecond: 5})
+	selector.ServerLoadReported(6, &protocol.LoadReportMessage{PeerCount: 10, MaxPeers: 100})
+
+	t.Run("clients", func(t *testing.T) {
+		require.Equal(t, []uint64{2, 4, 1, 5}, selector.GetServerAliasList(protocol.Role_CLIENT))
+	})
+
+	t.Run("servers", func(t *testing.T) {
+		require.Equal(t, []uint64{1, 2, 3, 4, 5}, selector.GetServerAliasList(protocol.Role_COMMUNICATION_SERVER))
+		require.Equal(t, 5, selector.GetServerCount())
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		selector.ServerLoadReported(5, &protocol.LoadReportMessage{PeerCount: 1000})
+		require.Equal(t, []uint64{5, 2, 4, 1}, selector.GetServerAliasList(protocol.Role_CLIENT))
+	})
+
+	t.Run("stale reports", func(t *testing.T) {
+		selector.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		defer func() { selector.now = time.Now }()
+
+		require.Equal(t, []uint64{1, 2, 3, 4, 5}, selector.GetServerAliasList(protocol.Role_CLIENT))
+	})
+
+	selector.ServerUnregistered(2)
+	require.Equal(t, []uint64{5, 4, 1}, selector.GetServerAliasList(protocol.Role_CLIENT))
+}
+
+func TestReportLoad(t *testing.T) {
+	selector := NewLoadAwareServerSelector(0)
+	state := MakeState(&Config{ServerSelector: selector})
+	defer closeState(state)
+
+	s1 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s1.Alias = 1
+	state.Peers[s1.Alias] = s1
+	selector.ServerRegistered(s1.role, s1.Alias)
+
+	s2 := makePeer(state, &MockWebsocket{}, protocol.Role_COMMUNICATION_SERVER)
+	s2.Alias = 2
+	state.Peers[s2.Alias] = s2
+	selector.ServerRegistered(s2.role, s2.Alias)
+
+	reportLoad(state, &loadReport{from: s1, report: &protocol.LoadReportMessage{PeerCount: 10, MaxPeers: 10}})
+	reportLoad(state, &loadReport{from: s2, report: &protocol.LoadReportMessage{PeerCount: 1, MaxPeers: 10}})
+	require.Equal(t, []uint64{2}, selector.GetServerAliasList(protocol.Role_CLIENT))
+
+	// reports from unregistered servers are ignored
+	delete(state.Peers, s2.Alias)
+	reportLoad(state, &loadReport{from: s2, report: &protocol.LoadReportMessage{PeerCount: 10, MaxPeers: 10}})
+	require.Equal(t, []uint64{2}, selector.GetServerAliasList(protocol.Role_CLIENT))
+}
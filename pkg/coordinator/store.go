@@ -0,0 +1,319 @@
+package coordinator
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+// PeerRecord is a peer as seen by every coordinator instance
+type PeerRecord struct {
+	Alias uint64        `json:"alias"`
+	Role  protocol.Role `json:"role"`
+	// Instance is the coordinator instance the peer is attached to
+	Instance    string                      `json:"instance"`
+	FallbackURL string                      `json:"fallbackURL,omitempty"`
+	Labels      map[string]string           `json:"labels,omitempty"`
+	Load        *protocol.LoadReportMessage `json:"load,omitempty"`
+	LoadTime    time.Time                   `json:"loadTime,omitempty"`
+	// Identity is the authenticated peer identity, only the same server can claim its alias again
+	Identity []byte `json:"identity,omitempty"`
+}
+
+// RelayedMessage is a signaling message relayed to the instance the recipient is attached to
+type RelayedMessage struct {
+	From     uint64        `json:"from"`
+	FromRole protocol.Role `json:"fromRole"`
+	To       uint64        `json:"to"`
+	Bytes    []byte        `json:"bytes"`
+}
+
+// Store is the state shared by the coordinator instances of a cluster, so several instances can run behind a
+// load balancer. Implementations have to be safe for concurrent use
+type Store interface {
+	// NextAlias allocates a cluster wide unique peer alias
+	NextAlias() (uint64, error)
+	// ClaimAlias stores the peer under an already allocated alias, if no other peer holds it, so a reconnecting
+	// server can keep its alias. Only the server stored last under the alias, with the same role and identity,
+	// can claim it. It returns false if the alias cannot be claimed
+	ClaimAlias(record PeerRecord) (bool, error)
+
+	// Heartbeat records the instance is alive
+	Heartbeat(instance string, at time.Time) error
+	// Instances returns the last heartbeat of every instance
+	Instances() (map[string]time.Time, error)
+	// RemoveInstance removes the instance, its peers and its pending messages
+	RemoveInstance(instance string) error
+
+	PutPeer(record PeerRecord) error
+	DeletePeer(alias uint64) error
+	// GetPeer returns nil if the peer is unknown
+	GetPeer(alias uint64) (*PeerRecord, error)
+	// ListServers returns every server in the cluster, sorted by alias
+	ListServers() ([]PeerRecord, error)
+
+	// Relay queues a message for the instance
+	Relay(instance string, msg RelayedMessage) error
+	// Receive returns and removes the messages queued for the instance
+	Receive(instance string) ([]RelayedMessage, error)
+
+	// Revoke records a revoked identity, until the given time
+	Revoke(identity string, until time.Time) error
+	// Revocations returns the active revocations
+	Revocations() (map[string]time.Time, error)
+}
+
+// storeData is the shared state, as kept by MemoryStore and persisted by FileStore
+type storeData struct {
+	LastAlias uint64                      `json:"lastAlias"`
+	Instances map[string]time.Time        `json:"instances"`
+	Peers     map[uint64]PeerRecord       `json:"peers"`
+	Inboxes   map[string][]RelayedMessage `json:"inboxes"`
+	Revoked   map[string]time.Time        `json:"revoked"`
+	// ServerAliases are the servers stored last under every server alias, even if they left, see claimAlias
+	ServerAliases map[uint64]aliasOwner `json:"serverAliases"`
+}
+
+type aliasOwner struct {
+	Role     protocol.Role `json:"role"`
+	Identity []byte        `json:"identity,omitempty"`
+}
+
+func newStoreData() *storeData {
+	return &storeData{
+		Instances: make(map[string]time.Time),
+		Peers:     make(map[uint64]PeerRecord),
+		Inboxes:   make(map[string][]RelayedMessage),
+		Revoked:   make(map[string]time.Time),
+
+		ServerAliases: make(map[uint64]aliasOwner),
+	}
+}
+
+func (d *storeData) nextAlias() uint64 {
+	d.LastAlias++
+	return d.LastAlias
+}
+
+// claimAlias stores the peer under the alias if it was stored last by the same server, so clients' aliases and
+// other servers' ones cannot be taken
+func (d *storeData) claimAlias(record PeerRecord) bool {
+	owner, ok := d.ServerAliases[record.Alias]
+	if !ok || owner.Role != record.Role || !bytes.Equal(owner.Identity, record.Identity) {
+		return false
+	}
+
+	if _, ok := d.Peers[record.Alias]; ok {
+		return false
+	}
+
+	d.putPeer(record)
+
+	return true
+}
+
+func (d *storeData) putPeer(record PeerRecord) {
+	d.Peers[record.Alias] = record
+
+	if record.Role != protocol.Role_CLIENT {
+		d.ServerAliases[record.Alias] = aliasOwner{Role: record.Role, Identity: record.Identity}
+	}
+}
+
+func (d *storeData) removeInstance(instance string) {
+	delete(d.Instances, instance)
+	delete(d.Inboxes, instance)
+
+	for alias, p := range d.Peers {
+		if p.Instance == instance {
+			delete(d.Peers, alias)
+		}
+	}
+}
+
+func (d *storeData) getPeer(alias uint64) *PeerRecord {
+	p, ok := d.Peers[alias]
+	if !ok {
+		return nil
+	}
+
+	return &p
+}
+
+func (d *storeData) listServers() []PeerRecord {
+	servers := make([]PeerRecord, 0)
+
+	for _, p := range d.Peers {
+		if p.Role != protocol.Role_CLIENT {
+			servers = append(servers, p)
+		}
+	}
+
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Alias < servers[j].Alias })
+
+	return servers
+}
+
+func (d *storeData) revoke(identity string, until time.Time) {
+	now := time.Now()
+
+	for id, t := range d.Revoked {
+		if now.After(t) {
+			delete(d.Revoked, id)
+		}
+	}
+
+	d.Revoked[identity] = until
+}
+
+func (d *storeData) revocations() map[string]time.Time {
+	revocations := make(map[string]time.Time, len(d.Revoked))
+	for identity, until := range d.Revoked {
+		revocations[identity] = until
+	}
+
+	return revocations
+}
+
+func (d *storeData) receive(instance string) []RelayedMessage {
+	messages := d.Inboxes[instance]
+	delete(d.Inboxes, instance)
+
+	return messages
+}
+
+// MemoryStore is a Store for the coordinator instances running in the same process, it's the default store of
+// a single coordinator
+type MemoryStore struct {
+	data *storeData
+	mux  sync.Mutex
+}
+
+// NewMemoryStore creates a MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: newStoreData()}
+}
+
+// NextAlias allocates a unique peer alias
+func (s *MemoryStore) NextAlias() (uint64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.data.nextAlias(), nil
+}
+
+// ClaimAlias stores the peer under an already allocated alias, if no other peer holds it
+func (s *MemoryStore) ClaimAlias(record PeerRecord) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.data.claimAlias(record), nil
+}
+
+// Heartbeat records the instance is alive
+func (s *MemoryStore) Heartbeat(instance string, at time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.Instances[instance] = at
+
+	return nil
+}
+
+// Instances returns the last heartbeat of every instance
+func (s *MemoryStore) Instances() (map[string]time.Time, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	instances := make(map[string]time.Time, len(s.data.Instances))
+	for instance, at := range s.data.Instances {
+		instances[instance] = at
+	}
+
+	return instances, nil
+}
+
+// RemoveInstance removes the instance, its peers and its pending messages
+func (s *MemoryStore) RemoveInstance(instance string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.removeInstance(instance)
+
+	return nil
+}
+
+// PutPeer adds or replaces a peer
+func (s *MemoryStore) PutPeer(record PeerRecord) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.putPeer(record)
+
+	return nil
+}
+
+// DeletePeer removes a peer
+func (s *MemoryStore) DeletePeer(alias uint64) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	delete(s.data.Peers, alias)
+
+	return nil
+}
+
+// GetPeer returns the peer, nil if it's unknown
+func (s *MemoryStore) GetPeer(alias uint64) (*PeerRecord, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.data.getPeer(alias), nil
+}
+
+// ListServers returns every server, sorted by alias
+func (s *MemoryStore) ListServers() ([]PeerRecord, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.data.listServers(), nil
+}
+
+// Relay queues a message for the instance
+func (s *MemoryStore) Relay(instance string, msg RelayedMessage) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.Inboxes[instance] = append(s.data.Inboxes[instance], msg)
+
+	return nil
+}
+
+// Receive returns and removes the messages queued for the instance
+func (s *MemoryStore) Receive(instance string) ([]RelayedMessage, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.data.receive(instance), nil
+}
+
+// Revoke records a revoked identity, until the given time
+func (s *MemoryStore) Revoke(identity string, until time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.revoke(identity, until)
+
+	return nil
+}
+
+// Revocations returns the active revocations
+func (s *MemoryStore) Revocations() (map[string]time.Time, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.data.revocations(), nil
+}
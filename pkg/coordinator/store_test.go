@@ -0,0 +1,203 @@
+package coordinator
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+)
+
+func testStore(t *testing.T, store Store) {
+	t.Run("aliases", func(t *testing.T) {
+		alias1, err := store.NextAlias()
+		require.NoError(t, err)
+
+		alias2, err := store.NextAlias()
+		require.NoError(t, err)
+		require.NotEqual(t, alias1, alias2)
+	})
+
+	t.Run("claim alias", func(t *testing.T) {
+		alias, err := store.NextAlias()
+		require.NoError(t, err)
+
+		// NOTE: never stored, so it cannot be claimed
+		claimed, err := store.ClaimAlias(PeerRecord{Alias: alias, Role: protocol.Role_COMMUNICATION_SERVER})
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		server := PeerRecord{Alias: alias, Role: protocol.Role_COMMUNICATION_SERVER, Identity: []byte("server1")}
+		require.NoError(t, store.PutPeer(server))
+		require.NoError(t, store.DeletePeer(alias))
+
+		claimed, err = store.ClaimAlias(PeerRecord{Alias: alias, Role: protocol.Role_COMMUNICATION_SERVER})
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		claimed, err = store.ClaimAlias(server)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.ClaimAlias(server)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		client, err := store.NextAlias()
+		require.NoError(t, err)
+		require.NoError(t, store.PutPeer(PeerRecord{Alias: client, Role: protocol.Role_CLIENT}))
+		require.NoError(t, store.DeletePeer(client))
+
+		claimed, err = store.ClaimAlias(PeerRecord{Alias: client, Role: protocol.Role_COMMUNICATION_SERVER})
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		require.NoError(t, store.DeletePeer(alias))
+	})
+
+	t.Run("peers", func(t *testing.T) {
+		require.NoError(t, store.PutPeer(PeerRecord{Alias: 1, Role: protocol.Role_COMMUNICATION_SERVER, Instance: "a"}))
+		require.NoError(t, store.PutPeer(PeerRecord{Alias: 2, Role: protocol.Role_CLIENT, Instance: "a"}))
+		require.NoError(t, store.PutPeer(PeerRecord{
+			Alias:    3,
+			Role:     protocol.Role_COMMUNICATION_SERVER,
+			Instance: "b",
+			Labels:   map[string]string{"pool": "a"},
+		}))
+
+		record, err := store.GetPeer(3)
+		require.NoError(t, err)
+		require.Equal(t, "b", record.Instance)
+		require.Equal(t, map[string]string{"pool": "a"}, record.Labels)
+
+		record, err = store.GetPeer(4)
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		servers, err := store.ListServers()
+		require.NoError(t, err)
+		require.Len(t, servers, 2)
+		require.Equal(t, uint64(1), servers[0].Alias)
+		require.Equal(t, uint64(3), servers[1].Alias)
+
+		require.NoError(t, store.DeletePeer(1))
+		servers, err = store.ListServers()
+		require.NoError(t, err)
+		require.Len(t, servers, 1)
+	})
+
+	t.Run("relay", func(t *testing.T) {
+		require.NoError(t, store.Relay("b", RelayedMessage{To: 3, Bytes: []byte("offer")}))
+		require.NoError(t, store.Relay("b", RelayedMessage{To: 3, Bytes: []byte("candidate")}))
+
+		messages, err := store.Receive("a")
+		require.NoError(t, err)
+		require.Len(t, messages, 0)
+
+		messages, err = store.Receive("b")
+		require.NoError(t, err)
+		require.Equal(t, []RelayedMessage{
+			{To: 3, Bytes: []byte("offer")},
+			{To: 3, Bytes: []byte("candidate")},
+		}, messages)
+
+		messages, err = store.Receive("b")
+		require.NoError(t, err)
+		require.Len(t, messages, 0)
+	})
+
+	t.Run("instances", func(t *testing.T) {
+		now := time.Now()
+		require.NoError(t, store.Heartbeat("a", now))
+		require.NoError(t, store.Heartbeat("b", now))
+
+		instances, err := store.Instances()
+		require.NoError(t, err)
+		require.Len(t, instances, 2)
+		require.True(t, now.Equal(instances["a"]))
+
+		require.NoError(t, store.Relay("b", RelayedMessage{To: 3}))
+		require.NoError(t, store.RemoveInstance("b"))
+
+		instances, err = store.Instances()
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+
+		record, err := store.GetPeer(3)
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		record, err = store.GetPeer(2)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+
+		messages, err := store.Receive("b")
+		require.NoError(t, err)
+		require.Len(t, messages, 0)
+	})
+
+	t.Run("revocations", func(t *testing.T) {
+		until := time.Now().Add(time.Minute)
+		require.NoError(t, store.Revoke("user1", time.Now().Add(-time.Minute)))
+		require.NoError(t, store.Revoke("user2", until))
+
+		revocations, err := store.Revocations()
+		require.NoError(t, err)
+		require.Len(t, revocations, 1)
+		require.True(t, until.Equal(revocations["user2"]))
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "coordinator")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	testStore(t, store)
+
+	t.Run("shared", func(t *testing.T) {
+		other, err := NewFileStore(dir)
+		require.NoError(t, err)
+
+		alias, err := store.NextAlias()
+		require.NoError(t, err)
+
+		otherAlias, err := other.NextAlias()
+		require.NoError(t, err)
+		require.Equal(t, alias+1, otherAlias)
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		other, err := NewFileStore(dir)
+		require.NoError(t, err)
+
+		f, err := store.lock()
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			_, err := other.NextAlias()
+			done <- err
+		}()
+
+		select {
+		case <-done:
+			require.FailNow(t, "lock taken while held")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		store.unlock(f)
+		require.NoError(t, <-done)
+	})
+}
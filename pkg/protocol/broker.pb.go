@@ -39,6 +39,9 @@ const (
 	MessageType_TOPIC_IDENTITY_FW    MessageType = 12
 	MessageType_CONNECTION_REFUSED   MessageType = 13
 	MessageType_ERROR                MessageType = 14
+	MessageType_AUTH_REQUEST         MessageType = 15
+	MessageType_REVOKE               MessageType = 16
+	MessageType_LOAD_REPORT          MessageType = 17
 )
 
 var MessageType_name = map[int32]string{
@@ -57,6 +60,9 @@ var MessageType_name = map[int32]string{
 	12: "TOPIC_IDENTITY_FW",
 	13: "CONNECTION_REFUSED",
 	14: "ERROR",
+	15: "AUTH_REQUEST",
+	16: "REVOKE",
+	17: "LOAD_REPORT",
 }
 
 var MessageType_value = map[string]int32{
@@ -75,6 +81,9 @@ var MessageType_value = map[string]int32{
 	"TOPIC_IDENTITY_FW":    12,
 	"CONNECTION_REFUSED":   13,
 	"ERROR":                14,
+	"AUTH_REQUEST":         15,
+	"REVOKE":               16,
+	"LOAD_REPORT":          17,
 }
 
 func (x MessageType) String() string {
@@ -211,20 +220,134 @@ func (m *CoordinatorMessage) GetType() MessageType {
 	return MessageType_UNKNOWN_MESSAGE_TYPE
 }
 
+type FallbackEndpoint struct {
+	Alias uint64 `protobuf:"varint,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Url   string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// NOTE: a SignedConnectionTicket for the client, if the coordinator issues tickets
+	Ticket               []byte   `protobuf:"bytes,3,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FallbackEndpoint) Reset()         { *m = FallbackEndpoint{} }
+func (m *FallbackEndpoint) String() string { return proto.CompactTextString(m) }
+func (*FallbackEndpoint) ProtoMessage()    {}
+func (*FallbackEndpoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f209535e190f2bed, []int{1}
+}
+
+func (m *FallbackEndpoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FallbackEndpoint.Unmarshal(m, b)
+}
+func (m *FallbackEndpoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FallbackEndpoint.Marshal(b, m, deterministic)
+}
+func (m *FallbackEndpoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FallbackEndpoint.Merge(m, src)
+}
+func (m *FallbackEndpoint) XXX_Size() int {
+	return xxx_messageInfo_FallbackEndpoint.Size(m)
+}
+func (m *FallbackEndpoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_FallbackEndpoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FallbackEndpoint proto.InternalMessageInfo
+
+func (m *FallbackEndpoint) GetAlias() uint64 {
+	if m != nil {
+		return m.Alias
+	}
+	return 0
+}
+
+func (m *FallbackEndpoint) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *FallbackEndpoint) GetTicket() []byte {
+	if m != nil {
+		return m.Ticket
+	}
+	return nil
+}
+
+type IceServer struct {
+	Urls                 []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Credential           string   `protobuf:"bytes,3,opt,name=credential,proto3" json:"credential,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IceServer) Reset()         { *m = IceServer{} }
+func (m *IceServer) String() string { return proto.CompactTextString(m) }
+func (*IceServer) ProtoMessage()    {}
+func (*IceServer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f209535e190f2bed, []int{2}
+}
+
+func (m *IceServer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IceServer.Unmarshal(m, b)
+}
+func (m *IceServer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IceServer.Marshal(b, m, deterministic)
+}
+func (m *IceServer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IceServer.Merge(m, src)
+}
+func (m *IceServer) XXX_Size() int {
+	return xxx_messageInfo_IceServer.Size(m)
+}
+func (m *IceServer) XXX_DiscardUnknown() {
+	xxx_messageInfo_IceServer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IceServer proto.InternalMessageInfo
+
+func (m *IceServer) GetUrls() []string {
+	if m != nil {
+		return m.Urls
+	}
+	return nil
+}
+
+func (m *IceServer) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *IceServer) GetCredential() string {
+	if m != nil {
+		return m.Credential
+	}
+	return ""
+}
+
 type WelcomeMessage struct {
-	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
-	Alias                uint64      `protobuf:"varint,2,opt,name=alias,proto3" json:"alias,omitempty"`
-	AvailableServers     []uint64    `protobuf:"varint,3,rep,packed,name=available_servers,json=availableServers,proto3" json:"available_servers,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	Type              MessageType         `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
+	Alias             uint64              `protobuf:"varint,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	AvailableServers  []uint64            `protobuf:"varint,3,rep,packed,name=available_servers,json=availableServers,proto3" json:"available_servers,omitempty"`
+	FallbackEndpoints []*FallbackEndpoint `protobuf:"bytes,4,rep,name=fallback_endpoints,json=fallbackEndpoints,proto3" json:"fallback_endpoints,omitempty"`
+	// NOTE: the STUN and TURN servers the peer should use, with short lived TURN credentials
+	IceServers           []*IceServer `protobuf:"bytes,5,rep,name=ice_servers,json=iceServers,proto3" json:"ice_servers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
 func (m *WelcomeMessage) Reset()         { *m = WelcomeMessage{} }
 func (m *WelcomeMessage) String() string { return proto.CompactTextString(m) }
 func (*WelcomeMessage) ProtoMessage()    {}
 func (*WelcomeMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{1}
+	return fileDescriptor_f209535e190f2bed, []int{3}
 }
 
 func (m *WelcomeMessage) XXX_Unmarshal(b []byte) error {
@@ -266,20 +389,36 @@ func (m *WelcomeMessage) GetAvailableServers() []uint64 {
 	return nil
 }
 
+func (m *WelcomeMessage) GetFallbackEndpoints() []*FallbackEndpoint {
+	if m != nil {
+		return m.FallbackEndpoints
+	}
+	return nil
+}
+
+func (m *WelcomeMessage) GetIceServers() []*IceServer {
+	if m != nil {
+		return m.IceServers
+	}
+	return nil
+}
+
 type ConnectMessage struct {
-	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
-	FromAlias            uint64      `protobuf:"varint,2,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
-	ToAlias              uint64      `protobuf:"varint,3,opt,name=to_alias,json=toAlias,proto3" json:"to_alias,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	Type      MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
+	FromAlias uint64      `protobuf:"varint,2,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
+	ToAlias   uint64      `protobuf:"varint,3,opt,name=to_alias,json=toAlias,proto3" json:"to_alias,omitempty"`
+	// NOTE: set by the coordinator, a SignedConnectionTicket for from_alias
+	Ticket               []byte   `protobuf:"bytes,4,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ConnectMessage) Reset()         { *m = ConnectMessage{} }
 func (m *ConnectMessage) String() string { return proto.CompactTextString(m) }
 func (*ConnectMessage) ProtoMessage()    {}
 func (*ConnectMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{2}
+	return fileDescriptor_f209535e190f2bed, []int{4}
 }
 
 func (m *ConnectMessage) XXX_Unmarshal(b []byte) error {
@@ -321,21 +460,287 @@ func (m *ConnectMessage) GetToAlias() uint64 {
 	return 0
 }
 
-type WebRtcMessage struct {
+func (m *ConnectMessage) GetTicket() []byte {
+	if m != nil {
+		return m.Ticket
+	}
+	return nil
+}
+
+type ConnectionTicket struct {
+	Alias                uint64   `protobuf:"varint,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	ToAlias              uint64   `protobuf:"varint,2,opt,name=to_alias,json=toAlias,proto3" json:"to_alias,omitempty"`
+	Role                 Role     `protobuf:"varint,3,opt,name=role,proto3,enum=protocol.Role" json:"role,omitempty"`
+	Identity             []byte   `protobuf:"bytes,4,opt,name=identity,proto3" json:"identity,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConnectionTicket) Reset()         { *m = ConnectionTicket{} }
+func (m *ConnectionTicket) String() string { return proto.CompactTextString(m) }
+func (*ConnectionTicket) ProtoMessage()    {}
+func (*ConnectionTicket) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f209535e190f2bed, []int{5}
+}
+
+func (m *ConnectionTicket) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConnectionTicket.Unmarshal(m, b)
+}
+func (m *ConnectionTicket) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConnectionTicket.Marshal(b, m, deterministic)
+}
+func (m *ConnectionTicket) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConnectionTicket.Merge(m, src)
+}
+func (m *ConnectionTicket) XXX_Size() int {
+	return xxx_messageInfo_ConnectionTicket.Size(m)
+}
+func (m *ConnectionTicket) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConnectionTicket.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConnectionTicket proto.InternalMessageInfo
+
+func (m *ConnectionTicket) GetAlias() uint64 {
+	if m != nil {
+		return m.Alias
+	}
+	return 0
+}
+
+func (m *ConnectionTicket) GetToAlias() uint64 {
+	if m != nil {
+		return m.ToAlias
+	}
+	return 0
+}
+
+func (m *ConnectionTicket) GetRole() Role {
+	if m != nil {
+		return m.Role
+	}
+	return Role_UNKNOWN_ROLE
+}
+
+func (m *ConnectionTicket) GetIdentity() []byte {
+	if m != nil {
+		return m.Identity
+	}
+	return nil
+}
+
+func (m *ConnectionTicket) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+type SignedConnectionTicket struct {
+	Ticket               []byte   `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	Signature            []byte   `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignedConnectionTicket) Reset()         { *m = SignedConnectionTicket{} }
+func (m *SignedConnectionTicket) String() string { return proto.CompactTextString(m) }
+func (*SignedConnectionTicket) ProtoMessage()    {}
+func (*SignedConnectionTicket) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f209535e190f2bed, []int{6}
+}
+
+func (m *SignedConnectionTicket) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignedConnectionTicket.Unmarshal(m, b)
+}
+func (m *SignedConnectionTicket) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignedConnectionTicket.Marshal(b, m, deterministic)
+}
+func (m *SignedConnectionTicket) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignedConnectionTicket.Merge(m, src)
+}
+func (m *SignedConnectionTicket) XXX_Size() int {
+	return xxx_messageInfo_SignedConnectionTicket.Size(m)
+}
+func (m *SignedConnectionTicket) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignedConnectionTicket.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignedConnectionTicket proto.InternalMessageInfo
+
+func (m *SignedConnectionTicket) GetTicket() []byte {
+	if m != nil {
+		return m.Ticket
+	}
+	return nil
+}
+
+func (m *SignedConnectionTicket) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// NOTE: sent by the coordinator to every server, peers with the identity are disconnected and rejected until
+// expires_at (unix seconds)
+type RevokeMessage struct {
 	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
-	FromAlias            uint64      `protobuf:"varint,2,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
-	ToAlias              uint64      `protobuf:"varint,3,opt,name=to_alias,json=toAlias,proto3" json:"to_alias,omitempty"`
-	Data                 []byte      `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	Identity             []byte      `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	ExpiresAt            int64       `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
 }
 
+func (m *RevokeMessage) Reset()         { *m = RevokeMessage{} }
+func (m *RevokeMessage) String() string { return proto.CompactTextString(m) }
+func (*RevokeMessage) ProtoMessage()    {}
+func (*RevokeMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f209535e190f2bed, []int{7}
+}
+
+func (m *RevokeMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeMessage.Unmarshal(m, b)
+}
+func (m *RevokeMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeMessage.Marshal(b, m, deterministic)
+}
+func (m *RevokeMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeMessage.Merge(m, src)
+}
+func (m *RevokeMessage) XXX_Size() int {
+	return xxx_messageInfo_RevokeMessage.Size(m)
+}
+func (m *RevokeMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeMessage proto.InternalMessageInfo
+
+func (m *RevokeMessage) GetType() MessageType {
+	if m != nil {
+		return m.Type
+	}
+	return MessageType_UNKNOWN_MESSAGE_TYPE
+}
+
+func (m *RevokeMessage) GetIdentity() []byte {
+	if m != nil {
+		return m.Identity
+	}
+	return nil
+}
+
+func (m *RevokeMessage) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+// NOTE: sent periodically by the servers, rates are averages since the previous report. max_peers is 0 if the
+// server has no limit
+type LoadReportMessage struct {
+	Type                   MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
+	PeerCount              uint32      `protobuf:"varint,2,opt,name=peer_count,json=peerCount,proto3" json:"peer_count,omitempty"`
+	MaxPeers               uint32      `protobuf:"varint,3,opt,name=max_peers,json=maxPeers,proto3" json:"max_peers,omitempty"`
+	BytesReceivedPerSecond uint64      `protobuf:"varint,4,opt,name=bytes_received_per_second,json=bytesReceivedPerSecond,proto3" json:"bytes_received_per_second,omitempty"`
+	BytesSentPerSecond     uint64      `protobuf:"varint,5,opt,name=bytes_sent_per_second,json=bytesSentPerSecond,proto3" json:"bytes_sent_per_second,omitempty"`
+	MessagesPerSecond      float64     `protobuf:"fixed64,6,opt,name=messages_per_second,json=messagesPerSecond,proto3" json:"messages_per_second,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}    `json:"-"`
+	XXX_unrecognized       []byte      `json:"-"`
+	XXX_sizecache          int32       `json:"-"`
+}
+
+func (m *LoadReportMessage) Reset()         { *m = LoadReportMessage{} }
+func (m *LoadReportMessage) String() string { return proto.CompactTextString(m) }
+func (*LoadReportMessage) ProtoMessage()    {}
+func (*LoadReportMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f209535e190f2bed, []int{8}
+}
+
+func (m *LoadReportMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoadReportMessage.Unmarshal(m, b)
+}
+func (m *LoadReportMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoadReportMessage.Marshal(b, m, deterministic)
+}
+func (m *LoadReportMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoadReportMessage.Merge(m, src)
+}
+func (m *LoadReportMessage) XXX_Size() int {
+	return xxx_messageInfo_LoadReportMessage.Size(m)
+}
+func (m *LoadReportMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoadReportMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoadReportMessage proto.InternalMessageInfo
+
+func (m *LoadReportMessage) GetType() MessageType {
+	if m != nil {
+		return m.Type
+	}
+	return MessageType_UNKNOWN_MESSAGE_TYPE
+}
+
+func (m *LoadReportMessage) GetPeerCount() uint32 {
+	if m != nil {
+		return m.PeerCount
+	}
+	return 0
+}
+
+func (m *LoadReportMessage) GetMaxPeers() uint32 {
+	if m != nil {
+		return m.MaxPeers
+	}
+	return 0
+}
+
+func (m *LoadReportMessage) GetBytesReceivedPerSecond() uint64 {
+	if m != nil {
+		return m.BytesReceivedPerSecond
+	}
+	return 0
+}
+
+func (m *LoadReportMessage) GetBytesSentPerSecond() uint64 {
+	if m != nil {
+		return m.BytesSentPerSecond
+	}
+	return 0
+}
+
+func (m *LoadReportMessage) GetMessagesPerSecond() float64 {
+	if m != nil {
+		return m.MessagesPerSecond
+	}
+	return 0
+}
+
+type WebRtcMessage struct {
+	Type      MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
+	FromAlias uint64      `protobuf:"varint,2,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
+	ToAlias   uint64      `protobuf:"varint,3,opt,name=to_alias,json=toAlias,proto3" json:"to_alias,omitempty"`
+	Data      []byte      `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	// NOTE: set on the messages negotiating the connection that replaces a disconnected one, it's a new
+	// connection (a reconnect), not an ICE restart of the disconnected one
+	IceRestart           bool     `protobuf:"varint,5,opt,name=ice_restart,json=iceRestart,proto3" json:"ice_restart,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
 func (m *WebRtcMessage) Reset()         { *m = WebRtcMessage{} }
 func (m *WebRtcMessage) String() string { return proto.CompactTextString(m) }
 func (*WebRtcMessage) ProtoMessage()    {}
 func (*WebRtcMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{3}
+	return fileDescriptor_f209535e190f2bed, []int{9}
 }
 
 func (m *WebRtcMessage) XXX_Unmarshal(b []byte) error {
@@ -384,22 +789,32 @@ func (m *WebRtcMessage) GetData() []byte {
 	return nil
 }
 
+func (m *WebRtcMessage) GetIceRestart() bool {
+	if m != nil {
+		return m.IceRestart
+	}
+	return false
+}
+
 type ConnectionRefusedMessage struct {
-	Type                 MessageType             `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
-	FromAlias            uint64                  `protobuf:"varint,2,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
-	ToAlias              uint64                  `protobuf:"varint,3,opt,name=to_alias,json=toAlias,proto3" json:"to_alias,omitempty"`
-	Reason               ConnectionRefusedReason `protobuf:"varint,4,opt,name=reason,proto3,enum=protocol.ConnectionRefusedReason" json:"reason,omitempty"`
-	Data                 []byte                  `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
-	XXX_unrecognized     []byte                  `json:"-"`
-	XXX_sizecache        int32                   `json:"-"`
+	Type      MessageType             `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
+	FromAlias uint64                  `protobuf:"varint,2,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
+	ToAlias   uint64                  `protobuf:"varint,3,opt,name=to_alias,json=toAlias,proto3" json:"to_alias,omitempty"`
+	Reason    ConnectionRefusedReason `protobuf:"varint,4,opt,name=reason,proto3,enum=protocol.ConnectionRefusedReason" json:"reason,omitempty"`
+	Data      []byte                  `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	// NOTE: set by the coordinator when a server is full, the other servers the client can connect to
+	AvailableServers     []uint64            `protobuf:"varint,6,rep,packed,name=available_servers,json=availableServers,proto3" json:"available_servers,omitempty"`
+	FallbackEndpoints    []*FallbackEndpoint `protobuf:"bytes,7,rep,name=fallback_endpoints,json=fallbackEndpoints,proto3" json:"fallback_endpoints,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
 
 func (m *ConnectionRefusedMessage) Reset()         { *m = ConnectionRefusedMessage{} }
 func (m *ConnectionRefusedMessage) String() string { return proto.CompactTextString(m) }
 func (*ConnectionRefusedMessage) ProtoMessage()    {}
 func (*ConnectionRefusedMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{4}
+	return fileDescriptor_f209535e190f2bed, []int{10}
 }
 
 func (m *ConnectionRefusedMessage) XXX_Unmarshal(b []byte) error {
@@ -455,6 +870,20 @@ func (m *ConnectionRefusedMessage) GetData() []byte {
 	return nil
 }
 
+func (m *ConnectionRefusedMessage) GetAvailableServers() []uint64 {
+	if m != nil {
+		return m.AvailableServers
+	}
+	return nil
+}
+
+func (m *ConnectionRefusedMessage) GetFallbackEndpoints() []*FallbackEndpoint {
+	if m != nil {
+		return m.FallbackEndpoints
+	}
+	return nil
+}
+
 type MessageHeader struct {
 	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
@@ -466,7 +895,7 @@ func (m *MessageHeader) Reset()         { *m = MessageHeader{} }
 func (m *MessageHeader) String() string { return proto.CompactTextString(m) }
 func (*MessageHeader) ProtoMessage()    {}
 func (*MessageHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{5}
+	return fileDescriptor_f209535e190f2bed, []int{11}
 }
 
 func (m *MessageHeader) XXX_Unmarshal(b []byte) error {
@@ -494,9 +923,13 @@ func (m *MessageHeader) GetType() MessageType {
 	return MessageType_UNKNOWN_MESSAGE_TYPE
 }
 
+// NOTE: pings are echoed back as they are. The servers measure the connection quality with pings carrying their
+// alias in from_alias, so they don't echo them again when they come back
 type PingMessage struct {
 	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
 	Time                 float64     `protobuf:"fixed64,2,opt,name=time,proto3" json:"time,omitempty"`
+	Seq                  uint32      `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	FromAlias            uint64      `protobuf:"varint,4,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
@@ -506,7 +939,7 @@ func (m *PingMessage) Reset()         { *m = PingMessage{} }
 func (m *PingMessage) String() string { return proto.CompactTextString(m) }
 func (*PingMessage) ProtoMessage()    {}
 func (*PingMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{6}
+	return fileDescriptor_f209535e190f2bed, []int{12}
 }
 
 func (m *PingMessage) XXX_Unmarshal(b []byte) error {
@@ -541,6 +974,20 @@ func (m *PingMessage) GetTime() float64 {
 	return 0
 }
 
+func (m *PingMessage) GetSeq() uint32 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *PingMessage) GetFromAlias() uint64 {
+	if m != nil {
+		return m.FromAlias
+	}
+	return 0
+}
+
 // NOTE: topics is a space separated string in the format specified by Format
 type SubscriptionMessage struct {
 	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
@@ -555,7 +1002,7 @@ func (m *SubscriptionMessage) Reset()         { *m = SubscriptionMessage{} }
 func (m *SubscriptionMessage) String() string { return proto.CompactTextString(m) }
 func (*SubscriptionMessage) ProtoMessage()    {}
 func (*SubscriptionMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{7}
+	return fileDescriptor_f209535e190f2bed, []int{13}
 }
 
 func (m *SubscriptionMessage) XXX_Unmarshal(b []byte) error {
@@ -610,7 +1057,7 @@ func (m *AuthMessage) Reset()         { *m = AuthMessage{} }
 func (m *AuthMessage) String() string { return proto.CompactTextString(m) }
 func (*AuthMessage) ProtoMessage()    {}
 func (*AuthMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{8}
+	return fileDescriptor_f209535e190f2bed, []int{14}
 }
 
 func (m *AuthMessage) XXX_Unmarshal(b []byte) error {
@@ -652,6 +1099,54 @@ func (m *AuthMessage) GetBody() []byte {
 	return nil
 }
 
+// NOTE: the peer has to send a new AuthMessage before deadline (unix seconds), or it will be disconnected
+type AuthRequestMessage struct {
+	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
+	Deadline             int64       `protobuf:"varint,2,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *AuthRequestMessage) Reset()         { *m = AuthRequestMessage{} }
+func (m *AuthRequestMessage) String() string { return proto.CompactTextString(m) }
+func (*AuthRequestMessage) ProtoMessage()    {}
+func (*AuthRequestMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f209535e190f2bed, []int{15}
+}
+
+func (m *AuthRequestMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuthRequestMessage.Unmarshal(m, b)
+}
+func (m *AuthRequestMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuthRequestMessage.Marshal(b, m, deterministic)
+}
+func (m *AuthRequestMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuthRequestMessage.Merge(m, src)
+}
+func (m *AuthRequestMessage) XXX_Size() int {
+	return xxx_messageInfo_AuthRequestMessage.Size(m)
+}
+func (m *AuthRequestMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuthRequestMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuthRequestMessage proto.InternalMessageInfo
+
+func (m *AuthRequestMessage) GetType() MessageType {
+	if m != nil {
+		return m.Type
+	}
+	return MessageType_UNKNOWN_MESSAGE_TYPE
+}
+
+func (m *AuthRequestMessage) GetDeadline() int64 {
+	if m != nil {
+		return m.Deadline
+	}
+	return 0
+}
+
 type TopicMessage struct {
 	Type                 MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.MessageType" json:"type,omitempty"`
 	FromAlias            uint64      `protobuf:"varint,2,opt,name=from_alias,json=fromAlias,proto3" json:"from_alias,omitempty"`
@@ -666,7 +1161,7 @@ func (m *TopicMessage) Reset()         { *m = TopicMessage{} }
 func (m *TopicMessage) String() string { return proto.CompactTextString(m) }
 func (*TopicMessage) ProtoMessage()    {}
 func (*TopicMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{9}
+	return fileDescriptor_f209535e190f2bed, []int{16}
 }
 
 func (m *TopicMessage) XXX_Unmarshal(b []byte) error {
@@ -728,7 +1223,7 @@ func (m *TopicFWMessage) Reset()         { *m = TopicFWMessage{} }
 func (m *TopicFWMessage) String() string { return proto.CompactTextString(m) }
 func (*TopicFWMessage) ProtoMessage()    {}
 func (*TopicFWMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{10}
+	return fileDescriptor_f209535e190f2bed, []int{17}
 }
 
 func (m *TopicFWMessage) XXX_Unmarshal(b []byte) error {
@@ -786,7 +1281,7 @@ func (m *TopicIdentityMessage) Reset()         { *m = TopicIdentityMessage{} }
 func (m *TopicIdentityMessage) String() string { return proto.CompactTextString(m) }
 func (*TopicIdentityMessage) ProtoMessage()    {}
 func (*TopicIdentityMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{11}
+	return fileDescriptor_f209535e190f2bed, []int{18}
 }
 
 func (m *TopicIdentityMessage) XXX_Unmarshal(b []byte) error {
@@ -864,7 +1359,7 @@ func (m *TopicIdentityFWMessage) Reset()         { *m = TopicIdentityFWMessage{}
 func (m *TopicIdentityFWMessage) String() string { return proto.CompactTextString(m) }
 func (*TopicIdentityFWMessage) ProtoMessage()    {}
 func (*TopicIdentityFWMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f209535e190f2bed, []int{12}
+	return fileDescriptor_f209535e190f2bed, []int{19}
 }
 
 func (m *TopicIdentityFWMessage) XXX_Unmarshal(b []byte) error {
@@ -926,14 +1421,21 @@ func init() {
 	proto.RegisterEnum("protocol.Format", Format_name, Format_value)
 	proto.RegisterEnum("protocol.ConnectionRefusedReason", ConnectionRefusedReason_name, ConnectionRefusedReason_value)
 	proto.RegisterType((*CoordinatorMessage)(nil), "protocol.CoordinatorMessage")
+	proto.RegisterType((*FallbackEndpoint)(nil), "protocol.FallbackEndpoint")
+	proto.RegisterType((*IceServer)(nil), "protocol.IceServer")
 	proto.RegisterType((*WelcomeMessage)(nil), "protocol.WelcomeMessage")
 	proto.RegisterType((*ConnectMessage)(nil), "protocol.ConnectMessage")
+	proto.RegisterType((*ConnectionTicket)(nil), "protocol.ConnectionTicket")
+	proto.RegisterType((*SignedConnectionTicket)(nil), "protocol.SignedConnectionTicket")
+	proto.RegisterType((*RevokeMessage)(nil), "protocol.RevokeMessage")
+	proto.RegisterType((*LoadReportMessage)(nil), "protocol.LoadReportMessage")
 	proto.RegisterType((*WebRtcMessage)(nil), "protocol.WebRtcMessage")
 	proto.RegisterType((*ConnectionRefusedMessage)(nil), "protocol.ConnectionRefusedMessage")
 	proto.RegisterType((*MessageHeader)(nil), "protocol.MessageHeader")
 	proto.RegisterType((*PingMessage)(nil), "protocol.PingMessage")
 	proto.RegisterType((*SubscriptionMessage)(nil), "protocol.SubscriptionMessage")
 	proto.RegisterType((*AuthMessage)(nil), "protocol.AuthMessage")
+	proto.RegisterType((*AuthRequestMessage)(nil), "protocol.AuthRequestMessage")
 	proto.RegisterType((*TopicMessage)(nil), "protocol.TopicMessage")
 	proto.RegisterType((*TopicFWMessage)(nil), "protocol.TopicFWMessage")
 	proto.RegisterType((*TopicIdentityMessage)(nil), "protocol.TopicIdentityMessage")
@@ -943,55 +1445,83 @@ func init() {
 func init() { proto.RegisterFile("broker.proto", fileDescriptor_f209535e190f2bed) }
 
 var fileDescriptor_f209535e190f2bed = []byte{
-	// 788 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x94, 0x4f, 0x6f, 0xe3, 0x44,
-	0x18, 0xc6, 0xeb, 0xc4, 0x71, 0xd3, 0x37, 0x89, 0x99, 0x0e, 0xd9, 0x12, 0x10, 0x48, 0xc1, 0xa7,
-	0x10, 0xa4, 0x4a, 0x94, 0x13, 0x5c, 0x90, 0xeb, 0x8c, 0x5b, 0x0b, 0xc7, 0x8e, 0xc6, 0x0e, 0xd6,
-	0x22, 0x24, 0xcb, 0x49, 0xa6, 0x8b, 0x45, 0xe2, 0x89, 0x6c, 0x67, 0xa5, 0x5c, 0x38, 0x70, 0xe2,
-	0xc2, 0xd7, 0xe1, 0x43, 0x70, 0xe0, 0x33, 0xa1, 0x99, 0xb8, 0x49, 0x56, 0xec, 0x4a, 0xbb, 0xd1,
-	0x6e, 0x4f, 0x9e, 0xf7, 0x8f, 0xdf, 0xe7, 0xf7, 0xbc, 0x1e, 0x19, 0xda, 0xb3, 0x9c, 0xff, 0xc6,
-	0xf2, 0xeb, 0x75, 0xce, 0x4b, 0x8e, 0x9b, 0xf2, 0x31, 0xe7, 0x4b, 0xe3, 0x07, 0xc0, 0x16, 0xe7,
-	0xf9, 0x22, 0xcd, 0x92, 0x92, 0xe7, 0x63, 0x56, 0x14, 0xc9, 0x0b, 0x86, 0xbf, 0x02, 0xb5, 0xdc,
-	0xae, 0x59, 0x4f, 0xe9, 0x2b, 0x03, 0xfd, 0xe6, 0xd9, 0xf5, 0x63, 0xfb, 0x75, 0xd5, 0x10, 0x6e,
-	0xd7, 0x8c, 0xca, 0x16, 0xe3, 0x77, 0xd0, 0x23, 0xb6, 0x9c, 0xf3, 0x15, 0x7b, 0xf7, 0x97, 0x71,
-	0x17, 0x1a, 0xc9, 0x32, 0x4d, 0x8a, 0x5e, 0xad, 0xaf, 0x0c, 0x54, 0xba, 0x0b, 0xf0, 0xd7, 0x70,
-	0x99, 0xbc, 0x4c, 0xd2, 0x65, 0x32, 0x5b, 0xb2, 0xb8, 0x60, 0xf9, 0x4b, 0x96, 0x17, 0xbd, 0x7a,
-	0xbf, 0x3e, 0x50, 0x29, 0xda, 0x17, 0x82, 0x5d, 0xde, 0xd8, 0x80, 0x6e, 0xf1, 0x2c, 0x63, 0xf3,
-	0xf2, 0x04, 0xfd, 0x2f, 0x00, 0x1e, 0x72, 0xbe, 0x8a, 0x8f, 0x21, 0x2e, 0x44, 0xc6, 0x94, 0x20,
-	0x9f, 0x42, 0xb3, 0xe4, 0x55, 0xb1, 0x2e, 0x8b, 0xe7, 0x25, 0x97, 0x25, 0xe3, 0x4f, 0x05, 0x3a,
-	0x11, 0x9b, 0xd1, 0x72, 0xfe, 0x94, 0xb2, 0x18, 0x83, 0xba, 0x48, 0xca, 0xa4, 0xa7, 0xf6, 0x95,
-	0x41, 0x9b, 0xca, 0xb3, 0xf1, 0xaf, 0x02, 0xbd, 0x6a, 0x05, 0x29, 0xcf, 0x28, 0x7b, 0xd8, 0x14,
-	0x6c, 0xf1, 0xa4, 0x54, 0xdf, 0x81, 0x96, 0xb3, 0xa4, 0xe0, 0x99, 0xe4, 0xd2, 0x6f, 0xbe, 0x3c,
-	0xc8, 0xfc, 0x0f, 0x8c, 0xca, 0x46, 0x5a, 0xbd, 0xb0, 0x37, 0xd4, 0x38, 0x32, 0xf4, 0x3d, 0x74,
-	0x2a, 0xba, 0x7b, 0x96, 0x2c, 0x58, 0xfe, 0x2e, 0xd7, 0xd1, 0x85, 0xd6, 0x24, 0xcd, 0x5e, 0x9c,
-	0x60, 0x1f, 0x83, 0x5a, 0xa6, 0x2b, 0x26, 0x8d, 0x2b, 0x54, 0x9e, 0x8d, 0x3f, 0x14, 0xf8, 0x38,
-	0xd8, 0xcc, 0x8a, 0x79, 0x9e, 0xae, 0x85, 0x87, 0x13, 0xc6, 0x0e, 0x40, 0x7b, 0xe0, 0xf9, 0x2a,
-	0x29, 0xe5, 0x60, 0xfd, 0x06, 0x1d, 0x9a, 0x6d, 0x99, 0xa7, 0x55, 0x1d, 0x5f, 0x81, 0x56, 0xf2,
-	0x75, 0x3a, 0xdf, 0xad, 0xb7, 0x4d, 0xab, 0xc8, 0x58, 0x43, 0xcb, 0xdc, 0x94, 0xbf, 0x9e, 0xa0,
-	0x6d, 0x80, 0x9a, 0xf3, 0x25, 0xab, 0x94, 0xf5, 0x43, 0x2b, 0xe5, 0x4b, 0x46, 0x65, 0x4d, 0xd8,
-	0x9e, 0xf1, 0xc5, 0xb6, 0xd2, 0x94, 0x67, 0x61, 0xbb, 0x1d, 0x0a, 0xf1, 0xf7, 0x7f, 0x8b, 0xba,
-	0xd0, 0x90, 0xb6, 0xa4, 0xde, 0x05, 0xdd, 0x05, 0x7b, 0x08, 0xf5, 0x08, 0x22, 0x03, 0x5d, 0x32,
-	0xd8, 0xd1, 0xfb, 0xa7, 0x78, 0x9d, 0xe9, 0x7f, 0x14, 0xe8, 0x4a, 0x41, 0x67, 0xc1, 0xb2, 0x32,
-	0x2d, 0xb7, 0x4f, 0x65, 0xfe, 0x33, 0x68, 0xa6, 0x95, 0x64, 0xb5, 0x80, 0x7d, 0xbc, 0xff, 0x82,
-	0x8d, 0xb7, 0xf8, 0x82, 0xda, 0x91, 0x99, 0xbf, 0x15, 0xb8, 0x7a, 0xc5, 0xcc, 0x87, 0xd8, 0xe2,
-	0x31, 0x78, 0xfd, 0x0d, 0xe0, 0xea, 0x5b, 0x80, 0x37, 0x0e, 0xe0, 0xc3, 0xbf, 0x6a, 0xd0, 0x3a,
-	0x02, 0xc1, 0x3d, 0xe8, 0x4e, 0xbd, 0x1f, 0x3d, 0x3f, 0xf2, 0xe2, 0x31, 0x09, 0x02, 0xf3, 0x8e,
-	0xc4, 0xe1, 0xf3, 0x09, 0x41, 0x67, 0xb8, 0x05, 0xe7, 0x11, 0x71, 0x2d, 0x7f, 0x4c, 0x90, 0x22,
-	0x02, 0xcb, 0xf7, 0x3c, 0x62, 0x85, 0xa8, 0x86, 0x11, 0xb4, 0x23, 0x72, 0x4b, 0x43, 0x2b, 0xf6,
-	0x6d, 0x9b, 0x50, 0x54, 0xc7, 0x97, 0xd0, 0xa9, 0x32, 0xa6, 0x17, 0x44, 0x84, 0x22, 0x55, 0x0c,
-	0xae, 0x52, 0x8e, 0x45, 0x62, 0xcb, 0xf4, 0x46, 0xce, 0xc8, 0x0c, 0x09, 0x6a, 0xe0, 0x26, 0xa8,
-	0x13, 0xc7, 0xbb, 0x43, 0x9a, 0x18, 0x14, 0x4c, 0x6f, 0x03, 0x8b, 0x3a, 0x93, 0xd0, 0xf1, 0x3d,
-	0x74, 0x2e, 0x6a, 0xe6, 0x34, 0xbc, 0x47, 0x4d, 0x7c, 0x01, 0x8d, 0xd0, 0x9f, 0x38, 0x16, 0xba,
-	0xc0, 0x6d, 0x68, 0xca, 0x63, 0x6c, 0x47, 0x08, 0x30, 0x06, 0x7d, 0x17, 0x39, 0x23, 0xe2, 0x85,
-	0x4e, 0xf8, 0x1c, 0xb5, 0xf0, 0x33, 0xb8, 0x7c, 0x35, 0x27, 0x5a, 0xdb, 0xf8, 0x0a, 0x70, 0x45,
-	0xed, 0xf8, 0x5e, 0x4c, 0x89, 0x3d, 0x0d, 0xc8, 0x08, 0x75, 0xc4, 0x6c, 0x42, 0xa9, 0x4f, 0x91,
-	0x3e, 0xfc, 0x05, 0x54, 0xb1, 0x31, 0x81, 0xf2, 0xb8, 0x07, 0xea, 0xbb, 0xc2, 0x3f, 0x80, 0x66,
-	0xb9, 0x0e, 0xf1, 0x42, 0xa4, 0x08, 0x33, 0x96, 0x3f, 0x1e, 0x4f, 0x3d, 0xc7, 0x32, 0xe5, 0xac,
-	0x80, 0xd0, 0x9f, 0x08, 0x45, 0x35, 0xfc, 0x39, 0xf4, 0x5e, 0x57, 0x89, 0xef, 0xa7, 0xb7, 0xa8,
-	0x3e, 0xfc, 0x06, 0xb4, 0xdd, 0x4f, 0x48, 0x50, 0x3f, 0xce, 0xb7, 0x7d, 0x3a, 0x36, 0x43, 0x74,
-	0x26, 0x30, 0x26, 0xae, 0xe9, 0x78, 0x48, 0x11, 0xbe, 0xef, 0x7e, 0x76, 0x26, 0xa8, 0x36, 0xf4,
-	0xe1, 0x93, 0x37, 0xfc, 0xd3, 0x8f, 0x67, 0x50, 0x62, 0x06, 0xbe, 0x87, 0xce, 0xf0, 0x47, 0xd0,
-	0xaa, 0x14, 0xed, 0xa9, 0xeb, 0x22, 0x45, 0x24, 0xc4, 0x06, 0x63, 0xdb, 0x74, 0x5c, 0x32, 0x42,
-	0xb5, 0x99, 0x26, 0xaf, 0xc6, 0xb7, 0xff, 0x05, 0x00, 0x00, 0xff, 0xff, 0x3d, 0xe5, 0x73, 0x58,
-	0xa2, 0x08, 0x00, 0x00,
+	// 1248 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0x4f, 0x6f, 0xdb, 0xc6,
+	0x13, 0x0d, 0x25, 0x4a, 0x96, 0x46, 0x96, 0xb2, 0xda, 0x38, 0xfe, 0x29, 0xf9, 0xa5, 0xad, 0xca,
+	0x93, 0xea, 0x02, 0x06, 0xe2, 0xf6, 0x92, 0x5e, 0x0a, 0x86, 0x5e, 0x25, 0x44, 0x64, 0x52, 0x5d,
+	0x52, 0x11, 0xd2, 0x14, 0x20, 0x28, 0x6a, 0xed, 0x12, 0x96, 0x48, 0x65, 0x49, 0x19, 0xf1, 0xa1,
+	0x97, 0xde, 0x7b, 0xef, 0xb9, 0xb7, 0x7e, 0x81, 0x9e, 0x7a, 0xee, 0xa1, 0x5f, 0xaa, 0xc5, 0x2e,
+	0x29, 0x89, 0x76, 0xfe, 0x20, 0x11, 0xd2, 0x9c, 0xb4, 0x33, 0xb3, 0x3b, 0xf3, 0xde, 0xdb, 0xe1,
+	0xac, 0x60, 0x77, 0xc2, 0xe3, 0x73, 0xc6, 0x0f, 0x17, 0x3c, 0x4e, 0x63, 0x5c, 0x93, 0x3f, 0x41,
+	0x3c, 0xd3, 0xbe, 0x05, 0x6c, 0xc4, 0x31, 0x9f, 0x86, 0x91, 0x9f, 0xc6, 0xfc, 0x84, 0x25, 0x89,
+	0x7f, 0xc6, 0xf0, 0x17, 0xa0, 0xa6, 0x97, 0x0b, 0xd6, 0x51, 0xba, 0x4a, 0xaf, 0x75, 0x74, 0xfb,
+	0x70, 0xb5, 0xfd, 0x30, 0xdf, 0xe0, 0x5e, 0x2e, 0x18, 0x95, 0x5b, 0x34, 0x0a, 0xa8, 0xef, 0xcf,
+	0x66, 0x13, 0x3f, 0x38, 0x27, 0xd1, 0x74, 0x11, 0x87, 0x51, 0x8a, 0xf7, 0xa0, 0xe2, 0xcf, 0x42,
+	0x3f, 0x91, 0xe7, 0x55, 0x9a, 0x19, 0x18, 0x41, 0x79, 0xc9, 0x67, 0x9d, 0x52, 0x57, 0xe9, 0xd5,
+	0xa9, 0x58, 0xe2, 0x7d, 0xa8, 0xa6, 0x61, 0x70, 0xce, 0xd2, 0x4e, 0xb9, 0xab, 0xf4, 0x76, 0x69,
+	0x6e, 0x69, 0xcf, 0xa1, 0x6e, 0x06, 0xcc, 0x61, 0xfc, 0x82, 0x71, 0x8c, 0x41, 0x5d, 0xf2, 0x99,
+	0xc8, 0x55, 0xee, 0xd5, 0xa9, 0x5c, 0xe3, 0xbb, 0x50, 0x5b, 0x26, 0x8c, 0x47, 0xfe, 0x9c, 0xe5,
+	0xf9, 0xd6, 0x36, 0xfe, 0x14, 0x20, 0xe0, 0x6c, 0xca, 0xa2, 0x34, 0xf4, 0x67, 0x32, 0x71, 0x9d,
+	0x16, 0x3c, 0xda, 0x3f, 0x0a, 0xb4, 0xc6, 0x6c, 0x16, 0xc4, 0x73, 0xf6, 0xfe, 0x74, 0x37, 0xd4,
+	0x4a, 0x45, 0x6a, 0x5f, 0x42, 0xdb, 0xbf, 0xf0, 0xc3, 0x99, 0x3f, 0x99, 0x31, 0x2f, 0x91, 0xb8,
+	0x93, 0x4e, 0xb9, 0x5b, 0xee, 0xa9, 0x14, 0xad, 0x03, 0x19, 0x9f, 0x04, 0x9b, 0x80, 0x4f, 0x73,
+	0xc5, 0x3c, 0x96, 0x4b, 0x96, 0x74, 0xd4, 0x6e, 0xb9, 0xd7, 0x38, 0xba, 0xbb, 0xa9, 0x7d, 0x5d,
+	0x55, 0xda, 0x3e, 0xbd, 0xe6, 0x49, 0xf0, 0xd7, 0xd0, 0x08, 0x83, 0x4d, 0xc5, 0x8a, 0xcc, 0x71,
+	0x6b, 0x93, 0x63, 0xad, 0x22, 0x85, 0x70, 0xb5, 0x4c, 0xb4, 0x5f, 0x14, 0x68, 0x19, 0x71, 0x14,
+	0xb1, 0x20, 0xdd, 0x42, 0x81, 0x4f, 0x00, 0x4e, 0x79, 0x3c, 0xf7, 0x8a, 0x32, 0xd4, 0x85, 0x47,
+	0x97, 0x52, 0xdc, 0x81, 0x5a, 0x1a, 0xe7, 0xc1, 0xb2, 0x0c, 0xee, 0xa4, 0x71, 0x16, 0xda, 0x5c,
+	0xb7, 0x7a, 0xe5, 0xba, 0x7f, 0x53, 0x00, 0xe5, 0x78, 0xc2, 0x38, 0x72, 0xa5, 0xf3, 0x0d, 0x3d,
+	0x54, 0xcc, 0x5e, 0xba, 0x9a, 0x5d, 0x03, 0x95, 0xc7, 0x33, 0x26, 0x8b, 0xb6, 0x8e, 0x5a, 0x1b,
+	0x0a, 0x34, 0x9e, 0x31, 0x2a, 0x63, 0xa2, 0x6f, 0x42, 0xd9, 0x07, 0xe9, 0x65, 0x8e, 0x61, 0x6d,
+	0x0b, 0x5e, 0xec, 0xe5, 0x22, 0xe4, 0x2c, 0xf1, 0xfc, 0xb4, 0x53, 0xe9, 0x2a, 0xbd, 0x32, 0xad,
+	0xe7, 0x1e, 0x3d, 0xd5, 0x2c, 0xd8, 0x77, 0xc2, 0xb3, 0x88, 0x4d, 0x5f, 0x41, 0xba, 0xa1, 0xa5,
+	0x14, 0x69, 0xe1, 0x7b, 0x50, 0x4f, 0xc2, 0xb3, 0xc8, 0x4f, 0x97, 0x3c, 0xeb, 0xd2, 0x5d, 0xba,
+	0x71, 0x68, 0x4b, 0x68, 0x52, 0x76, 0x11, 0x9f, 0x6f, 0xd3, 0x84, 0x45, 0x1a, 0xa5, 0xb7, 0xd2,
+	0x28, 0x5f, 0xa7, 0xf1, 0x6b, 0x09, 0xda, 0x83, 0xd8, 0x9f, 0x52, 0xb6, 0x88, 0xf9, 0x96, 0xd7,
+	0xbf, 0x60, 0x8c, 0x7b, 0x41, 0xbc, 0x8c, 0x52, 0x59, 0xbd, 0x49, 0xeb, 0xc2, 0x63, 0x08, 0x07,
+	0xfe, 0x3f, 0xd4, 0xe7, 0xfe, 0x4b, 0x4f, 0x38, 0xb2, 0xfb, 0x6f, 0xd2, 0xda, 0xdc, 0x7f, 0x39,
+	0x14, 0x36, 0x7e, 0x00, 0x77, 0x26, 0x97, 0x29, 0x4b, 0x3c, 0xce, 0x02, 0x16, 0x5e, 0xb0, 0xa9,
+	0xb7, 0x60, 0xdc, 0x4b, 0x58, 0x10, 0x47, 0x53, 0x79, 0x1f, 0x2a, 0xdd, 0x97, 0x1b, 0x68, 0x1e,
+	0x1f, 0x32, 0xee, 0xc8, 0x28, 0xbe, 0x0f, 0xb7, 0xb3, 0xa3, 0x09, 0x8b, 0xd2, 0xe2, 0xb1, 0x8a,
+	0x3c, 0x86, 0x65, 0xd0, 0x61, 0x51, 0xba, 0x39, 0x72, 0x08, 0xb7, 0xe6, 0x19, 0xfc, 0xa4, 0x78,
+	0xa0, 0xda, 0x55, 0x7a, 0x0a, 0x6d, 0xaf, 0x42, 0xeb, 0xfd, 0xda, 0xef, 0x0a, 0x34, 0xc7, 0x6c,
+	0x42, 0xd3, 0xe0, 0xa3, 0x7e, 0x15, 0x18, 0xd4, 0xa9, 0x9f, 0xfa, 0x79, 0x3f, 0xca, 0x35, 0xfe,
+	0x2c, 0xfb, 0xae, 0x39, 0x4b, 0x52, 0x9f, 0x67, 0xcd, 0x58, 0x93, 0x9f, 0x30, 0xcd, 0x3c, 0xda,
+	0x5f, 0x25, 0xe8, 0x6c, 0x1a, 0x91, 0xb2, 0xd3, 0x65, 0xc2, 0xa6, 0x1f, 0x15, 0xf6, 0x03, 0xa8,
+	0x72, 0xe6, 0x27, 0x71, 0x24, 0x81, 0xb7, 0x8e, 0x3e, 0xdf, 0x94, 0x79, 0x05, 0x18, 0x95, 0x1b,
+	0x69, 0x7e, 0x60, 0xcd, 0xb8, 0x52, 0x60, 0xfc, 0xda, 0x09, 0x5a, 0x7d, 0xaf, 0x09, 0xba, 0xb3,
+	0xc5, 0x04, 0xd5, 0xbe, 0x81, 0x66, 0xae, 0xca, 0x63, 0xe6, 0x4f, 0x19, 0x7f, 0x9f, 0xa7, 0xef,
+	0x27, 0x68, 0x0c, 0xc3, 0xe8, 0x6c, 0x0b, 0xd9, 0x31, 0xa8, 0x69, 0x98, 0xbf, 0x5d, 0x0a, 0x95,
+	0x6b, 0xf1, 0x3c, 0x26, 0xec, 0x45, 0xfe, 0xcd, 0x88, 0xe5, 0xb5, 0xcb, 0x51, 0xaf, 0x5d, 0x8e,
+	0xf6, 0xb3, 0x02, 0xb7, 0x9c, 0xe5, 0x24, 0x09, 0x78, 0xb8, 0x10, 0x62, 0x6f, 0x81, 0xa3, 0x07,
+	0xd5, 0xd3, 0x98, 0xcf, 0xfd, 0xec, 0x43, 0x6e, 0x1d, 0xa1, 0x82, 0x78, 0xd2, 0x4f, 0xf3, 0xb8,
+	0x1c, 0x72, 0xf1, 0x22, 0x0c, 0x92, 0xf5, 0x53, 0x2d, 0x2d, 0x6d, 0x01, 0x0d, 0x7d, 0x99, 0xfe,
+	0xb8, 0x45, 0xed, 0xd5, 0xbc, 0x2e, 0xbd, 0x65, 0x5e, 0x63, 0x50, 0x27, 0xf1, 0xf4, 0x32, 0xaf,
+	0x29, 0xd7, 0xda, 0x73, 0xc0, 0xa2, 0x22, 0x65, 0x2f, 0x96, 0x2c, 0x49, 0xb7, 0x9b, 0x9e, 0x53,
+	0xe6, 0x4f, 0x67, 0x61, 0x94, 0x15, 0x2f, 0xd3, 0xb5, 0x2d, 0x34, 0xdd, 0x75, 0x05, 0xb3, 0x0f,
+	0xff, 0x2d, 0xed, 0x41, 0x45, 0x6a, 0x96, 0xff, 0x25, 0xc9, 0x8c, 0x35, 0x43, 0xb5, 0xc0, 0x30,
+	0x82, 0x96, 0xc4, 0xd0, 0x1f, 0x7f, 0x78, 0x14, 0xaf, 0x53, 0xf4, 0x6f, 0x05, 0xf6, 0x64, 0x41,
+	0x33, 0x7f, 0x44, 0x3e, 0x16, 0xf9, 0xb7, 0x3d, 0xc7, 0xab, 0xf6, 0xa8, 0xbc, 0x43, 0x7b, 0x54,
+	0x0b, 0x64, 0xfe, 0x50, 0x60, 0xff, 0x0a, 0x99, 0xff, 0x42, 0xc5, 0x22, 0xf0, 0xf2, 0x1b, 0x80,
+	0xab, 0xef, 0x00, 0xbc, 0xb2, 0x01, 0x7e, 0xf0, 0x67, 0x09, 0x1a, 0x05, 0x20, 0xb8, 0x03, 0x7b,
+	0x23, 0xeb, 0x89, 0x65, 0x8f, 0x2d, 0xef, 0x84, 0x38, 0x8e, 0xfe, 0x88, 0x78, 0xee, 0xb3, 0x21,
+	0x41, 0x37, 0x70, 0x03, 0x76, 0xc6, 0x64, 0x60, 0xd8, 0x27, 0x04, 0x29, 0xc2, 0x30, 0x6c, 0xcb,
+	0x22, 0x86, 0x8b, 0x4a, 0x18, 0xc1, 0xee, 0x98, 0x3c, 0xa4, 0xae, 0xe1, 0xd9, 0xfd, 0x3e, 0xa1,
+	0xa8, 0x8c, 0xdb, 0xd0, 0xcc, 0x3d, 0xba, 0xe5, 0x8c, 0x09, 0x45, 0xaa, 0x48, 0x9c, 0xbb, 0x4c,
+	0x83, 0x78, 0x86, 0x6e, 0x1d, 0x9b, 0xc7, 0xba, 0x4b, 0x50, 0x05, 0xd7, 0x40, 0x1d, 0x9a, 0xd6,
+	0x23, 0x54, 0x15, 0x89, 0x9c, 0xd1, 0x43, 0xc7, 0xa0, 0xe6, 0xd0, 0x35, 0x6d, 0x0b, 0xed, 0x88,
+	0x98, 0x3e, 0x72, 0x1f, 0xa3, 0x1a, 0xae, 0x43, 0xc5, 0xb5, 0x87, 0xa6, 0x81, 0xea, 0x78, 0x17,
+	0x6a, 0x72, 0xe9, 0xf5, 0xc7, 0x08, 0x30, 0x86, 0x56, 0x66, 0x99, 0xc7, 0xc4, 0x72, 0x4d, 0xf7,
+	0x19, 0x6a, 0xe0, 0xdb, 0xd0, 0xbe, 0xea, 0x13, 0x5b, 0x77, 0xf1, 0x3e, 0xe0, 0x1c, 0xb5, 0x69,
+	0x5b, 0x1e, 0x25, 0xfd, 0x91, 0x43, 0x8e, 0x51, 0x53, 0xe4, 0x26, 0x94, 0xda, 0x14, 0xb5, 0x04,
+	0x04, 0x51, 0xd0, 0xa3, 0xe4, 0xbb, 0x11, 0x71, 0x5c, 0x74, 0x13, 0x03, 0x54, 0x29, 0x79, 0x6a,
+	0x3f, 0x21, 0x08, 0xe1, 0x9b, 0xd0, 0x18, 0xd8, 0xfa, 0xb1, 0x47, 0xc9, 0xd0, 0xa6, 0x2e, 0x6a,
+	0x1f, 0xfc, 0x00, 0xaa, 0x10, 0x58, 0x1c, 0x5b, 0xc9, 0x46, 0xed, 0x81, 0x90, 0x0b, 0xa0, 0x6a,
+	0x0c, 0x4c, 0x62, 0xb9, 0x48, 0x11, 0xdc, 0x0d, 0xfb, 0xe4, 0x64, 0x64, 0x99, 0x86, 0x2e, 0x4b,
+	0x3b, 0x84, 0x3e, 0x25, 0x14, 0x95, 0xf0, 0x3d, 0xe8, 0xbc, 0x2e, 0xe2, 0x3d, 0x1e, 0x3d, 0x44,
+	0xe5, 0x83, 0xfb, 0x50, 0xcd, 0x06, 0xa2, 0x20, 0xb9, 0xca, 0xdf, 0xb7, 0xe9, 0x89, 0xee, 0xa2,
+	0x1b, 0x02, 0xf5, 0x70, 0xa0, 0x9b, 0x16, 0x52, 0x84, 0x4c, 0x8f, 0xbe, 0x37, 0x87, 0xa8, 0x74,
+	0x60, 0xc3, 0xff, 0xde, 0xf0, 0x10, 0x16, 0x73, 0x50, 0xa2, 0x3b, 0xb6, 0x85, 0x6e, 0x08, 0x42,
+	0x79, 0xc5, 0xfe, 0x68, 0x30, 0x40, 0x8a, 0x70, 0x48, 0xfe, 0x7d, 0xdd, 0x1c, 0x90, 0x63, 0x54,
+	0x9a, 0x54, 0x65, 0x27, 0x7d, 0xf5, 0xef, 0x00, 0x38, 0x20, 0xf9, 0xb1, 0xcb, 0x0d, 0x00, 0x00,
 }
@@ -23,6 +23,66 @@ export namespace CoordinatorMessage {
   }
 }
 
+export class FallbackEndpoint extends jspb.Message {
+  getAlias(): number;
+  setAlias(value: number): void;
+
+  getUrl(): string;
+  setUrl(value: string): void;
+
+  getTicket(): Uint8Array | string;
+  getTicket_asU8(): Uint8Array;
+  getTicket_asB64(): string;
+  setTicket(value: Uint8Array | string): void;
+
+  serializeBinary(): Uint8Array;
+  toObject(includeInstance?: boolean): FallbackEndpoint.AsObject;
+  static toObject(includeInstance: boolean, msg: FallbackEndpoint): FallbackEndpoint.AsObject;
+  static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+  static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+  static serializeBinaryToWriter(message: FallbackEndpoint, writer: jspb.BinaryWriter): void;
+  static deserializeBinary(bytes: Uint8Array): FallbackEndpoint;
+  static deserializeBinaryFromReader(message: FallbackEndpoint, reader: jspb.BinaryReader): FallbackEndpoint;
+}
+
+export namespace FallbackEndpoint {
+  export type AsObject = {
+    alias: number,
+    url: string,
+    ticket: Uint8Array | string,
+  }
+}
+
+export class IceServer extends jspb.Message {
+  clearUrlsList(): void;
+  getUrlsList(): Array<string>;
+  setUrlsList(value: Array<string>): void;
+  addUrls(value: string, index?: number): string;
+
+  getUsername(): string;
+  setUsername(value: string): void;
+
+  getCredential(): string;
+  setCredential(value: string): void;
+
+  serializeBinary(): Uint8Array;
+  toObject(includeInstance?: boolean): IceServer.AsObject;
+  static toObject(includeInstance: boolean, msg: IceServer): IceServer.AsObject;
+  static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+  static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+  static serializeBinaryToWriter(message: IceServer, writer: jspb.BinaryWriter): void;
+  static deserializeBinary(bytes: Uint8Array): IceServer;
+  static deserializeBinaryFromReader(message: IceServer, reader: jspb.BinaryReader): IceServer;
+}
+
+export namespace IceServer {
+  export type AsObject = {
+    urlsList: Array<string>,
+    username: string,
+    credential: string,
+  }
+}
+
 export class WelcomeMessage extends jspb.Message {
   getType(): MessageType;
   setType(value: MessageType): void;
@@ -35,6 +95,16 @@ export class WelcomeMessage extends jspb.Message {
   setAvailableServersList(value: Array<number>): void;
   addAvailableServers(value: number, index?: number): number;
 
+  clearFallbackEndpointsList(): void;
+  getFallbackEndpointsList(): Array<FallbackEndpoint>;
+  setFallbackEndpointsList(value: Array<FallbackEndpoint>): void;
+  addFallbackEndpoints(value?: FallbackEndpoint, index?: number): FallbackEndpoint;
+
+  clearIceServersList(): void;
+  getIceServersList(): Array<IceServer>;
+  setIceServersList(value: Array<IceServer>): void;
+  addIceServers(value?: IceServer, index?: number): IceServer;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): WelcomeMessage.AsObject;
   static toObject(includeInstance: boolean, msg: WelcomeMessage): WelcomeMessage.AsObject;
@@ -50,6 +120,8 @@ export namespace WelcomeMessage {
     type: MessageType,
     alias: number,
     availableServersList: Array<number>,
+    fallbackEndpointsList: Array<FallbackEndpoint.AsObject>,
+    iceServersList: Array<IceServer.AsObject>,
   }
 }
 
@@ -63,6 +135,11 @@ export class ConnectMessage extends jspb.Message {
   getToAlias(): number;
   setToAlias(value: number): void;
 
+  getTicket(): Uint8Array | string;
+  getTicket_asU8(): Uint8Array;
+  getTicket_asB64(): string;
+  setTicket(value: Uint8Array | string): void;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): ConnectMessage.AsObject;
   static toObject(includeInstance: boolean, msg: ConnectMessage): ConnectMessage.AsObject;
@@ -78,6 +155,143 @@ export namespace ConnectMessage {
     type: MessageType,
     fromAlias: number,
     toAlias: number,
+    ticket: Uint8Array | string,
+  }
+}
+
+export class ConnectionTicket extends jspb.Message {
+  getAlias(): number;
+  setAlias(value: number): void;
+
+  getToAlias(): number;
+  setToAlias(value: number): void;
+
+  getRole(): Role;
+  setRole(value: Role): void;
+
+  getIdentity(): Uint8Array | string;
+  getIdentity_asU8(): Uint8Array;
+  getIdentity_asB64(): string;
+  setIdentity(value: Uint8Array | string): void;
+
+  getExpiresAt(): number;
+  setExpiresAt(value: number): void;
+
+  serializeBinary(): Uint8Array;
+  toObject(includeInstance?: boolean): ConnectionTicket.AsObject;
+  static toObject(includeInstance: boolean, msg: ConnectionTicket): ConnectionTicket.AsObject;
+  static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+  static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+  static serializeBinaryToWriter(message: ConnectionTicket, writer: jspb.BinaryWriter): void;
+  static deserializeBinary(bytes: Uint8Array): ConnectionTicket;
+  static deserializeBinaryFromReader(message: ConnectionTicket, reader: jspb.BinaryReader): ConnectionTicket;
+}
+
+export namespace ConnectionTicket {
+  export type AsObject = {
+    alias: number,
+    toAlias: number,
+    role: Role,
+    identity: Uint8Array | string,
+    expiresAt: number,
+  }
+}
+
+export class SignedConnectionTicket extends jspb.Message {
+  getTicket(): Uint8Array | string;
+  getTicket_asU8(): Uint8Array;
+  getTicket_asB64(): string;
+  setTicket(value: Uint8Array | string): void;
+
+  getSignature(): Uint8Array | string;
+  getSignature_asU8(): Uint8Array;
+  getSignature_asB64(): string;
+  setSignature(value: Uint8Array | string): void;
+
+  serializeBinary(): Uint8Array;
+  toObject(includeInstance?: boolean): SignedConnectionTicket.AsObject;
+  static toObject(includeInstance: boolean, msg: SignedConnectionTicket): SignedConnectionTicket.AsObject;
+  static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+  static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+  static serializeBinaryToWriter(message: SignedConnectionTicket, writer: jspb.BinaryWriter): void;
+  static deserializeBinary(bytes: Uint8Array): SignedConnectionTicket;
+  static deserializeBinaryFromReader(message: SignedConnectionTicket, reader: jspb.BinaryReader): SignedConnectionTicket;
+}
+
+export namespace SignedConnectionTicket {
+  export type AsObject = {
+    ticket: Uint8Array | string,
+    signature: Uint8Array | string,
+  }
+}
+
+export class RevokeMessage extends jspb.Message {
+  getType(): MessageType;
+  setType(value: MessageType): void;
+
+  getIdentity(): Uint8Array | string;
+  getIdentity_asU8(): Uint8Array;
+  getIdentity_asB64(): string;
+  setIdentity(value: Uint8Array | string): void;
+
+  getExpiresAt(): number;
+  setExpiresAt(value: number): void;
+
+  serializeBinary(): Uint8Array;
+  toObject(includeInstance?: boolean): RevokeMessage.AsObject;
+  static toObject(includeInstance: boolean, msg: RevokeMessage): RevokeMessage.AsObject;
+  static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+  static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+  static serializeBinaryToWriter(message: RevokeMessage, writer: jspb.BinaryWriter): void;
+  static deserializeBinary(bytes: Uint8Array): RevokeMessage;
+  static deserializeBinaryFromReader(message: RevokeMessage, reader: jspb.BinaryReader): RevokeMessage;
+}
+
+export namespace RevokeMessage {
+  export type AsObject = {
+    type: MessageType,
+    identity: Uint8Array | string,
+    expiresAt: number,
+  }
+}
+
+export class LoadReportMessage extends jspb.Message {
+  getType(): MessageType;
+  setType(value: MessageType): void;
+
+  getPeerCount(): number;
+  setPeerCount(value: number): void;
+
+  getMaxPeers(): number;
+  setMaxPeers(value: number): void;
+
+  getBytesReceivedPerSecond(): number;
+  setBytesReceivedPerSecond(value: number): void;
+
+  getBytesSentPerSecond(): number;
+  setBytesSentPerSecond(value: number): void;
+
+  getMessagesPerSecond(): number;
+  setMessagesPerSecond(value: number): void;
+
+  serializeBinary(): Uint8Array;
+  toObject(includeInstance?: boolean): LoadReportMessage.AsObject;
+  static toObject(includeInstance: boolean, msg: LoadReportMessage): LoadReportMessage.AsObject;
+  static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+  static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+  static serializeBinaryToWriter(message: LoadReportMessage, writer: jspb.BinaryWriter): void;
+  static deserializeBinary(bytes: Uint8Array): LoadReportMessage;
+  static deserializeBinaryFromReader(message: LoadReportMessage, reader: jspb.BinaryReader): LoadReportMessage;
+}
+
+export namespace LoadReportMessage {
+  export type AsObject = {
+    type: MessageType,
+    peerCount: number,
+    maxPeers: number,
+    bytesReceivedPerSecond: number,
+    bytesSentPerSecond: number,
+    messagesPerSecond: number,
   }
 }
 
@@ -96,6 +310,9 @@ export class WebRtcMessage extends jspb.Message {
   getData_asB64(): string;
   setData(value: Uint8Array | string): void;
 
+  getIceRestart(): boolean;
+  setIceRestart(value: boolean): void;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): WebRtcMessage.AsObject;
   static toObject(includeInstance: boolean, msg: WebRtcMessage): WebRtcMessage.AsObject;
@@ -112,6 +329,7 @@ export namespace WebRtcMessage {
     fromAlias: number,
     toAlias: number,
     data: Uint8Array | string,
+    iceRestart: boolean,
   }
 }
 
@@ -133,6 +351,16 @@ export class ConnectionRefusedMessage extends jspb.Message {
   getData_asB64(): string;
   setData(value: Uint8Array | string): void;
 
+  clearAvailableServersList(): void;
+  getAvailableServersList(): Array<number>;
+  setAvailableServersList(value: Array<number>): void;
+  addAvailableServers(value: number, index?: number): number;
+
+  clearFallbackEndpointsList(): void;
+  getFallbackEndpointsList(): Array<FallbackEndpoint>;
+  setFallbackEndpointsList(value: Array<FallbackEndpoint>): void;
+  addFallbackEndpoints(value?: FallbackEndpoint, index?: number): FallbackEndpoint;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): ConnectionRefusedMessage.AsObject;
   static toObject(includeInstance: boolean, msg: ConnectionRefusedMessage): ConnectionRefusedMessage.AsObject;
@@ -150,6 +378,8 @@ export namespace ConnectionRefusedMessage {
     toAlias: number,
     reason: ConnectionRefusedReason,
     data: Uint8Array | string,
+    availableServersList: Array<number>,
+    fallbackEndpointsList: Array<FallbackEndpoint.AsObject>,
   }
 }
 
@@ -180,6 +410,12 @@ export class PingMessage extends jspb.Message {
   getTime(): number;
   setTime(value: number): void;
 
+  getSeq(): number;
+  setSeq(value: number): void;
+
+  getFromAlias(): number;
+  setFromAlias(value: number): void;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): PingMessage.AsObject;
   static toObject(includeInstance: boolean, msg: PingMessage): PingMessage.AsObject;
@@ -194,6 +430,8 @@ export namespace PingMessage {
   export type AsObject = {
     type: MessageType,
     time: number,
+    seq: number,
+    fromAlias: number,
   }
 }
 
@@ -257,6 +495,30 @@ export namespace AuthMessage {
   }
 }
 
+export class AuthRequestMessage extends jspb.Message {
+  getType(): MessageType;
+  setType(value: MessageType): void;
+
+  getDeadline(): number;
+  setDeadline(value: number): void;
+
+  serializeBinary(): Uint8Array;
+  toObject(includeInstance?: boolean): AuthRequestMessage.AsObject;
+  static toObject(includeInstance: boolean, msg: AuthRequestMessage): AuthRequestMessage.AsObject;
+  static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+  static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+  static serializeBinaryToWriter(message: AuthRequestMessage, writer: jspb.BinaryWriter): void;
+  static deserializeBinary(bytes: Uint8Array): AuthRequestMessage;
+  static deserializeBinaryFromReader(message: AuthRequestMessage, reader: jspb.BinaryReader): AuthRequestMessage;
+}
+
+export namespace AuthRequestMessage {
+  export type AsObject = {
+    type: MessageType,
+    deadline: number,
+  }
+}
+
 export class TopicMessage extends jspb.Message {
   getType(): MessageType;
   setType(value: MessageType): void;
@@ -421,6 +683,9 @@ export enum MessageType {
   TOPIC_IDENTITY_FW = 12,
   CONNECTION_REFUSED = 13,
   ERROR = 14,
+  AUTH_REQUEST = 15,
+  REVOKE = 16,
+  LOAD_REPORT = 17,
 }
 
 export enum Role {
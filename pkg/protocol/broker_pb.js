@@ -12,15 +12,22 @@ var goog = jspb;
 var global = Function('return this')();
 
 goog.exportSymbol('proto.protocol.AuthMessage', null, global);
+goog.exportSymbol('proto.protocol.AuthRequestMessage', null, global);
 goog.exportSymbol('proto.protocol.ConnectMessage', null, global);
 goog.exportSymbol('proto.protocol.ConnectionRefusedMessage', null, global);
 goog.exportSymbol('proto.protocol.ConnectionRefusedReason', null, global);
+goog.exportSymbol('proto.protocol.ConnectionTicket', null, global);
 goog.exportSymbol('proto.protocol.CoordinatorMessage', null, global);
+goog.exportSymbol('proto.protocol.FallbackEndpoint', null, global);
 goog.exportSymbol('proto.protocol.Format', null, global);
+goog.exportSymbol('proto.protocol.IceServer', null, global);
+goog.exportSymbol('proto.protocol.LoadReportMessage', null, global);
 goog.exportSymbol('proto.protocol.MessageHeader', null, global);
 goog.exportSymbol('proto.protocol.MessageType', null, global);
 goog.exportSymbol('proto.protocol.PingMessage', null, global);
+goog.exportSymbol('proto.protocol.RevokeMessage', null, global);
 goog.exportSymbol('proto.protocol.Role', null, global);
+goog.exportSymbol('proto.protocol.SignedConnectionTicket', null, global);
 goog.exportSymbol('proto.protocol.SubscriptionMessage', null, global);
 goog.exportSymbol('proto.protocol.TopicFWMessage', null, global);
 goog.exportSymbol('proto.protocol.TopicIdentityFWMessage', null, global);
@@ -171,6 +178,443 @@ proto.protocol.CoordinatorMessage.prototype.setType = function(value) {
 
 
 
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.protocol.FallbackEndpoint = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.protocol.FallbackEndpoint, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.FallbackEndpoint.displayName = 'proto.protocol.FallbackEndpoint';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.protocol.FallbackEndpoint.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.FallbackEndpoint.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.protocol.FallbackEndpoint} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.FallbackEndpoint.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    alias: jspb.Message.getFieldWithDefault(msg, 1, 0),
+    url: jspb.Message.getFieldWithDefault(msg, 2, ""),
+    ticket: msg.getTicket_asB64()
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.protocol.FallbackEndpoint}
+ */
+proto.protocol.FallbackEndpoint.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.protocol.FallbackEndpoint;
+  return proto.protocol.FallbackEndpoint.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.protocol.FallbackEndpoint} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.protocol.FallbackEndpoint}
+ */
+proto.protocol.FallbackEndpoint.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setAlias(value);
+      break;
+    case 2:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setUrl(value);
+      break;
+    case 3:
+      var value = /** @type {!Uint8Array} */ (reader.readBytes());
+      msg.setTicket(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.protocol.FallbackEndpoint.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.protocol.FallbackEndpoint.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.protocol.FallbackEndpoint} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.FallbackEndpoint.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getAlias();
+  if (f !== 0) {
+    writer.writeUint64(
+      1,
+      f
+    );
+  }
+  f = message.getUrl();
+  if (f.length > 0) {
+    writer.writeString(
+      2,
+      f
+    );
+  }
+  f = message.getTicket_asU8();
+  if (f.length > 0) {
+    writer.writeBytes(
+      3,
+      f
+    );
+  }
+};
+
+
+/**
+ * optional uint64 alias = 1;
+ * @return {number}
+ */
+proto.protocol.FallbackEndpoint.prototype.getAlias = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.FallbackEndpoint.prototype.setAlias = function(value) {
+  jspb.Message.setProto3IntField(this, 1, value);
+};
+
+
+/**
+ * optional string url = 2;
+ * @return {string}
+ */
+proto.protocol.FallbackEndpoint.prototype.getUrl = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
+};
+
+
+/** @param {string} value */
+proto.protocol.FallbackEndpoint.prototype.setUrl = function(value) {
+  jspb.Message.setProto3StringField(this, 2, value);
+};
+
+
+/**
+ * optional bytes ticket = 3;
+ * @return {!(string|Uint8Array)}
+ */
+proto.protocol.FallbackEndpoint.prototype.getTicket = function() {
+  return /** @type {!(string|Uint8Array)} */ (jspb.Message.getFieldWithDefault(this, 3, ""));
+};
+
+
+/**
+ * optional bytes ticket = 3;
+ * This is a type-conversion wrapper around `getTicket()`
+ * @return {string}
+ */
+proto.protocol.FallbackEndpoint.prototype.getTicket_asB64 = function() {
+  return /** @type {string} */ (jspb.Message.bytesAsB64(
+      this.getTicket()));
+};
+
+
+/**
+ * optional bytes ticket = 3;
+ * Note that Uint8Array is not supported on all browsers.
+ * @see http://caniuse.com/Uint8Array
+ * This is a type-conversion wrapper around `getTicket()`
+ * @return {!Uint8Array}
+ */
+proto.protocol.FallbackEndpoint.prototype.getTicket_asU8 = function() {
+  return /** @type {!Uint8Array} */ (jspb.Message.bytesAsU8(
+      this.getTicket()));
+};
+
+
+/** @param {!(string|Uint8Array)} value */
+proto.protocol.FallbackEndpoint.prototype.setTicket = function(value) {
+  jspb.Message.setProto3BytesField(this, 3, value);
+};
+
+
+
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.protocol.IceServer = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, proto.protocol.IceServer.repeatedFields_, null);
+};
+goog.inherits(proto.protocol.IceServer, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.IceServer.displayName = 'proto.protocol.IceServer';
+}
+/**
+ * List of repeated fields within this message type.
+ * @private {!Array<number>}
+ * @const
+ */
+proto.protocol.IceServer.repeatedFields_ = [1];
+
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.protocol.IceServer.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.IceServer.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.protocol.IceServer} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.IceServer.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    urlsList: jspb.Message.getRepeatedField(msg, 1),
+    username: jspb.Message.getFieldWithDefault(msg, 2, ""),
+    credential: jspb.Message.getFieldWithDefault(msg, 3, "")
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.protocol.IceServer}
+ */
+proto.protocol.IceServer.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.protocol.IceServer;
+  return proto.protocol.IceServer.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.protocol.IceServer} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.protocol.IceServer}
+ */
+proto.protocol.IceServer.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {string} */ (reader.readString());
+      msg.addUrls(value);
+      break;
+    case 2:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setUsername(value);
+      break;
+    case 3:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setCredential(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.protocol.IceServer.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.protocol.IceServer.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.protocol.IceServer} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.IceServer.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getUrlsList();
+  if (f.length > 0) {
+    writer.writeRepeatedString(
+      1,
+      f
+    );
+  }
+  f = message.getUsername();
+  if (f.length > 0) {
+    writer.writeString(
+      2,
+      f
+    );
+  }
+  f = message.getCredential();
+  if (f.length > 0) {
+    writer.writeString(
+      3,
+      f
+    );
+  }
+};
+
+
+/**
+ * repeated string urls = 1;
+ * @return {!Array<string>}
+ */
+proto.protocol.IceServer.prototype.getUrlsList = function() {
+  return /** @type {!Array<string>} */ (jspb.Message.getRepeatedField(this, 1));
+};
+
+
+/** @param {!Array<string>} value */
+proto.protocol.IceServer.prototype.setUrlsList = function(value) {
+  jspb.Message.setField(this, 1, value || []);
+};
+
+
+/**
+ * @param {!string} value
+ * @param {number=} opt_index
+ */
+proto.protocol.IceServer.prototype.addUrls = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 1, value, opt_index);
+};
+
+
+proto.protocol.IceServer.prototype.clearUrlsList = function() {
+  this.setUrlsList([]);
+};
+
+
+/**
+ * optional string username = 2;
+ * @return {string}
+ */
+proto.protocol.IceServer.prototype.getUsername = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
+};
+
+
+/** @param {string} value */
+proto.protocol.IceServer.prototype.setUsername = function(value) {
+  jspb.Message.setProto3StringField(this, 2, value);
+};
+
+
+/**
+ * optional string credential = 3;
+ * @return {string}
+ */
+proto.protocol.IceServer.prototype.getCredential = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 3, ""));
+};
+
+
+/** @param {string} value */
+proto.protocol.IceServer.prototype.setCredential = function(value) {
+  jspb.Message.setProto3StringField(this, 3, value);
+};
+
+
+
 /**
  * Generated by JsPbCodeGenerator.
  * @param {Array=} opt_data Optional initial data array, typically from a
@@ -184,17 +628,1057 @@ proto.protocol.CoordinatorMessage.prototype.setType = function(value) {
 proto.protocol.WelcomeMessage = function(opt_data) {
   jspb.Message.initialize(this, opt_data, 0, -1, proto.protocol.WelcomeMessage.repeatedFields_, null);
 };
-goog.inherits(proto.protocol.WelcomeMessage, jspb.Message);
-if (goog.DEBUG && !COMPILED) {
-  proto.protocol.WelcomeMessage.displayName = 'proto.protocol.WelcomeMessage';
-}
+goog.inherits(proto.protocol.WelcomeMessage, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.WelcomeMessage.displayName = 'proto.protocol.WelcomeMessage';
+}
+/**
+ * List of repeated fields within this message type.
+ * @private {!Array<number>}
+ * @const
+ */
+proto.protocol.WelcomeMessage.repeatedFields_ = [3,4,5];
+
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.protocol.WelcomeMessage.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.WelcomeMessage.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.protocol.WelcomeMessage} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.WelcomeMessage.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    type: jspb.Message.getFieldWithDefault(msg, 1, 0),
+    alias: jspb.Message.getFieldWithDefault(msg, 2, 0),
+    availableServersList: jspb.Message.getRepeatedField(msg, 3),
+    fallbackEndpointsList: jspb.Message.toObjectList(msg.getFallbackEndpointsList(),
+    proto.protocol.FallbackEndpoint.toObject, includeInstance),
+    iceServersList: jspb.Message.toObjectList(msg.getIceServersList(),
+    proto.protocol.IceServer.toObject, includeInstance)
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.protocol.WelcomeMessage}
+ */
+proto.protocol.WelcomeMessage.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.protocol.WelcomeMessage;
+  return proto.protocol.WelcomeMessage.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.protocol.WelcomeMessage} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.protocol.WelcomeMessage}
+ */
+proto.protocol.WelcomeMessage.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {!proto.protocol.MessageType} */ (reader.readEnum());
+      msg.setType(value);
+      break;
+    case 2:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setAlias(value);
+      break;
+    case 3:
+      var value = /** @type {!Array<number>} */ (reader.readPackedUint64());
+      msg.setAvailableServersList(value);
+      break;
+    case 4:
+      var value = new proto.protocol.FallbackEndpoint;
+      reader.readMessage(value,proto.protocol.FallbackEndpoint.deserializeBinaryFromReader);
+      msg.addFallbackEndpoints(value);
+      break;
+    case 5:
+      var value = new proto.protocol.IceServer;
+      reader.readMessage(value,proto.protocol.IceServer.deserializeBinaryFromReader);
+      msg.addIceServers(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.protocol.WelcomeMessage.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.protocol.WelcomeMessage.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.protocol.WelcomeMessage} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.WelcomeMessage.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getType();
+  if (f !== 0.0) {
+    writer.writeEnum(
+      1,
+      f
+    );
+  }
+  f = message.getAlias();
+  if (f !== 0) {
+    writer.writeUint64(
+      2,
+      f
+    );
+  }
+  f = message.getAvailableServersList();
+  if (f.length > 0) {
+    writer.writePackedUint64(
+      3,
+      f
+    );
+  }
+  f = message.getFallbackEndpointsList();
+  if (f.length > 0) {
+    writer.writeRepeatedMessage(
+      4,
+      f,
+      proto.protocol.FallbackEndpoint.serializeBinaryToWriter
+    );
+  }
+  f = message.getIceServersList();
+  if (f.length > 0) {
+    writer.writeRepeatedMessage(
+      5,
+      f,
+      proto.protocol.IceServer.serializeBinaryToWriter
+    );
+  }
+};
+
+
+/**
+ * optional MessageType type = 1;
+ * @return {!proto.protocol.MessageType}
+ */
+proto.protocol.WelcomeMessage.prototype.getType = function() {
+  return /** @type {!proto.protocol.MessageType} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
+};
+
+
+/** @param {!proto.protocol.MessageType} value */
+proto.protocol.WelcomeMessage.prototype.setType = function(value) {
+  jspb.Message.setProto3EnumField(this, 1, value);
+};
+
+
+/**
+ * optional uint64 alias = 2;
+ * @return {number}
+ */
+proto.protocol.WelcomeMessage.prototype.getAlias = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.WelcomeMessage.prototype.setAlias = function(value) {
+  jspb.Message.setProto3IntField(this, 2, value);
+};
+
+
+/**
+ * repeated uint64 available_servers = 3;
+ * @return {!Array<number>}
+ */
+proto.protocol.WelcomeMessage.prototype.getAvailableServersList = function() {
+  return /** @type {!Array<number>} */ (jspb.Message.getRepeatedField(this, 3));
+};
+
+
+/** @param {!Array<number>} value */
+proto.protocol.WelcomeMessage.prototype.setAvailableServersList = function(value) {
+  jspb.Message.setField(this, 3, value || []);
+};
+
+
+/**
+ * @param {!number} value
+ * @param {number=} opt_index
+ */
+proto.protocol.WelcomeMessage.prototype.addAvailableServers = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 3, value, opt_index);
+};
+
+
+proto.protocol.WelcomeMessage.prototype.clearAvailableServersList = function() {
+  this.setAvailableServersList([]);
+};
+
+
+/**
+ * repeated FallbackEndpoint fallback_endpoints = 4;
+ * @return {!Array<!proto.protocol.FallbackEndpoint>}
+ */
+proto.protocol.WelcomeMessage.prototype.getFallbackEndpointsList = function() {
+  return /** @type{!Array<!proto.protocol.FallbackEndpoint>} */ (
+    jspb.Message.getRepeatedWrapperField(this, proto.protocol.FallbackEndpoint, 4));
+};
+
+
+/** @param {!Array<!proto.protocol.FallbackEndpoint>} value */
+proto.protocol.WelcomeMessage.prototype.setFallbackEndpointsList = function(value) {
+  jspb.Message.setRepeatedWrapperField(this, 4, value);
+};
+
+
+/**
+ * @param {!proto.protocol.FallbackEndpoint=} opt_value
+ * @param {number=} opt_index
+ * @return {!proto.protocol.FallbackEndpoint}
+ */
+proto.protocol.WelcomeMessage.prototype.addFallbackEndpoints = function(opt_value, opt_index) {
+  return jspb.Message.addToRepeatedWrapperField(this, 4, opt_value, proto.protocol.FallbackEndpoint, opt_index);
+};
+
+
+proto.protocol.WelcomeMessage.prototype.clearFallbackEndpointsList = function() {
+  this.setFallbackEndpointsList([]);
+};
+
+
+/**
+ * repeated IceServer ice_servers = 5;
+ * @return {!Array<!proto.protocol.IceServer>}
+ */
+proto.protocol.WelcomeMessage.prototype.getIceServersList = function() {
+  return /** @type{!Array<!proto.protocol.IceServer>} */ (
+    jspb.Message.getRepeatedWrapperField(this, proto.protocol.IceServer, 5));
+};
+
+
+/** @param {!Array<!proto.protocol.IceServer>} value */
+proto.protocol.WelcomeMessage.prototype.setIceServersList = function(value) {
+  jspb.Message.setRepeatedWrapperField(this, 5, value);
+};
+
+
+/**
+ * @param {!proto.protocol.IceServer=} opt_value
+ * @param {number=} opt_index
+ * @return {!proto.protocol.IceServer}
+ */
+proto.protocol.WelcomeMessage.prototype.addIceServers = function(opt_value, opt_index) {
+  return jspb.Message.addToRepeatedWrapperField(this, 5, opt_value, proto.protocol.IceServer, opt_index);
+};
+
+
+proto.protocol.WelcomeMessage.prototype.clearIceServersList = function() {
+  this.setIceServersList([]);
+};
+
+
+
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.protocol.ConnectMessage = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.protocol.ConnectMessage, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.ConnectMessage.displayName = 'proto.protocol.ConnectMessage';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.protocol.ConnectMessage.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.ConnectMessage.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.protocol.ConnectMessage} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.ConnectMessage.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    type: jspb.Message.getFieldWithDefault(msg, 1, 0),
+    fromAlias: jspb.Message.getFieldWithDefault(msg, 2, 0),
+    toAlias: jspb.Message.getFieldWithDefault(msg, 3, 0),
+    ticket: msg.getTicket_asB64()
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.protocol.ConnectMessage}
+ */
+proto.protocol.ConnectMessage.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.protocol.ConnectMessage;
+  return proto.protocol.ConnectMessage.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.protocol.ConnectMessage} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.protocol.ConnectMessage}
+ */
+proto.protocol.ConnectMessage.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {!proto.protocol.MessageType} */ (reader.readEnum());
+      msg.setType(value);
+      break;
+    case 2:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setFromAlias(value);
+      break;
+    case 3:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setToAlias(value);
+      break;
+    case 4:
+      var value = /** @type {!Uint8Array} */ (reader.readBytes());
+      msg.setTicket(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.protocol.ConnectMessage.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.protocol.ConnectMessage.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.protocol.ConnectMessage} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.ConnectMessage.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getType();
+  if (f !== 0.0) {
+    writer.writeEnum(
+      1,
+      f
+    );
+  }
+  f = message.getFromAlias();
+  if (f !== 0) {
+    writer.writeUint64(
+      2,
+      f
+    );
+  }
+  f = message.getToAlias();
+  if (f !== 0) {
+    writer.writeUint64(
+      3,
+      f
+    );
+  }
+  f = message.getTicket_asU8();
+  if (f.length > 0) {
+    writer.writeBytes(
+      4,
+      f
+    );
+  }
+};
+
+
+/**
+ * optional MessageType type = 1;
+ * @return {!proto.protocol.MessageType}
+ */
+proto.protocol.ConnectMessage.prototype.getType = function() {
+  return /** @type {!proto.protocol.MessageType} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
+};
+
+
+/** @param {!proto.protocol.MessageType} value */
+proto.protocol.ConnectMessage.prototype.setType = function(value) {
+  jspb.Message.setProto3EnumField(this, 1, value);
+};
+
+
+/**
+ * optional uint64 from_alias = 2;
+ * @return {number}
+ */
+proto.protocol.ConnectMessage.prototype.getFromAlias = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.ConnectMessage.prototype.setFromAlias = function(value) {
+  jspb.Message.setProto3IntField(this, 2, value);
+};
+
+
+/**
+ * optional uint64 to_alias = 3;
+ * @return {number}
+ */
+proto.protocol.ConnectMessage.prototype.getToAlias = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 3, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.ConnectMessage.prototype.setToAlias = function(value) {
+  jspb.Message.setProto3IntField(this, 3, value);
+};
+
+
+/**
+ * optional bytes ticket = 4;
+ * @return {!(string|Uint8Array)}
+ */
+proto.protocol.ConnectMessage.prototype.getTicket = function() {
+  return /** @type {!(string|Uint8Array)} */ (jspb.Message.getFieldWithDefault(this, 4, ""));
+};
+
+
+/**
+ * optional bytes ticket = 4;
+ * This is a type-conversion wrapper around `getTicket()`
+ * @return {string}
+ */
+proto.protocol.ConnectMessage.prototype.getTicket_asB64 = function() {
+  return /** @type {string} */ (jspb.Message.bytesAsB64(
+      this.getTicket()));
+};
+
+
+/**
+ * optional bytes ticket = 4;
+ * Note that Uint8Array is not supported on all browsers.
+ * @see http://caniuse.com/Uint8Array
+ * This is a type-conversion wrapper around `getTicket()`
+ * @return {!Uint8Array}
+ */
+proto.protocol.ConnectMessage.prototype.getTicket_asU8 = function() {
+  return /** @type {!Uint8Array} */ (jspb.Message.bytesAsU8(
+      this.getTicket()));
+};
+
+
+/** @param {!(string|Uint8Array)} value */
+proto.protocol.ConnectMessage.prototype.setTicket = function(value) {
+  jspb.Message.setProto3BytesField(this, 4, value);
+};
+
+
+
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.protocol.ConnectionTicket = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.protocol.ConnectionTicket, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.ConnectionTicket.displayName = 'proto.protocol.ConnectionTicket';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.protocol.ConnectionTicket.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.ConnectionTicket.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.protocol.ConnectionTicket} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.ConnectionTicket.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    alias: jspb.Message.getFieldWithDefault(msg, 1, 0),
+    toAlias: jspb.Message.getFieldWithDefault(msg, 2, 0),
+    role: jspb.Message.getFieldWithDefault(msg, 3, 0),
+    identity: msg.getIdentity_asB64(),
+    expiresAt: jspb.Message.getFieldWithDefault(msg, 5, 0)
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.protocol.ConnectionTicket}
+ */
+proto.protocol.ConnectionTicket.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.protocol.ConnectionTicket;
+  return proto.protocol.ConnectionTicket.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.protocol.ConnectionTicket} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.protocol.ConnectionTicket}
+ */
+proto.protocol.ConnectionTicket.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setAlias(value);
+      break;
+    case 2:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setToAlias(value);
+      break;
+    case 3:
+      var value = /** @type {!proto.protocol.Role} */ (reader.readEnum());
+      msg.setRole(value);
+      break;
+    case 4:
+      var value = /** @type {!Uint8Array} */ (reader.readBytes());
+      msg.setIdentity(value);
+      break;
+    case 5:
+      var value = /** @type {number} */ (reader.readInt64());
+      msg.setExpiresAt(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.protocol.ConnectionTicket.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.protocol.ConnectionTicket.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.protocol.ConnectionTicket} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.ConnectionTicket.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getAlias();
+  if (f !== 0) {
+    writer.writeUint64(
+      1,
+      f
+    );
+  }
+  f = message.getToAlias();
+  if (f !== 0) {
+    writer.writeUint64(
+      2,
+      f
+    );
+  }
+  f = message.getRole();
+  if (f !== 0.0) {
+    writer.writeEnum(
+      3,
+      f
+    );
+  }
+  f = message.getIdentity_asU8();
+  if (f.length > 0) {
+    writer.writeBytes(
+      4,
+      f
+    );
+  }
+  f = message.getExpiresAt();
+  if (f !== 0) {
+    writer.writeInt64(
+      5,
+      f
+    );
+  }
+};
+
+
+/**
+ * optional uint64 alias = 1;
+ * @return {number}
+ */
+proto.protocol.ConnectionTicket.prototype.getAlias = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.ConnectionTicket.prototype.setAlias = function(value) {
+  jspb.Message.setProto3IntField(this, 1, value);
+};
+
+
+/**
+ * optional uint64 to_alias = 2;
+ * @return {number}
+ */
+proto.protocol.ConnectionTicket.prototype.getToAlias = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.ConnectionTicket.prototype.setToAlias = function(value) {
+  jspb.Message.setProto3IntField(this, 2, value);
+};
+
+
+/**
+ * optional Role role = 3;
+ * @return {!proto.protocol.Role}
+ */
+proto.protocol.ConnectionTicket.prototype.getRole = function() {
+  return /** @type {!proto.protocol.Role} */ (jspb.Message.getFieldWithDefault(this, 3, 0));
+};
+
+
+/** @param {!proto.protocol.Role} value */
+proto.protocol.ConnectionTicket.prototype.setRole = function(value) {
+  jspb.Message.setProto3EnumField(this, 3, value);
+};
+
+
+/**
+ * optional bytes identity = 4;
+ * @return {!(string|Uint8Array)}
+ */
+proto.protocol.ConnectionTicket.prototype.getIdentity = function() {
+  return /** @type {!(string|Uint8Array)} */ (jspb.Message.getFieldWithDefault(this, 4, ""));
+};
+
+
+/**
+ * optional bytes identity = 4;
+ * This is a type-conversion wrapper around `getIdentity()`
+ * @return {string}
+ */
+proto.protocol.ConnectionTicket.prototype.getIdentity_asB64 = function() {
+  return /** @type {string} */ (jspb.Message.bytesAsB64(
+      this.getIdentity()));
+};
+
+
+/**
+ * optional bytes identity = 4;
+ * Note that Uint8Array is not supported on all browsers.
+ * @see http://caniuse.com/Uint8Array
+ * This is a type-conversion wrapper around `getIdentity()`
+ * @return {!Uint8Array}
+ */
+proto.protocol.ConnectionTicket.prototype.getIdentity_asU8 = function() {
+  return /** @type {!Uint8Array} */ (jspb.Message.bytesAsU8(
+      this.getIdentity()));
+};
+
+
+/** @param {!(string|Uint8Array)} value */
+proto.protocol.ConnectionTicket.prototype.setIdentity = function(value) {
+  jspb.Message.setProto3BytesField(this, 4, value);
+};
+
+
+/**
+ * optional int64 expires_at = 5;
+ * @return {number}
+ */
+proto.protocol.ConnectionTicket.prototype.getExpiresAt = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 5, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.ConnectionTicket.prototype.setExpiresAt = function(value) {
+  jspb.Message.setProto3IntField(this, 5, value);
+};
+
+
+
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.protocol.SignedConnectionTicket = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.protocol.SignedConnectionTicket, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.SignedConnectionTicket.displayName = 'proto.protocol.SignedConnectionTicket';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.protocol.SignedConnectionTicket.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.SignedConnectionTicket.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.protocol.SignedConnectionTicket} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.SignedConnectionTicket.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    ticket: msg.getTicket_asB64(),
+    signature: msg.getSignature_asB64()
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.protocol.SignedConnectionTicket}
+ */
+proto.protocol.SignedConnectionTicket.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.protocol.SignedConnectionTicket;
+  return proto.protocol.SignedConnectionTicket.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.protocol.SignedConnectionTicket} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.protocol.SignedConnectionTicket}
+ */
+proto.protocol.SignedConnectionTicket.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {!Uint8Array} */ (reader.readBytes());
+      msg.setTicket(value);
+      break;
+    case 2:
+      var value = /** @type {!Uint8Array} */ (reader.readBytes());
+      msg.setSignature(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.protocol.SignedConnectionTicket.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.protocol.SignedConnectionTicket.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.protocol.SignedConnectionTicket} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.SignedConnectionTicket.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getTicket_asU8();
+  if (f.length > 0) {
+    writer.writeBytes(
+      1,
+      f
+    );
+  }
+  f = message.getSignature_asU8();
+  if (f.length > 0) {
+    writer.writeBytes(
+      2,
+      f
+    );
+  }
+};
+
+
 /**
- * List of repeated fields within this message type.
- * @private {!Array<number>}
- * @const
+ * optional bytes ticket = 1;
+ * @return {!(string|Uint8Array)}
+ */
+proto.protocol.SignedConnectionTicket.prototype.getTicket = function() {
+  return /** @type {!(string|Uint8Array)} */ (jspb.Message.getFieldWithDefault(this, 1, ""));
+};
+
+
+/**
+ * optional bytes ticket = 1;
+ * This is a type-conversion wrapper around `getTicket()`
+ * @return {string}
+ */
+proto.protocol.SignedConnectionTicket.prototype.getTicket_asB64 = function() {
+  return /** @type {string} */ (jspb.Message.bytesAsB64(
+      this.getTicket()));
+};
+
+
+/**
+ * optional bytes ticket = 1;
+ * Note that Uint8Array is not supported on all browsers.
+ * @see http://caniuse.com/Uint8Array
+ * This is a type-conversion wrapper around `getTicket()`
+ * @return {!Uint8Array}
+ */
+proto.protocol.SignedConnectionTicket.prototype.getTicket_asU8 = function() {
+  return /** @type {!Uint8Array} */ (jspb.Message.bytesAsU8(
+      this.getTicket()));
+};
+
+
+/** @param {!(string|Uint8Array)} value */
+proto.protocol.SignedConnectionTicket.prototype.setTicket = function(value) {
+  jspb.Message.setProto3BytesField(this, 1, value);
+};
+
+
+/**
+ * optional bytes signature = 2;
+ * @return {!(string|Uint8Array)}
+ */
+proto.protocol.SignedConnectionTicket.prototype.getSignature = function() {
+  return /** @type {!(string|Uint8Array)} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
+};
+
+
+/**
+ * optional bytes signature = 2;
+ * This is a type-conversion wrapper around `getSignature()`
+ * @return {string}
+ */
+proto.protocol.SignedConnectionTicket.prototype.getSignature_asB64 = function() {
+  return /** @type {string} */ (jspb.Message.bytesAsB64(
+      this.getSignature()));
+};
+
+
+/**
+ * optional bytes signature = 2;
+ * Note that Uint8Array is not supported on all browsers.
+ * @see http://caniuse.com/Uint8Array
+ * This is a type-conversion wrapper around `getSignature()`
+ * @return {!Uint8Array}
  */
-proto.protocol.WelcomeMessage.repeatedFields_ = [3];
+proto.protocol.SignedConnectionTicket.prototype.getSignature_asU8 = function() {
+  return /** @type {!Uint8Array} */ (jspb.Message.bytesAsU8(
+      this.getSignature()));
+};
+
+
+/** @param {!(string|Uint8Array)} value */
+proto.protocol.SignedConnectionTicket.prototype.setSignature = function(value) {
+  jspb.Message.setProto3BytesField(this, 2, value);
+};
+
+
 
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.protocol.RevokeMessage = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.protocol.RevokeMessage, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.RevokeMessage.displayName = 'proto.protocol.RevokeMessage';
+}
 
 
 if (jspb.Message.GENERATE_TO_OBJECT) {
@@ -208,8 +1692,8 @@ if (jspb.Message.GENERATE_TO_OBJECT) {
  *     for transitional soy proto support: http://goto/soy-param-migration
  * @return {!Object}
  */
-proto.protocol.WelcomeMessage.prototype.toObject = function(opt_includeInstance) {
-  return proto.protocol.WelcomeMessage.toObject(opt_includeInstance, this);
+proto.protocol.RevokeMessage.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.RevokeMessage.toObject(opt_includeInstance, this);
 };
 
 
@@ -218,15 +1702,15 @@ proto.protocol.WelcomeMessage.prototype.toObject = function(opt_includeInstance)
  * @param {boolean|undefined} includeInstance Whether to include the JSPB
  *     instance for transitional soy proto support:
  *     http://goto/soy-param-migration
- * @param {!proto.protocol.WelcomeMessage} msg The msg instance to transform.
+ * @param {!proto.protocol.RevokeMessage} msg The msg instance to transform.
  * @return {!Object}
  * @suppress {unusedLocalVariables} f is only used for nested messages
  */
-proto.protocol.WelcomeMessage.toObject = function(includeInstance, msg) {
+proto.protocol.RevokeMessage.toObject = function(includeInstance, msg) {
   var f, obj = {
     type: jspb.Message.getFieldWithDefault(msg, 1, 0),
-    alias: jspb.Message.getFieldWithDefault(msg, 2, 0),
-    availableServersList: jspb.Message.getRepeatedField(msg, 3)
+    identity: msg.getIdentity_asB64(),
+    expiresAt: jspb.Message.getFieldWithDefault(msg, 3, 0)
   };
 
   if (includeInstance) {
@@ -240,23 +1724,23 @@ proto.protocol.WelcomeMessage.toObject = function(includeInstance, msg) {
 /**
  * Deserializes binary data (in protobuf wire format).
  * @param {jspb.ByteSource} bytes The bytes to deserialize.
- * @return {!proto.protocol.WelcomeMessage}
+ * @return {!proto.protocol.RevokeMessage}
  */
-proto.protocol.WelcomeMessage.deserializeBinary = function(bytes) {
+proto.protocol.RevokeMessage.deserializeBinary = function(bytes) {
   var reader = new jspb.BinaryReader(bytes);
-  var msg = new proto.protocol.WelcomeMessage;
-  return proto.protocol.WelcomeMessage.deserializeBinaryFromReader(msg, reader);
+  var msg = new proto.protocol.RevokeMessage;
+  return proto.protocol.RevokeMessage.deserializeBinaryFromReader(msg, reader);
 };
 
 
 /**
  * Deserializes binary data (in protobuf wire format) from the
  * given reader into the given message object.
- * @param {!proto.protocol.WelcomeMessage} msg The message object to deserialize into.
+ * @param {!proto.protocol.RevokeMessage} msg The message object to deserialize into.
  * @param {!jspb.BinaryReader} reader The BinaryReader to use.
- * @return {!proto.protocol.WelcomeMessage}
+ * @return {!proto.protocol.RevokeMessage}
  */
-proto.protocol.WelcomeMessage.deserializeBinaryFromReader = function(msg, reader) {
+proto.protocol.RevokeMessage.deserializeBinaryFromReader = function(msg, reader) {
   while (reader.nextField()) {
     if (reader.isEndGroup()) {
       break;
@@ -268,12 +1752,12 @@ proto.protocol.WelcomeMessage.deserializeBinaryFromReader = function(msg, reader
       msg.setType(value);
       break;
     case 2:
-      var value = /** @type {number} */ (reader.readUint64());
-      msg.setAlias(value);
+      var value = /** @type {!Uint8Array} */ (reader.readBytes());
+      msg.setIdentity(value);
       break;
     case 3:
-      var value = /** @type {!Array<number>} */ (reader.readPackedUint64());
-      msg.setAvailableServersList(value);
+      var value = /** @type {number} */ (reader.readInt64());
+      msg.setExpiresAt(value);
       break;
     default:
       reader.skipField();
@@ -288,9 +1772,9 @@ proto.protocol.WelcomeMessage.deserializeBinaryFromReader = function(msg, reader
  * Serializes the message to binary data (in protobuf wire format).
  * @return {!Uint8Array}
  */
-proto.protocol.WelcomeMessage.prototype.serializeBinary = function() {
+proto.protocol.RevokeMessage.prototype.serializeBinary = function() {
   var writer = new jspb.BinaryWriter();
-  proto.protocol.WelcomeMessage.serializeBinaryToWriter(this, writer);
+  proto.protocol.RevokeMessage.serializeBinaryToWriter(this, writer);
   return writer.getResultBuffer();
 };
 
@@ -298,11 +1782,11 @@ proto.protocol.WelcomeMessage.prototype.serializeBinary = function() {
 /**
  * Serializes the given message to binary data (in protobuf wire
  * format), writing to the given BinaryWriter.
- * @param {!proto.protocol.WelcomeMessage} message
+ * @param {!proto.protocol.RevokeMessage} message
  * @param {!jspb.BinaryWriter} writer
  * @suppress {unusedLocalVariables} f is only used for nested messages
  */
-proto.protocol.WelcomeMessage.serializeBinaryToWriter = function(message, writer) {
+proto.protocol.RevokeMessage.serializeBinaryToWriter = function(message, writer) {
   var f = undefined;
   f = message.getType();
   if (f !== 0.0) {
@@ -311,16 +1795,16 @@ proto.protocol.WelcomeMessage.serializeBinaryToWriter = function(message, writer
       f
     );
   }
-  f = message.getAlias();
-  if (f !== 0) {
-    writer.writeUint64(
+  f = message.getIdentity_asU8();
+  if (f.length > 0) {
+    writer.writeBytes(
       2,
       f
     );
   }
-  f = message.getAvailableServersList();
-  if (f.length > 0) {
-    writer.writePackedUint64(
+  f = message.getExpiresAt();
+  if (f !== 0) {
+    writer.writeInt64(
       3,
       f
     );
@@ -332,58 +1816,68 @@ proto.protocol.WelcomeMessage.serializeBinaryToWriter = function(message, writer
  * optional MessageType type = 1;
  * @return {!proto.protocol.MessageType}
  */
-proto.protocol.WelcomeMessage.prototype.getType = function() {
+proto.protocol.RevokeMessage.prototype.getType = function() {
   return /** @type {!proto.protocol.MessageType} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
 };
 
 
 /** @param {!proto.protocol.MessageType} value */
-proto.protocol.WelcomeMessage.prototype.setType = function(value) {
+proto.protocol.RevokeMessage.prototype.setType = function(value) {
   jspb.Message.setProto3EnumField(this, 1, value);
 };
 
 
 /**
- * optional uint64 alias = 2;
- * @return {number}
+ * optional bytes identity = 2;
+ * @return {!(string|Uint8Array)}
  */
-proto.protocol.WelcomeMessage.prototype.getAlias = function() {
-  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
+proto.protocol.RevokeMessage.prototype.getIdentity = function() {
+  return /** @type {!(string|Uint8Array)} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
 };
 
 
-/** @param {number} value */
-proto.protocol.WelcomeMessage.prototype.setAlias = function(value) {
-  jspb.Message.setProto3IntField(this, 2, value);
+/**
+ * optional bytes identity = 2;
+ * This is a type-conversion wrapper around `getIdentity()`
+ * @return {string}
+ */
+proto.protocol.RevokeMessage.prototype.getIdentity_asB64 = function() {
+  return /** @type {string} */ (jspb.Message.bytesAsB64(
+      this.getIdentity()));
 };
 
 
 /**
- * repeated uint64 available_servers = 3;
- * @return {!Array<number>}
+ * optional bytes identity = 2;
+ * Note that Uint8Array is not supported on all browsers.
+ * @see http://caniuse.com/Uint8Array
+ * This is a type-conversion wrapper around `getIdentity()`
+ * @return {!Uint8Array}
  */
-proto.protocol.WelcomeMessage.prototype.getAvailableServersList = function() {
-  return /** @type {!Array<number>} */ (jspb.Message.getRepeatedField(this, 3));
+proto.protocol.RevokeMessage.prototype.getIdentity_asU8 = function() {
+  return /** @type {!Uint8Array} */ (jspb.Message.bytesAsU8(
+      this.getIdentity()));
 };
 
 
-/** @param {!Array<number>} value */
-proto.protocol.WelcomeMessage.prototype.setAvailableServersList = function(value) {
-  jspb.Message.setField(this, 3, value || []);
+/** @param {!(string|Uint8Array)} value */
+proto.protocol.RevokeMessage.prototype.setIdentity = function(value) {
+  jspb.Message.setProto3BytesField(this, 2, value);
 };
 
 
 /**
- * @param {!number} value
- * @param {number=} opt_index
+ * optional int64 expires_at = 3;
+ * @return {number}
  */
-proto.protocol.WelcomeMessage.prototype.addAvailableServers = function(value, opt_index) {
-  jspb.Message.addToRepeatedField(this, 3, value, opt_index);
+proto.protocol.RevokeMessage.prototype.getExpiresAt = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 3, 0));
 };
 
 
-proto.protocol.WelcomeMessage.prototype.clearAvailableServersList = function() {
-  this.setAvailableServersList([]);
+/** @param {number} value */
+proto.protocol.RevokeMessage.prototype.setExpiresAt = function(value) {
+  jspb.Message.setProto3IntField(this, 3, value);
 };
 
 
@@ -398,12 +1892,12 @@ proto.protocol.WelcomeMessage.prototype.clearAvailableServersList = function() {
  * @extends {jspb.Message}
  * @constructor
  */
-proto.protocol.ConnectMessage = function(opt_data) {
+proto.protocol.LoadReportMessage = function(opt_data) {
   jspb.Message.initialize(this, opt_data, 0, -1, null, null);
 };
-goog.inherits(proto.protocol.ConnectMessage, jspb.Message);
+goog.inherits(proto.protocol.LoadReportMessage, jspb.Message);
 if (goog.DEBUG && !COMPILED) {
-  proto.protocol.ConnectMessage.displayName = 'proto.protocol.ConnectMessage';
+  proto.protocol.LoadReportMessage.displayName = 'proto.protocol.LoadReportMessage';
 }
 
 
@@ -418,8 +1912,8 @@ if (jspb.Message.GENERATE_TO_OBJECT) {
  *     for transitional soy proto support: http://goto/soy-param-migration
  * @return {!Object}
  */
-proto.protocol.ConnectMessage.prototype.toObject = function(opt_includeInstance) {
-  return proto.protocol.ConnectMessage.toObject(opt_includeInstance, this);
+proto.protocol.LoadReportMessage.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.LoadReportMessage.toObject(opt_includeInstance, this);
 };
 
 
@@ -428,15 +1922,18 @@ proto.protocol.ConnectMessage.prototype.toObject = function(opt_includeInstance)
  * @param {boolean|undefined} includeInstance Whether to include the JSPB
  *     instance for transitional soy proto support:
  *     http://goto/soy-param-migration
- * @param {!proto.protocol.ConnectMessage} msg The msg instance to transform.
+ * @param {!proto.protocol.LoadReportMessage} msg The msg instance to transform.
  * @return {!Object}
  * @suppress {unusedLocalVariables} f is only used for nested messages
  */
-proto.protocol.ConnectMessage.toObject = function(includeInstance, msg) {
+proto.protocol.LoadReportMessage.toObject = function(includeInstance, msg) {
   var f, obj = {
     type: jspb.Message.getFieldWithDefault(msg, 1, 0),
-    fromAlias: jspb.Message.getFieldWithDefault(msg, 2, 0),
-    toAlias: jspb.Message.getFieldWithDefault(msg, 3, 0)
+    peerCount: jspb.Message.getFieldWithDefault(msg, 2, 0),
+    maxPeers: jspb.Message.getFieldWithDefault(msg, 3, 0),
+    bytesReceivedPerSecond: jspb.Message.getFieldWithDefault(msg, 4, 0),
+    bytesSentPerSecond: jspb.Message.getFieldWithDefault(msg, 5, 0),
+    messagesPerSecond: +jspb.Message.getFieldWithDefault(msg, 6, 0.0)
   };
 
   if (includeInstance) {
@@ -450,23 +1947,23 @@ proto.protocol.ConnectMessage.toObject = function(includeInstance, msg) {
 /**
  * Deserializes binary data (in protobuf wire format).
  * @param {jspb.ByteSource} bytes The bytes to deserialize.
- * @return {!proto.protocol.ConnectMessage}
+ * @return {!proto.protocol.LoadReportMessage}
  */
-proto.protocol.ConnectMessage.deserializeBinary = function(bytes) {
+proto.protocol.LoadReportMessage.deserializeBinary = function(bytes) {
   var reader = new jspb.BinaryReader(bytes);
-  var msg = new proto.protocol.ConnectMessage;
-  return proto.protocol.ConnectMessage.deserializeBinaryFromReader(msg, reader);
+  var msg = new proto.protocol.LoadReportMessage;
+  return proto.protocol.LoadReportMessage.deserializeBinaryFromReader(msg, reader);
 };
 
 
 /**
  * Deserializes binary data (in protobuf wire format) from the
  * given reader into the given message object.
- * @param {!proto.protocol.ConnectMessage} msg The message object to deserialize into.
+ * @param {!proto.protocol.LoadReportMessage} msg The message object to deserialize into.
  * @param {!jspb.BinaryReader} reader The BinaryReader to use.
- * @return {!proto.protocol.ConnectMessage}
+ * @return {!proto.protocol.LoadReportMessage}
  */
-proto.protocol.ConnectMessage.deserializeBinaryFromReader = function(msg, reader) {
+proto.protocol.LoadReportMessage.deserializeBinaryFromReader = function(msg, reader) {
   while (reader.nextField()) {
     if (reader.isEndGroup()) {
       break;
@@ -478,12 +1975,24 @@ proto.protocol.ConnectMessage.deserializeBinaryFromReader = function(msg, reader
       msg.setType(value);
       break;
     case 2:
-      var value = /** @type {number} */ (reader.readUint64());
-      msg.setFromAlias(value);
+      var value = /** @type {number} */ (reader.readUint32());
+      msg.setPeerCount(value);
       break;
     case 3:
+      var value = /** @type {number} */ (reader.readUint32());
+      msg.setMaxPeers(value);
+      break;
+    case 4:
       var value = /** @type {number} */ (reader.readUint64());
-      msg.setToAlias(value);
+      msg.setBytesReceivedPerSecond(value);
+      break;
+    case 5:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setBytesSentPerSecond(value);
+      break;
+    case 6:
+      var value = /** @type {number} */ (reader.readDouble());
+      msg.setMessagesPerSecond(value);
       break;
     default:
       reader.skipField();
@@ -498,9 +2007,9 @@ proto.protocol.ConnectMessage.deserializeBinaryFromReader = function(msg, reader
  * Serializes the message to binary data (in protobuf wire format).
  * @return {!Uint8Array}
  */
-proto.protocol.ConnectMessage.prototype.serializeBinary = function() {
+proto.protocol.LoadReportMessage.prototype.serializeBinary = function() {
   var writer = new jspb.BinaryWriter();
-  proto.protocol.ConnectMessage.serializeBinaryToWriter(this, writer);
+  proto.protocol.LoadReportMessage.serializeBinaryToWriter(this, writer);
   return writer.getResultBuffer();
 };
 
@@ -508,11 +2017,11 @@ proto.protocol.ConnectMessage.prototype.serializeBinary = function() {
 /**
  * Serializes the given message to binary data (in protobuf wire
  * format), writing to the given BinaryWriter.
- * @param {!proto.protocol.ConnectMessage} message
+ * @param {!proto.protocol.LoadReportMessage} message
  * @param {!jspb.BinaryWriter} writer
  * @suppress {unusedLocalVariables} f is only used for nested messages
  */
-proto.protocol.ConnectMessage.serializeBinaryToWriter = function(message, writer) {
+proto.protocol.LoadReportMessage.serializeBinaryToWriter = function(message, writer) {
   var f = undefined;
   f = message.getType();
   if (f !== 0.0) {
@@ -521,20 +2030,41 @@ proto.protocol.ConnectMessage.serializeBinaryToWriter = function(message, writer
       f
     );
   }
-  f = message.getFromAlias();
+  f = message.getPeerCount();
   if (f !== 0) {
-    writer.writeUint64(
+    writer.writeUint32(
       2,
       f
     );
   }
-  f = message.getToAlias();
+  f = message.getMaxPeers();
   if (f !== 0) {
-    writer.writeUint64(
+    writer.writeUint32(
       3,
       f
     );
   }
+  f = message.getBytesReceivedPerSecond();
+  if (f !== 0) {
+    writer.writeUint64(
+      4,
+      f
+    );
+  }
+  f = message.getBytesSentPerSecond();
+  if (f !== 0) {
+    writer.writeUint64(
+      5,
+      f
+    );
+  }
+  f = message.getMessagesPerSecond();
+  if (f !== 0.0) {
+    writer.writeDouble(
+      6,
+      f
+    );
+  }
 };
 
 
@@ -542,47 +2072,92 @@ proto.protocol.ConnectMessage.serializeBinaryToWriter = function(message, writer
  * optional MessageType type = 1;
  * @return {!proto.protocol.MessageType}
  */
-proto.protocol.ConnectMessage.prototype.getType = function() {
+proto.protocol.LoadReportMessage.prototype.getType = function() {
   return /** @type {!proto.protocol.MessageType} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
 };
 
 
 /** @param {!proto.protocol.MessageType} value */
-proto.protocol.ConnectMessage.prototype.setType = function(value) {
+proto.protocol.LoadReportMessage.prototype.setType = function(value) {
   jspb.Message.setProto3EnumField(this, 1, value);
 };
 
 
 /**
- * optional uint64 from_alias = 2;
+ * optional uint32 peer_count = 2;
  * @return {number}
  */
-proto.protocol.ConnectMessage.prototype.getFromAlias = function() {
+proto.protocol.LoadReportMessage.prototype.getPeerCount = function() {
   return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
 };
 
 
 /** @param {number} value */
-proto.protocol.ConnectMessage.prototype.setFromAlias = function(value) {
+proto.protocol.LoadReportMessage.prototype.setPeerCount = function(value) {
   jspb.Message.setProto3IntField(this, 2, value);
 };
 
 
 /**
- * optional uint64 to_alias = 3;
+ * optional uint32 max_peers = 3;
  * @return {number}
  */
-proto.protocol.ConnectMessage.prototype.getToAlias = function() {
+proto.protocol.LoadReportMessage.prototype.getMaxPeers = function() {
   return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 3, 0));
 };
 
 
 /** @param {number} value */
-proto.protocol.ConnectMessage.prototype.setToAlias = function(value) {
+proto.protocol.LoadReportMessage.prototype.setMaxPeers = function(value) {
   jspb.Message.setProto3IntField(this, 3, value);
 };
 
 
+/**
+ * optional uint64 bytes_received_per_second = 4;
+ * @return {number}
+ */
+proto.protocol.LoadReportMessage.prototype.getBytesReceivedPerSecond = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 4, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.LoadReportMessage.prototype.setBytesReceivedPerSecond = function(value) {
+  jspb.Message.setProto3IntField(this, 4, value);
+};
+
+
+/**
+ * optional uint64 bytes_sent_per_second = 5;
+ * @return {number}
+ */
+proto.protocol.LoadReportMessage.prototype.getBytesSentPerSecond = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 5, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.LoadReportMessage.prototype.setBytesSentPerSecond = function(value) {
+  jspb.Message.setProto3IntField(this, 5, value);
+};
+
+
+/**
+ * optional double messages_per_second = 6;
+ * @return {number}
+ */
+proto.protocol.LoadReportMessage.prototype.getMessagesPerSecond = function() {
+  return /** @type {number} */ (+jspb.Message.getFieldWithDefault(this, 6, 0.0));
+};
+
+
+/** @param {number} value */
+proto.protocol.LoadReportMessage.prototype.setMessagesPerSecond = function(value) {
+  jspb.Message.setProto3FloatField(this, 6, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -633,7 +2208,8 @@ proto.protocol.WebRtcMessage.toObject = function(includeInstance, msg) {
     type: jspb.Message.getFieldWithDefault(msg, 1, 0),
     fromAlias: jspb.Message.getFieldWithDefault(msg, 2, 0),
     toAlias: jspb.Message.getFieldWithDefault(msg, 3, 0),
-    data: msg.getData_asB64()
+    data: msg.getData_asB64(),
+    iceRestart: jspb.Message.getFieldWithDefault(msg, 5, false)
   };
 
   if (includeInstance) {
@@ -686,6 +2262,10 @@ proto.protocol.WebRtcMessage.deserializeBinaryFromReader = function(msg, reader)
       var value = /** @type {!Uint8Array} */ (reader.readBytes());
       msg.setData(value);
       break;
+    case 5:
+      var value = /** @type {boolean} */ (reader.readBool());
+      msg.setIceRestart(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -743,6 +2323,13 @@ proto.protocol.WebRtcMessage.serializeBinaryToWriter = function(message, writer)
       f
     );
   }
+  f = message.getIceRestart();
+  if (f) {
+    writer.writeBool(
+      5,
+      f
+    );
+  }
 };
 
 
@@ -830,6 +2417,23 @@ proto.protocol.WebRtcMessage.prototype.setData = function(value) {
 };
 
 
+/**
+ * optional bool ice_restart = 5;
+ * Note that Boolean fields may be set to 0/1 when serialized from a Java server.
+ * You should avoid comparisons like {@code val === true/false} in those cases.
+ * @return {boolean}
+ */
+proto.protocol.WebRtcMessage.prototype.getIceRestart = function() {
+  return /** @type {boolean} */ (jspb.Message.getFieldWithDefault(this, 5, false));
+};
+
+
+/** @param {boolean} value */
+proto.protocol.WebRtcMessage.prototype.setIceRestart = function(value) {
+  jspb.Message.setProto3BooleanField(this, 5, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -842,12 +2446,19 @@ proto.protocol.WebRtcMessage.prototype.setData = function(value) {
  * @constructor
  */
 proto.protocol.ConnectionRefusedMessage = function(opt_data) {
-  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+  jspb.Message.initialize(this, opt_data, 0, -1, proto.protocol.ConnectionRefusedMessage.repeatedFields_, null);
 };
 goog.inherits(proto.protocol.ConnectionRefusedMessage, jspb.Message);
 if (goog.DEBUG && !COMPILED) {
   proto.protocol.ConnectionRefusedMessage.displayName = 'proto.protocol.ConnectionRefusedMessage';
 }
+/**
+ * List of repeated fields within this message type.
+ * @private {!Array<number>}
+ * @const
+ */
+proto.protocol.ConnectionRefusedMessage.repeatedFields_ = [6,7];
+
 
 
 if (jspb.Message.GENERATE_TO_OBJECT) {
@@ -881,7 +2492,10 @@ proto.protocol.ConnectionRefusedMessage.toObject = function(includeInstance, msg
     fromAlias: jspb.Message.getFieldWithDefault(msg, 2, 0),
     toAlias: jspb.Message.getFieldWithDefault(msg, 3, 0),
     reason: jspb.Message.getFieldWithDefault(msg, 4, 0),
-    data: msg.getData_asB64()
+    data: msg.getData_asB64(),
+    availableServersList: jspb.Message.getRepeatedField(msg, 6),
+    fallbackEndpointsList: jspb.Message.toObjectList(msg.getFallbackEndpointsList(),
+    proto.protocol.FallbackEndpoint.toObject, includeInstance)
   };
 
   if (includeInstance) {
@@ -938,6 +2552,15 @@ proto.protocol.ConnectionRefusedMessage.deserializeBinaryFromReader = function(m
       var value = /** @type {!Uint8Array} */ (reader.readBytes());
       msg.setData(value);
       break;
+    case 6:
+      var value = /** @type {!Array<number>} */ (reader.readPackedUint64());
+      msg.setAvailableServersList(value);
+      break;
+    case 7:
+      var value = new proto.protocol.FallbackEndpoint;
+      reader.readMessage(value,proto.protocol.FallbackEndpoint.deserializeBinaryFromReader);
+      msg.addFallbackEndpoints(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -1002,6 +2625,21 @@ proto.protocol.ConnectionRefusedMessage.serializeBinaryToWriter = function(messa
       f
     );
   }
+  f = message.getAvailableServersList();
+  if (f.length > 0) {
+    writer.writePackedUint64(
+      6,
+      f
+    );
+  }
+  f = message.getFallbackEndpointsList();
+  if (f.length > 0) {
+    writer.writeRepeatedMessage(
+      7,
+      f,
+      proto.protocol.FallbackEndpoint.serializeBinaryToWriter
+    );
+  }
 };
 
 
@@ -1104,6 +2742,66 @@ proto.protocol.ConnectionRefusedMessage.prototype.setData = function(value) {
 };
 
 
+/**
+ * repeated uint64 available_servers = 6;
+ * @return {!Array<number>}
+ */
+proto.protocol.ConnectionRefusedMessage.prototype.getAvailableServersList = function() {
+  return /** @type {!Array<number>} */ (jspb.Message.getRepeatedField(this, 6));
+};
+
+
+/** @param {!Array<number>} value */
+proto.protocol.ConnectionRefusedMessage.prototype.setAvailableServersList = function(value) {
+  jspb.Message.setField(this, 6, value || []);
+};
+
+
+/**
+ * @param {!number} value
+ * @param {number=} opt_index
+ */
+proto.protocol.ConnectionRefusedMessage.prototype.addAvailableServers = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 6, value, opt_index);
+};
+
+
+proto.protocol.ConnectionRefusedMessage.prototype.clearAvailableServersList = function() {
+  this.setAvailableServersList([]);
+};
+
+
+/**
+ * repeated FallbackEndpoint fallback_endpoints = 7;
+ * @return {!Array<!proto.protocol.FallbackEndpoint>}
+ */
+proto.protocol.ConnectionRefusedMessage.prototype.getFallbackEndpointsList = function() {
+  return /** @type{!Array<!proto.protocol.FallbackEndpoint>} */ (
+    jspb.Message.getRepeatedWrapperField(this, proto.protocol.FallbackEndpoint, 7));
+};
+
+
+/** @param {!Array<!proto.protocol.FallbackEndpoint>} value */
+proto.protocol.ConnectionRefusedMessage.prototype.setFallbackEndpointsList = function(value) {
+  jspb.Message.setRepeatedWrapperField(this, 7, value);
+};
+
+
+/**
+ * @param {!proto.protocol.FallbackEndpoint=} opt_value
+ * @param {number=} opt_index
+ * @return {!proto.protocol.FallbackEndpoint}
+ */
+proto.protocol.ConnectionRefusedMessage.prototype.addFallbackEndpoints = function(opt_value, opt_index) {
+  return jspb.Message.addToRepeatedWrapperField(this, 7, opt_value, proto.protocol.FallbackEndpoint, opt_index);
+};
+
+
+proto.protocol.ConnectionRefusedMessage.prototype.clearFallbackEndpointsList = function() {
+  this.setFallbackEndpointsList([]);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -1294,7 +2992,9 @@ proto.protocol.PingMessage.prototype.toObject = function(opt_includeInstance) {
 proto.protocol.PingMessage.toObject = function(includeInstance, msg) {
   var f, obj = {
     type: jspb.Message.getFieldWithDefault(msg, 1, 0),
-    time: +jspb.Message.getFieldWithDefault(msg, 2, 0.0)
+    time: +jspb.Message.getFieldWithDefault(msg, 2, 0.0),
+    seq: jspb.Message.getFieldWithDefault(msg, 3, 0),
+    fromAlias: jspb.Message.getFieldWithDefault(msg, 4, 0)
   };
 
   if (includeInstance) {
@@ -1339,6 +3039,14 @@ proto.protocol.PingMessage.deserializeBinaryFromReader = function(msg, reader) {
       var value = /** @type {number} */ (reader.readDouble());
       msg.setTime(value);
       break;
+    case 3:
+      var value = /** @type {number} */ (reader.readUint32());
+      msg.setSeq(value);
+      break;
+    case 4:
+      var value = /** @type {number} */ (reader.readUint64());
+      msg.setFromAlias(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -1382,6 +3090,20 @@ proto.protocol.PingMessage.serializeBinaryToWriter = function(message, writer) {
       f
     );
   }
+  f = message.getSeq();
+  if (f !== 0) {
+    writer.writeUint32(
+      3,
+      f
+    );
+  }
+  f = message.getFromAlias();
+  if (f !== 0) {
+    writer.writeUint64(
+      4,
+      f
+    );
+  }
 };
 
 
@@ -1415,6 +3137,36 @@ proto.protocol.PingMessage.prototype.setTime = function(value) {
 };
 
 
+/**
+ * optional uint32 seq = 3;
+ * @return {number}
+ */
+proto.protocol.PingMessage.prototype.getSeq = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 3, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.PingMessage.prototype.setSeq = function(value) {
+  jspb.Message.setProto3IntField(this, 3, value);
+};
+
+
+/**
+ * optional uint64 from_alias = 4;
+ * @return {number}
+ */
+proto.protocol.PingMessage.prototype.getFromAlias = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 4, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.PingMessage.prototype.setFromAlias = function(value) {
+  jspb.Message.setProto3IntField(this, 4, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -1856,6 +3608,175 @@ proto.protocol.AuthMessage.prototype.setBody = function(value) {
 
 
 
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.protocol.AuthRequestMessage = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.protocol.AuthRequestMessage, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.protocol.AuthRequestMessage.displayName = 'proto.protocol.AuthRequestMessage';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.protocol.AuthRequestMessage.prototype.toObject = function(opt_includeInstance) {
+  return proto.protocol.AuthRequestMessage.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.protocol.AuthRequestMessage} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.AuthRequestMessage.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    type: jspb.Message.getFieldWithDefault(msg, 1, 0),
+    deadline: jspb.Message.getFieldWithDefault(msg, 2, 0)
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.protocol.AuthRequestMessage}
+ */
+proto.protocol.AuthRequestMessage.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.protocol.AuthRequestMessage;
+  return proto.protocol.AuthRequestMessage.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.protocol.AuthRequestMessage} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.protocol.AuthRequestMessage}
+ */
+proto.protocol.AuthRequestMessage.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {!proto.protocol.MessageType} */ (reader.readEnum());
+      msg.setType(value);
+      break;
+    case 2:
+      var value = /** @type {number} */ (reader.readInt64());
+      msg.setDeadline(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.protocol.AuthRequestMessage.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.protocol.AuthRequestMessage.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.protocol.AuthRequestMessage} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.protocol.AuthRequestMessage.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getType();
+  if (f !== 0.0) {
+    writer.writeEnum(
+      1,
+      f
+    );
+  }
+  f = message.getDeadline();
+  if (f !== 0) {
+    writer.writeInt64(
+      2,
+      f
+    );
+  }
+};
+
+
+/**
+ * optional MessageType type = 1;
+ * @return {!proto.protocol.MessageType}
+ */
+proto.protocol.AuthRequestMessage.prototype.getType = function() {
+  return /** @type {!proto.protocol.MessageType} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
+};
+
+
+/** @param {!proto.protocol.MessageType} value */
+proto.protocol.AuthRequestMessage.prototype.setType = function(value) {
+  jspb.Message.setProto3EnumField(this, 1, value);
+};
+
+
+/**
+ * optional int64 deadline = 2;
+ * @return {number}
+ */
+proto.protocol.AuthRequestMessage.prototype.getDeadline = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
+};
+
+
+/** @param {number} value */
+proto.protocol.AuthRequestMessage.prototype.setDeadline = function(value) {
+  jspb.Message.setProto3IntField(this, 2, value);
+};
+
+
+
 /**
  * Generated by JsPbCodeGenerator.
  * @param {Array=} opt_data Optional initial data array, typically from a
@@ -2963,7 +4884,10 @@ proto.protocol.MessageType = {
   TOPIC_IDENTITY: 11,
   TOPIC_IDENTITY_FW: 12,
   CONNECTION_REFUSED: 13,
-  ERROR: 14
+  ERROR: 14,
+  AUTH_REQUEST: 15,
+  REVOKE: 16,
+  LOAD_REPORT: 17
 };
 
 /**
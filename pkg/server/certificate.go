@@ -0,0 +1,191 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+	"unsafe" //nolint:gosec
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+
+	pion "github.com/pion/webrtc/v2"
+)
+
+const dtlsCertReloadCheckPeriod = 10 * time.Second
+
+var errUnsupportedDTLSKey = errors.New("unsupported DTLS private key, it has to be ECDSA or RSA")
+
+// Certificate is the pion's DTLS Certificate
+type Certificate = pion.Certificate
+
+// CertificateProvider provides the DTLS certificate of the new peer connections, it's called for every new
+// connection so the certificate can be rotated without restarting, the established connections keep theirs
+type CertificateProvider interface {
+	GetCertificate() (*Certificate, error)
+}
+
+// pionCertificate mirrors pion.Certificate
+// NOTE: pion v2 has no way to build a certificate from an existing key pair, only to sign a new one, so the
+// DTLS fingerprint would change anyway. Keep it in sync with the pion version, TestFileCertificateProvider
+// checks the fingerprint matches the certificate file
+type pionCertificate struct {
+	privateKey crypto.PrivateKey
+	x509Cert   *x509.Certificate
+}
+
+// NewCertificate creates a DTLS certificate from an existing ECDSA or RSA key pair, so its fingerprint can be
+// pinned by the peers
+func NewCertificate(key crypto.PrivateKey, cert *x509.Certificate) (*Certificate, error) {
+	switch key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+	default:
+		return nil, errUnsupportedDTLSKey
+	}
+
+	return (*Certificate)(unsafe.Pointer(&pionCertificate{privateKey: key, x509Cert: cert})), nil //nolint:gosec
+}
+
+// generatedCertificateProvider generates a new certificate on startup and whenever it expires
+type generatedCertificateProvider struct {
+	mux  sync.Mutex
+	cert *Certificate
+}
+
+func (p *generatedCertificateProvider) GetCertificate() (*Certificate, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.cert == nil || (!p.cert.Expires().IsZero() && time.Now().After(p.cert.Expires())) {
+		sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		p.cert, err = pion.GenerateCertificate(sk)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p.cert, nil
+}
+
+// FileCertificateProvider serves a DTLS certificate and key pair from PEM files, reloading them when the files
+// change, so the certificate can be rotated without restarting
+type FileCertificateProvider struct {
+	certFile string
+	keyFile  string
+	log      logging.Logger
+
+	mux       sync.Mutex
+	cert      *Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewFileCertificateProvider loads the certificate and key pair, failing if they are not valid
+func NewFileCertificateProvider(certFile, keyFile string, log logging.Logger) (*FileCertificateProvider, error) {
+	p := &FileCertificateProvider{certFile: certFile, keyFile: keyFile, log: log}
+
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Reload loads the certificate and key pair from disk, keeping the previous ones on error
+func (p *FileCertificateProvider) Reload() error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.reload()
+}
+
+func (p *FileCertificateProvider) reload() error {
+	modTime, err := p.filesModTime()
+	if err != nil {
+		return err
+	}
+
+	pair, err := tls.LoadX509KeyPair(p.certFile, p.keyFile)
+	if err != nil {
+		return err
+	}
+
+	x509Cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	cert, err := NewCertificate(pair.PrivateKey, x509Cert)
+	if err != nil {
+		return err
+	}
+
+	fingerprints, err := cert.GetFingerprints()
+	if err != nil {
+		return err
+	}
+
+	if time.Now().After(x509Cert.NotAfter) {
+		p.log.Warn().Time("notAfter", x509Cert.NotAfter).Msg("DTLS certificate expired")
+	}
+
+	for _, f := range fingerprints {
+		p.log.Info().Str("algorithm", f.Algorithm).Str("fingerprint", f.Value).Msg("DTLS certificate loaded")
+	}
+
+	p.cert = cert
+	p.modTime = modTime
+	p.lastCheck = time.Now()
+
+	return nil
+}
+
+func (p *FileCertificateProvider) filesModTime() (time.Time, error) {
+	var modTime time.Time
+
+	for _, file := range []string{p.certFile, p.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTime, err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+
+	return modTime, nil
+}
+
+// GetCertificate returns the current certificate, checking for changes at most every dtlsCertReloadCheckPeriod
+func (p *FileCertificateProvider) GetCertificate() (*Certificate, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if time.Since(p.lastCheck) > dtlsCertReloadCheckPeriod {
+		p.lastCheck = time.Now()
+
+		modTime, err := p.filesModTime()
+
+		if err != nil {
+			p.log.Error().Err(err).Msg("cannot check DTLS certificate files, keeping the current certificate")
+		} else if modTime.After(p.modTime) {
+			if err := p.reload(); err != nil {
+				p.log.Error().Err(err).Msg("cannot reload DTLS certificate, keeping the current certificate")
+			}
+		}
+	}
+
+	return p.cert, nil
+}
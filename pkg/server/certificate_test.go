@@ -0,0 +1,146 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+)
+
+// writeCertificate writes a self signed certificate and its key, returning the expected DTLS fingerprint
+func writeCertificate(t *testing.T, certFile string, keyFile string) string {
+	sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "broker"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tpl, &tpl, sk.Public(), sk)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(sk)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	require.NoError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+
+	digest := sha256.Sum256(der)
+	parts := make([]string, len(digest))
+
+	for i, b := range digest {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func getFingerprint(t *testing.T, p CertificateProvider) string {
+	cert, err := p.GetCertificate()
+	require.NoError(t, err)
+
+	fingerprints, err := cert.GetFingerprints()
+	require.NoError(t, err)
+	require.Len(t, fingerprints, 1)
+
+	return fingerprints[0].Value
+}
+
+func TestFileCertificateProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dtls")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	t.Run("invalid files", func(t *testing.T) {
+		_, err := NewFileCertificateProvider(certFile, keyFile, logging.New())
+		require.Error(t, err)
+	})
+
+	fingerprint := writeCertificate(t, certFile, keyFile)
+
+	p, err := NewFileCertificateProvider(certFile, keyFile, logging.New())
+	require.NoError(t, err)
+
+	t.Run("fingerprint matches the certificate file", func(t *testing.T) {
+		require.Equal(t, fingerprint, getFingerprint(t, p))
+
+		w := &webRTC{Certificates: p}
+		conn, err := w.newConnection(1)
+		require.NoError(t, err)
+
+		defer conn.Close()
+
+		_, err = conn.CreateDataChannel("reliable", nil)
+		require.NoError(t, err)
+
+		offer, err := w.createOffer(conn)
+		require.NoError(t, err)
+		require.Contains(t, offer.SDP, strings.ToUpper(fingerprint))
+	})
+
+	t.Run("reload on change", func(t *testing.T) {
+		newFingerprint := writeCertificate(t, certFile, keyFile)
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(certFile, future, future))
+
+		// NOTE: files are only checked every dtlsCertReloadCheckPeriod
+		require.Equal(t, fingerprint, getFingerprint(t, p))
+
+		p.lastCheck = time.Time{}
+		require.Equal(t, newFingerprint, getFingerprint(t, p))
+	})
+
+	t.Run("invalid reload keeps the current certificate", func(t *testing.T) {
+		current := getFingerprint(t, p)
+
+		require.NoError(t, ioutil.WriteFile(keyFile, []byte("invalid"), 0600))
+		require.Error(t, p.Reload())
+		require.Equal(t, current, getFingerprint(t, p))
+	})
+}
+
+func TestNewCertificate(t *testing.T) {
+	_, sk, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewCertificate(crypto.PrivateKey(sk), &x509.Certificate{})
+	require.Equal(t, errUnsupportedDTLSKey, err)
+}
+
+func TestGeneratedCertificateProvider(t *testing.T) {
+	p := &generatedCertificateProvider{}
+
+	cert, err := p.GetCertificate()
+	require.NoError(t, err)
+
+	again, err := p.GetCertificate()
+	require.NoError(t, err)
+	require.Equal(t, cert, again)
+}
@@ -1,8 +1,12 @@
 package server
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/decentraland/webrtc-broker/internal/logging"
@@ -17,23 +21,52 @@ const (
 	maxCoordinatorMessageSize = 5000 // NOTE let's adjust this later
 	retryCount                = 5
 	retryInitialPeriod        = 1 * time.Second
+	reconnectInitialPeriod    = 1 * time.Second
+	reconnectMaxPeriod        = 30 * time.Second
 )
 
+var errCoordinatorClosed = errors.New("coordinator connection is closed")
+
+// coordinator is a single coordinator connection, a new one is created on every reconnection
 type coordinator struct {
 	log         logging.Logger
 	conn        ws.IWebsocket
 	send        chan []byte
 	exitOnClose bool
 	closed      bool
+	tlsConfig   *tls.Config
+
+	// mux guards closed, so the send channel is not used once it's closed
+	mux sync.RWMutex
+	// done is closed when the writePump exits, so senders don't block on a full send channel
+	done chan struct{}
+	// closedCh is closed when the connection is closed
+	closedCh chan struct{}
 }
 
-func (c *coordinator) Connect(server *Server, url string) error {
+func newCoordinator(log logging.Logger, exitOnClose bool, tlsConfig *tls.Config) *coordinator {
+	return &coordinator{
+		log:         log,
+		send:        make(chan []byte, 256),
+		exitOnClose: exitOnClose,
+		tlsConfig:   tlsConfig,
+		done:        make(chan struct{}),
+		closedCh:    make(chan struct{}),
+	}
+}
+
+// Connect dials the coordinator, getURL is called on every retry so the url can carry short lived credentials
+func (c *coordinator) Connect(server *Server, getURL func() (string, error)) error {
 	retryPeriod := retryInitialPeriod
 
 	for retryIndex := 0; retryIndex < retryCount; retryIndex++ {
-		conn, err := ws.Dial(url)
+		url, err := getURL()
+		if err != nil {
+			return err
+		}
+
+		err = c.dial(url)
 		if err == nil {
-			c.conn = conn
 			return nil
 		}
 
@@ -52,11 +85,25 @@ func (c *coordinator) Connect(server *Server, url string) error {
 	return fmt.Errorf("cannot connect to coordinator after %d retries", retryCount)
 }
 
+func (c *coordinator) dial(url string) error {
+	conn, err := ws.DialWithOptions(url, ws.DialOptions{TLSConfig: c.tlsConfig})
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+
+	return nil
+}
+
 func (c *coordinator) Send(msg protocol.Message) error {
 	log := c.log
 
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
 	if c.closed {
-		return errors.New("coordinator connection is closed")
+		return errCoordinatorClosed
 	}
 
 	bytes, err := proto.Marshal(msg)
@@ -65,9 +112,12 @@ func (c *coordinator) Send(msg protocol.Message) error {
 		return err
 	}
 
-	c.send <- bytes
-
-	return nil
+	select {
+	case c.send <- bytes:
+		return nil
+	case <-c.done:
+		return errCoordinatorClosed
+	}
 }
 
 func (c *coordinator) readPump(server *Server, welcomeChannel chan *protocol.WelcomeMessage) {
@@ -133,7 +183,19 @@ func (c *coordinator) readPump(server *Server, welcomeChannel chan *protocol.Wel
 			}
 
 			log.Debug().Uint64("to", connectMessage.FromAlias).Msg("Connect message received")
-			server.connectCh <- connectMessage.FromAlias
+			server.connectCh <- &connectRequest{alias: connectMessage.FromAlias, ticket: connectMessage.Ticket}
+		case protocol.MessageType_REVOKE:
+			revokeMessage := &protocol.RevokeMessage{}
+			if err := proto.Unmarshal(bytes, revokeMessage); err != nil {
+				log.Debug().Err(err).Msg("decode revoke message failure")
+				continue
+			}
+
+			log.Info().Str("identity", string(revokeMessage.Identity)).Msg("identity revoked")
+
+			if server.onRevokeHdlr != nil {
+				server.onRevokeHdlr(revokeMessage.Identity, time.Unix(revokeMessage.ExpiresAt, 0))
+			}
 		default:
 			log.Debug().Str("type", msgType.String()).Msg("unhandled message from coordinator")
 		}
@@ -147,6 +209,10 @@ func (c *coordinator) writePump() {
 	defer func() {
 		ticker.Stop()
 
+		if c.done != nil {
+			close(c.done)
+		}
+
 		if err := c.conn.Close(); err != nil {
 			log.Debug().Err(err).Msg("error closing connection on writePump exit")
 		}
@@ -188,7 +254,10 @@ func (c *coordinator) writePump() {
 }
 
 func (c *coordinator) Close() {
+	c.mux.Lock()
+
 	if c.closed {
+		c.mux.Unlock()
 		return
 	}
 
@@ -201,7 +270,34 @@ func (c *coordinator) Close() {
 
 	close(c.send)
 
+	if c.closedCh != nil {
+		close(c.closedCh)
+	}
+
+	c.mux.Unlock()
+
 	if c.exitOnClose {
 		c.log.Fatal().Msg("Coordinator connection closed, exiting process")
 	}
 }
+
+func (c *coordinator) isClosed() bool {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	return c.closed
+}
+
+// addAlias asks the coordinator to keep the server alias when reconnecting
+func addAlias(connectURL string, alias uint64) (string, error) {
+	u, err := url.Parse(connectURL)
+	if err != nil {
+		return "", err
+	}
+
+	qs := u.Query()
+	qs.Set("alias", strconv.FormatUint(alias, 10))
+	u.RawQuery = qs.Encode()
+
+	return u.String(), nil
+}
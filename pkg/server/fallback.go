@@ -0,0 +1,307 @@
+package server
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/internal/ws"
+)
+
+// Every fallback websocket message is prefixed by a single byte identifying the emulated channel
+const (
+	FallbackReliableChannel   byte = 0
+	FallbackUnreliableChannel byte = 1
+)
+
+const (
+	fallbackMaxMessageSize = 5121 // NOTE: channel byte + max world comm message size
+	fallbackSendQueueSize  = 256
+	fallbackReadQueueSize  = 256
+)
+
+// FallbackChannel emulates a detached data channel over the fallback websocket
+type FallbackChannel struct {
+	id       byte
+	conn     *FallbackConn
+	reliable bool
+	readCh   chan []byte
+
+	bufferedAmount      uint64
+	bufferedAmountLow   uint64
+	onBufferedAmountLow func()
+
+	messagesSent     uint32
+	bytesSent        uint64
+	messagesReceived uint32
+	bytesReceived    uint64
+}
+
+// FallbackChannelStats are the emulated channel stats
+type FallbackChannelStats struct {
+	MessagesSent     uint32
+	BytesSent        uint64
+	MessagesReceived uint32
+	BytesReceived    uint64
+}
+
+type fallbackFrame struct {
+	channel *FallbackChannel
+	bytes   []byte
+}
+
+// FallbackConn is a peer connection over websocket, used by peers that cannot establish a webrtc connection.
+// It carries the same messages as the data channels, emulating both the reliable and the unreliable channel.
+type FallbackConn struct {
+	conn      ws.IWebsocket
+	sendCh    chan fallbackFrame
+	closed    chan struct{}
+	closeOnce sync.Once
+	log       logging.Logger
+
+	Reliable   *FallbackChannel
+	Unreliable *FallbackChannel
+}
+
+// NewFallbackConn creates a new fallback connection on top of the given websocket, Start has to be called
+// for the connection to begin processing messages
+func NewFallbackConn(conn ws.IWebsocket, log logging.Logger) *FallbackConn {
+	c := &FallbackConn{
+		conn:   conn,
+		sendCh: make(chan fallbackFrame, fallbackSendQueueSize),
+		closed: make(chan struct{}),
+		log:    log,
+	}
+
+	c.Reliable = &FallbackChannel{
+		id:       FallbackReliableChannel,
+		conn:     c,
+		reliable: true,
+		readCh:   make(chan []byte, fallbackReadQueueSize),
+	}
+
+	c.Unreliable = &FallbackChannel{
+		id:     FallbackUnreliableChannel,
+		conn:   c,
+		readCh: make(chan []byte, fallbackReadQueueSize),
+	}
+
+	return c
+}
+
+// Start starts the connection read and write pumps
+func (c *FallbackConn) Start() {
+	go c.readPump()
+
+	go c.writePump()
+}
+
+// IsClosed returns true if the connection is closed
+func (c *FallbackConn) IsClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes the underlying websocket
+func (c *FallbackConn) Close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+
+	return err
+}
+
+func (c *FallbackConn) readPump() {
+	defer func() {
+		if err := c.Close(); err != nil {
+			c.log.Debug().Err(err).Msg("error closing fallback connection")
+		}
+	}()
+
+	c.conn.SetReadLimit(fallbackMaxMessageSize)
+
+	if err := c.conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
+		c.log.Error().Err(err).Msg("cannot set read deadline")
+		return
+	}
+
+	c.conn.SetPongHandler(func(s string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		bytes, err := c.conn.ReadMessage()
+		if err != nil {
+			if ws.IsUnexpectedCloseError(err) {
+				c.log.Error().Err(err).Msg("unexcepted close error")
+			} else {
+				c.log.Debug().Err(err).Msg("read error in fallback ws")
+			}
+
+			return
+		}
+
+		if len(bytes) < 2 {
+			continue
+		}
+
+		var channel *FallbackChannel
+
+		switch bytes[0] {
+		case FallbackReliableChannel:
+			channel = c.Reliable
+		case FallbackUnreliableChannel:
+			channel = c.Unreliable
+		default:
+			c.log.Debug().Uint8("channel", bytes[0]).Msg("unknown fallback channel")
+			continue
+		}
+
+		msg := bytes[1:]
+
+		atomic.AddUint32(&channel.messagesReceived, 1)
+		atomic.AddUint64(&channel.bytesReceived, uint64(len(msg)))
+
+		if channel.reliable {
+			select {
+			case channel.readCh <- msg:
+			case <-c.closed:
+				return
+			}
+		} else {
+			select {
+			case channel.readCh <- msg:
+			default:
+				// NOTE: emulate the unreliable channel, if the reader is not keeping up we discard the message
+			}
+		}
+	}
+}
+
+func (c *FallbackConn) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+
+	defer func() {
+		ticker.Stop()
+
+		if err := c.Close(); err != nil {
+			c.log.Debug().Err(err).Msg("error closing fallback connection")
+		}
+	}()
+
+	for {
+		select {
+		case frame := <-c.sendCh:
+			if err := c.conn.WriteMessage(frame.bytes); err != nil {
+				c.log.Error().Err(err).Msg("error writing fallback message")
+				return
+			}
+
+			frame.channel.onSent(uint64(len(frame.bytes) - 1))
+		case <-ticker.C:
+			if err := c.conn.WritePingMessage(); err != nil {
+				c.log.Error().Err(err).Msg("error writing ping message")
+				return
+			}
+		case <-c.closed:
+			if err := c.conn.WriteCloseMessage(); err != nil {
+				c.log.Debug().Err(err).Msg("error writing close message")
+			}
+
+			return
+		}
+	}
+}
+
+// Read reads the next message, blocks until a message is available or the connection is closed
+func (ch *FallbackChannel) Read(p []byte) (int, error) {
+	select {
+	case msg := <-ch.readCh:
+		return copy(p, msg), nil
+	case <-ch.conn.closed:
+		return 0, io.EOF
+	}
+}
+
+// ReadDataChannel reads the next message, messages are always binary
+func (ch *FallbackChannel) ReadDataChannel(p []byte) (int, bool, error) {
+	n, err := ch.Read(p)
+	return n, false, err
+}
+
+// Write queues a message to be sent
+func (ch *FallbackChannel) Write(p []byte) (int, error) {
+	if ch.conn.IsClosed() {
+		return 0, io.ErrClosedPipe
+	}
+
+	bytes := make([]byte, len(p)+1)
+	bytes[0] = ch.id
+	copy(bytes[1:], p)
+
+	atomic.AddUint64(&ch.bufferedAmount, uint64(len(p)))
+
+	select {
+	case ch.conn.sendCh <- fallbackFrame{channel: ch, bytes: bytes}:
+		return len(p), nil
+	case <-ch.conn.closed:
+		return 0, io.ErrClosedPipe
+	}
+}
+
+// WriteDataChannel queues a message to be sent, messages are always binary
+func (ch *FallbackChannel) WriteDataChannel(p []byte, isString bool) (int, error) {
+	return ch.Write(p)
+}
+
+// Close closes the whole fallback connection
+func (ch *FallbackChannel) Close() error {
+	return ch.conn.Close()
+}
+
+// BufferedAmount is the amount of bytes queued and not sent yet
+func (ch *FallbackChannel) BufferedAmount() uint64 {
+	return atomic.LoadUint64(&ch.bufferedAmount)
+}
+
+// SetBufferedAmountLowThreshold sets the threshold for OnBufferedAmountLow, it has to be called before Start
+func (ch *FallbackChannel) SetBufferedAmountLowThreshold(th uint64) {
+	ch.bufferedAmountLow = th
+}
+
+// OnBufferedAmountLow sets the handler called when the buffered amount drops to the threshold, it has to
+// be called before Start
+func (ch *FallbackChannel) OnBufferedAmountLow(f func()) {
+	ch.onBufferedAmountLow = f
+}
+
+// Stats returns the channel stats
+func (ch *FallbackChannel) Stats() FallbackChannelStats {
+	return FallbackChannelStats{
+		MessagesSent:     atomic.LoadUint32(&ch.messagesSent),
+		BytesSent:        atomic.LoadUint64(&ch.bytesSent),
+		MessagesReceived: atomic.LoadUint32(&ch.messagesReceived),
+		BytesReceived:    atomic.LoadUint64(&ch.bytesReceived),
+	}
+}
+
+func (ch *FallbackChannel) onSent(n uint64) {
+	atomic.AddUint32(&ch.messagesSent, 1)
+	atomic.AddUint64(&ch.bytesSent, n)
+
+	before := atomic.AddUint64(&ch.bufferedAmount, ^(n-1)) + n
+
+	if ch.onBufferedAmountLow != nil && before > ch.bufferedAmountLow && before-n <= ch.bufferedAmountLow {
+		// NOTE: the handler usually writes again, so it cannot run on the write pump
+		go ch.onBufferedAmountLow()
+	}
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pion "github.com/pion/webrtc/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/internal/ws"
+)
+
+func makeWebsocketPair(t *testing.T) (*httptest.Server, ws.IWebsocket, ws.IWebsocket) {
+	upgrader := ws.MakeUpgrader()
+	serverConnCh := make(chan ws.IWebsocket, 1)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r)
+		require.NoError(t, err)
+		serverConnCh <- conn
+	}))
+
+	clientConn, err := ws.Dial("ws" + strings.TrimPrefix(httpServer.URL, "http"))
+	require.NoError(t, err)
+
+	return httpServer, <-serverConnCh, clientConn
+}
+
+func TestFallbackConn(t *testing.T) {
+	log := logging.New()
+	httpServer, serverConn, clientConn := makeWebsocketPair(t)
+	defer httpServer.Close()
+
+	server := NewFallbackConn(serverConn, log)
+	client := NewFallbackConn(clientConn, log)
+
+	lowCh := make(chan bool, 10)
+	client.Reliable.SetBufferedAmountLowThreshold(0)
+	client.Reliable.OnBufferedAmountLow(func() { lowCh <- true })
+
+	server.Start()
+	client.Start()
+
+	_, err := client.Reliable.Write([]byte("reliable"))
+	require.NoError(t, err)
+
+	_, err = client.Unreliable.WriteDataChannel([]byte("unreliable"), false)
+	require.NoError(t, err)
+
+	buffer := make([]byte, 1024)
+
+	n, err := server.Reliable.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "reliable", string(buffer[:n]))
+
+	n, isString, err := server.Unreliable.ReadDataChannel(buffer)
+	require.NoError(t, err)
+	require.False(t, isString)
+	require.Equal(t, "unreliable", string(buffer[:n]))
+
+	<-lowCh
+	require.Equal(t, uint64(0), client.Reliable.BufferedAmount())
+
+	require.Equal(t, FallbackChannelStats{MessagesSent: 1, BytesSent: 8}, client.Reliable.Stats())
+	require.Equal(t, FallbackChannelStats{MessagesReceived: 1, BytesReceived: 10}, server.Unreliable.Stats())
+
+	require.NoError(t, client.Close())
+	require.True(t, client.IsClosed())
+
+	_, err = server.Reliable.Read(buffer)
+	require.Equal(t, io.EOF, err)
+	require.True(t, server.IsClosed())
+
+	_, err = client.Reliable.Write([]byte("closed"))
+	require.Error(t, err)
+}
+
+func TestProcessFallback(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var newPeer *Peer
+
+		s, err := NewServer(&Config{
+			WebRtc: &mockWebRtc{},
+			OnNewPeerHdlr: func(p *Peer) error {
+				newPeer = p
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		httpServer, serverConn, clientConn := makeWebsocketPair(t)
+		defer httpServer.Close()
+
+		require.NoError(t, s.processFallback(&fallbackRequest{alias: 1, conn: serverConn}))
+		require.Len(t, s.peers, 1)
+		require.Equal(t, newPeer, s.peers[0])
+		require.NotNil(t, newPeer.Fallback)
+		require.False(t, newPeer.IsClosed())
+
+		stats := s.GetServerStats()
+		require.Len(t, stats.Peers, 1)
+		require.True(t, stats.Peers[0].Fallback)
+
+		require.NoError(t, clientConn.Close())
+
+		p := <-s.unregisterCh
+		require.Equal(t, newPeer, p)
+		require.True(t, p.IsClosed())
+	})
+
+	t.Run("peer already connected", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		webRtc.
+			On("isClosed", p.Conn).Return(false).Once().
+			On("isNew", p.Conn).Return(false).Once()
+
+		httpServer, serverConn, clientConn := makeWebsocketPair(t)
+		defer httpServer.Close()
+
+		require.Equal(t, ErrPeerAlreadyConnected, s.processFallback(&fallbackRequest{alias: 1, conn: serverConn}))
+		require.Len(t, s.peers, 1)
+
+		_, err = clientConn.ReadMessage()
+		require.Error(t, err)
+
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("replaces pending webrtc peer", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		webRtc.
+			On("isClosed", p.Conn).Return(false).Once().
+			On("isNew", p.Conn).Return(true).Once().
+			On("close", p.Conn).Return(nil).Once()
+
+		httpServer, serverConn, clientConn := makeWebsocketPair(t)
+		defer httpServer.Close()
+		defer clientConn.Close()
+
+		require.NoError(t, s.processFallback(&fallbackRequest{alias: 1, ticket: []byte("ticket"), conn: serverConn}))
+		require.Len(t, s.peers, 1)
+		require.NotNil(t, s.peers[0].Fallback)
+
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("keeps pending webrtc peer without ticket", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		webRtc.
+			On("isClosed", p.Conn).Return(false).Once().
+			On("isNew", p.Conn).Return(true).Once()
+
+		httpServer, serverConn, clientConn := makeWebsocketPair(t)
+		defer httpServer.Close()
+
+		require.Equal(t, ErrPeerAlreadyConnected, s.processFallback(&fallbackRequest{alias: 1, conn: serverConn}))
+		require.Len(t, s.peers, 1)
+		require.Equal(t, p, s.peers[0])
+
+		_, err = clientConn.ReadMessage()
+		require.Error(t, err)
+
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("server shut down", func(t *testing.T) {
+		s, err := NewServer(&Config{WebRtc: &mockWebRtc{}})
+		require.NoError(t, err)
+
+		Shutdown(s)
+
+		httpServer, serverConn, clientConn := makeWebsocketPair(t)
+		defer httpServer.Close()
+
+		s.AcceptFallbackPeer(1, serverConn)
+
+		_, err = clientConn.ReadMessage()
+		require.Error(t, err)
+	})
+
+	t.Run("server full", func(t *testing.T) {
+		s, err := NewServer(&Config{WebRtc: &mockWebRtc{}, MaxPeers: 1})
+		require.NoError(t, err)
+
+		s.peers = append(s.peers, &Peer{Alias: 2, Conn: &pion.PeerConnection{}})
+
+		httpServer, serverConn, clientConn := makeWebsocketPair(t)
+		defer httpServer.Close()
+
+		require.Equal(t, ErrServerFull, s.processFallback(&fallbackRequest{alias: 1, conn: serverConn}))
+		require.Len(t, s.peers, 1)
+
+		_, err = clientConn.ReadMessage()
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	pion "github.com/pion/webrtc/v2"
+)
+
+var (
+	// ErrInvalidSDP indicates that an HTTP offer or candidates fragment cannot be applied
+	ErrInvalidSDP = errors.New("invalid SDP")
+	// ErrUnknownHTTPSession indicates that there is no peer for an HTTP signaling session
+	ErrUnknownHTTPSession = errors.New("unknown HTTP signaling session")
+	// ErrServerClosed indicates that the server is shut down
+	ErrServerClosed = errors.New("server closed")
+)
+
+// HTTPSession is a peer signaled over HTTP, see AcceptHTTPOffer
+type HTTPSession struct {
+	ID     string
+	Alias  uint64
+	Answer string
+}
+
+type httpOfferRequest struct {
+	alias  uint64
+	ticket []byte
+	offer  pion.SessionDescription
+	result chan httpOfferResult
+}
+
+type httpOfferResult struct {
+	session *HTTPSession
+	err     error
+}
+
+// nextHTTPAlias returns an alias for a peer signaled over HTTP without a coordinator alias, from a range the
+// coordinator never allocates, made unique across the cluster with the server alias
+func (s *Server) nextHTTPAlias() uint64 {
+	n := atomic.AddUint32(&s.lastHTTPAlias, 1)
+	return 1<<63 | (s.GetAlias()&0x7fffffff)<<32 | uint64(n)
+}
+
+func (s *Server) getHTTPSession(session string) *Peer {
+	s.httpSessionsMux.Lock()
+	defer s.httpSessionsMux.Unlock()
+
+	return s.httpSessions[session]
+}
+
+// AcceptHTTPOffer creates a peer for an SDP offer received over HTTP (WHIP style), through the same path as the
+// peers signaled by the coordinator. A zero alias gets one allocated by the server, any other alias has to be
+// verified with its connection ticket before calling it. The answer includes the server candidates, the peer
+// ones are either in the offer or added later with AddHTTPCandidates
+func (s *Server) AcceptHTTPOffer(alias uint64, ticket []byte, offer string) (*HTTPSession, error) {
+	req := &httpOfferRequest{
+		alias:  alias,
+		ticket: ticket,
+		offer:  pion.SessionDescription{Type: pion.SDPTypeOffer, SDP: offer},
+		result: make(chan httpOfferResult, 1),
+	}
+
+	if s.isClosed() {
+		return nil, ErrServerClosed
+	}
+
+	select {
+	case s.httpOfferCh <- req:
+	case <-s.closed:
+		return nil, ErrServerClosed
+	}
+
+	select {
+	case res := <-req.result:
+		return res.session, res.err
+	case <-s.closed:
+		return nil, ErrServerClosed
+	}
+}
+
+// AddHTTPCandidates adds the peer candidates of a trickle ICE SDP fragment (application/trickle-ice-sdpfrag),
+// the other fragment lines are ignored
+func (s *Server) AddHTTPCandidates(session string, fragment string) error {
+	p := s.getHTTPSession(session)
+	if p == nil {
+		return ErrUnknownHTTPSession
+	}
+
+	for _, line := range strings.Split(fragment, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "a=candidate:") {
+			continue
+		}
+
+		candidate := pion.ICECandidateInit{Candidate: strings.TrimPrefix(line, "a=")}
+		if err := s.webRtc.onIceCandidate(p.GetConn(), candidate); err != nil {
+			p.Log.Debug().Err(err).Msg("error adding remote ice candidate")
+			return ErrInvalidSDP
+		}
+	}
+
+	return nil
+}
+
+// CloseHTTPSession closes the peer of an HTTP signaling session
+func (s *Server) CloseHTTPSession(session string) error {
+	p := s.getHTTPSession(session)
+	if p == nil {
+		return ErrUnknownHTTPSession
+	}
+
+	p.Close()
+
+	return nil
+}
+
+func (s *Server) processHTTPOffer(req *httpOfferRequest) error {
+	reply := func(session *HTTPSession, err error) error {
+		req.result <- httpOfferResult{session: session, err: err}
+		return err
+	}
+
+	alias := req.alias
+	if alias == 0 {
+		alias = s.nextHTTPAlias()
+	}
+
+	oldP := findPeer(s.peers, alias)
+	if oldP != nil && !oldP.IsClosed() {
+		s.log.Info().Uint64("peer", alias).Msg("reject HTTP peer, already connected")
+		return reply(nil, ErrPeerAlreadyConnected)
+	}
+
+	// NOTE: unlike initPeer, the HTTP peers are refused in the response instead of through the coordinator
+	if s.isFull() {
+		s.log.Info().Uint64("peer", alias).Msg("reject HTTP peer, the server is full")
+		return reply(nil, ErrServerFull)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return reply(nil, err)
+	}
+
+	conn, err := s.webRtc.newHTTPConnection(alias)
+	if err != nil {
+		s.log.Error().Err(err).Msg("error creating new peer connection")
+		return reply(nil, err)
+	}
+
+	p, err := s.addPeer(alias, conn, hex.EncodeToString(id))
+	if err != nil {
+		return reply(nil, err)
+	}
+
+	p.ConnectTicket = req.ticket
+
+	answer, err := s.webRtc.onOffer(p.GetConn(), req.offer)
+	if err != nil {
+		p.Log.Info().Err(err).Msg("invalid HTTP offer, closing connection")
+		p.Close()
+
+		return reply(nil, ErrInvalidSDP)
+	}
+
+	s.httpSessionsMux.Lock()
+	s.httpSessions[p.httpSession] = p
+	s.httpSessionsMux.Unlock()
+
+	p.Log.Debug().Msg("HTTP peer registered")
+
+	return reply(&HTTPSession{ID: p.httpSession, Alias: alias, Answer: answer.SDP}, nil)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	pion "github.com/pion/webrtc/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// newHTTPOffer creates a data channel offer, with its candidates, as a peer signaling over HTTP would
+func newHTTPOffer(t *testing.T) (*pion.PeerConnection, string) {
+	conn, err := pion.NewPeerConnection(pion.Configuration{})
+	require.NoError(t, err)
+
+	_, err = conn.CreateDataChannel("data", nil)
+	require.NoError(t, err)
+
+	offer, err := conn.CreateOffer(nil)
+	require.NoError(t, err)
+	require.NoError(t, conn.SetLocalDescription(offer))
+
+	return conn, conn.LocalDescription().SDP
+}
+
+func TestHTTPSignaling(t *testing.T) {
+	s, err := NewServer(&Config{MaxPeers: 1})
+	require.NoError(t, err)
+
+	s.Alias = 3
+
+	go s.ProcessControlMessages()
+
+	defer Shutdown(s)
+
+	client, offer := newHTTPOffer(t)
+	defer client.Close()
+
+	connected := make(chan struct{})
+	client.OnICEConnectionStateChange(func(state pion.ICEConnectionState) {
+		if state == pion.ICEConnectionStateConnected {
+			close(connected)
+		}
+	})
+
+	session, err := s.AcceptHTTPOffer(0, nil, offer)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<63|3<<32|1), session.Alias)
+	require.NotEmpty(t, session.ID)
+	require.Contains(t, session.Answer, "a=candidate:")
+	require.Contains(t, session.Answer, "a=end-of-candidates")
+
+	require.NoError(t, client.SetRemoteDescription(pion.SessionDescription{
+		Type: pion.SDPTypeAnswer,
+		SDP:  session.Answer,
+	}))
+
+	select {
+	case <-connected:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "HTTP peer not connected")
+	}
+
+	t.Run("server full", func(t *testing.T) {
+		_, offer := newHTTPOffer(t)
+		_, err := s.AcceptHTTPOffer(0, nil, offer)
+		require.Equal(t, ErrServerFull, err)
+	})
+
+	t.Run("trickle candidates", func(t *testing.T) {
+		require.NoError(t, s.AddHTTPCandidates(session.ID,
+			"a=ice-ufrag:abcd\r\na=candidate:1 1 udp 1 192.0.2.1 5000 typ host\r\n"))
+		require.Equal(t, ErrInvalidSDP, s.AddHTTPCandidates(session.ID, "a=candidate:invalid\r\n"))
+		require.Equal(t, ErrUnknownHTTPSession, s.AddHTTPCandidates("unknown", ""))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		require.NoError(t, s.CloseHTTPSession(session.ID))
+
+		require.Eventually(t, func() bool { return s.getHTTPSession(session.ID) == nil },
+			time.Second, 10*time.Millisecond)
+		require.Equal(t, ErrUnknownHTTPSession, s.CloseHTTPSession(session.ID))
+	})
+
+	t.Run("invalid offer", func(t *testing.T) {
+		_, err := s.AcceptHTTPOffer(0, nil, "invalid")
+		require.Equal(t, ErrInvalidSDP, err)
+	})
+
+	t.Run("server shut down", func(t *testing.T) {
+		s, err := NewServer(&Config{})
+		require.NoError(t, err)
+
+		Shutdown(s)
+
+		_, offer := newHTTPOffer(t)
+		_, err = s.AcceptHTTPOffer(0, nil, offer)
+		require.Equal(t, ErrServerClosed, err)
+	})
+}
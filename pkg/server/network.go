@@ -0,0 +1,88 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	pion "github.com/pion/webrtc/v2"
+)
+
+// NetworkType is the pion's NetworkType
+type NetworkType = pion.NetworkType
+
+var errInvalidPortRange = errors.New("invalid ephemeral UDP port range")
+
+// NetworkConfig configures the ICE networking of every peer connection, e.g. for servers behind a cloud NAT
+// or a firewall. The zero value gathers candidates on every interface and network type, with any ephemeral port
+type NetworkConfig struct {
+	// PortMin and PortMax limit the ephemeral UDP ports, both have to be set
+	PortMin uint16
+	PortMax uint16
+
+	// NAT1To1IPs are the public IPs of a 1:1 NAT (e.g. a cloud instance public IP). With NAT1To1CandidateType
+	// host (the default) they replace the private IPs of the host candidates, with srflx they are advertised as
+	// server reflexive candidates, which cannot be combined with STUN servers
+	NAT1To1IPs           []string
+	NAT1To1CandidateType ICECandidateType
+
+	// NetworkTypes are the network types candidates are gathered for (e.g. udp4 only), every one by default
+	NetworkTypes []NetworkType
+
+	// InterfaceFilter returns true for the network interfaces candidates are gathered on, every one by default
+	InterfaceFilter func(name string) bool
+}
+
+// ParseNetworkType parses a network type, udp4 or udp6
+func ParseNetworkType(raw string) (NetworkType, error) {
+	switch raw {
+	case pion.NetworkTypeUDP4.String():
+		return pion.NetworkTypeUDP4, nil
+	case pion.NetworkTypeUDP6.String():
+		return pion.NetworkTypeUDP6, nil
+	default:
+		return NetworkType(0), fmt.Errorf("unsupported network type %q", raw)
+	}
+}
+
+func (c *NetworkConfig) validate() error {
+	if (c.PortMin == 0) != (c.PortMax == 0) || c.PortMax < c.PortMin {
+		return errInvalidPortRange
+	}
+
+	for _, ip := range c.NAT1To1IPs {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid NAT 1:1 IP %q", ip)
+		}
+	}
+
+	return nil
+}
+
+// apply sets the network options in the setting engine of a new peer connection
+func (c *NetworkConfig) apply(s *pion.SettingEngine) error {
+	if c.PortMin != 0 {
+		if err := s.SetEphemeralUDPPortRange(c.PortMin, c.PortMax); err != nil {
+			return err
+		}
+	}
+
+	if len(c.NAT1To1IPs) > 0 {
+		candidateType := c.NAT1To1CandidateType
+		if candidateType == ICECandidateType(0) {
+			candidateType = pion.ICECandidateTypeHost
+		}
+
+		s.SetNAT1To1IPs(c.NAT1To1IPs, candidateType)
+	}
+
+	if len(c.NetworkTypes) > 0 {
+		s.SetNetworkTypes(c.NetworkTypes)
+	}
+
+	if c.InterfaceFilter != nil {
+		s.SetInterfaceFilter(c.InterfaceFilter)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"testing"
+
+	pion "github.com/pion/webrtc/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetworkType(t *testing.T) {
+	networkType, err := ParseNetworkType("udp4")
+	require.NoError(t, err)
+	require.Equal(t, pion.NetworkTypeUDP4, networkType)
+
+	networkType, err = ParseNetworkType("udp6")
+	require.NoError(t, err)
+	require.Equal(t, pion.NetworkTypeUDP6, networkType)
+
+	_, err = ParseNetworkType("tcp4")
+	require.Error(t, err)
+}
+
+func TestNetworkConfig(t *testing.T) {
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewServer(&Config{Network: NetworkConfig{PortMin: 50100, PortMax: 50000}})
+		require.Error(t, err)
+
+		_, err = NewServer(&Config{Network: NetworkConfig{PortMin: 50000}})
+		require.Error(t, err)
+
+		_, err = NewServer(&Config{Network: NetworkConfig{NAT1To1IPs: []string{"public"}}})
+		require.Error(t, err)
+	})
+
+	t.Run("new connection", func(t *testing.T) {
+		w := &webRTC{Certificates: &generatedCertificateProvider{}, Network: NetworkConfig{
+			PortMin:         50000,
+			PortMax:         50100,
+			NAT1To1IPs:      []string{"203.0.113.10"},
+			NetworkTypes:    []NetworkType{pion.NetworkTypeUDP4},
+			InterfaceFilter: func(name string) bool { return name != "docker0" },
+		}}
+
+		conn, err := w.newConnection(1)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+	})
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+
+	pion "github.com/pion/webrtc/v2"
+)
+
+// NOTE: pion doesn't support restarting ICE on an existing connection, so a disconnected peer is reconnected: a new
+// connection, with new data channels, is negotiated through the coordinator and replaces the disconnected one once
+// connected. The peer state (e.g. topics and authentication) is kept, but not the DTLS and SCTP state of the
+// replaced connection, the reliable messages still in flight on it are lost
+
+const defaultReconnectGracePeriod = 5 * time.Second
+
+// GetConn returns the peer webrtc connection, it's replaced when a reconnect completes
+func (p *Peer) GetConn() *PeerConnection {
+	p.connMux.RLock()
+	defer p.connMux.RUnlock()
+
+	return p.Conn
+}
+
+// IsReconnecting returns true while the connection replacing a disconnected one is negotiated
+func (p *Peer) IsReconnecting() bool {
+	return p.getReconnectConn() != nil
+}
+
+// CloseConn closes the peer after an error on one of the conn data channels, unless conn was replaced, or is
+// being replaced, by a reconnect
+func (p *Peer) CloseConn(conn *PeerConnection) {
+	if p.Fallback == nil {
+		p.connMux.RLock()
+		replaced := conn != p.Conn || p.reconnectConn != nil
+		p.connMux.RUnlock()
+
+		if replaced {
+			p.Log.Debug().Msg("ignoring the error of a connection replaced by a reconnect")
+			return
+		}
+	}
+
+	p.Close()
+}
+
+func (p *Peer) getReconnectConn() *PeerConnection {
+	p.connMux.RLock()
+	defer p.connMux.RUnlock()
+
+	return p.reconnectConn
+}
+
+// getSignalingConn returns the connection the signaling messages are for, the one replacing the peer connection
+// during a reconnect
+func (p *Peer) getSignalingConn() *PeerConnection {
+	p.connMux.RLock()
+	defer p.connMux.RUnlock()
+
+	if p.reconnectConn != nil {
+		return p.reconnectConn
+	}
+
+	return p.Conn
+}
+
+// discardReconnectConn drops the connection negotiated by a reconnect, it returns false if conn is not the
+// pending one
+func (s *Server) discardReconnectConn(p *Peer, conn *PeerConnection) bool {
+	p.connMux.Lock()
+	if p.reconnectConn != conn {
+		p.connMux.Unlock()
+		return false
+	}
+	p.reconnectConn = nil
+	p.connMux.Unlock()
+
+	if err := s.webRtc.close(conn); err != nil {
+		p.Log.Debug().Err(err).Msg("error closing reconnect connection")
+	}
+
+	return true
+}
+
+// initConn handles the ICE events of conn, which is either the peer connection or the one replacing it
+func (s *Server) initConn(p *Peer, conn *PeerConnection) {
+	alias := p.Alias
+
+	conn.OnICECandidate(func(candidate *ICECandidate) {
+		if candidate == nil {
+			s.log.Debug().Uint64("peer", alias).Msg("finish collecting candidates")
+			return
+		}
+
+		// NOTE: the candidates of the peers signaled over HTTP are already in the answer
+		if p.httpSession != "" {
+			return
+		}
+
+		p.candidatesMux.Lock()
+		defer p.candidatesMux.Unlock()
+
+		desc := conn.RemoteDescription()
+		if desc == nil {
+			p.pendingCandidates = append(p.pendingCandidates, candidate)
+		} else {
+			s.sendICECandidate(alias, candidate)
+		}
+	})
+
+	conn.OnICEConnectionStateChange(func(connectionState pion.ICEConnectionState) {
+		s.log.Debug().
+			Uint64("peer", alias).
+			Str("iceConnectionState", connectionState.String()).
+			Msg("ICE Connection State has changed")
+
+		switch connectionState {
+		case pion.ICEConnectionStateConnected, pion.ICEConnectionStateCompleted:
+			if conn == p.GetConn() {
+				s.iceConnected(p)
+			}
+
+			s.reconnectCompleted(p, conn)
+		case pion.ICEConnectionStateDisconnected:
+			s.peerDisconnected(p, conn)
+		case pion.ICEConnectionStateFailed:
+			s.peerFailed(p, conn)
+		}
+	})
+}
+
+// peerDisconnected gives the connection a grace period to recover, the disconnection is often a brief network
+// change. The peer that offered the connection reconnects it if it doesn't
+func (s *Server) peerDisconnected(p *Peer, conn *PeerConnection) {
+	if conn != p.GetConn() {
+		return
+	}
+
+	if s.reconnectGracePeriod < 0 {
+		p.Log.Debug().Msg("Connection state is disconnected, closing connection")
+		p.Close()
+
+		return
+	}
+
+	p.Log.Debug().Msg("Connection state is disconnected, waiting for it to recover")
+
+	time.AfterFunc(s.reconnectGracePeriod, func() {
+		if !p.offerer || conn != p.GetConn() || p.IsReconnecting() || !s.webRtc.isDisconnected(conn) {
+			return
+		}
+
+		s.reconnectCh <- p
+	})
+}
+
+// peerFailed reconnects the peer, if it offered the connection, or closes it. A failed reconnect closes
+// the peer unless the replaced connection recovered meanwhile
+func (s *Server) peerFailed(p *Peer, conn *PeerConnection) {
+	if conn == p.getReconnectConn() {
+		s.reconnectFailed(p, conn)
+		return
+	}
+
+	if conn != p.GetConn() || p.IsReconnecting() {
+		return
+	}
+
+	if p.offerer && s.reconnectGracePeriod >= 0 {
+		s.reconnectCh <- p
+		return
+	}
+
+	p.Log.Debug().Msg("Connection state is failed, closing connection")
+	p.Close()
+}
+
+func (s *Server) reconnectFailed(p *Peer, conn *PeerConnection) {
+	if !s.discardReconnectConn(p, conn) {
+		return
+	}
+
+	atomic.AddUint32(&s.failedReconnects, 1)
+
+	if s.webRtc.isDisconnected(p.GetConn()) {
+		p.Log.Info().Msg("reconnect failed, closing connection")
+		p.Close()
+
+		return
+	}
+
+	p.Log.Info().Msg("reconnect failed, but the connection recovered")
+}
+
+// reconnectCompleted replaces the peer connection once the connection negotiated by the reconnect is connected
+func (s *Server) reconnectCompleted(p *Peer, conn *PeerConnection) {
+	p.connMux.Lock()
+	if p.reconnectConn != conn {
+		p.connMux.Unlock()
+		return
+	}
+
+	replaced := p.Conn
+	p.Conn = conn
+	p.reconnectConn = nil
+	p.connMux.Unlock()
+
+	atomic.AddUint32(&s.reconnects, 1)
+	p.Log.Info().Msg("reconnect completed")
+
+	if err := s.webRtc.close(replaced); err != nil {
+		p.Log.Debug().Err(err).Msg("error closing replaced connection")
+	}
+}
+
+// startReconnect creates the connection replacing the peer one, the reconnect fails if it doesn't connect
+// before the establish session timeout
+func (s *Server) startReconnect(p *Peer) (*PeerConnection, error) {
+	if reconnectConn := p.getReconnectConn(); reconnectConn != nil {
+		s.discardReconnectConn(p, reconnectConn)
+	}
+
+	conn, err := s.webRtc.newConnection(p.Alias)
+	if err != nil {
+		return nil, err
+	}
+
+	p.connMux.Lock()
+	p.reconnectConn = conn
+	p.connMux.Unlock()
+
+	s.initConn(p, conn)
+
+	if s.onPeerReconnectHdlr != nil {
+		if err := s.onPeerReconnectHdlr(p, conn); err != nil {
+			s.reconnectFailed(p, conn)
+			return nil, err
+		}
+	}
+
+	go func() {
+		time.Sleep(s.establishSessionTimeout)
+
+		if p.getReconnectConn() == conn {
+			p.Log.Info().Msg("reconnect not connected after establish timeout")
+			s.reconnectFailed(p, conn)
+		}
+	}()
+
+	return conn, nil
+}
+
+// processReconnect offers a new connection to a disconnected peer through the coordinator, the peer keeps its
+// state (e.g. topics) and the connection replaces the disconnected one once connected
+func (s *Server) processReconnect(p *Peer) error {
+	if p.index == -1 || p.IsReconnecting() || p.IsClosed() {
+		return nil
+	}
+
+	p.Log.Info().Msg("reconnecting")
+
+	conn, err := s.startReconnect(p)
+	if err != nil {
+		p.Log.Error().Err(err).Msg("cannot create reconnect connection")
+		p.Close()
+
+		return err
+	}
+
+	offer, err := s.webRtc.createOffer(conn)
+	if err != nil {
+		p.Log.Error().Err(err).Msg("cannot create reconnect offer")
+		s.reconnectFailed(p, conn)
+
+		return err
+	}
+
+	serializedOffer, err := json.Marshal(offer)
+	if err != nil {
+		p.Log.Error().Err(err).Msg("cannot serialize reconnect offer")
+		s.reconnectFailed(p, conn)
+
+		return err
+	}
+
+	return s.getCoordinator().Send(&protocol.WebRtcMessage{
+		Type:       protocol.MessageType_WEBRTC_OFFER,
+		Data:       serializedOffer,
+		ToAlias:    p.Alias,
+		IceRestart: true,
+	})
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	pion "github.com/pion/webrtc/v2"
+
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func addReconnectingPeer(t *testing.T, s *Server, alias uint64) (*Peer, *PeerConnection) {
+	p := addPeer(s, alias)
+	p.Log = s.log
+	p.reconnectConn = newConnection(t)
+
+	return p, p.reconnectConn
+}
+
+func TestProcessReconnect(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		conn := newConnection(t)
+		offer := pion.SessionDescription{Type: pion.SDPTypeOffer, SDP: "sdp"}
+
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		p.Log = s.log
+
+		webRtc.
+			On("isClosed", p.Conn).Return(false).Once().
+			On("newConnection", uint64(1)).Return(conn, nil).Once().
+			On("createOffer", conn).Return(offer, nil).Once()
+
+		require.NoError(t, s.processReconnect(p))
+		require.True(t, p.IsReconnecting())
+		require.Equal(t, conn, p.getSignalingConn())
+
+		require.Len(t, s.coordinator.send, 1)
+
+		msg := protocol.WebRtcMessage{}
+		require.NoError(t, proto.Unmarshal(<-s.coordinator.send, &msg))
+		require.Equal(t, protocol.MessageType_WEBRTC_OFFER, msg.Type)
+		require.Equal(t, uint64(1), msg.ToAlias)
+		require.True(t, msg.IceRestart)
+
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("reconnect already pending", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p, _ := addReconnectingPeer(t, s, 1)
+
+		require.NoError(t, s.processReconnect(p))
+		require.Len(t, s.coordinator.send, 0)
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("connection error closes the peer", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		p.Log = s.log
+
+		webRtc.
+			On("isClosed", p.Conn).Return(false).Once().
+			On("newConnection", uint64(1)).Return(&PeerConnection{}, errors.New("cannot create connection")).Once().
+			On("close", p.Conn).Return(nil).Once()
+
+		require.Error(t, s.processReconnect(p))
+		require.Equal(t, p, <-s.unregisterCh)
+		require.Len(t, s.coordinator.send, 0)
+		webRtc.AssertExpectations(t)
+	})
+}
+
+func TestReconnectCompleted(t *testing.T) {
+	webRtc := &mockWebRtc{}
+	s, err := NewServer(&Config{WebRtc: webRtc})
+	require.NoError(t, err)
+
+	p, conn := addReconnectingPeer(t, s, 1)
+	replaced := p.Conn
+
+	webRtc.On("close", replaced).Return(nil).Once()
+
+	s.reconnectCompleted(p, newConnection(t))
+	require.Equal(t, replaced, p.GetConn())
+	require.True(t, p.IsReconnecting())
+
+	s.reconnectCompleted(p, conn)
+	require.Equal(t, conn, p.GetConn())
+	require.False(t, p.IsReconnecting())
+	require.Equal(t, uint32(1), s.reconnects)
+	webRtc.AssertExpectations(t)
+}
+
+func TestReconnectFailed(t *testing.T) {
+	t.Run("replaced connection still disconnected", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p, conn := addReconnectingPeer(t, s, 1)
+
+		webRtc.
+			On("close", conn).Return(nil).Once().
+			On("isDisconnected", p.Conn).Return(true).Once().
+			On("close", p.Conn).Return(nil).Once()
+
+		s.reconnectFailed(p, conn)
+		require.False(t, p.IsReconnecting())
+		require.Equal(t, p, <-s.unregisterCh)
+		require.Equal(t, uint32(1), s.failedReconnects)
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("replaced connection recovered", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p, conn := addReconnectingPeer(t, s, 1)
+		replaced := p.Conn
+
+		webRtc.
+			On("close", conn).Return(nil).Once().
+			On("isDisconnected", replaced).Return(false).Once()
+
+		s.reconnectFailed(p, conn)
+		require.False(t, p.IsReconnecting())
+		require.Equal(t, replaced, p.GetConn())
+		require.Len(t, s.unregisterCh, 0)
+		require.Equal(t, uint32(1), s.failedReconnects)
+		webRtc.AssertExpectations(t)
+	})
+}
+
+func TestPeerFailed(t *testing.T) {
+	t.Run("offerer reconnects", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		p.Log = s.log
+		p.offerer = true
+
+		s.peerFailed(p, p.Conn)
+		require.Equal(t, p, <-s.reconnectCh)
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("answerer closes the peer", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		p.Log = s.log
+
+		webRtc.On("close", p.Conn).Return(nil).Once()
+
+		s.peerFailed(p, p.Conn)
+		require.Equal(t, p, <-s.unregisterCh)
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("reconnect disabled closes the peer", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc, ReconnectGracePeriod: -1})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		p.Log = s.log
+		p.offerer = true
+
+		webRtc.On("close", p.Conn).Return(nil).Once()
+
+		s.peerFailed(p, p.Conn)
+		require.Equal(t, p, <-s.unregisterCh)
+		require.Len(t, s.reconnectCh, 0)
+		webRtc.AssertExpectations(t)
+	})
+}
+
+func TestPeerCloseConn(t *testing.T) {
+	webRtc := &mockWebRtc{}
+	s, err := NewServer(&Config{WebRtc: webRtc})
+	require.NoError(t, err)
+
+	p, conn := addReconnectingPeer(t, s, 1)
+
+	webRtc.On("close", p.Conn).Return(nil).Once()
+
+	// NOTE: errors on the replaced connection, or while it's being replaced, don't close the peer
+	p.CloseConn(p.Conn)
+	s.reconnectCompleted(p, conn)
+	p.CloseConn(&PeerConnection{})
+	require.Len(t, s.unregisterCh, 0)
+
+	webRtc.On("close", conn).Return(nil).Once()
+
+	p.CloseConn(conn)
+	require.Equal(t, p, <-s.unregisterCh)
+	webRtc.AssertExpectations(t)
+}
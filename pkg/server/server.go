@@ -2,19 +2,29 @@
 package server
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/internal/ws"
 	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
 
 	pion "github.com/pion/webrtc/v2"
 )
 
+var (
+	// ErrServerFull indicates that the server reached its max peers
+	ErrServerFull = errors.New("server full")
+	// ErrPeerAlreadyConnected indicates that a peer with the same alias is connected
+	ErrPeerAlreadyConnected = errors.New("peer already connected")
+)
+
 // Config represents the server config
 type Config struct {
 	Log                     *logging.Logger
@@ -25,8 +35,72 @@ type Config struct {
 	MaxPeers                uint16
 	WebRtcLogLevel          zerolog.Level
 
+	// CoordinatorTLSConfig is used when connecting to a wss coordinator url, see ws.ClientTLSConfig
+	CoordinatorTLSConfig *tls.Config
+
+	// Network configures the ICE networking of the peer connections, e.g. port range or NAT 1:1 IPs
+	Network NetworkConfig
+
+	// CertificateProvider provides the DTLS certificate of the peer connections, e.g. a FileCertificateProvider so
+	// the fingerprint doesn't change on restart. By default a certificate is generated on startup
+	CertificateProvider CertificateProvider
+
 	OnNewPeerHdlr          func(p *Peer) error
 	OnPeerDisconnectedHdlr func(p *Peer)
+
+	// ICETimeout is how long a new peer has to connect ICE, EstablishSessionTimeout by default, and DTLSTimeout
+	// how long it has to complete the DTLS handshake once ICE is connected, 10 seconds by default
+	ICETimeout  time.Duration
+	DTLSTimeout time.Duration
+
+	// OnPeerConnectedHdlr is called once the peer ICE and DTLS are connected, e.g. to watch the next phases of
+	// the session, such as the data channels or the authentication
+	OnPeerConnectedHdlr func(p *Peer)
+
+	// ReconnectGracePeriod is how long a disconnected peer has to recover before it's reconnected, 5 seconds by
+	// default, a negative period disables the reconnect and closes the disconnected peers
+	ReconnectGracePeriod time.Duration
+
+	// OnPeerReconnectHdlr is called with the connection that will replace the peer one when it's reconnected, before
+	// negotiating it, so the data channels can be created
+	OnPeerReconnectHdlr func(p *Peer, conn *PeerConnection) error
+
+	// OnRevokeHdlr is called when the coordinator revokes an identity until the given time
+	OnRevokeHdlr func(identity []byte, until time.Time)
+
+	// OnCoordinatorReconnectHdlr is called with the new welcome message every time the server reconnects to the
+	// coordinator after the connection dropped, see ExitOnCoordinatorClose
+	OnCoordinatorReconnectHdlr func(welcomeMessage *protocol.WelcomeMessage)
+}
+
+// CoordinatorState is the state of the server connection to the coordinator
+type CoordinatorState int
+
+const (
+	// CoordinatorDisconnected means the server never connected to the coordinator
+	CoordinatorDisconnected CoordinatorState = iota
+	// CoordinatorConnected means the server is registered in the coordinator
+	CoordinatorConnected
+	// CoordinatorReconnecting means the connection dropped and the server is trying to reconnect, new peers
+	// cannot reach the server meanwhile but the existing ones are kept
+	CoordinatorReconnecting
+	// CoordinatorClosed means the server was shut down
+	CoordinatorClosed
+)
+
+func (s CoordinatorState) String() string {
+	switch s {
+	case CoordinatorDisconnected:
+		return "disconnected"
+	case CoordinatorConnected:
+		return "connected"
+	case CoordinatorReconnecting:
+		return "reconnecting"
+	case CoordinatorClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
 }
 
 // Server ...
@@ -34,10 +108,12 @@ type Server struct {
 	Alias                   uint64
 	webRtc                  IWebRtc
 	log                     logging.Logger
-	coordinator             *coordinator
 	peers                   []*Peer
 	peersMux                sync.Mutex
-	connectCh               chan uint64
+	connectCh               chan *connectRequest
+	fallbackCh              chan *fallbackRequest
+	closed                  chan struct{}
+	httpOfferCh             chan *httpOfferRequest
 	webRtcControlCh         chan *protocol.WebRtcMessage
 	unregisterCh            chan *Peer
 	establishSessionTimeout time.Duration
@@ -45,6 +121,33 @@ type Server struct {
 
 	onNewPeerHdlr          func(p *Peer) error
 	onPeerDisconnectedHdlr func(p *Peer)
+	onRevokeHdlr           func(identity []byte, until time.Time)
+
+	iceTimeout          time.Duration
+	dtlsTimeout         time.Duration
+	onPeerConnectedHdlr func(p *Peer)
+	iceTimeouts         uint32
+	dtlsTimeouts        uint32
+
+	reconnectCh          chan *Peer
+	reconnectGracePeriod time.Duration
+	onPeerReconnectHdlr  func(p *Peer, conn *PeerConnection) error
+	reconnects           uint32
+	failedReconnects     uint32
+
+	httpSessions    map[string]*Peer
+	httpSessionsMux sync.Mutex
+	lastHTTPAlias   uint32
+
+	// coordinatorMux guards the coordinator connection, which is replaced on every reconnection, its state and
+	// the server alias
+	coordinatorMux             sync.RWMutex
+	coordinator                *coordinator
+	coordinatorState           CoordinatorState
+	coordinatorReconnects      uint32
+	coordinatorTLSConfig       *tls.Config
+	reconnectInitialPeriod     time.Duration
+	onCoordinatorReconnectHdlr func(welcomeMessage *protocol.WelcomeMessage)
 }
 
 // Peer represents a server connection
@@ -58,6 +161,24 @@ type Peer struct {
 
 	Conn *PeerConnection
 
+	// connMux guards Conn, which is replaced when a reconnect completes, and the connection replacing it
+	connMux       sync.RWMutex
+	reconnectConn *PeerConnection
+	// offerer is set for the peers this server offered the connection to, they are reconnected by it
+	offerer bool
+	// phase is the connection establishment phase, see watchSession
+	phase int32
+
+	// Fallback is set instead of Conn when the peer is connected through the websocket fallback transport
+	Fallback *FallbackConn
+
+	// ConnectTicket is the coordinator connection ticket, set for peers that requested the connection (and
+	// fallback peers that presented one)
+	ConnectTicket []byte
+
+	// httpSession is set for the peers signaled over HTTP instead of the coordinator, see AcceptHTTPOffer
+	httpSession string
+
 	candidatesMux     sync.Mutex
 	pendingCandidates []*ICECandidate
 
@@ -66,12 +187,31 @@ type Peer struct {
 
 // IsClosed is the underline webrtc connection is closed
 func (p *Peer) IsClosed() bool {
-	return p.webRtc.isClosed(p.Conn)
+	if p.Fallback != nil {
+		return p.Fallback.IsClosed()
+	}
+
+	return p.webRtc.isClosed(p.GetConn())
 }
 
 // Close ...
 func (p *Peer) Close() {
-	if err := p.webRtc.close(p.Conn); err != nil {
+	if p.Fallback != nil {
+		// NOTE: fallback peers are unregistered once the connection pumps exit
+		if err := p.Fallback.Close(); err != nil {
+			p.Log.Debug().Err(err).Msg("error closing fallback connection")
+		}
+
+		return
+	}
+
+	if reconnectConn := p.getReconnectConn(); reconnectConn != nil {
+		if err := p.webRtc.close(reconnectConn); err != nil {
+			p.Log.Debug().Err(err).Msg("error closing reconnect connection")
+		}
+	}
+
+	if err := p.webRtc.close(p.GetConn()); err != nil {
 		p.Log.Warn().Err(err).Msg("error closing connection")
 		return
 	}
@@ -79,6 +219,17 @@ func (p *Peer) Close() {
 	p.unregisterCh <- p
 }
 
+type fallbackRequest struct {
+	alias  uint64
+	ticket []byte
+	conn   ws.IWebsocket
+}
+
+type connectRequest struct {
+	alias  uint64
+	ticket []byte
+}
+
 func findPeer(peers []*Peer, alias uint64) *Peer {
 	for _, p := range peers {
 		if p.Alias == alias {
@@ -91,11 +242,30 @@ func findPeer(peers []*Peer, alias uint64) *Peer {
 
 // NewServer creates a new communication server
 func NewServer(config *Config) (*Server, error) {
+	if err := config.Network.validate(); err != nil {
+		return nil, err
+	}
+
 	establishSessionTimeout := config.EstablishSessionTimeout
 	if establishSessionTimeout.Seconds() == 0 {
 		establishSessionTimeout = 1 * time.Minute
 	}
 
+	iceTimeout := config.ICETimeout
+	if iceTimeout == 0 {
+		iceTimeout = establishSessionTimeout
+	}
+
+	dtlsTimeout := config.DTLSTimeout
+	if dtlsTimeout == 0 {
+		dtlsTimeout = defaultDTLSTimeout
+	}
+
+	reconnectGracePeriod := config.ReconnectGracePeriod
+	if reconnectGracePeriod == 0 {
+		reconnectGracePeriod = defaultReconnectGracePeriod
+	}
+
 	var log logging.Logger
 	if config.Log == nil {
 		log = logging.New()
@@ -104,26 +274,50 @@ func NewServer(config *Config) (*Server, error) {
 	}
 
 	server := &Server{
-		coordinator: &coordinator{
-			log:         log,
-			send:        make(chan []byte, 256),
-			exitOnClose: config.ExitOnCoordinatorClose,
-		},
+		coordinator:             newCoordinator(log, config.ExitOnCoordinatorClose, config.CoordinatorTLSConfig),
 		peers:                   make([]*Peer, 0),
 		unregisterCh:            make(chan *Peer, 255),
-		connectCh:               make(chan uint64, 255),
+		connectCh:               make(chan *connectRequest, 255),
+		fallbackCh:              make(chan *fallbackRequest, 255),
+		closed:                  make(chan struct{}),
+		httpOfferCh:             make(chan *httpOfferRequest, 255),
 		webRtcControlCh:         make(chan *protocol.WebRtcMessage, 255),
 		establishSessionTimeout: establishSessionTimeout,
 		webRtc:                  config.WebRtc,
 		onNewPeerHdlr:           config.OnNewPeerHdlr,
 		onPeerDisconnectedHdlr:  config.OnPeerDisconnectedHdlr,
+		onRevokeHdlr:            config.OnRevokeHdlr,
 		maxPeers:                config.MaxPeers,
+
+		iceTimeout:          iceTimeout,
+		dtlsTimeout:         dtlsTimeout,
+		onPeerConnectedHdlr: config.OnPeerConnectedHdlr,
+
+		reconnectCh:          make(chan *Peer, 255),
+		reconnectGracePeriod: reconnectGracePeriod,
+		onPeerReconnectHdlr:  config.OnPeerReconnectHdlr,
+
+		httpSessions: make(map[string]*Peer),
+
+		coordinatorTLSConfig:       config.CoordinatorTLSConfig,
+		reconnectInitialPeriod:     reconnectInitialPeriod,
+		onCoordinatorReconnectHdlr: config.OnCoordinatorReconnectHdlr,
 	}
 
 	server.log = log
 
 	if server.webRtc == nil {
-		server.webRtc = &webRTC{ICEServers: config.ICEServers, LogLevel: config.WebRtcLogLevel}
+		certificates := config.CertificateProvider
+		if certificates == nil {
+			certificates = &generatedCertificateProvider{}
+		}
+
+		server.webRtc = &webRTC{
+			ICEServers:   config.ICEServers,
+			Network:      config.Network,
+			Certificates: certificates,
+			LogLevel:     config.WebRtcLogLevel,
+		}
 	}
 
 	return server, nil
@@ -131,22 +325,182 @@ func NewServer(config *Config) (*Server, error) {
 
 // ConnectCoordinator establish a connection with the coordinator
 func (s *Server) ConnectCoordinator(url string) (*protocol.WelcomeMessage, error) {
-	c := s.coordinator
-	if err := c.Connect(s, url); err != nil {
+	return s.ConnectCoordinatorFunc(func() (string, error) { return url, nil })
+}
+
+// ConnectCoordinatorFunc establish a connection with the coordinator, getURL is called on every connection
+// attempt, so time bound credentials are not reused. Unless ExitOnCoordinatorClose is set, the server reconnects
+// every time the connection drops, see OnCoordinatorReconnectHdlr
+func (s *Server) ConnectCoordinatorFunc(getURL func() (string, error)) (*protocol.WelcomeMessage, error) {
+	c := s.getCoordinator()
+	if err := c.Connect(s, getURL); err != nil {
 		return nil, err
 	}
 
-	welcomeChannel := make(chan *protocol.WelcomeMessage)
+	welcomeMessage, err := s.startCoordinator(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.exitOnClose {
+		go s.superviseCoordinator(getURL)
+	}
+
+	return welcomeMessage, nil
+}
+
+func (s *Server) getCoordinator() *coordinator {
+	s.coordinatorMux.RLock()
+	defer s.coordinatorMux.RUnlock()
+
+	return s.coordinator
+}
+
+// GetAlias returns the server alias, which may change when the server reconnects to the coordinator
+func (s *Server) GetAlias() uint64 {
+	s.coordinatorMux.RLock()
+	defer s.coordinatorMux.RUnlock()
+
+	return s.Alias
+}
+
+// GetCoordinatorState returns the state of the connection to the coordinator
+func (s *Server) GetCoordinatorState() CoordinatorState {
+	s.coordinatorMux.RLock()
+	defer s.coordinatorMux.RUnlock()
+
+	return s.coordinatorState
+}
+
+// setCoordinatorState fails once the server is shut down
+func (s *Server) setCoordinatorState(state CoordinatorState) bool {
+	s.coordinatorMux.Lock()
+	defer s.coordinatorMux.Unlock()
+
+	if s.coordinatorState == CoordinatorClosed {
+		return false
+	}
+
+	s.coordinatorState = state
+
+	return true
+}
+
+// startCoordinator starts the connection pumps and waits for the welcome message
+func (s *Server) startCoordinator(c *coordinator) (*protocol.WelcomeMessage, error) {
+	welcomeChannel := make(chan *protocol.WelcomeMessage, 1)
 
 	go c.readPump(s, welcomeChannel)
 
 	go c.writePump()
 
-	welcomeMessage := <-welcomeChannel
+	select {
+	case welcomeMessage := <-welcomeChannel:
+		// NOTE: the ICE servers handed out by the coordinator replace the configured ones, the TURN credentials
+		// are renewed on every reconnection
+		if len(welcomeMessage.IceServers) > 0 {
+			s.webRtc.setICEServers(ToICEServers(welcomeMessage.IceServers))
+		}
+
+		s.coordinatorMux.Lock()
+		s.Alias = welcomeMessage.Alias
+		if s.coordinatorState != CoordinatorClosed {
+			s.coordinatorState = CoordinatorConnected
+		}
+		s.coordinatorMux.Unlock()
 
-	s.Alias = welcomeMessage.Alias
+		return welcomeMessage, nil
+	case <-c.closedCh:
+		return nil, errCoordinatorClosed
+	}
+}
 
-	return welcomeMessage, nil
+// superviseCoordinator reconnects to the coordinator every time the connection drops, until the server is shut
+// down. The peers are kept, and the server asks the coordinator to keep its alias
+func (s *Server) superviseCoordinator(getURL func() (string, error)) {
+	for {
+		<-s.getCoordinator().closedCh
+
+		if !s.setCoordinatorState(CoordinatorReconnecting) {
+			return
+		}
+
+		s.log.Warn().Msg("coordinator connection lost, reconnecting")
+
+		welcomeMessage := s.reconnectCoordinator(getURL)
+		if welcomeMessage == nil {
+			return
+		}
+
+		s.coordinatorMux.Lock()
+		s.coordinatorReconnects++
+		s.coordinatorMux.Unlock()
+
+		s.log.Info().Uint64("alias", welcomeMessage.Alias).Msg("reconnected to coordinator")
+
+		if s.onCoordinatorReconnectHdlr != nil {
+			s.onCoordinatorReconnectHdlr(welcomeMessage)
+		}
+	}
+}
+
+// reconnectCoordinator retries with an exponential backoff until it succeeds, it returns nil if the server is shut
+// down meanwhile
+func (s *Server) reconnectCoordinator(getURL func() (string, error)) *protocol.WelcomeMessage {
+	retryPeriod := s.reconnectInitialPeriod
+
+	for {
+		if s.GetCoordinatorState() == CoordinatorClosed {
+			return nil
+		}
+
+		welcomeMessage, err := s.dialCoordinator(getURL)
+		if err == nil {
+			return welcomeMessage
+		}
+
+		if err == errCoordinatorClosed && s.GetCoordinatorState() == CoordinatorClosed {
+			return nil
+		}
+
+		s.log.Error().Err(err).Str("retry", retryPeriod.String()).Msg("cannot reconnect to coordinator")
+		time.Sleep(retryPeriod)
+
+		retryPeriod *= 2
+		if retryPeriod > reconnectMaxPeriod {
+			retryPeriod = reconnectMaxPeriod
+		}
+	}
+}
+
+func (s *Server) dialCoordinator(getURL func() (string, error)) (*protocol.WelcomeMessage, error) {
+	url, err := getURL()
+	if err != nil {
+		return nil, err
+	}
+
+	url, err = addAlias(url, s.GetAlias())
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCoordinator(s.log, false, s.coordinatorTLSConfig)
+	if err := c.dial(url); err != nil {
+		return nil, err
+	}
+
+	s.coordinatorMux.Lock()
+	if s.coordinatorState == CoordinatorClosed {
+		s.coordinatorMux.Unlock()
+		c.Close()
+
+		return nil, errCoordinatorClosed
+	}
+
+	s.coordinator = c
+	s.coordinatorMux.Unlock()
+
+	return s.startCoordinator(c)
 }
 
 // ConnectPeer initiates the peer connection, it will send a connect message thought the coordinator
@@ -157,7 +511,7 @@ func (s *Server) ConnectPeer(alias uint64) error {
 		ToAlias: alias,
 	}
 
-	if err := s.coordinator.Send(&connectMessage); err != nil {
+	if err := s.getCoordinator().Send(&connectMessage); err != nil {
 		return err
 	}
 
@@ -168,6 +522,39 @@ func (s *Server) ConnectPeer(alias uint64) error {
 	return nil
 }
 
+// AcceptFallbackPeer queues a peer connected through the websocket fallback transport, the connection
+// will be closed if the peer is rejected
+func (s *Server) AcceptFallbackPeer(alias uint64, conn ws.IWebsocket) {
+	s.AcceptFallbackPeerWithTicket(alias, nil, conn)
+}
+
+// AcceptFallbackPeerWithTicket is AcceptFallbackPeer with the coordinator connection ticket the peer presented,
+// which has to be verified for the alias before calling it. A peer with a ticket replaces the pending webrtc
+// connection of the same alias, if the peer gave up on ICE and fell back
+func (s *Server) AcceptFallbackPeerWithTicket(alias uint64, ticket []byte, conn ws.IWebsocket) {
+	if !s.isClosed() {
+		select {
+		case s.fallbackCh <- &fallbackRequest{alias: alias, ticket: ticket, conn: conn}:
+			return
+		case <-s.closed:
+		}
+	}
+
+	if err := conn.Close(); err != nil {
+		s.log.Debug().Err(err).Msg("error closing fallback connection")
+	}
+}
+
+// isClosed returns true once the server is shut down
+func (s *Server) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
 // ProcessControlMessages starts the control message processor
 func (s *Server) ProcessControlMessages() {
 	defer func() {
@@ -184,23 +571,23 @@ func (s *Server) ProcessControlMessages() {
 
 	for {
 		select {
-		case alias, ok := <-s.connectCh:
+		case req, ok := <-s.connectCh:
 			if !ok {
 				s.log.Info().Str("channel", "connect").Msg("channel close, exiting control loop")
 				return
 			}
 
-			ignoreError(s.processConnect(alias))
+			ignoreError(s.processConnect(req))
 
 			n := len(s.connectCh)
 			for i := 0; i < n; i++ {
-				alias, ok := <-s.connectCh
+				req, ok := <-s.connectCh
 				if !ok {
 					s.log.Info().Str("channel", "connect").Msg("channel close, exiting control loop")
 					return
 				}
 
-				ignoreError(s.processConnect(alias))
+				ignoreError(s.processConnect(req))
 			}
 		case p, ok := <-s.unregisterCh:
 			if !ok {
@@ -220,6 +607,22 @@ func (s *Server) ProcessControlMessages() {
 
 				ignoreError(s.processUnregister(p))
 			}
+		case req := <-s.fallbackCh:
+			ignoreError(s.processFallback(req))
+
+			n := len(s.fallbackCh)
+			for i := 0; i < n; i++ {
+				req = <-s.fallbackCh
+				ignoreError(s.processFallback(req))
+			}
+		case req := <-s.httpOfferCh:
+			ignoreError(s.processHTTPOffer(req))
+
+			n := len(s.httpOfferCh)
+			for i := 0; i < n; i++ {
+				req = <-s.httpOfferCh
+				ignoreError(s.processHTTPOffer(req))
+			}
 		case webRtcMessage, ok := <-s.webRtcControlCh:
 			if !ok {
 				s.log.Info().Str("channel", "webrtc").Msg("channel close, exiting control loop")
@@ -238,6 +641,11 @@ func (s *Server) ProcessControlMessages() {
 
 				ignoreError(s.processWebRtcControlMessage(webRtcMessage))
 			}
+		case p := <-s.reconnectCh:
+			ignoreError(s.processReconnect(p))
+		case <-s.closed:
+			s.log.Info().Msg("server closed, exiting control loop")
+			return
 		}
 	}
 }
@@ -245,10 +653,18 @@ func (s *Server) ProcessControlMessages() {
 // Shutdown ...
 // NOTE(hugo): we cannot close the unregisterCh because it's
 // shared with peers, we would need to wait for peers to be unnregistered first
+// NOTE: nor fallbackCh and httpOfferCh, fed by the HTTP handlers that may still be serving, they select on
+// closed instead
 func Shutdown(server *Server) {
-	server.coordinator.Close()
+	server.coordinatorMux.Lock()
+	server.coordinatorState = CoordinatorClosed
+	c := server.coordinator
+	server.coordinatorMux.Unlock()
+
+	c.Close()
 	close(server.webRtcControlCh)
 	close(server.connectCh)
+	close(server.closed)
 }
 
 func (s *Server) sendICECandidate(alias uint64, candidate *ICECandidate) {
@@ -260,7 +676,7 @@ func (s *Server) sendICECandidate(alias uint64, candidate *ICECandidate) {
 		return
 	}
 
-	err = s.coordinator.Send(&protocol.WebRtcMessage{
+	err = s.getCoordinator().Send(&protocol.WebRtcMessage{
 		Type:    protocol.MessageType_WEBRTC_ICE_CANDIDATE,
 		Data:    serializedCandidate,
 		ToAlias: alias,
@@ -271,31 +687,48 @@ func (s *Server) sendICECandidate(alias uint64, candidate *ICECandidate) {
 	}
 }
 
+// ReportLoad sends a load report to the coordinator, the peer count and the peer limit are filled in
+func (s *Server) ReportLoad(report *protocol.LoadReportMessage) error {
+	s.peersMux.Lock()
+	report.PeerCount = uint32(len(s.peers))
+	s.peersMux.Unlock()
+
+	report.Type = protocol.MessageType_LOAD_REPORT
+	report.MaxPeers = uint32(s.maxPeers)
+
+	return s.getCoordinator().Send(report)
+}
+
+func (s *Server) isFull() bool {
+	if s.maxPeers == 0 {
+		return false
+	}
+
+	s.peersMux.Lock()
+	size := len(s.peers)
+	s.peersMux.Unlock()
+
+	return uint16(size+1) > s.maxPeers
+}
+
 func (s *Server) initPeer(alias uint64) (*Peer, error) {
-	if s.maxPeers > 0 {
-		s.peersMux.Lock()
-		size := len(s.peers)
-		s.peersMux.Unlock()
-
-		if uint16(size+1) > s.maxPeers {
-			s.log.Info().Uint64("peer", alias).Msg("reject peer, the server is full")
-
-			refusedMessage := protocol.ConnectionRefusedMessage{
-				Type:    protocol.MessageType_CONNECTION_REFUSED,
-				ToAlias: alias,
-				Reason:  protocol.ConnectionRefusedReason_SERVER_FULL,
-			}
+	if s.isFull() {
+		s.log.Info().Uint64("peer", alias).Msg("reject peer, the server is full")
 
-			if err := s.coordinator.Send(&refusedMessage); err != nil {
-				s.log.Info().Uint64("peer", alias).Msg("cannot send refused connection message")
-			}
+		refusedMessage := protocol.ConnectionRefusedMessage{
+			Type:    protocol.MessageType_CONNECTION_REFUSED,
+			ToAlias: alias,
+			Reason:  protocol.ConnectionRefusedReason_SERVER_FULL,
+		}
 
-			return nil, errors.New("server full")
+		if err := s.getCoordinator().Send(&refusedMessage); err != nil {
+			s.log.Info().Uint64("peer", alias).Msg("cannot send refused connection message")
 		}
+
+		return nil, ErrServerFull
 	}
 
-	establishSessionTimeout := s.establishSessionTimeout
-	s.log.Debug().Uint64("serverAlias", s.Alias).Uint64("peer", alias).Msg("init peer")
+	s.log.Debug().Uint64("serverAlias", s.GetAlias()).Uint64("peer", alias).Msg("init peer")
 
 	conn, err := s.webRtc.newConnection(alias)
 	if err != nil {
@@ -303,50 +736,90 @@ func (s *Server) initPeer(alias uint64) (*Peer, error) {
 		return nil, err
 	}
 
+	return s.addPeer(alias, conn, "")
+}
+
+// addPeer registers the peer of a new connection, httpSession is only set for the peers signaled over HTTP
+func (s *Server) addPeer(alias uint64, conn *PeerConnection, httpSession string) (*Peer, error) {
 	p := &Peer{
 		webRtc:       s.webRtc,
 		Alias:        alias,
 		index:        len(s.peers),
 		Conn:         conn,
+		httpSession:  httpSession,
 		unregisterCh: s.unregisterCh,
-		Log:          s.log.With().Uint64("serverAlias", s.Alias).Uint64("peer", alias).Logger(),
+		Log:          s.log.With().Uint64("serverAlias", s.GetAlias()).Uint64("peer", alias).Logger(),
 	}
 
-	conn.OnICECandidate(func(candidate *ICECandidate) {
-		if candidate == nil {
-			s.log.Debug().Uint64("peer", alias).Msg("finish collecting candidates")
-			return
+	s.initConn(p, conn)
+
+	if s.onNewPeerHdlr != nil {
+		if err := s.onNewPeerHdlr(p); err != nil {
+			return nil, err
 		}
+	}
 
-		p.candidatesMux.Lock()
-		defer p.candidatesMux.Unlock()
+	s.peersMux.Lock()
+	s.peers = append(s.peers, p)
+	s.peersMux.Unlock()
 
-		desc := conn.RemoteDescription()
-		if desc == nil {
-			p.pendingCandidates = append(p.pendingCandidates, candidate)
-		} else {
-			s.sendICECandidate(alias, candidate)
+	s.watchSession(p, conn)
+
+	return p, nil
+}
+
+func (s *Server) processFallback(req *fallbackRequest) error {
+	alias := req.alias
+	log := s.log.With().Uint64("serverAlias", s.GetAlias()).Uint64("peer", alias).Logger()
+
+	reject := func(err error) error {
+		log.Info().Err(err).Msg("reject fallback peer")
+
+		if err := req.conn.Close(); err != nil {
+			log.Debug().Err(err).Msg("error closing fallback connection")
 		}
-	})
 
-	conn.OnICEConnectionStateChange(func(connectionState pion.ICEConnectionState) {
-		s.log.Debug().
-			Uint64("peer", alias).
-			Str("iceConnectionState", connectionState.String()).
-			Msg("ICE Connection State has changed")
-		if connectionState == pion.ICEConnectionStateDisconnected {
-			s.log.Debug().Uint64("peer", alias).Msg("Connection state is disconnected, closing connection")
-			p.Close()
-		} else if connectionState == pion.ICEConnectionStateFailed {
-			s.log.Debug().Uint64("peer", alias).Msg("Connection state is failed, closing connection")
-			p.Close()
+		return err
+	}
+
+	oldP := findPeer(s.peers, alias)
+	if oldP != nil && !oldP.IsClosed() {
+		// NOTE: a peer that gave up on ICE and fell back replaces its pending webrtc connection
+		if oldP.Fallback != nil || !s.webRtc.isNew(oldP.Conn) {
+			return reject(ErrPeerAlreadyConnected)
 		}
-	})
+
+		// NOTE: only a peer with a verified ticket for the alias can replace it, otherwise anyone guessing the
+		// alias could close the pending connection of another peer
+		if len(req.ticket) == 0 {
+			return reject(ErrPeerAlreadyConnected)
+		}
+
+		if err := s.webRtc.close(oldP.Conn); err != nil {
+			log.Debug().Err(err).Msg("error closing pending webrtc connection")
+		}
+
+		if err := s.processUnregister(oldP); err != nil {
+			return err
+		}
+	}
+
+	if s.isFull() {
+		return reject(ErrServerFull)
+	}
+
+	p := &Peer{
+		Alias:         alias,
+		index:         len(s.peers),
+		Fallback:      NewFallbackConn(req.conn, log),
+		ConnectTicket: req.ticket,
+		unregisterCh:  s.unregisterCh,
+		Log:           log,
+	}
 
 	if s.onNewPeerHdlr != nil {
-		err = s.onNewPeerHdlr(p)
-		if err != nil {
-			return nil, err
+		if err := s.onNewPeerHdlr(p); err != nil {
+			return reject(err)
 		}
 	}
 
@@ -354,17 +827,16 @@ func (s *Server) initPeer(alias uint64) (*Peer, error) {
 	s.peers = append(s.peers, p)
 	s.peersMux.Unlock()
 
-	go func() {
-		time.Sleep(establishSessionTimeout)
+	log.Debug().Msg("fallback peer registered")
 
-		if s.webRtc.isNew(conn) {
-			p.Log.Info().
-				Msg("ICEConnectionStateNew after establish timeout, closing connection and queued to unregister")
-			p.Close()
-		}
+	go func() {
+		<-p.Fallback.closed
+		p.unregisterCh <- p
 	}()
 
-	return p, nil
+	p.Fallback.Start()
+
+	return nil
 }
 
 func (s *Server) processUnregister(p *Peer) error {
@@ -393,6 +865,12 @@ func (s *Server) processUnregister(p *Peer) error {
 	p.index = -1
 	s.peersMux.Unlock()
 
+	if p.httpSession != "" {
+		s.httpSessionsMux.Lock()
+		delete(s.httpSessions, p.httpSession)
+		s.httpSessionsMux.Unlock()
+	}
+
 	if s.onPeerDisconnectedHdlr != nil {
 		s.onPeerDisconnectedHdlr(p)
 	}
@@ -400,7 +878,9 @@ func (s *Server) processUnregister(p *Peer) error {
 	return nil
 }
 
-func (s *Server) processConnect(alias uint64) error {
+func (s *Server) processConnect(req *connectRequest) error {
+	alias := req.alias
+
 	oldP := findPeer(s.peers, alias)
 	if oldP != nil && !oldP.IsClosed() {
 		if err := s.processUnregister(oldP); err != nil {
@@ -413,6 +893,9 @@ func (s *Server) processConnect(alias uint64) error {
 		return err
 	}
 
+	p.ConnectTicket = req.ticket
+	p.offerer = true
+
 	offer, err := s.webRtc.createOffer(p.Conn)
 	if err != nil {
 		s.log.Error().Err(err).Uint64("peer", alias).Msg("cannot create offer")
@@ -425,7 +908,7 @@ func (s *Server) processConnect(alias uint64) error {
 		return err
 	}
 
-	return s.coordinator.Send(&protocol.WebRtcMessage{
+	return s.getCoordinator().Send(&protocol.WebRtcMessage{
 		Type:    protocol.MessageType_WEBRTC_OFFER,
 		Data:    serializedOffer,
 		ToAlias: alias,
@@ -436,6 +919,13 @@ func (s *Server) processWebRtcControlMessage(webRtcMessage *protocol.WebRtcMessa
 	alias := webRtcMessage.FromAlias
 
 	p := findPeer(s.peers, alias)
+	if p != nil && p.Fallback != nil {
+		p.Log.Debug().Msg("ignoring webrtc message for fallback peer")
+		return nil
+	}
+
+	reconnect := webRtcMessage.Type == protocol.MessageType_WEBRTC_OFFER && webRtcMessage.IceRestart && p != nil
+
 	if p == nil {
 		var err error
 
@@ -447,7 +937,7 @@ func (s *Server) processWebRtcControlMessage(webRtcMessage *protocol.WebRtcMessa
 
 	switch webRtcMessage.Type {
 	case protocol.MessageType_WEBRTC_OFFER:
-		p.Log.Debug().Msg("webrtc offer received")
+		p.Log.Debug().Bool("reconnect", reconnect).Msg("webrtc offer received")
 
 		offer := pion.SessionDescription{}
 
@@ -456,7 +946,19 @@ func (s *Server) processWebRtcControlMessage(webRtcMessage *protocol.WebRtcMessa
 			return err
 		}
 
-		answer, err := s.webRtc.onOffer(p.Conn, offer)
+		conn := p.GetConn()
+
+		if reconnect {
+			var err error
+
+			conn, err = s.startReconnect(p)
+			if err != nil {
+				p.Log.Error().Err(err).Msg("cannot create reconnect connection")
+				return err
+			}
+		}
+
+		answer, err := s.webRtc.onOffer(conn, offer)
 		if err != nil {
 			p.Log.Error().Err(err).Msg("error setting webrtc offer")
 			return err
@@ -468,10 +970,11 @@ func (s *Server) processWebRtcControlMessage(webRtcMessage *protocol.WebRtcMessa
 			return err
 		}
 
-		return s.coordinator.Send(&protocol.WebRtcMessage{
-			Type:    protocol.MessageType_WEBRTC_ANSWER,
-			Data:    serializedAnswer,
-			ToAlias: p.Alias,
+		return s.getCoordinator().Send(&protocol.WebRtcMessage{
+			Type:       protocol.MessageType_WEBRTC_ANSWER,
+			Data:       serializedAnswer,
+			ToAlias:    p.Alias,
+			IceRestart: reconnect,
 		})
 	case protocol.MessageType_WEBRTC_ANSWER:
 		p.Log.Debug().Msg("webrtc answer received")
@@ -483,7 +986,7 @@ func (s *Server) processWebRtcControlMessage(webRtcMessage *protocol.WebRtcMessa
 		}
 
 		p.candidatesMux.Lock()
-		if err := s.webRtc.onAnswer(p.Conn, answer); err != nil {
+		if err := s.webRtc.onAnswer(p.getSignalingConn(), answer); err != nil {
 			p.Log.Error().Err(err).Msg("error settinng webrtc answer")
 			return err
 		}
@@ -501,7 +1004,7 @@ func (s *Server) processWebRtcControlMessage(webRtcMessage *protocol.WebRtcMessa
 			return err
 		}
 
-		if err := s.webRtc.onIceCandidate(p.Conn, candidate); err != nil {
+		if err := s.webRtc.onIceCandidate(p.getSignalingConn(), candidate); err != nil {
 			p.Log.Error().Err(err).Msg("error adding remote ice candidate")
 			return err
 		}
@@ -520,12 +1023,24 @@ type Stats struct {
 	WebRtcControlChSize int
 	UnregisterChSize    int
 	Peers               []PeerStats
+
+	CoordinatorState      CoordinatorState
+	CoordinatorReconnects uint32
+
+	// Reconnects counts the completed reconnects, FailedReconnects the ones that didn't connect
+	Reconnects       uint32
+	FailedReconnects uint32
+
+	// ICETimeouts and DTLSTimeouts count the peers closed because the phase didn't complete in time
+	ICETimeouts  uint32
+	DTLSTimeouts uint32
 }
 
 // PeerStats ...
 type PeerStats struct {
 	pion.StatsReport
-	Alias uint64
+	Alias    uint64
+	Fallback bool
 }
 
 // Get get stats by stats ID
@@ -536,24 +1051,42 @@ func (ps *PeerStats) Get(id string) (pion.Stats, bool) {
 
 // GetServerStats ...
 func (s *Server) GetServerStats() Stats {
+	s.coordinatorMux.RLock()
+	alias := s.Alias
+	coordinatorState := s.coordinatorState
+	coordinatorReconnects := s.coordinatorReconnects
+	s.coordinatorMux.RUnlock()
+
 	s.peersMux.Lock()
 	defer s.peersMux.Unlock()
 
 	serverStats := Stats{
-		Time:                time.Now(),
-		Alias:               s.Alias,
-		Peers:               make([]PeerStats, len(s.peers)),
-		ConnectChSize:       len(s.connectCh),
-		WebRtcControlChSize: len(s.webRtcControlCh),
-		UnregisterChSize:    len(s.unregisterCh),
+		Time:                  time.Now(),
+		Alias:                 alias,
+		Peers:                 make([]PeerStats, len(s.peers)),
+		ConnectChSize:         len(s.connectCh),
+		WebRtcControlChSize:   len(s.webRtcControlCh),
+		UnregisterChSize:      len(s.unregisterCh),
+		CoordinatorState:      coordinatorState,
+		CoordinatorReconnects: coordinatorReconnects,
+		Reconnects:            atomic.LoadUint32(&s.reconnects),
+		FailedReconnects:      atomic.LoadUint32(&s.failedReconnects),
+		ICETimeouts:           atomic.LoadUint32(&s.iceTimeouts),
+		DTLSTimeouts:          atomic.LoadUint32(&s.dtlsTimeouts),
 	}
 
 	for i, p := range s.peers {
-		report := p.Conn.GetStats()
 		stats := PeerStats{
-			StatsReport: report,
-			Alias:       p.Alias,
+			Alias:    p.Alias,
+			Fallback: p.Fallback != nil,
 		}
+
+		if p.Fallback == nil {
+			stats.StatsReport = p.GetConn().GetStats()
+		} else {
+			stats.StatsReport = pion.StatsReport{}
+		}
+
 		serverStats.Peers[i] = stats
 	}
 
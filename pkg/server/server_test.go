@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"time"
 
 	"testing"
@@ -13,6 +17,8 @@ import (
 
 	"github.com/decentraland/webrtc-broker/internal/logging"
 	_testing "github.com/decentraland/webrtc-broker/internal/testing"
+	"github.com/decentraland/webrtc-broker/pkg/authentication"
+	_coordinator "github.com/decentraland/webrtc-broker/pkg/coordinator"
 	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/require"
@@ -29,6 +35,11 @@ func (m *mockWebRtc) newConnection(peerAlias uint64) (*PeerConnection, error) {
 	return args.Get(0).(*PeerConnection), args.Error(1)
 }
 
+func (m *mockWebRtc) newHTTPConnection(peerAlias uint64) (*PeerConnection, error) {
+	args := m.Called(peerAlias)
+	return args.Get(0).(*PeerConnection), args.Error(1)
+}
+
 func (m *mockWebRtc) createOffer(conn *PeerConnection) (pion.SessionDescription, error) {
 	args := m.Called(conn)
 	return args.Get(0).(pion.SessionDescription), args.Error(1)
@@ -59,6 +70,11 @@ func (m *mockWebRtc) isNew(conn *PeerConnection) bool {
 	return args.Bool(0)
 }
 
+func (m *mockWebRtc) isDisconnected(conn *PeerConnection) bool {
+	args := m.Called(conn)
+	return args.Bool(0)
+}
+
 func (m *mockWebRtc) close(conn io.Closer) error {
 	args := m.Called(conn)
 	return args.Error(0)
@@ -69,6 +85,10 @@ func (m *mockWebRtc) getStats(conn *PeerConnection) pion.StatsReport {
 	return args.Get(0).(pion.StatsReport)
 }
 
+func (m *mockWebRtc) setICEServers(servers []ICEServer) {
+	m.Called(servers)
+}
+
 func addPeer(s *Server, alias uint64) *Peer {
 	p := &Peer{
 		Alias:        alias,
@@ -168,6 +188,7 @@ func TestCoordinatorReadPump(t *testing.T) {
 		msg := &protocol.ConnectMessage{
 			Type:      protocol.MessageType_CONNECT,
 			FromAlias: 2,
+			Ticket:    []byte("ticket"),
 		}
 		encodedMsg, err := proto.Marshal(msg)
 		require.NoError(t, err)
@@ -185,7 +206,10 @@ func TestCoordinatorReadPump(t *testing.T) {
 		<-s.coordinator.send
 
 		require.Len(t, s.connectCh, 1)
-		require.Equal(t, uint64(2), <-s.connectCh)
+
+		req := <-s.connectCh
+		require.Equal(t, uint64(2), req.alias)
+		require.Equal(t, []byte("ticket"), req.ticket)
 	})
 }
 
@@ -221,6 +245,69 @@ func newConnection(t *testing.T) *pion.PeerConnection {
 	return conn
 }
 
+func TestCoordinatorReconnect(t *testing.T) {
+	state := _coordinator.MakeState(&_coordinator.Config{
+		Auth: &authentication.NoopAuthenticator{},
+		ICE:  _coordinator.ICEConfig{TURNURLs: []string{"turn:turn.example.com"}, TURNSecret: "secret"},
+	})
+	go _coordinator.Start(state)
+
+	requestedAliases := make(chan string, 10)
+
+	coordinatorMux := http.NewServeMux()
+	_coordinator.Register(state, coordinatorMux)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/discover", func(w http.ResponseWriter, r *http.Request) {
+		requestedAliases <- r.URL.Query().Get("alias")
+		coordinatorMux.ServeHTTP(w, r)
+	})
+
+	coordinatorServer := httptest.NewServer(mux)
+	defer coordinatorServer.Close()
+
+	reconnected := make(chan *protocol.WelcomeMessage, 1)
+
+	s, err := NewServer(&Config{
+		OnCoordinatorReconnectHdlr: func(welcomeMessage *protocol.WelcomeMessage) {
+			reconnected <- welcomeMessage
+		},
+	})
+	require.NoError(t, err)
+
+	s.reconnectInitialPeriod = 10 * time.Millisecond
+
+	require.Equal(t, CoordinatorDisconnected, s.GetServerStats().CoordinatorState)
+
+	welcomeMessage, err := s.ConnectCoordinator(strings.Replace(coordinatorServer.URL, "http", "ws", 1) + "/discover")
+	require.NoError(t, err)
+	require.Equal(t, "", <-requestedAliases)
+	require.Equal(t, CoordinatorConnected, s.GetServerStats().CoordinatorState)
+
+	iceServers := s.webRtc.(*webRTC).getICEServers()
+	require.Len(t, iceServers, 1)
+	require.Equal(t, []string{"turn:turn.example.com"}, iceServers[0].URLs)
+	require.True(t, strings.HasSuffix(iceServers[0].Username, ":"+strconv.FormatUint(welcomeMessage.Alias, 10)))
+
+	require.NoError(t, s.getCoordinator().conn.Close())
+
+	alias := welcomeMessage.Alias
+
+	welcomeMessage = <-reconnected
+	require.Equal(t, strconv.FormatUint(alias, 10), <-requestedAliases)
+	require.Equal(t, welcomeMessage.Alias, s.GetAlias())
+	require.Equal(t, welcomeMessage.IceServers[0].Username, s.webRtc.(*webRTC).getICEServers()[0].Username)
+
+	stats := s.GetServerStats()
+	require.Equal(t, CoordinatorConnected, stats.CoordinatorState)
+	require.Equal(t, uint32(1), stats.CoordinatorReconnects)
+	require.NoError(t, s.ReportLoad(&protocol.LoadReportMessage{}))
+
+	Shutdown(s)
+	require.Equal(t, CoordinatorClosed, s.GetServerStats().CoordinatorState)
+	require.Error(t, s.ReportLoad(&protocol.LoadReportMessage{}))
+}
+
 func TestProcessConnect(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		conn1 := newConnection(t)
@@ -230,19 +317,19 @@ func TestProcessConnect(t *testing.T) {
 		webRtc := &mockWebRtc{}
 		webRtc.
 			On("newConnection", uint64(1)).Return(conn1, nil).Once().
-			On("isNew", conn1).Return(true).Maybe().
+			On("isClosed", conn1).Return(false).Maybe().
 			On("close", conn1).Return(nil).Maybe().
 			On("createOffer", conn1).Return(offer, nil).Once().
 			On("newConnection", uint64(2)).Return(conn2, nil).Once().
-			On("isNew", conn2).Return(true).Maybe().
+			On("isClosed", conn2).Return(false).Maybe().
 			On("close", conn2).Return(nil).Maybe().
 			On("createOffer", conn2).Return(offer, nil).Once()
 
 		s, err := NewServer(&Config{WebRtc: webRtc})
 		require.NoError(t, err)
 
-		s.connectCh <- 1
-		s.connectCh <- 2
+		s.connectCh <- &connectRequest{alias: 1}
+		s.connectCh <- &connectRequest{alias: 2}
 
 		close(s.connectCh)
 
@@ -262,14 +349,14 @@ func TestProcessConnect(t *testing.T) {
 		webRtc := &mockWebRtc{}
 		webRtc.
 			On("newConnection", uint64(1)).Return(conn, nil).
-			On("isNew", conn).Return(true).Maybe().
+			On("isClosed", conn).Return(false).Maybe().
 			On("close", conn).Return(nil).Maybe().
 			On("createOffer", conn).Return(pion.SessionDescription{}, errors.New("cannot create offer")).Once()
 
 		s, err := NewServer(&Config{WebRtc: webRtc})
 		require.NoError(t, err)
 
-		s.connectCh <- 1
+		s.connectCh <- &connectRequest{alias: 1}
 		close(s.connectCh)
 
 		s.ProcessControlMessages()
@@ -315,7 +402,7 @@ func TestProcessWebRtcMessage(t *testing.T) {
 		webRtc := &mockWebRtc{}
 		webRtc.
 			On("newConnection", uint64(1)).Return(conn, nil).Once().
-			On("isNew", conn).Return(true).Maybe().
+			On("isClosed", conn).Return(false).Maybe().
 			On("close", conn).Return(nil).
 			On("onOffer", conn, offer).Return(pion.SessionDescription{}, nil).Once()
 
@@ -477,7 +564,7 @@ func TestInitPeer(t *testing.T) {
 
 		webRtc.
 			On("newConnection", uint64(1)).Return(conn, nil).
-			On("isNew", conn).Return(true).
+			On("isClosed", conn).Return(false).
 			On("close", conn).Return(nil).
 			On("close", conn).Return(errors.New("already closed"))
 
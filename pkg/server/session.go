@@ -0,0 +1,58 @@
+package server
+
+import (
+	"sync/atomic"
+	"time"
+
+	pion "github.com/pion/webrtc/v2"
+)
+
+const defaultDTLSTimeout = 10 * time.Second
+
+// Establishment phases of a peer connection, the server watches the ICE and DTLS ones, the data channels and the
+// authentication are up to the OnPeerConnectedHdlr
+const (
+	phaseICE int32 = iota
+	phaseDTLS
+	phaseConnected
+)
+
+// watchSession closes the peer if ICE doesn't connect before the ICE timeout, whatever the ICE state is (e.g. still
+// checking), or DTLS before the DTLS timeout once ICE is connected. OnPeerConnectedHdlr is called when both are
+func (s *Server) watchSession(p *Peer, conn *PeerConnection) {
+	conn.OnConnectionStateChange(func(state pion.PeerConnectionState) {
+		if state != pion.PeerConnectionStateConnected || atomic.SwapInt32(&p.phase, phaseConnected) == phaseConnected {
+			return
+		}
+
+		p.Log.Debug().Msg("peer connected")
+
+		if s.onPeerConnectedHdlr != nil {
+			s.onPeerConnectedHdlr(p)
+		}
+	})
+
+	time.AfterFunc(s.iceTimeout, func() {
+		// NOTE: the peer stays in the ICE phase until its ICE is connected for the first time
+		if atomic.LoadInt32(&p.phase) == phaseICE && !p.IsClosed() {
+			p.Log.Info().Msg("ICE not connected after the ICE timeout, closing connection")
+			atomic.AddUint32(&s.iceTimeouts, 1)
+			p.Close()
+		}
+	})
+}
+
+// iceConnected starts the DTLS phase, the first time the peer connection ICE is connected
+func (s *Server) iceConnected(p *Peer) {
+	if !atomic.CompareAndSwapInt32(&p.phase, phaseICE, phaseDTLS) {
+		return
+	}
+
+	time.AfterFunc(s.dtlsTimeout, func() {
+		if atomic.LoadInt32(&p.phase) == phaseDTLS && !p.IsClosed() {
+			p.Log.Info().Msg("DTLS not connected after the DTLS timeout, closing connection")
+			atomic.AddUint32(&s.dtlsTimeouts, 1)
+			p.Close()
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTimeouts(t *testing.T) {
+	t.Run("ICE timeout", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		conn := newConnection(t)
+
+		webRtc.
+			On("newConnection", uint64(1)).Return(conn, nil).Once().
+			On("isClosed", conn).Return(false).Once().
+			On("close", conn).Return(nil).Once()
+
+		s, err := NewServer(&Config{WebRtc: webRtc, ICETimeout: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		_, err = s.initPeer(1)
+		require.NoError(t, err)
+
+		p := <-s.unregisterCh
+		require.Equal(t, uint64(1), p.Alias)
+		require.Equal(t, uint32(1), atomic.LoadUint32(&s.iceTimeouts))
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("ICE connected", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		conn := newConnection(t)
+
+		webRtc.On("newConnection", uint64(1)).Return(conn, nil).Once()
+
+		s, err := NewServer(&Config{WebRtc: webRtc, ICETimeout: 10 * time.Millisecond, DTLSTimeout: time.Minute})
+		require.NoError(t, err)
+
+		p, err := s.initPeer(1)
+		require.NoError(t, err)
+
+		s.iceConnected(p)
+
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, s.unregisterCh, 0)
+		require.Equal(t, uint32(0), atomic.LoadUint32(&s.iceTimeouts))
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("DTLS timeout", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc, DTLSTimeout: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		p.Log = s.log
+
+		webRtc.
+			On("isClosed", p.Conn).Return(false).Once().
+			On("close", p.Conn).Return(nil).Once()
+
+		s.iceConnected(p)
+		s.iceConnected(p)
+
+		require.Equal(t, p, <-s.unregisterCh)
+		require.Equal(t, uint32(1), atomic.LoadUint32(&s.dtlsTimeouts))
+		webRtc.AssertExpectations(t)
+	})
+
+	t.Run("DTLS connected", func(t *testing.T) {
+		webRtc := &mockWebRtc{}
+		s, err := NewServer(&Config{WebRtc: webRtc, DTLSTimeout: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		p := addPeer(s, 1)
+		p.Log = s.log
+
+		s.iceConnected(p)
+		atomic.StoreInt32(&p.phase, phaseConnected)
+
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, s.unregisterCh, 0)
+		require.Equal(t, uint32(0), atomic.LoadUint32(&s.dtlsTimeouts))
+		webRtc.AssertExpectations(t)
+	})
+}
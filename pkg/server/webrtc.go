@@ -1,15 +1,14 @@
 package server
 
 import (
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/decentraland/webrtc-broker/internal/logging"
+	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
 	"github.com/pion/datachannel"
 	pion "github.com/pion/webrtc/v2"
 )
@@ -41,49 +40,89 @@ type ICECandidate = pion.ICECandidate
 // IWebRtc is this module interface
 type IWebRtc interface {
 	newConnection(peerAlias uint64) (*PeerConnection, error)
+	newHTTPConnection(peerAlias uint64) (*PeerConnection, error)
 	createOffer(conn *PeerConnection) (pion.SessionDescription, error)
 	onAnswer(conn *PeerConnection, answer pion.SessionDescription) error
 	onOffer(conn *PeerConnection, offer pion.SessionDescription) (pion.SessionDescription, error)
 	onIceCandidate(conn *PeerConnection, candidate pion.ICECandidateInit) error
 	isClosed(conn *PeerConnection) bool
 	isNew(conn *PeerConnection) bool
+	isDisconnected(conn *PeerConnection) bool
 	close(conn io.Closer) error
 	getStats(conn *PeerConnection) pion.StatsReport
+	setICEServers(servers []ICEServer)
 }
 
 // WebRtc is our inmplemenation of IWebRtc
 type webRTC struct {
-	ICEServers  []ICEServer
-	certificate *pion.Certificate
-	LogLevel    zerolog.Level
+	ICEServers   []ICEServer
+	Network      NetworkConfig
+	Certificates CertificateProvider
+	LogLevel     zerolog.Level
+
+	iceServersMux sync.RWMutex
 }
 
-func (w *webRTC) getCertificates() ([]pion.Certificate, error) {
-	if w.certificate == nil ||
-		(!w.certificate.Expires().IsZero() && time.Now().After(w.certificate.Expires())) {
-		// dtls cert
-		sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-		if err != nil {
-			return nil, err
-		}
+// ToICEServers converts the ICE servers handed out by the coordinator
+func ToICEServers(iceServers []*protocol.IceServer) []ICEServer {
+	servers := make([]ICEServer, len(iceServers))
+	for i, s := range iceServers {
+		servers[i] = ICEServer{URLs: s.Urls, Username: s.Username}
 
-		w.certificate, err = pion.GenerateCertificate(sk)
-		if err != nil {
-			return nil, err
+		if s.Credential != "" {
+			servers[i].Credential = s.Credential
 		}
 	}
 
-	return []pion.Certificate{*w.certificate}, nil
+	return servers
+}
+
+// setICEServers replaces the ICE servers of the new connections, the existing ones are not affected
+func (w *webRTC) setICEServers(servers []ICEServer) {
+	w.iceServersMux.Lock()
+	defer w.iceServersMux.Unlock()
+
+	w.ICEServers = servers
+}
+
+func (w *webRTC) getICEServers() []ICEServer {
+	w.iceServersMux.RLock()
+	defer w.iceServersMux.RUnlock()
+
+	return w.ICEServers
+}
+
+func (w *webRTC) getCertificates() ([]pion.Certificate, error) {
+	cert, err := w.Certificates.GetCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	return []pion.Certificate{*cert}, nil
 }
 
 func (w *webRTC) newConnection(peerAlias uint64) (*PeerConnection, error) {
+	return w.newPeerConnection(peerAlias, true)
+}
+
+// newHTTPConnection creates a connection that gathers its candidates before answering, so the answer has all of
+// them, since peers signaled over HTTP cannot receive trickled candidates
+func (w *webRTC) newHTTPConnection(peerAlias uint64) (*PeerConnection, error) {
+	return w.newPeerConnection(peerAlias, false)
+}
+
+func (w *webRTC) newPeerConnection(peerAlias uint64, trickle bool) (*PeerConnection, error) {
 	s := pion.SettingEngine{}
 	s.SetCandidateSelectionTimeout(20 * time.Second)
 	s.SetHostAcceptanceMinWait(0)
 	s.SetSrflxAcceptanceMinWait(0)
 	s.SetPrflxAcceptanceMinWait(0)
 	s.SetRelayAcceptanceMinWait(5 * time.Second)
-	s.SetTrickle(true)
+	s.SetTrickle(trickle)
+
+	if err := w.Network.apply(&s); err != nil {
+		return nil, err
+	}
 
 	s.LoggerFactory = &logging.PionLoggingFactory{DefaultLogLevel: w.LogLevel, PeerAlias: peerAlias}
 	s.DetachDataChannels()
@@ -96,7 +135,7 @@ func (w *webRTC) newConnection(peerAlias uint64) (*PeerConnection, error) {
 	}
 
 	conn, err := api.NewPeerConnection(pion.Configuration{
-		ICEServers:   w.ICEServers,
+		ICEServers:   w.getICEServers(),
 		Certificates: certs,
 	})
 	if err != nil {
@@ -115,6 +154,11 @@ func (w *webRTC) isNew(conn *PeerConnection) bool {
 		conn.ICEConnectionState() == pion.ICEConnectionStateChecking
 }
 
+func (w *webRTC) isDisconnected(conn *PeerConnection) bool {
+	return conn.ICEConnectionState() == pion.ICEConnectionStateDisconnected ||
+		conn.ICEConnectionState() == pion.ICEConnectionStateFailed
+}
+
 func (w *webRTC) close(conn io.Closer) error {
 	return conn.Close()
 }
@@ -9,8 +9,6 @@ import (
 	"github.com/decentraland/webrtc-broker/pkg/authentication"
 	"github.com/decentraland/webrtc-broker/pkg/protocol"
 	"github.com/golang/protobuf/proto"
-
-	pion "github.com/pion/webrtc/v2"
 )
 
 // BotOptions ...
@@ -28,12 +26,7 @@ func StartBot(opts BotOptions) {
 	config := Config{
 		Auth:           &authentication.NoopAuthenticator{},
 		CoordinatorURL: opts.CoordinatorURL,
-		ICEServers: []pion.ICEServer{
-			{
-				URLs: []string{"stun:stun.l.google.com:19302"},
-			},
-		},
-		Log: log,
+		Log:            log,
 	}
 
 	if opts.TrackStats {
@@ -2,15 +2,21 @@ package simulation
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/decentraland/webrtc-broker/internal/logging"
+	"github.com/decentraland/webrtc-broker/internal/ws"
 	"github.com/decentraland/webrtc-broker/pkg/authentication"
 	protocol "github.com/decentraland/webrtc-broker/pkg/protocol"
+	"github.com/decentraland/webrtc-broker/pkg/server"
 	"github.com/golang/protobuf/proto"
 	"github.com/gorilla/websocket"
 	"github.com/pion/datachannel"
@@ -22,17 +28,25 @@ const (
 )
 
 type peerData struct {
-	Alias            uint64
-	AvailableServers []uint64
+	Alias             uint64
+	AvailableServers  []uint64
+	FallbackEndpoints []*protocol.FallbackEndpoint
 }
 
 // Config is the client config
 type Config struct {
+	// ICEServers are used if the coordinator doesn't hand out any, only host candidates are gathered without them
 	ICEServers        []pion.ICEServer
 	Auth              authentication.ClientAuthenticator
 	OnMessageReceived func(reliable bool, msgType protocol.MessageType, raw []byte)
 	CoordinatorURL    string
 	Log               logging.Logger
+
+	// TLSConfig is used for wss coordinator and fallback urls, see ws.ClientTLSConfig
+	TLSConfig *tls.Config
+
+	// ForceFallback skips webrtc and connects through the server websocket fallback, if available
+	ForceFallback bool
 }
 
 // Client represents a peer with role CLIENT
@@ -46,17 +60,26 @@ type Client struct {
 	StopUnreliableQueue chan bool
 	PeerData            chan peerData
 
+	auth                  authentication.ClientAuthenticator
 	coordinatorURL        string
+	tlsConfig             *tls.Config
 	coordinator           *websocket.Conn
-	conn                  *pion.PeerConnection
 	authMessage           chan []byte
 	coordinatorWriteQueue chan []byte
 
+	// connClosed stops the write pumps of conn, replacedConn is the connection being replaced by a reconnect
+	connMux        sync.Mutex
+	conn           *pion.PeerConnection
+	connClosed     chan struct{}
+	replacedConn   *pion.PeerConnection
+	replacedClosed chan struct{}
+
 	candidatesMux     sync.Mutex
 	pendingCandidates []*pion.ICECandidate
 
-	alias       uint64
-	serverAlias uint64
+	alias        uint64
+	serverAlias  uint64
+	fallbackURLs map[uint64]string
 
 	log logging.Logger
 }
@@ -71,7 +94,9 @@ func MakeClient(config *Config) *Client {
 	c := &Client{
 		iceServers:            config.ICEServers,
 		onMessageReceived:     config.OnMessageReceived,
+		auth:                  config.Auth,
 		coordinatorURL:        url,
+		tlsConfig:             config.TLSConfig,
 		authMessage:           make(chan []byte),
 		SendReliable:          make(chan []byte, 256),
 		SendUnreliable:        make(chan []byte, 256),
@@ -79,6 +104,7 @@ func MakeClient(config *Config) *Client {
 		StopUnreliableQueue:   make(chan bool),
 		PeerData:              make(chan peerData),
 		coordinatorWriteQueue: make(chan []byte, 256),
+		fallbackURLs:          make(map[uint64]string),
 		log:                   config.Log,
 	}
 
@@ -144,8 +170,37 @@ func (client *Client) signalCandidate(candidate *pion.ICECandidate) {
 	client.coordinatorWriteQueue <- bytes
 }
 
-// Connect connect to specified server
-func (client *Client) Connect(alias uint64, serverAlias uint64) error {
+func (client *Client) getConn() *pion.PeerConnection {
+	client.connMux.Lock()
+	defer client.connMux.Unlock()
+
+	return client.conn
+}
+
+// closeReplacedConn closes the connection replaced by a reconnect, once the new one is connected
+func (client *Client) closeReplacedConn() {
+	client.connMux.Lock()
+	conn := client.replacedConn
+	closed := client.replacedClosed
+	client.replacedConn = nil
+	client.replacedClosed = nil
+	client.connMux.Unlock()
+
+	if conn == nil {
+		return
+	}
+
+	close(closed)
+
+	if err := conn.Close(); err != nil {
+		client.log.Debug().Err(err).Msg("error closing replaced connection")
+	}
+}
+
+// newConnection creates a connection to the server, the data channels of a connection created by a reconnect
+// skip the authentication, the server keeps the peer authenticated
+func (client *Client) newConnection(alias uint64, serverAlias uint64, reconnect bool) (*pion.PeerConnection,
+	chan struct{}, error) {
 	s := pion.SettingEngine{}
 	s.DetachDataChannels()
 	s.SetTrickle(true)
@@ -156,21 +211,10 @@ func (client *Client) Connect(alias uint64, serverAlias uint64) error {
 
 	conn, err := api.NewPeerConnection(webRtcConfig)
 	if err != nil {
-		return err
-	}
-
-	client.conn = conn
-	client.alias = alias
-	client.serverAlias = serverAlias
-
-	msg := &protocol.ConnectMessage{Type: protocol.MessageType_CONNECT, ToAlias: serverAlias}
-
-	bytes, err := proto.Marshal(msg)
-	if err != nil {
-		client.log.Fatal().Err(err).Msg("cannot marshall connect message")
+		return nil, nil, err
 	}
 
-	client.coordinatorWriteQueue <- bytes
+	closed := make(chan struct{})
 
 	conn.OnICECandidate(func(candidate *pion.ICECandidate) {
 		if candidate == nil {
@@ -193,88 +237,32 @@ func (client *Client) Connect(alias uint64, serverAlias uint64) error {
 
 	conn.OnICEConnectionStateChange(func(connectionState pion.ICEConnectionState) {
 		client.log.Info().Str("state", connectionState.String()).Msg("ICE Connection State has changed")
-		if connectionState == pion.ICEConnectionStateDisconnected ||
-			connectionState == pion.ICEConnectionStateFailed {
-			if err := conn.Close(); err != nil {
-				client.log.Debug().Err(err).Msg("error closing on disconnect")
-			}
-		}
-	})
-
-	conn.OnDataChannel(func(d *pion.DataChannel) {
-		readPump := func(client *Client, c datachannel.Reader, reliable bool) {
-			header := protocol.MessageHeader{}
-			buffer := make([]byte, 1024)
-			for {
-				n, _, err := c.ReadDataChannel(buffer)
-				if err != nil {
-					client.log.Debug().Bool("reliable", reliable).Msg("stop readPump, datachannel closed")
-					return
-				}
-
-				if n == 0 {
-					continue
-				}
 
-				bytes := make([]byte, n)
-				copy(bytes, buffer[:n])
-
-				if err := proto.Unmarshal(bytes, &header); err != nil {
-					client.log.Error().Err(err).Msg("Failed to unmarshall message header")
-					continue
-				}
-
-				if client.onMessageReceived != nil {
-					client.onMessageReceived(reliable, header.Type, bytes)
-				}
-			}
+		if conn != client.getConn() {
+			return
 		}
 
-		writePump := func(client *Client, c datachannel.Writer, reliable bool) {
-			var messagesQueue chan []byte
-			var stopQueue chan bool
-			if reliable {
-				stopQueue = client.StopReliableQueue
-				messagesQueue = client.SendReliable
-				bytes := <-client.authMessage
-				_, err := c.WriteDataChannel(bytes, false)
-				if err != nil {
-					client.log.Error().Err(err).Msg("error writing auth message")
-					return
-				}
-			} else {
-				stopQueue = client.StopUnreliableQueue
-				messagesQueue = client.SendUnreliable
+		switch connectionState {
+		case pion.ICEConnectionStateConnected, pion.ICEConnectionStateCompleted:
+			client.closeReplacedConn()
+		case pion.ICEConnectionStateDisconnected:
+			client.log.Info().Msg("ICE disconnected, waiting for the server to reconnect")
+		case pion.ICEConnectionStateFailed:
+			if err := conn.Close(); err != nil {
+				client.log.Debug().Err(err).Msg("error closing on failure")
 			}
-			for {
-				select {
-				case bytes, ok := <-messagesQueue:
-					if !ok {
-						client.log.Debug().Msg("close write pump, channel closed")
-						return
-					}
-
-					if _, err := c.WriteDataChannel(bytes, false); err != nil {
-						client.log.Error().Err(err).Msg("error writing")
-						return
-					}
-
-					n := len(messagesQueue)
-					for i := 0; i < n; i++ {
-						bytes = <-messagesQueue
-						_, err := c.WriteDataChannel(bytes, false)
-						if err != nil {
-							client.log.Error().Err(err).Msg("error writing")
-							return
-						}
-					}
-				case <-stopQueue:
-					client.log.Debug().Msg("close write pump, stopQueue")
-					return
+
+			if url, ok := client.fallbackURLs[serverAlias]; ok {
+				client.log.Info().Msg("ICE failed, connecting through fallback")
+
+				if err := client.ConnectFallback(alias, serverAlias, url); err != nil {
+					client.log.Error().Err(err).Msg("cannot connect through fallback")
 				}
 			}
 		}
+	})
 
+	conn.OnDataChannel(func(d *pion.DataChannel) {
 		d.OnOpen(func() {
 			dd, err := d.Detach()
 			if err != nil {
@@ -288,11 +276,232 @@ func (client *Client) Connect(alias uint64, serverAlias uint64) error {
 			} else {
 				client.log.Info().Msg("Data channel open (unreliable)")
 			}
-			go readPump(client, dd, reliable)
-			go writePump(client, dd, reliable)
+			go client.readPump(dd, reliable)
+			go client.writePump(dd, reliable, reliable && !reconnect, closed)
 		})
 	})
 
+	return conn, closed, nil
+}
+
+// Connect connect to specified server
+func (client *Client) Connect(alias uint64, serverAlias uint64) error {
+	client.alias = alias
+	client.serverAlias = serverAlias
+
+	conn, closed, err := client.newConnection(alias, serverAlias, false)
+	if err != nil {
+		return err
+	}
+
+	client.connMux.Lock()
+	client.conn = conn
+	client.connClosed = closed
+	client.connMux.Unlock()
+
+	msg := &protocol.ConnectMessage{Type: protocol.MessageType_CONNECT, ToAlias: serverAlias}
+
+	bytes, err := proto.Marshal(msg)
+	if err != nil {
+		client.log.Fatal().Err(err).Msg("cannot marshall connect message")
+	}
+
+	client.coordinatorWriteQueue <- bytes
+
+	return nil
+}
+
+// reconnect replaces the connection with one negotiated by a reconnect offer, the replaced connection is
+// closed once the new one is connected
+func (client *Client) reconnect() (*pion.PeerConnection, error) {
+	conn, closed, err := client.newConnection(client.alias, client.serverAlias, true)
+	if err != nil {
+		return nil, err
+	}
+
+	client.connMux.Lock()
+	replacedConn := client.replacedConn
+	replacedClosed := client.replacedClosed
+	client.replacedConn = client.conn
+	client.replacedClosed = client.connClosed
+	client.conn = conn
+	client.connClosed = closed
+	client.connMux.Unlock()
+
+	if replacedConn != nil {
+		close(replacedClosed)
+
+		if err := replacedConn.Close(); err != nil {
+			client.log.Debug().Err(err).Msg("error closing replaced connection")
+		}
+	}
+
+	return conn, nil
+}
+
+func (client *Client) readPump(c datachannel.Reader, reliable bool) {
+	header := protocol.MessageHeader{}
+	buffer := make([]byte, 1024)
+
+	for {
+		n, _, err := c.ReadDataChannel(buffer)
+		if err != nil {
+			client.log.Debug().Bool("reliable", reliable).Msg("stop readPump, datachannel closed")
+			return
+		}
+
+		if n == 0 {
+			continue
+		}
+
+		bytes := make([]byte, n)
+		copy(bytes, buffer[:n])
+
+		if err := proto.Unmarshal(bytes, &header); err != nil {
+			client.log.Error().Err(err).Msg("Failed to unmarshall message header")
+			continue
+		}
+
+		if header.Type == protocol.MessageType_AUTH_REQUEST {
+			client.reauthenticate()
+		}
+
+		// NOTE: pings are answered here, they are not topic traffic
+		if header.Type == protocol.MessageType_PING {
+			client.echoPing(reliable, bytes)
+			continue
+		}
+
+		if client.onMessageReceived != nil {
+			client.onMessageReceived(reliable, header.Type, bytes)
+		}
+	}
+}
+
+// reauthenticate sends a new auth message, the server requests it before the client credentials expire
+func (client *Client) reauthenticate() {
+	authMessage, err := client.auth.GenerateClientAuthMessage()
+	if err != nil {
+		client.log.Error().Err(err).Msg("cannot create auth message")
+		return
+	}
+
+	bytes, err := proto.Marshal(authMessage)
+	if err != nil {
+		client.log.Error().Err(err).Msg("cannot encode auth message")
+		return
+	}
+
+	client.SendReliable <- bytes
+}
+
+// echoPing echoes the server pings, the server measures the connection quality with them
+func (client *Client) echoPing(reliable bool, bytes []byte) {
+	ping := protocol.PingMessage{}
+	if err := proto.Unmarshal(bytes, &ping); err != nil {
+		client.log.Error().Err(err).Msg("Failed to unmarshall ping message")
+		return
+	}
+
+	if ping.FromAlias == 0 {
+		return
+	}
+
+	if reliable {
+		client.SendReliable <- bytes
+	} else {
+		client.SendUnreliable <- bytes
+	}
+}
+
+// writePump writes the queued messages until the queue is stopped or the connection closed, a nil closed channel
+// never closes
+func (client *Client) writePump(c datachannel.Writer, reliable bool, authenticate bool, closed <-chan struct{}) {
+	var messagesQueue chan []byte
+
+	var stopQueue chan bool
+
+	if reliable {
+		stopQueue = client.StopReliableQueue
+		messagesQueue = client.SendReliable
+
+		if authenticate {
+			bytes := <-client.authMessage
+
+			_, err := c.WriteDataChannel(bytes, false)
+			if err != nil {
+				client.log.Error().Err(err).Msg("error writing auth message")
+				return
+			}
+		}
+	} else {
+		stopQueue = client.StopUnreliableQueue
+		messagesQueue = client.SendUnreliable
+	}
+
+	for {
+		select {
+		case bytes, ok := <-messagesQueue:
+			if !ok {
+				client.log.Debug().Msg("close write pump, channel closed")
+				return
+			}
+
+			if _, err := c.WriteDataChannel(bytes, false); err != nil {
+				client.log.Error().Err(err).Msg("error writing")
+				return
+			}
+
+			n := len(messagesQueue)
+			for i := 0; i < n; i++ {
+				bytes = <-messagesQueue
+
+				_, err := c.WriteDataChannel(bytes, false)
+				if err != nil {
+					client.log.Error().Err(err).Msg("error writing")
+					return
+				}
+			}
+		case <-stopQueue:
+			client.log.Debug().Msg("close write pump, stopQueue")
+			return
+		case <-closed:
+			client.log.Debug().Msg("close write pump, connection closed")
+			return
+		}
+	}
+}
+
+// ConnectFallback connects to the specified server through its websocket fallback endpoint, the url may
+// include the connection ticket query param
+func (client *Client) ConnectFallback(alias uint64, serverAlias uint64, fallbackURL string) error {
+	u, err := url.Parse(fallbackURL)
+	if err != nil {
+		return err
+	}
+
+	qs := u.Query()
+	qs.Set("alias", strconv.FormatUint(alias, 10))
+	u.RawQuery = qs.Encode()
+
+	conn, err := ws.DialWithOptions(u.String(), ws.DialOptions{TLSConfig: client.tlsConfig})
+	if err != nil {
+		return err
+	}
+
+	client.alias = alias
+	client.serverAlias = serverAlias
+
+	fallback := server.NewFallbackConn(conn, client.log)
+	fallback.Start()
+
+	client.log.Info().Msg("Fallback connection open")
+
+	go client.readPump(fallback.Reliable, true)
+	go client.writePump(fallback.Reliable, true, true, nil)
+	go client.readPump(fallback.Unreliable, false)
+	go client.writePump(fallback.Unreliable, false, false, nil)
+
 	return nil
 }
 
@@ -315,7 +524,15 @@ func Start(config *Config) *Client {
 		client.log.Fatal().Msg("no available servers")
 	}
 
-	if err := client.Connect(pData.Alias, pData.AvailableServers[0]); err != nil {
+	client.setFallbackEndpoints(pData.FallbackEndpoints)
+
+	serverAlias := pData.AvailableServers[0]
+
+	if url, ok := client.fallbackURLs[serverAlias]; ok && config.ForceFallback {
+		if err := client.ConnectFallback(pData.Alias, serverAlias, url); err != nil {
+			client.log.Fatal().Err(err)
+		}
+	} else if err := client.Connect(pData.Alias, serverAlias); err != nil {
 		client.log.Fatal().Err(err)
 	}
 
@@ -334,8 +551,55 @@ func Start(config *Config) *Client {
 	return client
 }
 
+func (client *Client) setFallbackEndpoints(endpoints []*protocol.FallbackEndpoint) {
+	for _, endpoint := range endpoints {
+		fallbackURL := endpoint.Url
+
+		if len(endpoint.Ticket) > 0 {
+			u, err := url.Parse(fallbackURL)
+			if err != nil {
+				client.log.Error().Err(err).Str("url", fallbackURL).Msg("invalid fallback url")
+				continue
+			}
+
+			qs := u.Query()
+			qs.Set("ticket", base64.RawURLEncoding.EncodeToString(endpoint.Ticket))
+			u.RawQuery = qs.Encode()
+			fallbackURL = u.String()
+		}
+
+		client.fallbackURLs[endpoint.Alias] = fallbackURL
+	}
+}
+
+// redirect connects to another server after the server refused the connection, the coordinator lists the
+// servers the client can connect to in the refusal
+func (client *Client) redirect(refusal *protocol.ConnectionRefusedMessage) error {
+	serverAlias := refusal.AvailableServers[0]
+
+	client.log.Info().
+		Uint64("from", refusal.FromAlias).
+		Uint64("to", serverAlias).
+		Msg("connection refused, connecting to another server")
+
+	if err := client.getConn().Close(); err != nil {
+		client.log.Debug().Err(err).Msg("error closing refused connection")
+	}
+
+	client.candidatesMux.Lock()
+	client.pendingCandidates = nil
+	client.candidatesMux.Unlock()
+
+	client.setFallbackEndpoints(refusal.FallbackEndpoints)
+
+	return client.Connect(client.alias, serverAlias)
+}
+
 func (client *Client) startCoordination() error {
-	c, _, err := websocket.DefaultDialer.Dial(client.coordinatorURL, nil)
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = client.tlsConfig
+
+	c, _, err := dialer.Dial(client.coordinatorURL, nil)
 	if err != nil {
 		return err
 	}
@@ -390,9 +654,14 @@ func (client *Client) startCoordination() error {
 				client.log.Fatal().Msg("no server available to connect")
 			}
 
+			if len(welcomeMessage.IceServers) > 0 {
+				client.iceServers = server.ToICEServers(welcomeMessage.IceServers)
+			}
+
 			client.PeerData <- peerData{
-				Alias:            welcomeMessage.Alias,
-				AvailableServers: welcomeMessage.AvailableServers,
+				Alias:             welcomeMessage.Alias,
+				AvailableServers:  welcomeMessage.AvailableServers,
+				FallbackEndpoints: welcomeMessage.FallbackEndpoints,
 			}
 		case protocol.MessageType_WEBRTC_OFFER:
 			webRtcMessage := &protocol.WebRtcMessage{}
@@ -408,11 +677,22 @@ func (client *Client) startCoordination() error {
 				client.log.Fatal().Err(err).Msg("error unmarshalling webrtc message")
 			}
 
-			if err := client.conn.SetRemoteDescription(offer); err != nil {
+			conn := client.getConn()
+
+			if webRtcMessage.IceRestart {
+				client.log.Info().Msg("reconnect offer received")
+
+				if conn, err = client.reconnect(); err != nil {
+					client.log.Error().Err(err).Msg("cannot create reconnect connection")
+					continue
+				}
+			}
+
+			if err := conn.SetRemoteDescription(offer); err != nil {
 				client.log.Fatal().Err(err).Msg("error setting remote description")
 			}
 
-			answer, err := client.conn.CreateAnswer(nil)
+			answer, err := conn.CreateAnswer(nil)
 			if err != nil {
 				client.log.Fatal().Err(err).Msg("error creating webrtc answer")
 			}
@@ -423,9 +703,10 @@ func (client *Client) startCoordination() error {
 			}
 
 			answerWebRtcMessage := &protocol.WebRtcMessage{
-				Type:    protocol.MessageType_WEBRTC_ANSWER,
-				Data:    serializedAnswer,
-				ToAlias: webRtcMessage.FromAlias,
+				Type:       protocol.MessageType_WEBRTC_ANSWER,
+				Data:       serializedAnswer,
+				ToAlias:    webRtcMessage.FromAlias,
+				IceRestart: webRtcMessage.IceRestart,
 			}
 
 			bytes, err := proto.Marshal(answerWebRtcMessage)
@@ -437,7 +718,7 @@ func (client *Client) startCoordination() error {
 
 			client.coordinatorWriteQueue <- bytes
 
-			if err = client.conn.SetLocalDescription(answer); err != nil {
+			if err = conn.SetLocalDescription(answer); err != nil {
 				client.log.Fatal().Err(err).Msg("error setting local description")
 			}
 
@@ -461,7 +742,7 @@ func (client *Client) startCoordination() error {
 				client.log.Fatal().Err(err).Msg("error unmarshalling candidate")
 			}
 
-			if err := client.conn.AddICECandidate(candidate); err != nil {
+			if err := client.getConn().AddICECandidate(candidate); err != nil {
 				client.log.Fatal().Err(err).Msg("error adding remote ice candidate")
 			}
 		case protocol.MessageType_CONNECTION_REFUSED:
@@ -472,6 +753,16 @@ func (client *Client) startCoordination() error {
 			}
 
 			client.log.Info().Str("reason", connectionRefusedMessage.Reason.String()).Msg("connectionRefused")
+
+			if len(connectionRefusedMessage.AvailableServers) > 0 {
+				if err := client.redirect(connectionRefusedMessage); err != nil {
+					client.log.Error().Err(err).Msg("cannot connect to another server")
+					return err
+				}
+
+				continue
+			}
+
 			client.log.Fatal().Msg("connectionRefused")
 		}
 	}